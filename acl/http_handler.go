@@ -0,0 +1,116 @@
+package acl
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/Peltoche/avro-gateway/auth"
+	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/Peltoche/avro-gateway/model"
+	"github.com/gorilla/mux"
+)
+
+// HTTPHandler exposing the admin API managing the ACL rules.
+type HTTPHandler struct {
+	usecase usecase
+	// admins is the list of applications allowed to manage the rules. No one
+	// can manage them if empty.
+	admins []string
+}
+
+type usecase interface {
+	CreateRule(ctx context.Context, cmd *CreateRuleCmd) (*model.ACLRule, error)
+	DeleteRule(ctx context.Context, ruleID string) error
+	ListRules(ctx context.Context) ([]model.ACLRule, error)
+}
+
+// NewHTTPHandler instantiate a new HTTPHandler.
+func NewHTTPHandler(usecase usecase, admins []string) *HTTPHandler {
+	return &HTTPHandler{
+		usecase: usecase,
+		admins:  admins,
+	}
+}
+
+// RegisterRoutes into the givem mux.Router.
+func (t *HTTPHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/acls", t.List).Methods("GET")
+	router.HandleFunc("/acls", t.Post).Methods("POST")
+	router.HandleFunc("/acls/{id}", t.Delete).Methods("DELETE")
+}
+
+// List /acls
+func (t *HTTPHandler) List(w http.ResponseWriter, r *http.Request) {
+	err := t.checkAdmin(r)
+	if err != nil {
+		internal.WriteErrorIntoResponse(w, err)
+		return
+	}
+
+	rules, err := t.usecase.ListRules(r.Context())
+	if err != nil {
+		internal.WriteErrorIntoResponse(w, err)
+		return
+	}
+
+	internal.WriteJSON(r.Context(), w, http.StatusOK, rules)
+}
+
+// Post /acls
+func (t *HTTPHandler) Post(w http.ResponseWriter, r *http.Request) {
+	type request struct {
+		Effect      model.ACLEffect `json:"effect"`
+		Application string          `json:"application"`
+		Topic       string          `json:"topic"`
+		Actions     []string        `json:"actions"`
+	}
+
+	err := t.checkAdmin(r)
+	if err != nil {
+		internal.WriteErrorIntoResponse(w, err)
+		return
+	}
+
+	var req request
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		internal.WriteErrorIntoResponse(w, internal.NewError(internal.InvalidJSONBody, err.Error()))
+		return
+	}
+
+	rule, err := t.usecase.CreateRule(r.Context(), &CreateRuleCmd{
+		Effect:      req.Effect,
+		Application: req.Application,
+		Topic:       req.Topic,
+		Actions:     req.Actions,
+	})
+	if err != nil {
+		internal.WriteErrorIntoResponse(w, err)
+		return
+	}
+
+	internal.WriteJSON(r.Context(), w, http.StatusCreated, rule)
+}
+
+// Delete /acls/{id}
+func (t *HTTPHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	err := t.checkAdmin(r)
+	if err != nil {
+		internal.WriteErrorIntoResponse(w, err)
+		return
+	}
+
+	err = t.usecase.DeleteRule(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		internal.WriteErrorIntoResponse(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// checkAdmin return an error if the caller is not one of the admins.
+func (t *HTTPHandler) checkAdmin(r *http.Request) error {
+	return auth.CheckAdmin(r.Context(), t.admins, "ACL admin API")
+}
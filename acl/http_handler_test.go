@@ -0,0 +1,218 @@
+package acl
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Peltoche/avro-gateway/auth"
+	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/Peltoche/avro-gateway/model"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func asAdmin(r *http.Request) *http.Request {
+	return r.WithContext(auth.WithPrincipal(context.Background(), &auth.Principal{Application: "admin-app", Method: auth.APIKeyMethod}))
+}
+
+func Test_HTTPHandler_Post_success(t *testing.T) {
+	usecaseMock := new(UsecaseMock)
+
+	handler := NewHTTPHandler(usecaseMock, []string{"admin-app"})
+
+	usecaseMock.On("CreateRule", &CreateRuleCmd{
+		Effect:      model.ACLAllow,
+		Application: "my-application",
+		Topic:       "orders.*",
+		Actions:     []string{"write"},
+	}).Return(&model.ACLRule{
+		ID:          "some-id",
+		Effect:      model.ACLAllow,
+		Application: "my-application",
+		Topic:       "orders.*",
+		Actions:     []string{"write"},
+	}, nil).Once()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "http://example.com/acls", strings.NewReader(`{
+		"effect": "allow",
+		"application": "my-application",
+		"topic": "orders.*",
+		"actions": ["write"]
+	}`))
+	r = asAdmin(r)
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	res := w.Result()
+	body, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+	assert.JSONEq(t, `{
+		"id": "some-id",
+		"effect": "allow",
+		"application": "my-application",
+		"topic": "orders.*",
+		"actions": ["write"]
+	}`, string(body))
+
+	usecaseMock.AssertExpectations(t)
+}
+
+func Test_HTTPHandler_Post_with_an_invalid_body_format(t *testing.T) {
+	usecaseMock := new(UsecaseMock)
+
+	handler := NewHTTPHandler(usecaseMock, []string{"admin-app"})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "http://example.com/acls", strings.NewReader(`invalid json`))
+	r = asAdmin(r)
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	res := w.Result()
+
+	assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+
+	usecaseMock.AssertExpectations(t)
+}
+
+func Test_HTTPHandler_List_success(t *testing.T) {
+	usecaseMock := new(UsecaseMock)
+
+	handler := NewHTTPHandler(usecaseMock, []string{"admin-app"})
+
+	usecaseMock.On("ListRules").Return([]model.ACLRule{
+		{ID: "some-id", Effect: model.ACLDeny, Application: "*", Topic: "payments"},
+	}, nil).Once()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com/acls", nil)
+	r = asAdmin(r)
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	res := w.Result()
+	body, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.JSONEq(t, `[{"id": "some-id", "effect": "deny", "application": "*", "topic": "payments"}]`, string(body))
+
+	usecaseMock.AssertExpectations(t)
+}
+
+func Test_HTTPHandler_List_with_a_non_admin_caller(t *testing.T) {
+	usecaseMock := new(UsecaseMock)
+
+	handler := NewHTTPHandler(usecaseMock, []string{"admin-app"})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com/acls", nil)
+	r = r.WithContext(auth.WithPrincipal(context.Background(), &auth.Principal{Application: "some-app", Method: auth.APIKeyMethod}))
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	res := w.Result()
+
+	assert.Equal(t, http.StatusForbidden, res.StatusCode)
+
+	usecaseMock.AssertExpectations(t)
+}
+
+func Test_HTTPHandler_List_with_an_anonymous_caller(t *testing.T) {
+	usecaseMock := new(UsecaseMock)
+
+	handler := NewHTTPHandler(usecaseMock, []string{"admin-app"})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com/acls", nil)
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	res := w.Result()
+
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+	usecaseMock.AssertExpectations(t)
+}
+
+func Test_HTTPHandler_Delete_success(t *testing.T) {
+	usecaseMock := new(UsecaseMock)
+
+	handler := NewHTTPHandler(usecaseMock, []string{"admin-app"})
+
+	usecaseMock.On("DeleteRule", "some-id").Return(nil).Once()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("DELETE", "http://example.com/acls/some-id", nil)
+	r = asAdmin(r)
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	res := w.Result()
+
+	assert.Equal(t, http.StatusNoContent, res.StatusCode)
+
+	usecaseMock.AssertExpectations(t)
+}
+
+func Test_HTTPHandler_Delete_not_found(t *testing.T) {
+	usecaseMock := new(UsecaseMock)
+
+	handler := NewHTTPHandler(usecaseMock, []string{"admin-app"})
+
+	usecaseMock.On("DeleteRule", "some-id").Return(internal.NewError(internal.NotFound, `acl rule "some-id" not found`)).Once()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("DELETE", "http://example.com/acls/some-id", nil)
+	r = asAdmin(r)
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	res := w.Result()
+
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	usecaseMock.AssertExpectations(t)
+}
+
+func Test_HTTPHandler_List_without_admins(t *testing.T) {
+	usecaseMock := new(UsecaseMock)
+
+	handler := NewHTTPHandler(usecaseMock, nil)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com/acls", nil)
+	r = asAdmin(r)
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	res := w.Result()
+
+	assert.Equal(t, http.StatusForbidden, res.StatusCode)
+
+	usecaseMock.AssertExpectations(t)
+}
@@ -0,0 +1,172 @@
+package acl
+
+import (
+	"context"
+	"path"
+
+	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/Peltoche/avro-gateway/model"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Usecase handling all the logic about the ACL rules.
+//
+// A topic and action pair governed by at least one rule is restricted: the
+// application needs a matching "allow" rule and no matching "deny" rule. The
+// pairs governed by no rule stay open to everyone.
+type Usecase struct {
+	storage Storage
+	// Set the uuid generation function as an attribute in order to be able to
+	// mock id.
+	generateUUID func() string
+}
+
+// Storage used to persist the ACL rules.
+type Storage interface {
+	RegisterNewACLRule(ctx context.Context, rule *model.ACLRule) error
+	GetACLRuleByID(ctx context.Context, ruleID string) (*model.ACLRule, error)
+	GetAllACLRules(ctx context.Context) ([]model.ACLRule, error)
+	DeleteACLRule(ctx context.Context, ruleID string) error
+}
+
+// NewUsecase instantiate a new Usecase.
+func NewUsecase(storage Storage) *Usecase {
+	return &Usecase{
+		storage: storage,
+		generateUUID: func() string {
+			return uuid.NewV4().String()
+		},
+	}
+}
+
+// CreateRuleCmd is the requests parameters for the CreateRule method.
+type CreateRuleCmd struct {
+	Effect      model.ACLEffect
+	Application string
+	Topic       string
+	Actions     []string
+}
+
+// CreateRule validate and save a new rule.
+func (t *Usecase) CreateRule(ctx context.Context, cmd *CreateRuleCmd) (*model.ACLRule, error) {
+	err := validateCreateRuleCmd(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	rule := model.ACLRule{
+		ID:          t.generateUUID(),
+		Effect:      cmd.Effect,
+		Application: cmd.Application,
+		Topic:       cmd.Topic,
+		Actions:     cmd.Actions,
+	}
+
+	err = t.storage.RegisterNewACLRule(ctx, &rule)
+	if err != nil {
+		return nil, internal.Wrap(err, "failed to save the acl rule")
+	}
+
+	return &rule, nil
+}
+
+// DeleteRule remove the rule matching the id.
+func (t *Usecase) DeleteRule(ctx context.Context, ruleID string) error {
+	rule, err := t.storage.GetACLRuleByID(ctx, ruleID)
+	if err != nil {
+		return internal.Wrap(err, "failed to retrieve the acl rule")
+	}
+
+	if rule == nil {
+		return internal.Errorf(internal.NotFound, "acl rule %q not found", ruleID)
+	}
+
+	err = t.storage.DeleteACLRule(ctx, ruleID)
+	if err != nil {
+		return internal.Wrap(err, "failed to delete the acl rule")
+	}
+
+	return nil
+}
+
+// ListRules return all the rules.
+func (t *Usecase) ListRules(ctx context.Context) ([]model.ACLRule, error) {
+	rules, err := t.storage.GetAllACLRules(ctx)
+	if err != nil {
+		return nil, internal.Wrap(err, "failed to retrieve the acl rules")
+	}
+
+	return rules, nil
+}
+
+// Authorize return a Forbidden error if the application is not allowed to do
+// the action on the topic.
+func (t *Usecase) Authorize(ctx context.Context, application string, topic string, action string) error {
+	rules, err := t.storage.GetAllACLRules(ctx)
+	if err != nil {
+		return internal.Wrap(err, "failed to retrieve the acl rules")
+	}
+
+	// Only the allow rules restrict a topic to their applications, a deny rule
+	// only deny its application.
+	governed := false
+	allowed := false
+	for _, rule := range rules {
+		if !rule.Governs(topic, action) {
+			continue
+		}
+
+		if rule.Effect == model.ACLAllow {
+			governed = true
+		}
+
+		if !rule.Matches(application, topic, action) {
+			continue
+		}
+
+		if rule.Effect == model.ACLDeny {
+			return internal.Errorf(internal.Forbidden, "the application %q is denied to %s on the topic %q by the rule %q", application, action, topic, rule.ID)
+		}
+
+		allowed = true
+	}
+
+	if governed && !allowed {
+		return internal.Errorf(internal.Forbidden, "the application %q is not allowed to %s on the topic %q", application, action, topic)
+	}
+
+	return nil
+}
+
+func validateCreateRuleCmd(cmd *CreateRuleCmd) error {
+	// Parse the "Effect" field.
+	if cmd.Effect == "" {
+		return internal.NewError(internal.ValidationError, `missing field "effect"`)
+	}
+	if cmd.Effect != model.ACLAllow && cmd.Effect != model.ACLDeny {
+		return internal.NewError(internal.ValidationError, `invalid input for field "effect"`)
+	}
+
+	// Parse the "Application" field.
+	if cmd.Application == "" {
+		return internal.NewError(internal.ValidationError, `missing field "application"`)
+	}
+
+	// Parse the "Topic" field.
+	if cmd.Topic == "" {
+		return internal.NewError(internal.ValidationError, `missing field "topic"`)
+	}
+	_, err := path.Match(cmd.Topic, "")
+	if err != nil {
+		return internal.NewError(internal.ValidationError, `invalid input for field "topic"`)
+	}
+
+	// Parse the "Actions" field.
+	for _, action := range cmd.Actions {
+		if action != "read" && action != "write" {
+			return internal.NewError(internal.ValidationError, `invalid input for field "actions"`)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package acl
+
+import (
+	"context"
+
+	"github.com/Peltoche/avro-gateway/model"
+	"github.com/stretchr/testify/mock"
+)
+
+// UsecaseMock is a mock implementation of acl.Usecase.
+type UsecaseMock struct {
+	mock.Mock
+}
+
+// CreateRule method mock.
+func (t *UsecaseMock) CreateRule(ctx context.Context, cmd *CreateRuleCmd) (*model.ACLRule, error) {
+	args := t.Called(cmd)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.ACLRule), args.Error(1)
+}
+
+// DeleteRule method mock.
+func (t *UsecaseMock) DeleteRule(ctx context.Context, ruleID string) error {
+	return t.Called(ruleID).Error(0)
+}
+
+// ListRules method mock.
+func (t *UsecaseMock) ListRules(ctx context.Context) ([]model.ACLRule, error) {
+	args := t.Called()
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]model.ACLRule), args.Error(1)
+}
+
+// Authorize method mock.
+func (t *UsecaseMock) Authorize(ctx context.Context, application string, topic string, action string) error {
+	return t.Called(application, topic, action).Error(0)
+}
@@ -0,0 +1,263 @@
+package acl
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Peltoche/avro-gateway/model"
+	"github.com/Peltoche/avro-gateway/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Usecase_CreateRule_success(t *testing.T) {
+	storageMock := new(storage.Mock)
+
+	usecase := NewUsecase(storageMock)
+	usecase.generateUUID = func() string { return "some-id" }
+
+	expected := &model.ACLRule{
+		ID:          "some-id",
+		Effect:      model.ACLAllow,
+		Application: "my-application",
+		Topic:       "orders.*",
+		Actions:     []string{"write"},
+	}
+
+	storageMock.On("RegisterNewACLRule", expected).Return(nil).Once()
+
+	rule, err := usecase.CreateRule(context.Background(), &CreateRuleCmd{
+		Effect:      model.ACLAllow,
+		Application: "my-application",
+		Topic:       "orders.*",
+		Actions:     []string{"write"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, rule)
+
+	storageMock.AssertExpectations(t)
+}
+
+func Test_Usecase_CreateRule_with_a_storage_error(t *testing.T) {
+	storageMock := new(storage.Mock)
+
+	usecase := NewUsecase(storageMock)
+	usecase.generateUUID = func() string { return "some-id" }
+
+	storageMock.On("RegisterNewACLRule", &model.ACLRule{
+		ID:          "some-id",
+		Effect:      model.ACLDeny,
+		Application: "*",
+		Topic:       "orders",
+	}).Return(errors.New("some-error")).Once()
+
+	rule, err := usecase.CreateRule(context.Background(), &CreateRuleCmd{
+		Effect:      model.ACLDeny,
+		Application: "*",
+		Topic:       "orders",
+	})
+
+	assert.Nil(t, rule)
+	assert.EqualError(t, err, "internal error: failed to save the acl rule: some-error")
+
+	storageMock.AssertExpectations(t)
+}
+
+func Test_Usecase_CreateRule_with_invalid_inputs(t *testing.T) {
+	tests := []struct {
+		Name  string
+		Cmd   CreateRuleCmd
+		Error string
+	}{
+		{
+			Name:  "missing effect",
+			Cmd:   CreateRuleCmd{Application: "*", Topic: "*"},
+			Error: `validation error: missing field "effect"`,
+		},
+		{
+			Name:  "invalid effect",
+			Cmd:   CreateRuleCmd{Effect: "foo", Application: "*", Topic: "*"},
+			Error: `validation error: invalid input for field "effect"`,
+		},
+		{
+			Name:  "missing application",
+			Cmd:   CreateRuleCmd{Effect: model.ACLAllow, Topic: "*"},
+			Error: `validation error: missing field "application"`,
+		},
+		{
+			Name:  "missing topic",
+			Cmd:   CreateRuleCmd{Effect: model.ACLAllow, Application: "*"},
+			Error: `validation error: missing field "topic"`,
+		},
+		{
+			Name:  "invalid topic pattern",
+			Cmd:   CreateRuleCmd{Effect: model.ACLAllow, Application: "*", Topic: "[a-"},
+			Error: `validation error: invalid input for field "topic"`,
+		},
+		{
+			Name:  "invalid action",
+			Cmd:   CreateRuleCmd{Effect: model.ACLAllow, Application: "*", Topic: "*", Actions: []string{"delete"}},
+			Error: `validation error: invalid input for field "actions"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			usecase := NewUsecase(new(storage.Mock))
+
+			rule, err := usecase.CreateRule(context.Background(), &test.Cmd)
+
+			assert.Nil(t, rule)
+			assert.EqualError(t, err, test.Error)
+		})
+	}
+}
+
+func Test_Usecase_DeleteRule_success(t *testing.T) {
+	storageMock := new(storage.Mock)
+
+	usecase := NewUsecase(storageMock)
+
+	storageMock.On("GetACLRuleByID", "some-id").Return(&model.ACLRule{ID: "some-id"}, nil).Once()
+	storageMock.On("DeleteACLRule", "some-id").Return(nil).Once()
+
+	err := usecase.DeleteRule(context.Background(), "some-id")
+
+	assert.NoError(t, err)
+
+	storageMock.AssertExpectations(t)
+}
+
+func Test_Usecase_DeleteRule_not_found(t *testing.T) {
+	storageMock := new(storage.Mock)
+
+	usecase := NewUsecase(storageMock)
+
+	storageMock.On("GetACLRuleByID", "some-id").Return(nil, nil).Once()
+
+	err := usecase.DeleteRule(context.Background(), "some-id")
+
+	assert.EqualError(t, err, `not found: acl rule "some-id" not found`)
+
+	storageMock.AssertExpectations(t)
+}
+
+func Test_Usecase_ListRules_success(t *testing.T) {
+	storageMock := new(storage.Mock)
+
+	usecase := NewUsecase(storageMock)
+
+	storageMock.On("GetAllACLRules").Return([]model.ACLRule{{ID: "some-id"}}, nil).Once()
+
+	rules, err := usecase.ListRules(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []model.ACLRule{{ID: "some-id"}}, rules)
+
+	storageMock.AssertExpectations(t)
+}
+
+func Test_Usecase_Authorize(t *testing.T) {
+	rules := []model.ACLRule{
+		{ID: "owner", Effect: model.ACLAllow, Application: "orders-api", Topic: "orders.*", Actions: []string{"write"}},
+		{ID: "readers", Effect: model.ACLAllow, Application: "*", Topic: "orders.*", Actions: []string{"read"}},
+		{ID: "blacklist", Effect: model.ACLDeny, Application: "legacy-app", Topic: "orders.*"},
+		{ID: "private", Effect: model.ACLAllow, Application: "billing", Topic: "payments"},
+		{ID: "lone-deny", Effect: model.ACLDeny, Application: "legacy-app", Topic: "users"},
+	}
+
+	tests := []struct {
+		Name        string
+		Application string
+		Topic       string
+		Action      string
+		Error       string
+	}{
+		{
+			Name:        "allowed owner",
+			Application: "orders-api",
+			Topic:       "orders.created",
+			Action:      "write",
+		},
+		{
+			Name:        "not the owner",
+			Application: "some-app",
+			Topic:       "orders.created",
+			Action:      "write",
+			Error:       `forbidden: the application "some-app" is not allowed to write on the topic "orders.created"`,
+		},
+		{
+			Name:        "allowed by the wildcard",
+			Application: "some-app",
+			Topic:       "orders.created",
+			Action:      "read",
+		},
+		{
+			Name:        "denied whatever the allow rules",
+			Application: "legacy-app",
+			Topic:       "orders.created",
+			Action:      "read",
+			Error:       `forbidden: the application "legacy-app" is denied to read on the topic "orders.created" by the rule "blacklist"`,
+		},
+		{
+			Name:        "restricted topic",
+			Application: "some-app",
+			Topic:       "payments",
+			Action:      "read",
+			Error:       `forbidden: the application "some-app" is not allowed to read on the topic "payments"`,
+		},
+		{
+			Name:        "not restricted by a lone deny rule",
+			Application: "some-app",
+			Topic:       "users",
+			Action:      "write",
+		},
+		{
+			Name:        "denied by a lone deny rule",
+			Application: "legacy-app",
+			Topic:       "users",
+			Action:      "write",
+			Error:       `forbidden: the application "legacy-app" is denied to write on the topic "users" by the rule "lone-deny"`,
+		},
+		{
+			Name:        "ungoverned topic",
+			Application: "some-app",
+			Topic:       "invoices",
+			Action:      "write",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			storageMock := new(storage.Mock)
+			usecase := NewUsecase(storageMock)
+
+			storageMock.On("GetAllACLRules").Return(rules, nil).Once()
+
+			err := usecase.Authorize(context.Background(), test.Application, test.Topic, test.Action)
+			if test.Error == "" {
+				require.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.Error)
+			}
+
+			storageMock.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_Usecase_Authorize_with_a_storage_error(t *testing.T) {
+	storageMock := new(storage.Mock)
+
+	usecase := NewUsecase(storageMock)
+
+	storageMock.On("GetAllACLRules").Return(nil, errors.New("some-error")).Once()
+
+	err := usecase.Authorize(context.Background(), "some-app", "some-topic", "read")
+
+	assert.EqualError(t, err, "internal error: failed to retrieve the acl rules: some-error")
+
+	storageMock.AssertExpectations(t)
+}
@@ -0,0 +1,102 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Peltoche/avro-gateway/auth"
+	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/Peltoche/avro-gateway/logging"
+	"github.com/Peltoche/avro-gateway/model"
+	"github.com/gorilla/mux"
+)
+
+// HTTPHandler exposing the audit log to the admins, as it contains the caller
+// identities and addresses.
+type HTTPHandler struct {
+	storage Finder
+	// admins is the list of applications allowed to read the audit log. No
+	// one can read it if empty.
+	admins []string
+}
+
+// Finder is a storage able to query the audit entries.
+type Finder interface {
+	FindAuditEntries(ctx context.Context, filter *model.AuditFilter) ([]model.AuditEntry, error)
+}
+
+// NewHTTPHandler instantiate a new HTTPHandler.
+func NewHTTPHandler(storage Finder, admins []string) *HTTPHandler {
+	return &HTTPHandler{
+		storage: storage,
+		admins:  admins,
+	}
+}
+
+// RegisterRoutes into the givem mux.Router.
+func (t *HTTPHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/audit", t.Get).Methods("GET")
+}
+
+// Get /audit?topic={topic}&from={RFC3339}&to={RFC3339}&limit={limit}
+func (t *HTTPHandler) Get(w http.ResponseWriter, r *http.Request) {
+	err := auth.CheckAdmin(r.Context(), t.admins, "audit API")
+	if err != nil {
+		internal.WriteErrorIntoResponse(w, err)
+		return
+	}
+
+	filter, err := parseFilter(r)
+	if err != nil {
+		internal.WriteErrorIntoResponse(w, err)
+		return
+	}
+
+	entries, err := t.storage.FindAuditEntries(r.Context(), filter)
+	if err != nil {
+		internal.WriteErrorIntoResponse(w, internal.Wrap(err, "failed to retrieve the audit entries"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(entries)
+	if err != nil {
+		logging.Warn(r.Context(), "failed to write the response", logging.Fields{"error": err})
+	}
+}
+
+func parseFilter(r *http.Request) (*model.AuditFilter, error) {
+	query := r.URL.Query()
+
+	filter := model.AuditFilter{
+		Topic: query.Get("topic"),
+	}
+
+	var err error
+	if rawFrom := query.Get("from"); rawFrom != "" {
+		filter.From, err = time.Parse(time.RFC3339, rawFrom)
+		if err != nil {
+			return nil, internal.NewError(internal.ValidationError, `invalid input for parameter "from"`)
+		}
+	}
+
+	if rawTo := query.Get("to"); rawTo != "" {
+		filter.To, err = time.Parse(time.RFC3339, rawTo)
+		if err != nil {
+			return nil, internal.NewError(internal.ValidationError, `invalid input for parameter "to"`)
+		}
+	}
+
+	if rawLimit := query.Get("limit"); rawLimit != "" {
+		filter.Limit, err = strconv.Atoi(rawLimit)
+		if err != nil || filter.Limit < 1 {
+			return nil, internal.NewError(internal.ValidationError, `invalid input for parameter "limit"`)
+		}
+	}
+
+	return &filter, nil
+}
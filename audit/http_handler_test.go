@@ -0,0 +1,117 @@
+package audit
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Peltoche/avro-gateway/auth"
+	"github.com/Peltoche/avro-gateway/model"
+	"github.com/Peltoche/avro-gateway/storage"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func asAdmin(r *http.Request) *http.Request {
+	return r.WithContext(auth.WithPrincipal(context.Background(), &auth.Principal{Application: "admin-app", Method: auth.APIKeyMethod}))
+}
+
+func Test_HTTPHandler_Get_success(t *testing.T) {
+	store := storage.NewInMemory()
+
+	other := someEntry
+	other.ID = "some-other-id"
+	other.Topic = "some-other-topic"
+
+	late := someEntry
+	late.ID = "some-late-id"
+	late.Time = someDate.Add(2 * time.Hour)
+
+	for _, entry := range []model.AuditEntry{someEntry, other, late} {
+		require.NoError(t, store.AppendAuditEntry(context.Background(), &entry))
+	}
+
+	handler := NewHTTPHandler(store, []string{"admin-app"})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com/audit?topic=some-topic&from=2019-02-03T09:00:00Z&to=2019-02-03T11:00:00Z", nil)
+	r = asAdmin(r)
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	res := w.Result()
+	body, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.JSONEq(t, "["+someEntryJSON+"]", string(body))
+}
+
+func Test_HTTPHandler_Get_with_invalid_parameters(t *testing.T) {
+	tests := []struct {
+		Query string
+		Error string
+	}{
+		{"from=yesterday", `invalid input for parameter \"from\"`},
+		{"to=tomorrow", `invalid input for parameter \"to\"`},
+		{"limit=0", `invalid input for parameter \"limit\"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Query, func(t *testing.T) {
+			handler := NewHTTPHandler(storage.NewInMemory(), []string{"admin-app"})
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "http://example.com/audit?"+test.Query, nil)
+			r = asAdmin(r)
+
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+			router.ServeHTTP(w, r)
+
+			res := w.Result()
+			body, err := ioutil.ReadAll(res.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+			assert.JSONEq(t, `{"kind": "validation error", "message": "`+test.Error+`"}`, string(body))
+		})
+	}
+}
+
+func Test_HTTPHandler_Get_with_a_non_admin_caller(t *testing.T) {
+	handler := NewHTTPHandler(storage.NewInMemory(), []string{"admin-app"})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com/audit", nil)
+	r = r.WithContext(auth.WithPrincipal(context.Background(), &auth.Principal{Application: "some-app", Method: auth.APIKeyMethod}))
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	res := w.Result()
+
+	assert.Equal(t, http.StatusForbidden, res.StatusCode)
+}
+
+func Test_HTTPHandler_Get_with_an_anonymous_caller(t *testing.T) {
+	handler := NewHTTPHandler(storage.NewInMemory(), []string{"admin-app"})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com/audit", nil)
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	res := w.Result()
+
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+}
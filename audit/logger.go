@@ -0,0 +1,77 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/Peltoche/avro-gateway/logging"
+	"github.com/Peltoche/avro-gateway/model"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Sink is a destination for the audit entries.
+type Sink interface {
+	Record(ctx context.Context, entry *model.AuditEntry) error
+}
+
+// Logger write each audit entry into all its sinks.
+type Logger struct {
+	sinks []Sink
+	// Set the time and uuid generation functions as attributes in order to be
+	// able to mock them.
+	now          func() time.Time
+	generateUUID func() string
+}
+
+// NewLogger instantiate a new Logger.
+func NewLogger(sinks ...Sink) *Logger {
+	return &Logger{
+		sinks: sinks,
+		now:   time.Now,
+		generateUUID: func() string {
+			return uuid.NewV4().String()
+		},
+	}
+}
+
+// Record set an unique ID and the time to the entry then write it into all
+// the sinks.
+//
+// A failing sink doesn't prevent the others to receive the entry. The errors
+// are only logged in order to never fail a request because of the audit.
+func (t *Logger) Record(ctx context.Context, entry model.AuditEntry) {
+	entry.ID = t.generateUUID()
+	entry.Time = t.now().UTC()
+
+	for _, sink := range t.sinks {
+		err := sink.Record(ctx, &entry)
+		if err != nil {
+			logging.Error(ctx, "failed to record the audit entry", logging.Fields{"audit_entry": entry.ID, "error": err})
+		}
+	}
+}
+
+// DecisionOf return the decision and the reasons corresponding to the result
+// of a request.
+func DecisionOf(err error) (model.AuditDecision, []string) {
+	if err == nil {
+		return model.AuditAccepted, nil
+	}
+
+	for _, kind := range []internal.ErrorKind{
+		internal.ValidationError,
+		internal.InvalidJSONBody,
+		internal.BadRequest,
+		internal.NotFound,
+		internal.Unauthorized,
+		internal.Forbidden,
+		internal.TooManyRequests,
+	} {
+		if internal.IsKind(kind, err) {
+			return model.AuditRefused, []string{err.Error()}
+		}
+	}
+
+	return model.AuditError, []string{err.Error()}
+}
@@ -0,0 +1,76 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/Peltoche/avro-gateway/model"
+	"github.com/Peltoche/avro-gateway/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var someDate = time.Date(2019, time.February, 3, 10, 0, 0, 0, time.UTC)
+
+type failingSink struct{}
+
+func (t *failingSink) Record(ctx context.Context, entry *model.AuditEntry) error {
+	return errors.New("some-error")
+}
+
+func Test_Logger_Record_success(t *testing.T) {
+	store := storage.NewInMemory()
+
+	logger := NewLogger(&failingSink{}, NewStorageSink(store))
+	logger.now = func() time.Time { return someDate }
+	logger.generateUUID = func() string { return "some-id" }
+
+	logger.Record(context.Background(), model.AuditEntry{
+		Topic:       "some-topic",
+		Application: "some-app",
+		Action:      "read",
+		Subject:     "some-subject",
+		Version:     "1",
+		Decision:    model.AuditAccepted,
+	})
+
+	entries, err := store.FindAuditEntries(context.Background(), &model.AuditFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []model.AuditEntry{{
+		ID:          "some-id",
+		Time:        someDate,
+		Topic:       "some-topic",
+		Application: "some-app",
+		Action:      "read",
+		Subject:     "some-subject",
+		Version:     "1",
+		Decision:    model.AuditAccepted,
+	}}, entries)
+}
+
+func Test_DecisionOf(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Err      error
+		Decision model.AuditDecision
+		Reasons  []string
+	}{
+		{"success", nil, model.AuditAccepted, nil},
+		{"validation error", internal.NewError(internal.ValidationError, "some-reason"), model.AuditRefused, []string{"validation error: some-reason"}},
+		{"bad request", internal.NewError(internal.BadRequest, "some-reason"), model.AuditRefused, []string{"bad request: some-reason"}},
+		{"remote error", internal.NewError(internal.RemoteError, "some-reason"), model.AuditError, []string{"remote error: some-reason"}},
+		{"unexpected error", errors.New("some-reason"), model.AuditError, []string{"some-reason"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			decision, reasons := DecisionOf(test.Err)
+
+			assert.Equal(t, test.Decision, decision)
+			assert.Equal(t, test.Reasons, reasons)
+		})
+	}
+}
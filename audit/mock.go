@@ -0,0 +1,18 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/Peltoche/avro-gateway/model"
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock implementation of an audit Logger.
+type Mock struct {
+	mock.Mock
+}
+
+// Record method mock.
+func (t *Mock) Record(ctx context.Context, entry model.AuditEntry) {
+	t.Called(entry)
+}
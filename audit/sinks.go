@@ -0,0 +1,81 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/Peltoche/avro-gateway/model"
+)
+
+// JSONSink write the entries as JSON lines.
+type JSONSink struct {
+	mutex   *sync.Mutex
+	encoder *json.Encoder
+	closer  io.Closer
+}
+
+// NewJSONSink instantiate a new JSONSink writing into w.
+func NewJSONSink(w io.Writer) *JSONSink {
+	return &JSONSink{
+		mutex:   new(sync.Mutex),
+		encoder: json.NewEncoder(w),
+	}
+}
+
+// OpenFileSink instantiate a new JSONSink appending the entries at the end of
+// the file at path.
+func OpenFileSink(path string) (*JSONSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, internal.Errorf(internal.InternalError, "failed to open the audit file: %s", err)
+	}
+
+	sink := NewJSONSink(file)
+	sink.closer = file
+
+	return sink, nil
+}
+
+// Record write the entry as a single JSON line.
+func (t *JSONSink) Record(ctx context.Context, entry *model.AuditEntry) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.encoder.Encode(entry)
+}
+
+// Close the underlying file if the sink has been created with OpenFileSink.
+func (t *JSONSink) Close() error {
+	if t.closer == nil {
+		return nil
+	}
+
+	return t.closer.Close()
+}
+
+// StorageSink append the entries to the audit table of a storage, making them
+// available for the queries.
+type StorageSink struct {
+	storage Appender
+}
+
+// Appender is a storage able to save the audit entries.
+type Appender interface {
+	AppendAuditEntry(ctx context.Context, entry *model.AuditEntry) error
+}
+
+// NewStorageSink instantiate a new StorageSink.
+func NewStorageSink(storage Appender) *StorageSink {
+	return &StorageSink{
+		storage: storage,
+	}
+}
+
+// Record append the entry into the storage.
+func (t *StorageSink) Record(ctx context.Context, entry *model.AuditEntry) error {
+	return t.storage.AppendAuditEntry(ctx, entry)
+}
@@ -0,0 +1,68 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/Peltoche/avro-gateway/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var someEntry = model.AuditEntry{
+	ID:          "some-id",
+	Time:        someDate,
+	RemoteAddr:  "192.0.2.1:1234",
+	Topic:       "some-topic",
+	Application: "some-app",
+	Action:      "write",
+	Subject:     "some-subject",
+	Version:     "1",
+	Decision:    model.AuditRefused,
+	Reasons:     []string{"some-reason"},
+}
+
+const someEntryJSON = `{"id":"some-id","time":"2019-02-03T10:00:00Z","remote_addr":"192.0.2.1:1234","topic":"some-topic","application":"some-app","action":"write","subject":"some-subject","version":"1","decision":"refused","reasons":["some-reason"]}
+`
+
+func Test_JSONSink_Record_success(t *testing.T) {
+	buf := new(bytes.Buffer)
+
+	sink := NewJSONSink(buf)
+
+	err := sink.Record(context.Background(), &someEntry)
+
+	require.NoError(t, err)
+	assert.Equal(t, someEntryJSON, buf.String())
+	assert.NoError(t, sink.Close())
+}
+
+func Test_OpenFileSink_append_to_the_file(t *testing.T) {
+	file, err := ioutil.TempFile("", "audit")
+	require.NoError(t, err)
+	defer os.Remove(file.Name())
+
+	_, err = file.WriteString(someEntryJSON)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	sink, err := OpenFileSink(file.Name())
+	require.NoError(t, err)
+
+	require.NoError(t, sink.Record(context.Background(), &someEntry))
+	require.NoError(t, sink.Close())
+
+	content, err := ioutil.ReadFile(file.Name())
+	require.NoError(t, err)
+	assert.Equal(t, someEntryJSON+someEntryJSON, string(content))
+}
+
+func Test_OpenFileSink_with_an_invalid_path(t *testing.T) {
+	sink, err := OpenFileSink("/some/unknown/path")
+
+	assert.Nil(t, sink)
+	assert.EqualError(t, err, "internal error: failed to open the audit file: open /some/unknown/path: no such file or directory")
+}
@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/Peltoche/avro-gateway/internal"
+)
+
+// APIKeyHeader is the header containing the API key.
+const APIKeyHeader = "X-API-Key"
+
+// APIKey associate the hash of a key to an application.
+type APIKey struct {
+	Application string `json:"application"`
+	// SHA256 is the hex encoded SHA-256 hash of the key. The keys themselves
+	// are never saved.
+	SHA256 string `json:"sha256"`
+}
+
+// APIKeyAuthenticator authenticate the callers with the static API keys
+// passed in the X-API-Key header.
+type APIKeyAuthenticator struct {
+	keys []apiKeyHash
+}
+
+type apiKeyHash struct {
+	application string
+	hash        []byte
+}
+
+// NewAPIKeyAuthenticator instantiate a new APIKeyAuthenticator.
+func NewAPIKeyAuthenticator(keys []APIKey) (*APIKeyAuthenticator, error) {
+	hashes := make([]apiKeyHash, len(keys))
+	for i, key := range keys {
+		if key.Application == "" {
+			return nil, internal.Errorf(internal.ValidationError, `api key %d: missing field "application"`, i)
+		}
+
+		hash, err := hex.DecodeString(key.SHA256)
+		if err != nil || len(hash) != sha256.Size {
+			return nil, internal.Errorf(internal.ValidationError, `api key %d: invalid input for field "sha256"`, i)
+		}
+
+		hashes[i] = apiKeyHash{application: key.Application, hash: hash}
+	}
+
+	return &APIKeyAuthenticator{
+		keys: hashes,
+	}, nil
+}
+
+// Authenticate the request with the X-API-Key header.
+func (t *APIKeyAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	key := strings.TrimSpace(r.Header.Get(APIKeyHeader))
+	if key == "" {
+		return nil, nil
+	}
+
+	hash := sha256.Sum256([]byte(key))
+
+	// Check all the keys in order to take the same time whatever the key.
+	var principal *Principal
+	for _, known := range t.keys {
+		if subtle.ConstantTimeCompare(hash[:], known.hash) == 1 {
+			principal = &Principal{Application: known.application, Method: APIKeyMethod}
+		}
+	}
+
+	if principal == nil {
+		return nil, internal.NewError(internal.Unauthorized, "invalid api key")
+	}
+
+	return principal, nil
+}
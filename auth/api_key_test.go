@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func hashKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(hash[:])
+}
+
+func Test_APIKeyAuthenticator_Authenticate_success(t *testing.T) {
+	authenticator, err := NewAPIKeyAuthenticator([]APIKey{
+		{Application: "some-other-app", SHA256: "0000000000000000000000000000000000000000000000000000000000000000"},
+		{Application: "some-app", SHA256: hashKey("some-secret-key")},
+	})
+	require.NoError(t, err)
+
+	r := httptest.NewRequest("POST", "http://example.com/schema", nil)
+	r.Header.Set("X-API-Key", "some-secret-key")
+
+	principal, err := authenticator.Authenticate(r)
+
+	require.NoError(t, err)
+	assert.Equal(t, &Principal{Application: "some-app", Method: APIKeyMethod}, principal)
+}
+
+func Test_APIKeyAuthenticator_Authenticate_without_key(t *testing.T) {
+	authenticator, err := NewAPIKeyAuthenticator([]APIKey{})
+	require.NoError(t, err)
+
+	r := httptest.NewRequest("POST", "http://example.com/schema", nil)
+
+	principal, err := authenticator.Authenticate(r)
+
+	assert.NoError(t, err)
+	assert.Nil(t, principal)
+}
+
+func Test_APIKeyAuthenticator_Authenticate_with_an_invalid_key(t *testing.T) {
+	authenticator, err := NewAPIKeyAuthenticator([]APIKey{
+		{Application: "some-app", SHA256: hashKey("some-secret-key")},
+	})
+	require.NoError(t, err)
+
+	r := httptest.NewRequest("POST", "http://example.com/schema", nil)
+	r.Header.Set("X-API-Key", "some-invalid-key")
+
+	principal, err := authenticator.Authenticate(r)
+
+	assert.Nil(t, principal)
+	assert.EqualError(t, err, "unauthorized: invalid api key")
+}
+
+func Test_NewAPIKeyAuthenticator_with_invalid_keys(t *testing.T) {
+	authenticator, err := NewAPIKeyAuthenticator([]APIKey{{SHA256: hashKey("foo")}})
+	assert.Nil(t, authenticator)
+	assert.EqualError(t, err, `validation error: api key 0: missing field "application"`)
+
+	authenticator, err = NewAPIKeyAuthenticator([]APIKey{{Application: "some-app", SHA256: "not-hex"}})
+	assert.Nil(t, authenticator)
+	assert.EqualError(t, err, `validation error: api key 0: invalid input for field "sha256"`)
+}
@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"crypto/x509"
+	"net/http"
+	"strings"
+
+	"github.com/Peltoche/avro-gateway/internal"
+)
+
+// CertificateConfig is the configuration of a CertificateAuthenticator.
+type CertificateConfig struct {
+	// Source is the certificate field containing the application name: "cn"
+	// for the subject common name (default), "dns", "uri" or "email" for the
+	// first subject alternative name of this type.
+	Source string `json:"source"`
+	// Prefix is removed from the value, the certificates without it are
+	// refused. For example "spiffe://mesh.local/sa/".
+	Prefix string `json:"prefix"`
+}
+
+// CertificateAuthenticator authenticate the callers with the client
+// certificate verified during the TLS handshake.
+type CertificateAuthenticator struct {
+	source string
+	prefix string
+}
+
+// NewCertificateAuthenticator instantiate a new CertificateAuthenticator.
+func NewCertificateAuthenticator(config *CertificateConfig) (*CertificateAuthenticator, error) {
+	source := config.Source
+	if source == "" {
+		source = "cn"
+	}
+
+	if source != "cn" && source != "dns" && source != "uri" && source != "email" {
+		return nil, internal.NewError(internal.ValidationError, `certificate: invalid input for field "source"`)
+	}
+
+	return &CertificateAuthenticator{
+		source: source,
+		prefix: config.Prefix,
+	}, nil
+}
+
+// Authenticate the request with the client certificate.
+func (t *CertificateAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return nil, nil
+	}
+
+	// A certificate is only sent without being verified if the server
+	// doesn't have any client CA.
+	if len(r.TLS.VerifiedChains) == 0 {
+		return nil, internal.NewError(internal.Unauthorized, "unverified client certificate")
+	}
+
+	value := t.extract(r.TLS.PeerCertificates[0])
+	if value == "" || !strings.HasPrefix(value, t.prefix) {
+		return nil, internal.Errorf(internal.Unauthorized, "no application found in the client certificate %s", t.source)
+	}
+
+	application := strings.TrimPrefix(value, t.prefix)
+	if application == "" {
+		return nil, internal.Errorf(internal.Unauthorized, "no application found in the client certificate %s", t.source)
+	}
+
+	return &Principal{Application: application, Method: CertificateMethod}, nil
+}
+
+func (t *CertificateAuthenticator) extract(cert *x509.Certificate) string {
+	switch t.source {
+	case "dns":
+		if len(cert.DNSNames) > 0 {
+			return cert.DNSNames[0]
+		}
+	case "uri":
+		if len(cert.URIs) > 0 {
+			return cert.URIs[0].String()
+		}
+	case "email":
+		if len(cert.EmailAddresses) > 0 {
+			return cert.EmailAddresses[0]
+		}
+	default:
+		return cert.Subject.CommonName
+	}
+
+	return ""
+}
@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCertificate() *x509.Certificate {
+	uri, _ := url.Parse("spiffe://mesh.local/sa/uri-app")
+
+	return &x509.Certificate{
+		Subject:        pkix.Name{CommonName: "cn-app"},
+		DNSNames:       []string{"dns-app.mesh.local"},
+		URIs:           []*url.URL{uri},
+		EmailAddresses: []string{"email-app@example.com"},
+	}
+}
+
+func Test_CertificateAuthenticator_Authenticate_success(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Config   CertificateConfig
+		Expected string
+	}{
+		{Name: "default", Config: CertificateConfig{}, Expected: "cn-app"},
+		{Name: "dns", Config: CertificateConfig{Source: "dns"}, Expected: "dns-app.mesh.local"},
+		{Name: "uri with prefix", Config: CertificateConfig{Source: "uri", Prefix: "spiffe://mesh.local/sa/"}, Expected: "uri-app"},
+		{Name: "email", Config: CertificateConfig{Source: "email"}, Expected: "email-app@example.com"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			authenticator, err := NewCertificateAuthenticator(&test.Config)
+			require.NoError(t, err)
+
+			cert := newTestCertificate()
+			r := httptest.NewRequest("GET", "https://example.com/", nil)
+			r.TLS = &tls.ConnectionState{
+				PeerCertificates: []*x509.Certificate{cert},
+				VerifiedChains:   [][]*x509.Certificate{{cert}},
+			}
+
+			principal, err := authenticator.Authenticate(r)
+
+			assert.NoError(t, err)
+			assert.Equal(t, &Principal{Application: test.Expected, Method: CertificateMethod}, principal)
+		})
+	}
+}
+
+func Test_CertificateAuthenticator_Authenticate_without_certificate(t *testing.T) {
+	authenticator, err := NewCertificateAuthenticator(&CertificateConfig{})
+	require.NoError(t, err)
+
+	r := httptest.NewRequest("GET", "https://example.com/", nil)
+	r.TLS = &tls.ConnectionState{}
+
+	principal, err := authenticator.Authenticate(r)
+
+	assert.NoError(t, err)
+	assert.Nil(t, principal)
+}
+
+func Test_CertificateAuthenticator_Authenticate_with_an_unverified_certificate(t *testing.T) {
+	authenticator, err := NewCertificateAuthenticator(&CertificateConfig{})
+	require.NoError(t, err)
+
+	r := httptest.NewRequest("GET", "https://example.com/", nil)
+	r.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{newTestCertificate()}}
+
+	principal, err := authenticator.Authenticate(r)
+
+	assert.Nil(t, principal)
+	assert.EqualError(t, err, "unauthorized: unverified client certificate")
+}
+
+func Test_CertificateAuthenticator_Authenticate_with_an_invalid_prefix(t *testing.T) {
+	authenticator, err := NewCertificateAuthenticator(&CertificateConfig{Source: "uri", Prefix: "spiffe://other.local/"})
+	require.NoError(t, err)
+
+	cert := newTestCertificate()
+	r := httptest.NewRequest("GET", "https://example.com/", nil)
+	r.TLS = &tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{cert},
+		VerifiedChains:   [][]*x509.Certificate{{cert}},
+	}
+
+	principal, err := authenticator.Authenticate(r)
+
+	assert.Nil(t, principal)
+	assert.EqualError(t, err, "unauthorized: no application found in the client certificate uri")
+}
+
+func Test_NewCertificateAuthenticator_with_an_invalid_source(t *testing.T) {
+	authenticator, err := NewCertificateAuthenticator(&CertificateConfig{Source: "foo"})
+
+	assert.Nil(t, authenticator)
+	assert.EqualError(t, err, `validation error: certificate: invalid input for field "source"`)
+}
@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/Peltoche/avro-gateway/internal"
+)
+
+// Config is the authentication configuration.
+type Config struct {
+	// Required refuse the anonymous requests.
+	Required    bool               `json:"required"`
+	APIKeys     []APIKey           `json:"api_keys"`
+	JWT         *JWTConfig         `json:"jwt"`
+	Certificate *CertificateConfig `json:"certificate"`
+}
+
+// LoadConfig read the authentication configuration from a JSON file.
+func LoadConfig(path string) (*Config, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, internal.Errorf(internal.InternalError, "failed to open the auth config: %s", err)
+	}
+	defer file.Close()
+
+	var config Config
+	err = json.NewDecoder(file).Decode(&config)
+	if err != nil {
+		return nil, internal.Errorf(internal.ValidationError, "failed to decode the auth config: %s", err)
+	}
+
+	return &config, nil
+}
+
+// Authenticators instantiate the authenticators enabled by the
+// configuration.
+func (t *Config) Authenticators() ([]Authenticator, error) {
+	authenticators := []Authenticator{}
+
+	if len(t.APIKeys) > 0 {
+		apiKeyAuthenticator, err := NewAPIKeyAuthenticator(t.APIKeys)
+		if err != nil {
+			return nil, err
+		}
+
+		authenticators = append(authenticators, apiKeyAuthenticator)
+	}
+
+	if t.JWT != nil {
+		jwtAuthenticator, err := NewJWTAuthenticator(t.JWT)
+		if err != nil {
+			return nil, err
+		}
+
+		authenticators = append(authenticators, jwtAuthenticator)
+	}
+
+	if t.Certificate != nil {
+		certificateAuthenticator, err := NewCertificateAuthenticator(t.Certificate)
+		if err != nil {
+			return nil, err
+		}
+
+		authenticators = append(authenticators, certificateAuthenticator)
+	}
+
+	return authenticators, nil
+}
@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_LoadConfig_success(t *testing.T) {
+	keys := newTestKeys(t)
+	defer os.Remove(keys.jwksPath)
+
+	file, err := ioutil.TempFile("", "auth")
+	require.NoError(t, err)
+	defer os.Remove(file.Name())
+
+	_, err = file.WriteString(`{
+		"required": true,
+		"api_keys": [{"application": "some-app", "sha256": "` + hashKey("some-secret-key") + `"}],
+		"jwt": {"jwks_file": "` + keys.jwksPath + `", "issuer": "some-issuer"}
+	}`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	config, err := LoadConfig(file.Name())
+	require.NoError(t, err)
+
+	assert.True(t, config.Required)
+	assert.Equal(t, &JWTConfig{JWKSFile: keys.jwksPath, Issuer: "some-issuer"}, config.JWT)
+
+	authenticators, err := config.Authenticators()
+	require.NoError(t, err)
+	require.Len(t, authenticators, 2)
+	assert.IsType(t, &APIKeyAuthenticator{}, authenticators[0])
+	assert.IsType(t, &JWTAuthenticator{}, authenticators[1])
+}
+
+func Test_LoadConfig_with_a_missing_file(t *testing.T) {
+	config, err := LoadConfig("/some/unknown/path")
+
+	assert.Nil(t, config)
+	assert.EqualError(t, err, "internal error: failed to open the auth config: open /some/unknown/path: no such file or directory")
+}
+
+func Test_Config_Authenticators_with_an_invalid_api_key(t *testing.T) {
+	config := Config{APIKeys: []APIKey{{Application: "some-app", SHA256: "foo"}}}
+
+	authenticators, err := config.Authenticators()
+
+	assert.Nil(t, authenticators)
+	assert.EqualError(t, err, `validation error: api key 0: invalid input for field "sha256"`)
+}
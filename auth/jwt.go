@@ -0,0 +1,353 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	// Register the hash functions used by the supported algorithms.
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Peltoche/avro-gateway/internal"
+)
+
+// JWTConfig is the configuration of a JWTAuthenticator.
+type JWTConfig struct {
+	// JWKSFile is the path to the JSON Web Key Set containing the public keys
+	// used to verify the tokens.
+	JWKSFile string `json:"jwks_file"`
+	// Issuer is the expected "iss" claim. Not checked if empty.
+	Issuer string `json:"issuer"`
+	// Audience is the expected "aud" claim. Not checked if empty.
+	Audience string `json:"audience"`
+	// ApplicationClaim is the claim containing the application name, "sub"
+	// by default.
+	ApplicationClaim string `json:"application_claim"`
+	// AllowMissingExpiration accept the tokens without "exp" claim, which
+	// never expire. They are refused by default.
+	AllowMissingExpiration bool `json:"allow_missing_expiration"`
+}
+
+// JWTAuthenticator authenticate the callers with the JWT bearer tokens passed
+// in the Authorization header.
+//
+// Only the asymmetric algorithms RS256, RS384, RS512, ES256, ES384 and ES512
+// are accepted.
+type JWTAuthenticator struct {
+	keys             map[string]crypto.PublicKey
+	issuer           string
+	audience         string
+	applicationClaim string
+	allowMissingExp  bool
+	// leeway is the tolerated clock skew for the time based claims.
+	leeway time.Duration
+	// Set the time function as an attribute in order to be able to mock it.
+	now func() time.Time
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// jwtAlgorithm is the hash and, for ECDSA, the curve of a JWS algorithm.
+type jwtAlgorithm struct {
+	hash crypto.Hash
+	// curve is nil for the RSA algorithms.
+	curve elliptic.Curve
+}
+
+var jwtAlgorithms = map[string]jwtAlgorithm{
+	"RS256": {hash: crypto.SHA256},
+	"RS384": {hash: crypto.SHA384},
+	"RS512": {hash: crypto.SHA512},
+	"ES256": {hash: crypto.SHA256, curve: elliptic.P256()},
+	"ES384": {hash: crypto.SHA384, curve: elliptic.P384()},
+	"ES512": {hash: crypto.SHA512, curve: elliptic.P521()},
+}
+
+// NewJWTAuthenticator instantiate a new JWTAuthenticator loading the keys from
+// the JWKS file.
+func NewJWTAuthenticator(config *JWTConfig) (*JWTAuthenticator, error) {
+	file, err := os.Open(config.JWKSFile)
+	if err != nil {
+		return nil, internal.Errorf(internal.InternalError, "failed to open the jwks file: %s", err)
+	}
+	defer file.Close()
+
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	err = json.NewDecoder(file).Decode(&jwks)
+	if err != nil {
+		return nil, internal.Errorf(internal.ValidationError, "failed to decode the jwks file: %s", err)
+	}
+
+	keys := map[string]crypto.PublicKey{}
+	for i, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		key, parseErr := parseJSONWebKey(&jwk)
+		if parseErr != nil {
+			return nil, internal.Wrapf(parseErr, "jwks key %d", i)
+		}
+
+		if _, taken := keys[jwk.Kid]; taken {
+			return nil, internal.Errorf(internal.ValidationError, "jwks key %d: duplicate kid %q", i, jwk.Kid)
+		}
+
+		keys[jwk.Kid] = key
+	}
+
+	applicationClaim := config.ApplicationClaim
+	if applicationClaim == "" {
+		applicationClaim = "sub"
+	}
+
+	return &JWTAuthenticator{
+		keys:             keys,
+		issuer:           config.Issuer,
+		audience:         config.Audience,
+		applicationClaim: applicationClaim,
+		allowMissingExp:  config.AllowMissingExpiration,
+		leeway:           30 * time.Second,
+		now:              time.Now,
+	}, nil
+}
+
+// Authenticate the request with the "Authorization: Bearer <token>" header.
+func (t *JWTAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	authorization := r.Header.Get("Authorization")
+	if !strings.HasPrefix(authorization, "Bearer ") {
+		return nil, nil
+	}
+
+	claims, err := t.verify(strings.TrimSpace(strings.TrimPrefix(authorization, "Bearer ")))
+	if err != nil {
+		return nil, internal.Wrap(err, "invalid bearer token")
+	}
+
+	application, _ := claims[t.applicationClaim].(string)
+	if application == "" {
+		return nil, internal.Errorf(internal.Unauthorized, "invalid bearer token: missing claim %q", t.applicationClaim)
+	}
+
+	return &Principal{Application: application, Method: JWTMethod}, nil
+}
+
+// verify check the token signature and the registered claims then return all
+// the claims.
+func (t *JWTAuthenticator) verify(token string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, internal.NewError(internal.Unauthorized, "malformed token")
+	}
+
+	var header jwtHeader
+	err := decodeSegment(parts[0], &header)
+	if err != nil {
+		return nil, internal.NewError(internal.Unauthorized, "malformed header")
+	}
+
+	alg, supported := jwtAlgorithms[header.Alg]
+	if !supported {
+		return nil, internal.Errorf(internal.Unauthorized, "unsupported algorithm %q", header.Alg)
+	}
+
+	key, found := t.keys[header.Kid]
+	if !found {
+		return nil, internal.Errorf(internal.Unauthorized, "unknown key %q", header.Kid)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, internal.NewError(internal.Unauthorized, "malformed signature")
+	}
+
+	hasher := alg.hash.New()
+	_, _ = hasher.Write([]byte(parts[0] + "." + parts[1]))
+	digest := hasher.Sum(nil)
+
+	if !verifySignature(alg, key, digest, signature) {
+		return nil, internal.NewError(internal.Unauthorized, "invalid signature")
+	}
+
+	claims := map[string]interface{}{}
+	err = decodeSegment(parts[1], &claims)
+	if err != nil {
+		return nil, internal.NewError(internal.Unauthorized, "malformed claims")
+	}
+
+	err = t.checkClaims(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func (t *JWTAuthenticator) checkClaims(claims map[string]interface{}) error {
+	now := t.now()
+
+	exp, ok := claims["exp"].(float64)
+	switch {
+	case !ok && !t.allowMissingExp:
+		return internal.NewError(internal.Unauthorized, `missing claim "exp"`)
+	case ok && now.Add(-t.leeway).After(time.Unix(int64(exp), 0)):
+		return internal.NewError(internal.Unauthorized, "token expired")
+	}
+
+	if nbf, ok := claims["nbf"].(float64); ok {
+		if now.Add(t.leeway).Before(time.Unix(int64(nbf), 0)) {
+			return internal.NewError(internal.Unauthorized, "token not valid yet")
+		}
+	}
+
+	if t.issuer != "" && claims["iss"] != t.issuer {
+		return internal.NewError(internal.Unauthorized, "invalid issuer")
+	}
+
+	if t.audience != "" && !hasAudience(claims["aud"], t.audience) {
+		return internal.NewError(internal.Unauthorized, "invalid audience")
+	}
+
+	return nil
+}
+
+// hasAudience check the "aud" claim which can be either a string or a list of
+// strings.
+func hasAudience(claim interface{}, audience string) bool {
+	switch aud := claim.(type) {
+	case string:
+		return aud == audience
+	case []interface{}:
+		for _, value := range aud {
+			if value == audience {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// verifySignature check the signature with the key, which must match the
+// algorithm: an RSA key for RS256, a P-256 key for ES256, ...
+func verifySignature(alg jwtAlgorithm, key crypto.PublicKey, digest []byte, signature []byte) bool {
+	switch pub := key.(type) {
+	case *rsa.PublicKey:
+		if alg.curve != nil {
+			return false
+		}
+
+		return rsa.VerifyPKCS1v15(pub, alg.hash, digest, signature) == nil
+	case *ecdsa.PublicKey:
+		if alg.curve != pub.Curve {
+			return false
+		}
+
+		// The JWS signature is the concatenation of R and S, each one using
+		// the curve size.
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return false
+		}
+
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+
+		return ecdsa.Verify(pub, digest, r, s)
+	default:
+		return false
+	}
+}
+
+func parseJSONWebKey(jwk *jsonWebKey) (crypto.PublicKey, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeBigInt(jwk.N)
+		if err != nil {
+			return nil, internal.NewError(internal.ValidationError, `invalid input for field "n"`)
+		}
+
+		e, err := decodeBigInt(jwk.E)
+		if err != nil || !e.IsInt64() {
+			return nil, internal.NewError(internal.ValidationError, `invalid input for field "e"`)
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, internal.Errorf(internal.ValidationError, "unsupported curve %q", jwk.Crv)
+		}
+
+		x, err := decodeBigInt(jwk.X)
+		if err != nil {
+			return nil, internal.NewError(internal.ValidationError, `invalid input for field "x"`)
+		}
+
+		y, err := decodeBigInt(jwk.Y)
+		if err != nil {
+			return nil, internal.NewError(internal.ValidationError, `invalid input for field "y"`)
+		}
+
+		if !curve.IsOnCurve(x, y) {
+			return nil, internal.NewError(internal.ValidationError, "the point is not on the curve")
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, internal.Errorf(internal.ValidationError, "unsupported key type %q", jwk.Kty)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(raw) == 0 {
+		return nil, internal.NewError(internal.ValidationError, "empty value")
+	}
+
+	return new(big.Int).SetBytes(raw), nil
+}
+
+func decodeSegment(segment string, value interface{}) error {
+	raw, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(raw, value)
+}
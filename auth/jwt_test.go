@@ -0,0 +1,280 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var someDate = time.Date(2019, time.February, 3, 10, 0, 0, 0, time.UTC)
+
+type testKeys struct {
+	rsa      *rsa.PrivateKey
+	ecdsa    *ecdsa.PrivateKey
+	jwksPath string
+}
+
+func newTestKeys(t *testing.T) *testKeys {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	encode := func(value *big.Int) string {
+		return base64.RawURLEncoding.EncodeToString(value.Bytes())
+	}
+
+	jwks, err := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{
+			{"kty": "RSA", "kid": "rsa-key", "use": "sig", "n": encode(rsaKey.N), "e": encode(big.NewInt(int64(rsaKey.E)))},
+			{"kty": "EC", "kid": "ec-key", "crv": "P-256", "x": encode(ecdsaKey.X), "y": encode(ecdsaKey.Y)},
+			{"kty": "RSA", "kid": "enc-key", "use": "enc"},
+		},
+	})
+	require.NoError(t, err)
+
+	file, err := ioutil.TempFile("", "jwks")
+	require.NoError(t, err)
+	defer file.Close()
+
+	_, err = file.Write(jwks)
+	require.NoError(t, err)
+
+	return &testKeys{rsa: rsaKey, ecdsa: ecdsaKey, jwksPath: file.Name()}
+}
+
+func (t *testKeys) sign(tb testing.TB, alg string, kid string, claims map[string]interface{}) string {
+	encode := func(value interface{}) string {
+		raw, err := json.Marshal(value)
+		require.NoError(tb, err)
+
+		return base64.RawURLEncoding.EncodeToString(raw)
+	}
+
+	signingInput := encode(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"}) + "." + encode(claims)
+	hash := crypto.SHA256
+	if strings.HasSuffix(alg, "384") {
+		hash = crypto.SHA384
+	}
+	hasher := hash.New()
+	_, _ = hasher.Write([]byte(signingInput))
+	digest := hasher.Sum(nil)
+
+	var signature []byte
+	switch alg {
+	case "RS256":
+		var err error
+		signature, err = rsa.SignPKCS1v15(rand.Reader, t.rsa, crypto.SHA256, digest)
+		require.NoError(tb, err)
+	case "ES256", "ES384":
+		// The key is always a P-256 one, whatever the algorithm.
+		r, s, err := ecdsa.Sign(rand.Reader, t.ecdsa, digest)
+		require.NoError(tb, err)
+
+		signature = make([]byte, 64)
+		rBytes, sBytes := r.Bytes(), s.Bytes()
+		copy(signature[32-len(rBytes):32], rBytes)
+		copy(signature[64-len(sBytes):], sBytes)
+	default:
+		signature = []byte("some-signature")
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func newTestJWTAuthenticator(t *testing.T, keys *testKeys) *JWTAuthenticator {
+	authenticator, err := NewJWTAuthenticator(&JWTConfig{
+		JWKSFile: keys.jwksPath,
+		Issuer:   "some-issuer",
+		Audience: "avro-gateway",
+	})
+	require.NoError(t, err)
+
+	authenticator.now = func() time.Time { return someDate }
+
+	return authenticator
+}
+
+func authenticateToken(authenticator *JWTAuthenticator, token string) (*Principal, error) {
+	r := httptest.NewRequest("POST", "http://example.com/schema", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	return authenticator.Authenticate(r)
+}
+
+func Test_JWTAuthenticator_Authenticate_success(t *testing.T) {
+	keys := newTestKeys(t)
+	defer os.Remove(keys.jwksPath)
+
+	authenticator := newTestJWTAuthenticator(t, keys)
+
+	for _, test := range []struct{ alg, kid string }{{"RS256", "rsa-key"}, {"ES256", "ec-key"}} {
+		t.Run(test.alg, func(t *testing.T) {
+			token := keys.sign(t, test.alg, test.kid, map[string]interface{}{
+				"sub": "some-app",
+				"iss": "some-issuer",
+				"aud": []string{"some-other-service", "avro-gateway"},
+				"exp": someDate.Add(time.Hour).Unix(),
+				"nbf": someDate.Add(-time.Hour).Unix(),
+			})
+
+			principal, err := authenticateToken(authenticator, token)
+
+			require.NoError(t, err)
+			assert.Equal(t, &Principal{Application: "some-app", Method: JWTMethod}, principal)
+		})
+	}
+}
+
+func Test_JWTAuthenticator_Authenticate_without_token(t *testing.T) {
+	keys := newTestKeys(t)
+	defer os.Remove(keys.jwksPath)
+
+	authenticator := newTestJWTAuthenticator(t, keys)
+
+	r := httptest.NewRequest("POST", "http://example.com/schema", nil)
+	r.Header.Set("Authorization", "Basic Zm9vOmJhcg==")
+
+	principal, err := authenticator.Authenticate(r)
+
+	assert.NoError(t, err)
+	assert.Nil(t, principal)
+}
+
+func Test_JWTAuthenticator_Authenticate_with_a_custom_claim(t *testing.T) {
+	keys := newTestKeys(t)
+	defer os.Remove(keys.jwksPath)
+
+	authenticator, err := NewJWTAuthenticator(&JWTConfig{JWKSFile: keys.jwksPath, ApplicationClaim: "app"})
+	require.NoError(t, err)
+	authenticator.now = func() time.Time { return someDate }
+
+	principal, err := authenticateToken(authenticator, keys.sign(t, "RS256", "rsa-key", map[string]interface{}{
+		"sub": "some-user",
+		"app": "some-app",
+		"exp": someDate.Add(time.Hour).Unix(),
+	}))
+
+	require.NoError(t, err)
+	assert.Equal(t, &Principal{Application: "some-app", Method: JWTMethod}, principal)
+}
+
+func Test_JWTAuthenticator_Authenticate_with_a_missing_expiration_allowed(t *testing.T) {
+	keys := newTestKeys(t)
+	defer os.Remove(keys.jwksPath)
+
+	authenticator, err := NewJWTAuthenticator(&JWTConfig{JWKSFile: keys.jwksPath, AllowMissingExpiration: true})
+	require.NoError(t, err)
+
+	principal, err := authenticateToken(authenticator, keys.sign(t, "RS256", "rsa-key", map[string]interface{}{"sub": "some-app"}))
+
+	require.NoError(t, err)
+	assert.Equal(t, &Principal{Application: "some-app", Method: JWTMethod}, principal)
+}
+
+func Test_JWTAuthenticator_Authenticate_with_invalid_tokens(t *testing.T) {
+	keys := newTestKeys(t)
+	defer os.Remove(keys.jwksPath)
+
+	authenticator := newTestJWTAuthenticator(t, keys)
+
+	validClaims := func() map[string]interface{} {
+		return map[string]interface{}{
+			"sub": "some-app",
+			"iss": "some-issuer",
+			"aud": "avro-gateway",
+			"exp": someDate.Add(time.Hour).Unix(),
+		}
+	}
+
+	withClaim := func(key string, value interface{}) map[string]interface{} {
+		claims := validClaims()
+		if value == nil {
+			delete(claims, key)
+		} else {
+			claims[key] = value
+		}
+
+		return claims
+	}
+
+	tamperedToken := keys.sign(t, "RS256", "rsa-key", validClaims())
+	tamperedToken = tamperedToken[:len(tamperedToken)-4] + "AAAA"
+
+	tests := []struct {
+		Name  string
+		Token string
+		Error string
+	}{
+		{"malformed", "foobar", "unauthorized: invalid bearer token: malformed token"},
+		{"malformed header", "foo.bar.baz", "unauthorized: invalid bearer token: malformed header"},
+		{"none algorithm", keys.sign(t, "none", "rsa-key", validClaims()), `unauthorized: invalid bearer token: unsupported algorithm "none"`},
+		{"symmetric algorithm", keys.sign(t, "HS256", "rsa-key", validClaims()), `unauthorized: invalid bearer token: unsupported algorithm "HS256"`},
+		{"unknown key", keys.sign(t, "RS256", "some-unknown-key", validClaims()), `unauthorized: invalid bearer token: unknown key "some-unknown-key"`},
+		{"algorithm mismatch", keys.sign(t, "RS256", "ec-key", validClaims()), "unauthorized: invalid bearer token: invalid signature"},
+		{"curve mismatch", keys.sign(t, "ES384", "ec-key", validClaims()), "unauthorized: invalid bearer token: invalid signature"},
+		{"tampered signature", tamperedToken, "unauthorized: invalid bearer token: invalid signature"},
+		{"expired", keys.sign(t, "RS256", "rsa-key", withClaim("exp", someDate.Add(-time.Minute).Unix())), "unauthorized: invalid bearer token: token expired"},
+		{"missing expiration", keys.sign(t, "RS256", "rsa-key", withClaim("exp", nil)), `unauthorized: invalid bearer token: missing claim "exp"`},
+		{"not valid yet", keys.sign(t, "RS256", "rsa-key", withClaim("nbf", someDate.Add(time.Minute).Unix())), "unauthorized: invalid bearer token: token not valid yet"},
+		{"invalid issuer", keys.sign(t, "RS256", "rsa-key", withClaim("iss", "some-other-issuer")), "unauthorized: invalid bearer token: invalid issuer"},
+		{"invalid audience", keys.sign(t, "RS256", "rsa-key", withClaim("aud", "some-other-service")), "unauthorized: invalid bearer token: invalid audience"},
+		{"missing application", keys.sign(t, "RS256", "rsa-key", withClaim("sub", nil)), `unauthorized: invalid bearer token: missing claim "sub"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			principal, err := authenticateToken(authenticator, test.Token)
+
+			assert.Nil(t, principal)
+			assert.EqualError(t, err, test.Error)
+		})
+	}
+}
+
+func Test_NewJWTAuthenticator_with_an_invalid_jwks(t *testing.T) {
+	file, err := ioutil.TempFile("", "jwks")
+	require.NoError(t, err)
+	defer os.Remove(file.Name())
+
+	_, err = file.WriteString(`{"keys": [{"kty": "EC", "crv": "P-192", "x": "AA", "y": "AA"}]}`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	authenticator, err := NewJWTAuthenticator(&JWTConfig{JWKSFile: file.Name()})
+
+	assert.Nil(t, authenticator)
+	assert.EqualError(t, err, `validation error: jwks key 0: unsupported curve "P-192"`)
+}
+
+func Test_NewJWTAuthenticator_with_a_duplicate_kid(t *testing.T) {
+	keys := newTestKeys(t)
+	defer os.Remove(keys.jwksPath)
+
+	jwks, err := ioutil.ReadFile(keys.jwksPath)
+	require.NoError(t, err)
+	err = ioutil.WriteFile(keys.jwksPath, []byte(strings.Replace(string(jwks), `"kid":"ec-key"`, `"kid":"rsa-key"`, 1)), 0600)
+	require.NoError(t, err)
+
+	authenticator, err := NewJWTAuthenticator(&JWTConfig{JWKSFile: keys.jwksPath})
+
+	assert.Nil(t, authenticator)
+	assert.EqualError(t, err, `validation error: jwks key 1: duplicate kid "rsa-key"`)
+}
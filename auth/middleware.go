@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/gorilla/mux"
+)
+
+// Middleware authenticate each request and save the principal into the
+// request context.
+//
+// The authenticators are tried in order, the first one finding some
+// credentials decides. Invalid credentials are always refused, the anonymous
+// requests are refused only if required is set.
+func Middleware(authenticators []Authenticator, required bool) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, authenticator := range authenticators {
+				principal, err := authenticator.Authenticate(r)
+				if err != nil {
+					writeUnauthorized(w, err)
+					return
+				}
+
+				if principal != nil {
+					next.ServeHTTP(w, r.WithContext(WithPrincipal(r.Context(), principal)))
+					return
+				}
+			}
+
+			if required {
+				writeUnauthorized(w, internal.NewError(internal.Unauthorized, "missing credentials"))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func writeUnauthorized(w http.ResponseWriter, err error) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="avro-gateway"`)
+	internal.WriteErrorIntoResponse(w, err)
+}
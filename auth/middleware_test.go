@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// whoami write the principal of the request.
+func whoami(w http.ResponseWriter, r *http.Request) {
+	principal := PrincipalFromContext(r.Context())
+	if principal == nil {
+		_, _ = w.Write([]byte("anonymous"))
+		return
+	}
+
+	_, _ = w.Write([]byte(principal.String()))
+}
+
+func Test_Middleware_with_valid_credentials(t *testing.T) {
+	apiKeyAuthenticator, err := NewAPIKeyAuthenticator([]APIKey{{Application: "some-app", SHA256: hashKey("some-secret-key")}})
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com/whoami", nil)
+	r.Header.Set("X-API-Key", "some-secret-key")
+
+	router := mux.NewRouter()
+	router.Use(Middleware([]Authenticator{apiKeyAuthenticator}, true))
+	router.HandleFunc("/whoami", whoami)
+	router.ServeHTTP(w, r)
+
+	res := w.Result()
+	body, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "api_key:some-app", string(body))
+}
+
+func Test_Middleware_with_invalid_credentials(t *testing.T) {
+	apiKeyAuthenticator, err := NewAPIKeyAuthenticator([]APIKey{{Application: "some-app", SHA256: hashKey("some-secret-key")}})
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com/whoami", nil)
+	r.Header.Set("X-API-Key", "some-invalid-key")
+
+	router := mux.NewRouter()
+	router.Use(Middleware([]Authenticator{apiKeyAuthenticator}, false))
+	router.HandleFunc("/whoami", whoami)
+	router.ServeHTTP(w, r)
+
+	res := w.Result()
+	body, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	assert.Equal(t, `Bearer realm="avro-gateway"`, res.Header.Get("WWW-Authenticate"))
+	assert.JSONEq(t, `{"kind": "unauthorized", "message": "invalid api key"}`, string(body))
+}
+
+func Test_Middleware_anonymous_allowed(t *testing.T) {
+	apiKeyAuthenticator, err := NewAPIKeyAuthenticator([]APIKey{{Application: "some-app", SHA256: hashKey("some-secret-key")}})
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com/whoami", nil)
+
+	router := mux.NewRouter()
+	router.Use(Middleware([]Authenticator{apiKeyAuthenticator}, false))
+	router.HandleFunc("/whoami", whoami)
+	router.ServeHTTP(w, r)
+
+	res := w.Result()
+	body, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "anonymous", string(body))
+}
+
+func Test_Middleware_anonymous_refused(t *testing.T) {
+	apiKeyAuthenticator, err := NewAPIKeyAuthenticator([]APIKey{{Application: "some-app", SHA256: hashKey("some-secret-key")}})
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com/whoami", nil)
+
+	router := mux.NewRouter()
+	router.Use(Middleware([]Authenticator{apiKeyAuthenticator}, true))
+	router.HandleFunc("/whoami", whoami)
+	router.ServeHTTP(w, r)
+
+	res := w.Result()
+	body, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	assert.JSONEq(t, `{"kind": "unauthorized", "message": "missing credentials"}`, string(body))
+}
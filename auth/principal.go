@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Peltoche/avro-gateway/internal"
+)
+
+// Method is the authentication method used by a caller.
+type Method string
+
+var (
+	// APIKeyMethod is used for the callers authenticated by an API key.
+	APIKeyMethod Method = "api_key"
+	// JWTMethod is used for the callers authenticated by a JWT bearer token.
+	JWTMethod Method = "jwt"
+	// CertificateMethod is used for the callers authenticated by a TLS client
+	// certificate.
+	CertificateMethod Method = "certificate"
+)
+
+// Principal is an authenticated caller.
+type Principal struct {
+	// Application is the name of the calling application.
+	Application string
+	Method      Method
+}
+
+// String return the principal as "<method>:<application>".
+func (t *Principal) String() string {
+	return string(t.Method) + ":" + t.Application
+}
+
+// Authenticator identify the caller of a request.
+type Authenticator interface {
+	// Authenticate return the caller identity. It return nil without error
+	// if the request doesn't contains any credentials handled by the
+	// Authenticator.
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+type principalKey struct{}
+
+// WithPrincipal return a copy of the context containing the principal.
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext return the principal saved into the context or nil for
+// an anonymous caller.
+func PrincipalFromContext(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalKey{}).(*Principal)
+
+	return principal
+}
+
+// CheckAdmin return an error if the caller is not authenticated or is not one
+// of the admins. The api is named in the error returned when there is no
+// admin, the api being disabled.
+func CheckAdmin(ctx context.Context, admins []string, api string) error {
+	principal := PrincipalFromContext(ctx)
+	if principal == nil {
+		return internal.NewError(internal.Unauthorized, "missing credentials")
+	}
+
+	if len(admins) == 0 {
+		return internal.Errorf(internal.Forbidden, "the %s is disabled as there is no admin", api)
+	}
+
+	for _, admin := range admins {
+		if admin == principal.Application {
+			return nil
+		}
+	}
+
+	return internal.Errorf(internal.Forbidden, "the application %q is not an admin", principal.Application)
+}
+
+// CallerApplication return the application of a request naming the requested
+// one. For an authenticated caller it's the authenticated application: the
+// requested one can be empty and any other value is refused.
+func CallerApplication(ctx context.Context, requested string) (string, error) {
+	principal := PrincipalFromContext(ctx)
+	if principal == nil {
+		return requested, nil
+	}
+
+	if requested != "" && requested != principal.Application {
+		return "", internal.Errorf(internal.ValidationError, `invalid input for field "application": authenticated as %q`, principal.Application)
+	}
+
+	return principal.Application, nil
+}
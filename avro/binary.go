@@ -0,0 +1,500 @@
+package avro
+
+import (
+	"encoding/binary"
+	"math"
+	"unicode/utf8"
+)
+
+// maxEmptyItems is the maximum number of items of an array or a map block
+// when the items are encoded without any byte (null or empty records). The
+// other blocks can't announce more items than the remaining bytes.
+const maxEmptyItems = 1 << 16
+
+// maxDepth is the maximum nesting of the records, arrays and maps of a decoded
+// value, a recursive schema allowing a few bytes to describe a deep value.
+const maxDepth = 1000
+
+// DecodeBinary decode a value written with the Avro binary encoding. All the
+// data must be used by the value.
+func DecodeBinary(schema *Schema, data []byte) (interface{}, error) {
+	d := &decoder{data: data}
+
+	value, err := d.decode(schema, rootLocation)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.pos != len(d.data) {
+		return nil, errorf("$", "%d unexpected bytes after the value", len(d.data)-d.pos)
+	}
+
+	return value, nil
+}
+
+// EncodeBinary encode a value with the Avro binary encoding.
+func EncodeBinary(schema *Schema, value interface{}) ([]byte, error) {
+	e := &encoder{buf: []byte{}}
+
+	err := e.encode(schema, value, "$")
+	if err != nil {
+		return nil, err
+	}
+
+	return e.buf, nil
+}
+
+type decoder struct {
+	data  []byte
+	pos   int
+	depth int
+}
+
+func (t *decoder) decode(schema *Schema, loc *location) (interface{}, error) {
+	leave, err := t.enter(schema, loc)
+	if err != nil {
+		return nil, err
+	}
+	defer leave()
+
+	switch schema.Type {
+	case Null:
+		return nil, nil
+	case Boolean:
+		b, err := t.read(1, loc)
+		if err != nil {
+			return nil, err
+		}
+
+		if b[0] > 1 {
+			return nil, errorf(loc.String(), "invalid boolean byte %d", b[0])
+		}
+
+		return b[0] == 1, nil
+	case Int:
+		return t.readInt(loc)
+	case Long:
+		return t.readLong(loc)
+	case Float:
+		b, err := t.read(4, loc)
+		if err != nil {
+			return nil, err
+		}
+
+		return math.Float32frombits(binary.LittleEndian.Uint32(b)), nil
+	case Double:
+		b, err := t.read(8, loc)
+		if err != nil {
+			return nil, err
+		}
+
+		return math.Float64frombits(binary.LittleEndian.Uint64(b)), nil
+	case Bytes:
+		b, err := t.readBytes(loc)
+		if err != nil {
+			return nil, err
+		}
+
+		return append([]byte{}, b...), nil
+	case String:
+		b, err := t.readBytes(loc)
+		if err != nil {
+			return nil, err
+		}
+
+		if !utf8.Valid(b) {
+			return nil, errorf(loc.String(), "invalid UTF-8 string")
+		}
+
+		return string(b), nil
+	case Fixed:
+		b, err := t.read(schema.Size, loc)
+		if err != nil {
+			return nil, err
+		}
+
+		return append([]byte{}, b...), nil
+	case Enum:
+		idx, err := t.readInt(loc)
+		if err != nil {
+			return nil, err
+		}
+
+		if idx < 0 || int(idx) >= len(schema.Symbols) {
+			return nil, errorf(loc.String(), "invalid symbol index %d of %s", idx, schema.Name)
+		}
+
+		return schema.Symbols[idx], nil
+	case Union:
+		idx, err := t.readLong(loc)
+		if err != nil {
+			return nil, err
+		}
+
+		if idx < 0 || idx >= int64(len(schema.Branches)) {
+			return nil, errorf(loc.String(), "invalid union branch index %d", idx)
+		}
+
+		value, err := t.decode(schema.Branches[idx], loc)
+		if err != nil {
+			return nil, err
+		}
+
+		return UnionValue{Index: int(idx), Value: value}, nil
+	case Record:
+		value := make(map[string]interface{}, len(schema.Fields))
+		for _, field := range schema.Fields {
+			var err error
+			value[field.Name], err = t.decode(field.Type, loc.field(field.Name))
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return value, nil
+	case Array:
+		value := []interface{}{}
+		err := t.readBlocks(schema.Items, loc, func() error {
+			item, err := t.decode(schema.Items, loc.item(len(value)))
+			value = append(value, item)
+
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return value, nil
+	case Map:
+		value := map[string]interface{}{}
+		err := t.readBlocks(schema.Values, loc, func() error {
+			key, err := t.decode(&Schema{Type: String}, loc.key(""))
+			if err != nil {
+				return err
+			}
+
+			value[key.(string)], err = t.decode(schema.Values, loc.key(key.(string)))
+
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return value, nil
+	}
+
+	return nil, errorf(loc.String(), "unsupported type %q", schema.Type)
+}
+
+// enter count the nesting of the records, arrays and maps and return the
+// function to call once the value is decoded.
+func (t *decoder) enter(schema *Schema, loc *location) (func(), error) {
+	if schema.Type != Record && schema.Type != Array && schema.Type != Map {
+		return func() {}, nil
+	}
+
+	if t.depth >= maxDepth {
+		return nil, errorf(loc.String(), "the value is nested deeper than %d levels", maxDepth)
+	}
+
+	t.depth++
+
+	return func() { t.depth-- }, nil
+}
+
+// readBlocks read the blocks of an array or a map and call readItem for each
+// item.
+func (t *decoder) readBlocks(items *Schema, loc *location, readItem func() error) error {
+	for {
+		count, err := t.readLong(loc)
+		if err != nil {
+			return err
+		}
+
+		if count == 0 {
+			return nil
+		}
+
+		if count < 0 {
+			// A negative count is followed by the block size in bytes.
+			count = -count
+			_, err = t.readLong(loc)
+			if err != nil {
+				return err
+			}
+		}
+
+		limit := int64(len(t.data) - t.pos)
+		if isEmpty(items) {
+			limit = maxEmptyItems
+		}
+		if count > limit {
+			return errorf(loc.String(), "invalid block of %d items", count)
+		}
+
+		for i := int64(0); i < count; i++ {
+			err = readItem()
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (t *decoder) read(size int, loc *location) ([]byte, error) {
+	if size > len(t.data)-t.pos {
+		return nil, errorf(loc.String(), "unexpected end of data")
+	}
+
+	b := t.data[t.pos : t.pos+size]
+	t.pos += size
+
+	return b, nil
+}
+
+func (t *decoder) readBytes(loc *location) ([]byte, error) {
+	size, err := t.readLong(loc)
+	if err != nil {
+		return nil, err
+	}
+
+	if size < 0 {
+		return nil, errorf(loc.String(), "invalid negative length %d", size)
+	}
+
+	if size > int64(len(t.data)-t.pos) {
+		return nil, errorf(loc.String(), "unexpected end of data")
+	}
+
+	return t.read(int(size), loc)
+}
+
+func (t *decoder) readInt(loc *location) (int32, error) {
+	value, err := t.readLong(loc)
+	if err != nil {
+		return 0, err
+	}
+
+	if value < math.MinInt32 || value > math.MaxInt32 {
+		return 0, errorf(loc.String(), "the value %d overflow an int", value)
+	}
+
+	return int32(value), nil
+}
+
+// readLong read a zig-zag encoded variable-length integer.
+func (t *decoder) readLong(loc *location) (int64, error) {
+	var value uint64
+	for shift := uint(0); shift < 70; shift += 7 {
+		if t.pos >= len(t.data) {
+			return 0, errorf(loc.String(), "unexpected end of data")
+		}
+
+		b := t.data[t.pos]
+		t.pos++
+
+		value |= uint64(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return int64(value>>1) ^ -int64(value&1), nil
+		}
+	}
+
+	return 0, errorf(loc.String(), "invalid variable-length integer")
+}
+
+type encoder struct {
+	buf []byte
+}
+
+func (t *encoder) encode(schema *Schema, value interface{}, path string) error {
+	switch schema.Type {
+	case Null:
+		return checkPrimitive(schema, value, path)
+	case Boolean:
+		err := checkPrimitive(schema, value, path)
+		if err != nil {
+			return err
+		}
+
+		if value.(bool) {
+			t.buf = append(t.buf, 1)
+		} else {
+			t.buf = append(t.buf, 0)
+		}
+	case Int:
+		err := checkPrimitive(schema, value, path)
+		if err != nil {
+			return err
+		}
+
+		t.writeLong(int64(value.(int32)))
+	case Long:
+		err := checkPrimitive(schema, value, path)
+		if err != nil {
+			return err
+		}
+
+		t.writeLong(value.(int64))
+	case Float:
+		err := checkPrimitive(schema, value, path)
+		if err != nil {
+			return err
+		}
+
+		var b [4]byte
+		binary.LittleEndian.PutUint32(b[:], math.Float32bits(value.(float32)))
+		t.buf = append(t.buf, b[:]...)
+	case Double:
+		err := checkPrimitive(schema, value, path)
+		if err != nil {
+			return err
+		}
+
+		var b [8]byte
+		binary.LittleEndian.PutUint64(b[:], math.Float64bits(value.(float64)))
+		t.buf = append(t.buf, b[:]...)
+	case String:
+		err := checkPrimitive(schema, value, path)
+		if err != nil {
+			return err
+		}
+
+		t.writeLong(int64(len(value.(string))))
+		t.buf = append(t.buf, value.(string)...)
+	case Bytes, Fixed:
+		data, ok := value.([]byte)
+		if !ok {
+			return errorf(path, "expected %s, got %s", article(schema.Type), describe(value))
+		}
+
+		if schema.Type == Fixed {
+			if len(data) != schema.Size {
+				return errorf(path, "expected %d bytes, got %d", schema.Size, len(data))
+			}
+		} else {
+			t.writeLong(int64(len(data)))
+		}
+
+		t.buf = append(t.buf, data...)
+	case Enum:
+		err := checkPrimitive(schema, value, path)
+		if err != nil {
+			return err
+		}
+
+		t.writeLong(int64(symbolIndex(schema, value.(string))))
+	case Union:
+		idx, value, err := unionBranch(schema, value, path)
+		if err != nil {
+			return err
+		}
+
+		t.writeLong(int64(idx))
+		return t.encode(schema.Branches[idx], value, path)
+	case Record:
+		values, ok := value.(map[string]interface{})
+		if !ok {
+			return errorf(path, "expected a record %s, got %s", schema.Name, describe(value))
+		}
+
+		err := checkRecordKeys(schema, values, path)
+		if err != nil {
+			return err
+		}
+
+		for _, field := range schema.Fields {
+			fieldValue, err := recordField(field, values, path)
+			if err != nil {
+				return err
+			}
+
+			err = t.encode(field.Type, fieldValue, fieldPath(path, field.Name))
+			if err != nil {
+				return err
+			}
+		}
+	case Array:
+		items, ok := value.([]interface{})
+		if !ok {
+			return errorf(path, "expected an array, got %s", describe(value))
+		}
+
+		if len(items) > 0 {
+			t.writeLong(int64(len(items)))
+			for i, item := range items {
+				err := t.encode(schema.Items, item, itemPath(path, i))
+				if err != nil {
+					return err
+				}
+			}
+		}
+		t.writeLong(0)
+	case Map:
+		values, ok := value.(map[string]interface{})
+		if !ok {
+			return errorf(path, "expected a map, got %s", describe(value))
+		}
+
+		if len(values) > 0 {
+			t.writeLong(int64(len(values)))
+			for _, key := range sortedKeys(values) {
+				t.writeLong(int64(len(key)))
+				t.buf = append(t.buf, key...)
+
+				err := t.encode(schema.Values, values[key], keyPath(path, key))
+				if err != nil {
+					return err
+				}
+			}
+		}
+		t.writeLong(0)
+	default:
+		return errorf(path, "unsupported type %q", schema.Type)
+	}
+
+	return nil
+}
+
+// writeLong write a zig-zag encoded variable-length integer.
+func (t *encoder) writeLong(value int64) {
+	encoded := uint64((value << 1) ^ (value >> 63))
+	for encoded >= 0x80 {
+		t.buf = append(t.buf, byte(encoded)|0x80)
+		encoded >>= 7
+	}
+	t.buf = append(t.buf, byte(encoded))
+}
+
+// isEmpty return true if the values of the schema are encoded without any
+// byte.
+func isEmpty(schema *Schema) bool {
+	return isEmptyRecord(schema, map[*Schema]bool{})
+}
+
+// isEmptyRecord is isEmpty with the records being visited, a record containing
+// itself being never empty.
+func isEmptyRecord(schema *Schema, visiting map[*Schema]bool) bool {
+	switch schema.Type {
+	case Null:
+		return true
+	case Fixed:
+		return schema.Size == 0
+	case Record:
+		if visiting[schema] {
+			return false
+		}
+		visiting[schema] = true
+		defer delete(visiting, schema)
+
+		for _, field := range schema.Fields {
+			if !isEmptyRecord(field.Type, visiting) {
+				return false
+			}
+		}
+
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,243 @@
+package avro
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func someUser() map[string]interface{} {
+	return map[string]interface{}{
+		"name":   "Alice",
+		"age":    int32(42),
+		"emails": []interface{}{"alice@example.com"},
+		"status": "DELETED",
+		"address": map[string]interface{}{
+			"street": "Main",
+			"tags":   map[string]interface{}{"a": int64(-1)},
+		},
+		"id":       []byte{1, 2, 3, 4},
+		"previous": nil,
+	}
+}
+
+// someDecodedUser is someUser as returned by the decoders, with the union
+// branches.
+func someDecodedUser() map[string]interface{} {
+	user := someUser()
+	user["age"] = UnionValue{Index: 1, Value: user["age"]}
+	user["address"] = UnionValue{Index: 1, Value: user["address"]}
+	user["previous"] = UnionValue{Index: 0, Value: nil}
+
+	return user
+}
+
+var someUserBinary = []byte{
+	// name
+	0x0a, 'A', 'l', 'i', 'c', 'e',
+	// age: branch 1, 42
+	0x02, 0x54,
+	// emails: 1 item, "alice@example.com", end
+	0x02, 0x22, 'a', 'l', 'i', 'c', 'e', '@', 'e', 'x', 'a', 'm', 'p', 'l', 'e', '.', 'c', 'o', 'm', 0x00,
+	// status: DELETED
+	0x02,
+	// address: branch 1, street, tags: 1 item, "a", -1, end
+	0x02, 0x08, 'M', 'a', 'i', 'n', 0x02, 0x02, 'a', 0x01, 0x00,
+	// id
+	0x01, 0x02, 0x03, 0x04,
+	// previous: branch 0
+	0x00,
+}
+
+func Test_EncodeBinary_success(t *testing.T) {
+	schema, err := Parse(userSchema)
+	require.NoError(t, err)
+
+	data, err := EncodeBinary(schema, someUser())
+
+	assert.NoError(t, err)
+	assert.Equal(t, someUserBinary, data)
+}
+
+func Test_EncodeBinary_with_the_default_values(t *testing.T) {
+	schema, err := Parse(userSchema)
+	require.NoError(t, err)
+
+	user := someDecodedUser()
+	delete(user, "emails")
+	delete(user, "address")
+
+	data, err := EncodeBinary(schema, user)
+	require.NoError(t, err)
+
+	decoded, err := DecodeBinary(schema, data)
+	require.NoError(t, err)
+
+	user["emails"] = []interface{}{}
+	user["address"] = UnionValue{Index: 0, Value: nil}
+	assert.Equal(t, user, decoded)
+}
+
+func Test_DecodeBinary_success(t *testing.T) {
+	schema, err := Parse(userSchema)
+	require.NoError(t, err)
+
+	value, err := DecodeBinary(schema, someUserBinary)
+
+	assert.NoError(t, err)
+	assert.Equal(t, someDecodedUser(), value)
+}
+
+func Test_Binary_primitives(t *testing.T) {
+	tests := []struct {
+		schema string
+		value  interface{}
+		data   []byte
+	}{
+		{`"null"`, nil, []byte{}},
+		{`"boolean"`, true, []byte{1}},
+		{`"int"`, int32(-64), []byte{0x7f}},
+		{`"int"`, int32(math.MaxInt32), []byte{0xfe, 0xff, 0xff, 0xff, 0x0f}},
+		{`"long"`, int64(64), []byte{0x80, 0x01}},
+		{`"long"`, int64(math.MinInt64), []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01}},
+		{`"float"`, float32(1.5), []byte{0, 0, 0xc0, 0x3f}},
+		{`"double"`, float64(-2), []byte{0, 0, 0, 0, 0, 0, 0, 0xc0}},
+		{`"bytes"`, []byte{0xff}, []byte{0x02, 0xff}},
+		{`"string"`, "é", []byte{0x04, 0xc3, 0xa9}},
+		{`{"type": "map", "values": "int"}`, map[string]interface{}{}, []byte{0}},
+		{`["string", "long"]`, UnionValue{Index: 1, Value: int64(1)}, []byte{0x02, 0x02}},
+	}
+
+	for _, test := range tests {
+		schema, err := Parse(test.schema)
+		require.NoError(t, err)
+
+		data, err := EncodeBinary(schema, test.value)
+		assert.NoError(t, err, test.schema)
+		assert.Equal(t, test.data, data, test.schema)
+
+		value, err := DecodeBinary(schema, test.data)
+		assert.NoError(t, err, test.schema)
+		assert.Equal(t, test.value, value, test.schema)
+	}
+}
+
+func Test_DecodeBinary_with_blocks_size(t *testing.T) {
+	schema, err := Parse(`{"type": "array", "items": "int"}`)
+	require.NoError(t, err)
+
+	// A block of -2 items with a size of 2 bytes, then a block of 1 item.
+	value, err := DecodeBinary(schema, []byte{0x03, 0x04, 0x02, 0x04, 0x02, 0x06, 0x00})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{int32(1), int32(2), int32(3)}, value)
+}
+
+func Test_DecodeBinary_errors(t *testing.T) {
+	schema, err := Parse(userSchema)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		data []byte
+		err  string
+	}{
+		{"empty", []byte{}, "$.name: unexpected end of data"},
+		{"truncated string", []byte{0x0a, 'A'}, "$.name: unexpected end of data"},
+		{"negative length", []byte{0x01}, "$.name: invalid negative length -1"},
+		{"invalid utf-8", []byte{0x02, 0xff}, "$.name: invalid UTF-8 string"},
+		{"invalid branch", []byte{0x00, 0x04}, "$.age: invalid union branch index 2"},
+		{"int overflow", []byte{0x00, 0x02, 0x80, 0x80, 0x80, 0x80, 0x10}, "$.age: the value 2147483648 overflow an int"},
+		{"invalid varint", []byte{0x00, 0x02, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x01}, "$.age: invalid variable-length integer"},
+		{"invalid array item", []byte{0x00, 0x00, 0x02, 0x02, 0xc3}, "$.emails[0]: invalid UTF-8 string"},
+		{"invalid block", []byte{0x00, 0x00, 0x7e}, "$.emails: invalid block of 63 items"},
+		{"invalid symbol", []byte{0x00, 0x00, 0x00, 0x04}, "$.status: invalid symbol index 2 of com.example.Status"},
+		{"invalid map value", []byte{0x00, 0x00, 0x00, 0x00, 0x02, 0x00, 0x02, 0x02, 'a'}, `$.address.tags["a"]: unexpected end of data`},
+		{"short fixed", []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x01}, "$.id: unexpected end of data"},
+		{"trailing bytes", append(append([]byte{}, someUserBinary...), 0x00, 0x00), "$: 2 unexpected bytes after the value"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			value, err := DecodeBinary(schema, test.data)
+
+			assert.Nil(t, value)
+			assert.EqualError(t, err, test.err)
+		})
+	}
+}
+
+func Test_DecodeBinary_with_too_many_empty_items(t *testing.T) {
+	schema, err := Parse(`{"type": "array", "items": "null"}`)
+	require.NoError(t, err)
+
+	_, err = DecodeBinary(schema, []byte{0xfe, 0xff, 0xff, 0xff, 0x0f, 0x00})
+
+	assert.EqualError(t, err, "$: invalid block of 2147483647 items")
+}
+
+func Test_DecodeBinary_with_empty_recursive_items(t *testing.T) {
+	schema, err := Parse(`{"type": "array", "items": {"type": "record", "name": "A", "fields": [
+		{"name": "b", "type": {"type": "record", "name": "B", "fields": []}},
+		{"name": "c", "type": "B"},
+		{"name": "a", "type": {"type": "array", "items": "A"}}
+	]}}`)
+	require.NoError(t, err)
+
+	value, err := DecodeBinary(schema, []byte{0x02, 0x00, 0x00})
+	require.NoError(t, err)
+
+	assert.Equal(t, []interface{}{map[string]interface{}{
+		"b": map[string]interface{}{},
+		"c": map[string]interface{}{},
+		"a": []interface{}{},
+	}}, value)
+}
+
+func Test_DecodeBinary_with_a_deep_value(t *testing.T) {
+	schema, err := Parse(`{"type": "record", "name": "N", "fields": [{"name": "n", "type": ["null", "N"]}]}`)
+	require.NoError(t, err)
+
+	// Each 0x02 byte nest a record in the previous one, the last 0x00 byte
+	// ending the list.
+	_, err = DecodeBinary(schema, append(bytes.Repeat([]byte{0x02}, maxDepth-1), 0x00))
+	assert.NoError(t, err)
+
+	_, err = DecodeBinary(schema, append(bytes.Repeat([]byte{0x02}, 80000), 0x00))
+	assert.EqualError(t, err, "$"+strings.Repeat(".n", maxDepth)+": the value is nested deeper than 1000 levels")
+}
+
+func Test_EncodeBinary_errors(t *testing.T) {
+	schema, err := Parse(userSchema)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		modify func(user map[string]interface{})
+		err    string
+	}{
+		{"missing field", func(user map[string]interface{}) { delete(user, "name") }, "$.name: missing field"},
+		{"unknown field", func(user map[string]interface{}) { user["foo"] = "bar" }, "$.foo: unknown field of the record com.example.User"},
+		{"invalid type", func(user map[string]interface{}) { user["name"] = int32(1) }, "$.name: expected a string, got int"},
+		{"no branch", func(user map[string]interface{}) { user["age"] = "42" }, "$.age: the string value doesn't match any branch of the union"},
+		{"invalid item", func(user map[string]interface{}) { user["emails"] = []interface{}{true} }, "$.emails[0]: expected a string, got boolean"},
+		{"unknown symbol", func(user map[string]interface{}) { user["status"] = "FOO" }, `$.status: unknown symbol "FOO" of com.example.Status`},
+		{"invalid fixed", func(user map[string]interface{}) { user["id"] = []byte{1} }, "$.id: expected 4 bytes, got 1"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			user := someUser()
+			test.modify(user)
+
+			data, err := EncodeBinary(schema, user)
+
+			assert.Nil(t, data)
+			assert.EqualError(t, err, test.err)
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package avro
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// crc64Empty is the CRC-64-AVRO fingerprint of an empty input.
+const crc64Empty = 0xc15d213aa4d7a795
+
+var crc64Table = func() [256]uint64 {
+	var table [256]uint64
+	for i := range table {
+		fp := uint64(i)
+		for j := 0; j < 8; j++ {
+			fp = (fp >> 1) ^ (crc64Empty & -(fp & 1))
+		}
+		table[i] = fp
+	}
+
+	return table
+}()
+
+// Fingerprints of the Parsing Canonical Form of a schema. Two schemas with the
+// same fingerprints are identical once the docs, the defaults, the aliases and
+// the formatting are ignored.
+type Fingerprints struct {
+	// CRC64Avro is the 64-bit Rabin fingerprint used by the Avro single
+	// object encoding, as a 16 characters hex string.
+	CRC64Avro string `json:"crc64_avro"`
+	MD5       string `json:"md5"`
+	SHA256    string `json:"sha256"`
+}
+
+// Fingerprint return the fingerprints of the schema.
+func Fingerprint(schema *Schema) *Fingerprints {
+	canonical := []byte(CanonicalForm(schema))
+
+	md5Sum := md5.Sum(canonical)
+	sha256Sum := sha256.Sum256(canonical)
+
+	return &Fingerprints{
+		CRC64Avro: fmt.Sprintf("%016x", crc64Avro(canonical)),
+		MD5:       hex.EncodeToString(md5Sum[:]),
+		SHA256:    hex.EncodeToString(sha256Sum[:]),
+	}
+}
+
+// CanonicalForm return the Parsing Canonical Form of the schema: only the
+// attributes changing the encoding are kept, with the full names, in a fixed
+// order and without any whitespace.
+func CanonicalForm(schema *Schema) string {
+	var b strings.Builder
+	writeCanonical(&b, schema, map[string]bool{})
+
+	return b.String()
+}
+
+func writeCanonical(b *strings.Builder, schema *Schema, defined map[string]bool) {
+	switch schema.Type {
+	case Record, Enum, Fixed:
+		// A named type is defined once then referenced by its name.
+		if defined[schema.Name] {
+			b.WriteString(strconv.Quote(schema.Name))
+			return
+		}
+		defined[schema.Name] = true
+
+		b.WriteString(`{"name":`)
+		b.WriteString(strconv.Quote(schema.Name))
+		b.WriteString(`,"type":`)
+		b.WriteString(strconv.Quote(string(schema.Type)))
+
+		switch schema.Type {
+		case Record:
+			b.WriteString(`,"fields":[`)
+			for i, field := range schema.Fields {
+				if i > 0 {
+					b.WriteByte(',')
+				}
+				b.WriteString(`{"name":`)
+				b.WriteString(strconv.Quote(field.Name))
+				b.WriteString(`,"type":`)
+				writeCanonical(b, field.Type, defined)
+				b.WriteByte('}')
+			}
+			b.WriteByte(']')
+		case Enum:
+			b.WriteString(`,"symbols":[`)
+			for i, symbol := range schema.Symbols {
+				if i > 0 {
+					b.WriteByte(',')
+				}
+				b.WriteString(strconv.Quote(symbol))
+			}
+			b.WriteByte(']')
+		case Fixed:
+			b.WriteString(`,"size":`)
+			b.WriteString(strconv.Itoa(schema.Size))
+		}
+
+		b.WriteByte('}')
+	case Array:
+		b.WriteString(`{"type":"array","items":`)
+		writeCanonical(b, schema.Items, defined)
+		b.WriteByte('}')
+	case Map:
+		b.WriteString(`{"type":"map","values":`)
+		writeCanonical(b, schema.Values, defined)
+		b.WriteByte('}')
+	case Union:
+		b.WriteByte('[')
+		for i, branch := range schema.Branches {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			writeCanonical(b, branch, defined)
+		}
+		b.WriteByte(']')
+	default:
+		b.WriteString(strconv.Quote(string(schema.Type)))
+	}
+}
+
+func crc64Avro(data []byte) uint64 {
+	fp := uint64(crc64Empty)
+	for _, b := range data {
+		fp = (fp >> 8) ^ crc64Table[byte(fp)^b]
+	}
+
+	return fp
+}
@@ -0,0 +1,85 @@
+package avro
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CanonicalForm(t *testing.T) {
+	tests := []struct {
+		name      string
+		schema    string
+		canonical string
+	}{
+		{"primitive", `"int"`, `"int"`},
+		{"primitive object", `{"type": "int", "logicalType": "date"}`, `"int"`},
+		{"array", `{"type": "array", "items": "long", "default": []}`, `{"type":"array","items":"long"}`},
+		{"map", `{"values": {"type": "string"}, "type": "map"}`, `{"type":"map","values":"string"}`},
+		{"union", `[ "null", {"type": "string"} ]`, `["null","string"]`},
+		{"fixed", `{"size": 16, "type": "fixed", "namespace": "com.example", "name": "MD5", "aliases": ["Hash"]}`, `{"name":"com.example.MD5","type":"fixed","size":16}`},
+		{"enum", `{"type": "enum", "name": "E", "doc": "An enum.", "symbols": ["A", "B"], "default": "A"}`, `{"name":"E","type":"enum","symbols":["A","B"]}`},
+		{
+			"user",
+			userSchema,
+			`{"name":"com.example.User","type":"record","fields":[` +
+				`{"name":"name","type":"string"},` +
+				`{"name":"age","type":["null","int"]},` +
+				`{"name":"emails","type":{"type":"array","items":"string"}},` +
+				`{"name":"status","type":{"name":"com.example.Status","type":"enum","symbols":["ACTIVE","DELETED"]}},` +
+				`{"name":"address","type":["null",{"name":"com.example.Address","type":"record","fields":[{"name":"street","type":"string"},{"name":"tags","type":{"type":"map","values":"long"}}]}]},` +
+				`{"name":"id","type":{"name":"com.example.ID","type":"fixed","size":4}},` +
+				`{"name":"previous","type":["null","com.example.User"]}]}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			schema, err := Parse(test.schema)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.canonical, CanonicalForm(schema))
+		})
+	}
+}
+
+func Test_Fingerprint_crc64(t *testing.T) {
+	// The values come from the test suite of the Avro specification, where
+	// the fingerprints are signed longs.
+	tests := []struct {
+		schema      string
+		fingerprint int64
+	}{
+		{`"null"`, 7195948357588979594},
+		{`{"type": "int"}`, 8247732601305521295},
+		{`"boolean"`, -6970731678124411036},
+	}
+
+	for _, test := range tests {
+		t.Run(test.schema, func(t *testing.T) {
+			schema, err := Parse(test.schema)
+			require.NoError(t, err)
+
+			fingerprint, err := strconv.ParseUint(Fingerprint(schema).CRC64Avro, 16, 64)
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.fingerprint, int64(fingerprint))
+		})
+	}
+}
+
+func Test_Fingerprint_ignore_the_formatting(t *testing.T) {
+	schema, err := Parse(`"int"`)
+	require.NoError(t, err)
+	other, err := Parse(`{"type": "int", "doc": "ignored"}`)
+	require.NoError(t, err)
+
+	assert.Equal(t, &Fingerprints{
+		CRC64Avro: "7275d51a3f395c8f",
+		MD5:       "ef524ea1b91e73173d938ade36c1db32",
+		SHA256:    "3f2b87a9fe7cc9b13835598c3981cd45e3e355309e5090aa0933d7becb6fba45",
+	}, Fingerprint(schema))
+	assert.Equal(t, Fingerprint(schema), Fingerprint(other))
+}
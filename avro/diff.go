@@ -0,0 +1,309 @@
+package avro
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ChangeKind is the kind of a change between two schemas.
+type ChangeKind string
+
+const (
+	// FieldAdded is a record field present only in the new schema.
+	FieldAdded ChangeKind = "field_added"
+	// FieldRemoved is a record field present only in the old schema.
+	FieldRemoved ChangeKind = "field_removed"
+	// FieldRenamed is a record field whose new name has the old one as alias.
+	FieldRenamed ChangeKind = "field_renamed"
+	// NameChanged is a named type with an other name.
+	NameChanged ChangeKind = "name_changed"
+	// TypeChanged is a value with an other type.
+	TypeChanged ChangeKind = "type_changed"
+	// DefaultChanged is a field default value added, removed or changed.
+	DefaultChanged ChangeKind = "default_changed"
+	// DocChanged is a doc added, removed or changed.
+	DocChanged ChangeKind = "doc_changed"
+	// SymbolAdded is an enum symbol present only in the new schema.
+	SymbolAdded ChangeKind = "symbol_added"
+	// SymbolRemoved is an enum symbol present only in the old schema.
+	SymbolRemoved ChangeKind = "symbol_removed"
+	// EnumDefaultChanged is an enum default symbol added, removed or changed.
+	EnumDefaultChanged ChangeKind = "enum_default_changed"
+	// SizeChanged is a fixed with an other size.
+	SizeChanged ChangeKind = "size_changed"
+)
+
+// Compatibility of a change, with the Schema Registry meaning: backward if the
+// new schema can read the data written with the old one and forward if the
+// old schema can read the data written with the new one.
+type Compatibility string
+
+const (
+	// FullCompatibility is a change both backward and forward compatible.
+	FullCompatibility Compatibility = "full"
+	// BackwardCompatibility is a change only backward compatible.
+	BackwardCompatibility Compatibility = "backward"
+	// ForwardCompatibility is a change only forward compatible.
+	ForwardCompatibility Compatibility = "forward"
+	// Breaking is a change neither backward nor forward compatible.
+	Breaking Compatibility = "breaking"
+)
+
+// CompatibilityOf return the compatibility of the new schema with the old one.
+func CompatibilityOf(from *Schema, to *Schema) Compatibility {
+	return compatibilityOf(CheckResolution(from, to) == nil, CheckResolution(to, from) == nil)
+}
+
+// compatibilityOf return the compatibility matching the two directions.
+func compatibilityOf(backward bool, forward bool) Compatibility {
+	switch {
+	case backward && forward:
+		return FullCompatibility
+	case backward:
+		return BackwardCompatibility
+	case forward:
+		return ForwardCompatibility
+	default:
+		return Breaking
+	}
+}
+
+// Change between two schemas.
+//
+// Path locate the changed element like the Error path, "[*]" being any array
+// item or map value. From and To are the old and new values, if any.
+type Change struct {
+	Kind          ChangeKind    `json:"kind"`
+	Path          string        `json:"path"`
+	From          string        `json:"from,omitempty"`
+	To            string        `json:"to,omitempty"`
+	Compatibility Compatibility `json:"compatibility"`
+}
+
+// String return a human readable description of the change.
+func (t Change) String() string {
+	var desc string
+	switch t.Kind {
+	case FieldAdded:
+		desc = fmt.Sprintf("field added with the type %s", t.To)
+	case FieldRemoved:
+		desc = fmt.Sprintf("field removed, it had the type %s", t.From)
+	case FieldRenamed:
+		desc = fmt.Sprintf("field renamed from %q to %q", t.From, t.To)
+	case NameChanged:
+		desc = fmt.Sprintf("type renamed from %s to %s", t.From, t.To)
+	case TypeChanged:
+		desc = fmt.Sprintf("type changed from %s to %s", t.From, t.To)
+	case DefaultChanged:
+		desc = fmt.Sprintf("default changed from %s to %s", orNone(t.From), orNone(t.To))
+	case DocChanged:
+		desc = fmt.Sprintf("doc changed from %q to %q", t.From, t.To)
+	case SymbolAdded:
+		desc = fmt.Sprintf("symbol %s added", t.To)
+	case SymbolRemoved:
+		desc = fmt.Sprintf("symbol %s removed", t.From)
+	case EnumDefaultChanged:
+		desc = fmt.Sprintf("enum default changed from %s to %s", orNone(t.From), orNone(t.To))
+	case SizeChanged:
+		desc = fmt.Sprintf("size changed from %s to %s", t.From, t.To)
+	default:
+		desc = string(t.Kind)
+	}
+
+	return fmt.Sprintf("%s: %s (%s)", t.Path, desc, t.Compatibility)
+}
+
+// Diff return the structural changes between the old and the new schema, in
+// the order of the new schema.
+func Diff(from *Schema, to *Schema) []Change {
+	d := differ{changes: []Change{}, seen: map[[2]*Schema]bool{}}
+	d.diff(from, to, "$")
+
+	return d.changes
+}
+
+type differ struct {
+	changes []Change
+	// seen contains the records already compared, for the recursive types.
+	seen map[[2]*Schema]bool
+}
+
+func (t *differ) add(kind ChangeKind, path string, from string, to string, compatibility Compatibility) {
+	t.changes = append(t.changes, Change{
+		Kind:          kind,
+		Path:          path,
+		From:          from,
+		To:            to,
+		Compatibility: compatibility,
+	})
+}
+
+func (t *differ) diff(from *Schema, to *Schema, path string) {
+	if from.Type != to.Type {
+		t.add(TypeChanged, path, typeName(from), typeName(to), CompatibilityOf(from, to))
+		return
+	}
+
+	if from.Type == Union {
+		if typeName(from) != typeName(to) {
+			t.add(TypeChanged, path, typeName(from), typeName(to), CompatibilityOf(from, to))
+		}
+
+		// The branches present in both unions are compared.
+		for _, toBranch := range to.Branches {
+			for _, fromBranch := range from.Branches {
+				if typeName(fromBranch) == typeName(toBranch) {
+					t.diff(fromBranch, toBranch, path)
+				}
+			}
+		}
+
+		return
+	}
+
+	// A recursive record is compared once: its name and doc changes are
+	// reported at its first path only.
+	if from.Type == Record {
+		if t.seen[[2]*Schema{from, to}] {
+			return
+		}
+		t.seen[[2]*Schema{from, to}] = true
+	}
+
+	if from.Name != to.Name {
+		t.add(NameChanged, path, from.Name, to.Name, compatibilityOf(namesMatch(from, to), namesMatch(to, from)))
+	}
+
+	if from.Doc != to.Doc {
+		t.add(DocChanged, path, from.Doc, to.Doc, FullCompatibility)
+	}
+
+	switch from.Type {
+	case Record:
+		t.diffFields(from, to, path)
+	case Enum:
+		t.diffSymbols(from, to, path)
+	case Fixed:
+		if from.Size != to.Size {
+			t.add(SizeChanged, path, fmt.Sprint(from.Size), fmt.Sprint(to.Size), Breaking)
+		}
+	case Array:
+		t.diff(from.Items, to.Items, path+"[*]")
+	case Map:
+		t.diff(from.Values, to.Values, path+"[*]")
+	}
+}
+
+func (t *differ) diffFields(from *Schema, to *Schema, path string) {
+	matched := map[*Field]bool{}
+	for _, toField := range to.Fields {
+		childPath := fieldPath(path, toField.Name)
+
+		fromField := writerFieldOf(from, to, toField)
+		if fromField == nil {
+			// The old readers ignore the new field.
+			t.add(FieldAdded, childPath, "", typeName(toField.Type), compatibilityOf(toField.HasDefault, true))
+			continue
+		}
+		matched[fromField] = true
+
+		if fromField.Name != toField.Name {
+			// The old readers don't know the alias.
+			t.add(FieldRenamed, childPath, fromField.Name, toField.Name, compatibilityOf(true, fromField.HasDefault))
+		}
+
+		if fromField.Doc != toField.Doc {
+			t.add(DocChanged, childPath, fromField.Doc, toField.Doc, FullCompatibility)
+		}
+
+		t.diff(fromField.Type, toField.Type, childPath)
+
+		fromDefault, toDefault := defaultJSON(fromField), defaultJSON(toField)
+		if fromDefault != toDefault {
+			t.add(DefaultChanged, childPath, fromDefault, toDefault, FullCompatibility)
+		}
+	}
+
+	for _, fromField := range from.Fields {
+		if !matched[fromField] {
+			// The new readers ignore the old field.
+			t.add(FieldRemoved, fieldPath(path, fromField.Name), typeName(fromField.Type), "", compatibilityOf(true, fromField.HasDefault))
+		}
+	}
+}
+
+func (t *differ) diffSymbols(from *Schema, to *Schema, path string) {
+	for _, symbol := range to.Symbols {
+		if !hasSymbol(from, symbol) {
+			// The old readers need a default for the new symbols.
+			t.add(SymbolAdded, path, "", symbol, compatibilityOf(true, from.EnumDefault != ""))
+		}
+	}
+
+	for _, symbol := range from.Symbols {
+		if !hasSymbol(to, symbol) {
+			// The new readers need a default for the old symbols.
+			t.add(SymbolRemoved, path, symbol, "", compatibilityOf(to.EnumDefault != "", true))
+		}
+	}
+
+	if from.EnumDefault != to.EnumDefault {
+		t.add(EnumDefaultChanged, path, from.EnumDefault, to.EnumDefault, FullCompatibility)
+	}
+}
+
+// typeName return a short description of a type, with the Avro IDL syntax
+// for the unnamed complex types.
+func typeName(schema *Schema) string {
+	switch schema.Type {
+	case Array:
+		return "array<" + typeName(schema.Items) + ">"
+	case Map:
+		return "map<" + typeName(schema.Values) + ">"
+	case Union:
+		names := make([]string, len(schema.Branches))
+		for i, branch := range schema.Branches {
+			names[i] = typeName(branch)
+		}
+
+		return "union{" + strings.Join(names, ",") + "}"
+	default:
+		return schema.TypeName()
+	}
+}
+
+// defaultJSON return the JSON of the field default value or an empty string.
+func defaultJSON(field *Field) string {
+	if !field.HasDefault {
+		return ""
+	}
+
+	// The default of an union is a value of its first branch.
+	schema := field.Type
+	value := field.Default
+	if union, ok := value.(UnionValue); ok && schema.Type == Union && union.Index < len(schema.Branches) {
+		schema = schema.Branches[union.Index]
+		value = union.Value
+	}
+
+	raw, err := toJSON(schema, value, "$")
+	if err != nil {
+		return ""
+	}
+
+	res, err := json.Marshal(raw)
+	if err != nil {
+		return ""
+	}
+
+	return string(res)
+}
+
+func orNone(value string) string {
+	if value == "" {
+		return "none"
+	}
+
+	return value
+}
@@ -0,0 +1,237 @@
+package avro
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func record(fields string) string {
+	return `{"type": "record", "name": "R", "fields": [` + fields + `]}`
+}
+
+func Test_Diff(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    string
+		to      string
+		changes []Change
+	}{
+		{"same schema", userSchema, userSchema, []Change{}},
+		{
+			"field added with a default",
+			record(`{"name": "a", "type": "int"}`),
+			record(`{"name": "a", "type": "int"}, {"name": "b", "type": ["null", "string"], "default": null}`),
+			[]Change{{Kind: FieldAdded, Path: "$.b", To: "union{null,string}", Compatibility: FullCompatibility}},
+		},
+		{
+			"field added without default",
+			record(`{"name": "a", "type": "int"}`),
+			record(`{"name": "a", "type": "int"}, {"name": "b", "type": "string"}`),
+			[]Change{{Kind: FieldAdded, Path: "$.b", To: "string", Compatibility: ForwardCompatibility}},
+		},
+		{
+			"field removed with a default",
+			record(`{"name": "a", "type": "int"}, {"name": "b", "type": "string", "default": ""}`),
+			record(`{"name": "a", "type": "int"}`),
+			[]Change{{Kind: FieldRemoved, Path: "$.b", From: "string", Compatibility: FullCompatibility}},
+		},
+		{
+			"field removed without default",
+			record(`{"name": "a", "type": "int"}, {"name": "b", "type": {"type": "array", "items": "long"}}`),
+			record(`{"name": "a", "type": "int"}`),
+			[]Change{{Kind: FieldRemoved, Path: "$.b", From: "array<long>", Compatibility: BackwardCompatibility}},
+		},
+		{
+			"field renamed",
+			record(`{"name": "a", "type": "int"}`),
+			record(`{"name": "b", "aliases": ["a"], "type": "int"}`),
+			[]Change{{Kind: FieldRenamed, Path: "$.b", From: "a", To: "b", Compatibility: BackwardCompatibility}},
+		},
+		{
+			"field renamed without alias",
+			record(`{"name": "a", "type": "int"}`),
+			record(`{"name": "b", "type": "int"}`),
+			[]Change{
+				{Kind: FieldAdded, Path: "$.b", To: "int", Compatibility: ForwardCompatibility},
+				{Kind: FieldRemoved, Path: "$.a", From: "int", Compatibility: BackwardCompatibility},
+			},
+		},
+		{
+			"type promoted",
+			record(`{"name": "a", "type": "int"}`),
+			record(`{"name": "a", "type": "long"}`),
+			[]Change{{Kind: TypeChanged, Path: "$.a", From: "int", To: "long", Compatibility: BackwardCompatibility}},
+		},
+		{
+			"type changed",
+			record(`{"name": "a", "type": "int"}`),
+			record(`{"name": "a", "type": "boolean"}`),
+			[]Change{{Kind: TypeChanged, Path: "$.a", From: "int", To: "boolean", Compatibility: Breaking}},
+		},
+		{
+			"string to bytes",
+			record(`{"name": "a", "type": "string"}`),
+			record(`{"name": "a", "type": "bytes"}`),
+			[]Change{{Kind: TypeChanged, Path: "$.a", From: "string", To: "bytes", Compatibility: FullCompatibility}},
+		},
+		{
+			"field made nullable",
+			record(`{"name": "a", "type": "string"}`),
+			record(`{"name": "a", "type": ["null", "string"]}`),
+			[]Change{{Kind: TypeChanged, Path: "$.a", From: "string", To: "union{null,string}", Compatibility: BackwardCompatibility}},
+		},
+		{
+			"union branch added",
+			record(`{"name": "a", "type": ["null", "string"]}`),
+			record(`{"name": "a", "type": ["null", "string", "long"]}`),
+			[]Change{{Kind: TypeChanged, Path: "$.a", From: "union{null,string}", To: "union{null,string,long}", Compatibility: BackwardCompatibility}},
+		},
+		{
+			"nested in an union",
+			record(`{"name": "a", "type": ["null", {"type": "record", "name": "N", "fields": [{"name": "x", "type": "int"}]}]}`),
+			record(`{"name": "a", "type": ["null", {"type": "record", "name": "N", "fields": [{"name": "x", "type": "long"}]}]}`),
+			[]Change{{Kind: TypeChanged, Path: "$.a.x", From: "int", To: "long", Compatibility: BackwardCompatibility}},
+		},
+		{
+			"array items",
+			record(`{"name": "a", "type": {"type": "array", "items": "float"}}`),
+			record(`{"name": "a", "type": {"type": "array", "items": "double"}}`),
+			[]Change{{Kind: TypeChanged, Path: "$.a[*]", From: "float", To: "double", Compatibility: BackwardCompatibility}},
+		},
+		{
+			"map values",
+			record(`{"name": "a", "type": {"type": "map", "values": "double"}}`),
+			record(`{"name": "a", "type": {"type": "map", "values": "float"}}`),
+			[]Change{{Kind: TypeChanged, Path: "$.a[*]", From: "double", To: "float", Compatibility: ForwardCompatibility}},
+		},
+		{
+			"default changed",
+			record(`{"name": "a", "type": "int", "default": 1}`),
+			record(`{"name": "a", "type": "int", "default": 2}`),
+			[]Change{{Kind: DefaultChanged, Path: "$.a", From: "1", To: "2", Compatibility: FullCompatibility}},
+		},
+		{
+			"default added",
+			record(`{"name": "a", "type": ["null", "int"]}`),
+			record(`{"name": "a", "type": ["null", "int"], "default": null}`),
+			[]Change{{Kind: DefaultChanged, Path: "$.a", To: "null", Compatibility: FullCompatibility}},
+		},
+		{
+			"docs changed",
+			`{"type": "record", "name": "R", "doc": "old", "fields": [{"name": "a", "type": "int"}]}`,
+			`{"type": "record", "name": "R", "doc": "new", "fields": [{"name": "a", "type": "int", "doc": "A field."}]}`,
+			[]Change{
+				{Kind: DocChanged, Path: "$", From: "old", To: "new", Compatibility: FullCompatibility},
+				{Kind: DocChanged, Path: "$.a", To: "A field.", Compatibility: FullCompatibility},
+			},
+		},
+		{
+			"symbols changed",
+			`{"type": "enum", "name": "E", "symbols": ["A", "B"]}`,
+			`{"type": "enum", "name": "E", "symbols": ["A", "C"]}`,
+			[]Change{
+				{Kind: SymbolAdded, Path: "$", To: "C", Compatibility: BackwardCompatibility},
+				{Kind: SymbolRemoved, Path: "$", From: "B", Compatibility: ForwardCompatibility},
+			},
+		},
+		{
+			"symbols changed with defaults",
+			`{"type": "enum", "name": "E", "symbols": ["A", "B"], "default": "A"}`,
+			`{"type": "enum", "name": "E", "symbols": ["A", "C"], "default": "A"}`,
+			[]Change{
+				{Kind: SymbolAdded, Path: "$", To: "C", Compatibility: FullCompatibility},
+				{Kind: SymbolRemoved, Path: "$", From: "B", Compatibility: FullCompatibility},
+			},
+		},
+		{
+			"enum default added",
+			`{"type": "enum", "name": "E", "symbols": ["A"]}`,
+			`{"type": "enum", "name": "E", "symbols": ["A"], "default": "A"}`,
+			[]Change{{Kind: EnumDefaultChanged, Path: "$", To: "A", Compatibility: FullCompatibility}},
+		},
+		{
+			"record renamed with an alias",
+			`{"type": "record", "name": "a.Old", "fields": []}`,
+			`{"type": "record", "name": "a.New", "aliases": ["Old"], "fields": []}`,
+			[]Change{{Kind: NameChanged, Path: "$", From: "a.Old", To: "a.New", Compatibility: BackwardCompatibility}},
+		},
+		{
+			"fixed resized",
+			`{"type": "fixed", "name": "F", "size": 4}`,
+			`{"type": "fixed", "name": "F", "size": 8}`,
+			[]Change{{Kind: SizeChanged, Path: "$", From: "4", To: "8", Compatibility: Breaking}},
+		},
+		{
+			"recursive record",
+			`{"type": "record", "name": "Node", "fields": [{"name": "v", "type": "int"}, {"name": "next", "type": ["null", "Node"]}]}`,
+			`{"type": "record", "name": "Node", "fields": [{"name": "v", "type": "long"}, {"name": "next", "type": ["null", "Node"]}]}`,
+			[]Change{{Kind: TypeChanged, Path: "$.v", From: "int", To: "long", Compatibility: BackwardCompatibility}},
+		},
+		{
+			"recursive record renamed",
+			`{"type": "record", "name": "Node", "fields": [{"name": "children", "type": {"type": "array", "items": "Node"}}]}`,
+			`{"type": "record", "name": "Tree", "aliases": ["Node"], "doc": "A tree.", "fields": [{"name": "children", "type": {"type": "array", "items": "Tree"}}]}`,
+			[]Change{
+				{Kind: NameChanged, Path: "$", From: "Node", To: "Tree", Compatibility: BackwardCompatibility},
+				{Kind: DocChanged, Path: "$", To: "A tree.", Compatibility: FullCompatibility},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			from, err := Parse(test.from)
+			require.NoError(t, err)
+			to, err := Parse(test.to)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.changes, Diff(from, to))
+		})
+	}
+}
+
+func Test_Change_String(t *testing.T) {
+	tests := []struct {
+		change Change
+		str    string
+	}{
+		{Change{Kind: FieldAdded, Path: "$.b", To: "string", Compatibility: ForwardCompatibility}, "$.b: field added with the type string (forward)"},
+		{Change{Kind: FieldRenamed, Path: "$.b", From: "a", To: "b", Compatibility: BackwardCompatibility}, `$.b: field renamed from "a" to "b" (backward)`},
+		{Change{Kind: DefaultChanged, Path: "$.a", To: "null", Compatibility: FullCompatibility}, "$.a: default changed from none to null (full)"},
+		{Change{Kind: SymbolRemoved, Path: "$", From: "B", Compatibility: Breaking}, "$: symbol B removed (breaking)"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.str, func(t *testing.T) {
+			assert.Equal(t, test.str, test.change.String())
+		})
+	}
+}
+
+func Test_CompatibilityOf(t *testing.T) {
+	tests := []struct {
+		name          string
+		from          string
+		to            string
+		compatibility Compatibility
+	}{
+		{"same schema", userSchema, userSchema, FullCompatibility},
+		{"optional field added", record(`{"name": "a", "type": "int"}`), record(`{"name": "a", "type": "int"}, {"name": "b", "type": "int", "default": 0}`), FullCompatibility},
+		{"field added", record(`{"name": "a", "type": "int"}`), record(`{"name": "a", "type": "int"}, {"name": "b", "type": "int"}`), ForwardCompatibility},
+		{"field removed", record(`{"name": "a", "type": "int"}, {"name": "b", "type": "int"}`), record(`{"name": "a", "type": "int"}`), BackwardCompatibility},
+		{"type changed", `"int"`, `"string"`, Breaking},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			from, err := Parse(test.from)
+			require.NoError(t, err)
+			to, err := Parse(test.to)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.compatibility, CompatibilityOf(from, to))
+		})
+	}
+}
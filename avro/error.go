@@ -0,0 +1,144 @@
+package avro
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Error is returned when a value doesn't match its schema.
+//
+// Path locate the invalid value: "$" is the root value, ".name" a record
+// field, "[2]" an array item and `["key"]` a map value.
+type Error struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// Error is an implementation of error.
+func (t *Error) Error() string {
+	return fmt.Sprintf("%s: %s", t.Path, t.Message)
+}
+
+func errorf(path string, msg string, args ...interface{}) error {
+	return &Error{
+		Path:    path,
+		Message: fmt.Sprintf(msg, args...),
+	}
+}
+
+func fieldPath(path string, name string) string {
+	return path + "." + name
+}
+
+func itemPath(path string, idx int) string {
+	return fmt.Sprintf("%s[%d]", path, idx)
+}
+
+func keyPath(path string, key string) string {
+	return fmt.Sprintf("%s[%q]", path, key)
+}
+
+// location is the path of a decoded value as a list of segments up to the
+// root. The path string is built only for the errors, a deep value being
+// decoded without copying the path of its parents at each level.
+type location struct {
+	parent *location
+	kind   segmentKind
+	name   string
+	index  int
+}
+
+type segmentKind int
+
+const (
+	rootSegment segmentKind = iota
+	fieldSegment
+	itemSegment
+	keySegment
+)
+
+var rootLocation = &location{kind: rootSegment}
+
+func (t *location) field(name string) *location {
+	return &location{parent: t, kind: fieldSegment, name: name}
+}
+
+func (t *location) item(idx int) *location {
+	return &location{parent: t, kind: itemSegment, index: idx}
+}
+
+func (t *location) key(key string) *location {
+	return &location{parent: t, kind: keySegment, name: key}
+}
+
+// String return the path with the fieldPath, itemPath and keyPath format.
+func (t *location) String() string {
+	segments := []*location{}
+	for l := t; l.kind != rootSegment; l = l.parent {
+		segments = append(segments, l)
+	}
+
+	path := "$"
+	for i := len(segments) - 1; i >= 0; i-- {
+		switch segments[i].kind {
+		case fieldSegment:
+			path = fieldPath(path, segments[i].name)
+		case itemSegment:
+			path = itemPath(path, segments[i].index)
+		case keySegment:
+			path = keyPath(path, segments[i].name)
+		}
+	}
+
+	return path
+}
+
+// describe return the Avro type name of a value.
+func describe(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case int32:
+		return "int"
+	case int64:
+		return "long"
+	case float32:
+		return "float"
+	case float64:
+		return "double"
+	case []byte:
+		return "bytes"
+	case string:
+		return "string"
+	case map[string]interface{}:
+		return "map"
+	case []interface{}:
+		return "array"
+	case UnionValue:
+		return "union"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// describeJSON return the JSON type name of a decoded JSON value.
+func describeJSON(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case string:
+		return "string"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
@@ -0,0 +1,423 @@
+package avro
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+// DecodeJSON decode a value written with the Avro JSON encoding.
+//
+// The union values other than null are wrapped into an object with the
+// branch type name as single key: {"string": "foo"}.
+func DecodeJSON(schema *Schema, data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var raw interface{}
+	err := decoder.Decode(&raw)
+	if err != nil {
+		return nil, errorf("$", "invalid JSON: %s", err)
+	}
+
+	if _, err = decoder.Token(); err != io.EOF {
+		return nil, errorf("$", "unexpected data after the value")
+	}
+
+	return fromJSON(schema, raw, "$", true)
+}
+
+// EncodeJSON encode a value with the Avro JSON encoding.
+func EncodeJSON(schema *Schema, value interface{}) ([]byte, error) {
+	raw, err := toJSON(schema, value, "$")
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(raw)
+}
+
+// fromJSON convert a decoded JSON value. The unions are wrapped with the
+// Avro JSON encoding and unwrapped in the default values, where the first
+// branch is used.
+func fromJSON(schema *Schema, raw interface{}, path string, wrappedUnions bool) (interface{}, error) {
+	switch schema.Type {
+	case Null:
+		if raw != nil {
+			return nil, errorf(path, "expected null, got %s", describeJSON(raw))
+		}
+
+		return nil, nil
+	case Boolean:
+		value, ok := raw.(bool)
+		if !ok {
+			return nil, errorf(path, "expected a boolean, got %s", describeJSON(raw))
+		}
+
+		return value, nil
+	case Int, Long:
+		number, ok := raw.(json.Number)
+		if !ok {
+			return nil, errorf(path, "expected %s, got %s", article(schema.Type), describeJSON(raw))
+		}
+
+		value, err := number.Int64()
+		if err != nil {
+			return nil, errorf(path, "expected %s, got %s", article(schema.Type), number)
+		}
+
+		if schema.Type == Long {
+			return value, nil
+		}
+
+		if value < math.MinInt32 || value > math.MaxInt32 {
+			return nil, errorf(path, "the value %d overflow an int", value)
+		}
+
+		return int32(value), nil
+	case Float, Double:
+		number, ok := raw.(json.Number)
+		if !ok {
+			return nil, errorf(path, "expected %s, got %s", article(schema.Type), describeJSON(raw))
+		}
+
+		value, err := number.Float64()
+		if err != nil {
+			return nil, errorf(path, "expected %s, got %s", article(schema.Type), number)
+		}
+
+		if schema.Type == Float {
+			return float32(value), nil
+		}
+
+		return value, nil
+	case String:
+		value, ok := raw.(string)
+		if !ok {
+			return nil, errorf(path, "expected a string, got %s", describeJSON(raw))
+		}
+
+		return value, nil
+	case Bytes, Fixed:
+		str, ok := raw.(string)
+		if !ok {
+			return nil, errorf(path, "expected a string of bytes, got %s", describeJSON(raw))
+		}
+
+		// Each byte is a code point between 0 and 255.
+		value := make([]byte, 0, len(str))
+		for _, r := range str {
+			if r > 255 {
+				return nil, errorf(path, "invalid character %q in a string of bytes", r)
+			}
+			value = append(value, byte(r))
+		}
+
+		if schema.Type == Fixed && len(value) != schema.Size {
+			return nil, errorf(path, "expected %d bytes, got %d", schema.Size, len(value))
+		}
+
+		return value, nil
+	case Enum:
+		symbol, ok := raw.(string)
+		if !ok {
+			return nil, errorf(path, "expected a symbol of %s, got %s", schema.Name, describeJSON(raw))
+		}
+
+		if !hasSymbol(schema, symbol) {
+			return nil, errorf(path, "unknown symbol %q of %s", symbol, schema.Name)
+		}
+
+		return symbol, nil
+	case Array:
+		items, ok := raw.([]interface{})
+		if !ok {
+			return nil, errorf(path, "expected an array, got %s", describeJSON(raw))
+		}
+
+		value := make([]interface{}, len(items))
+		for i, item := range items {
+			var err error
+			value[i], err = fromJSON(schema.Items, item, itemPath(path, i), wrappedUnions)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return value, nil
+	case Map:
+		object, ok := raw.(map[string]interface{})
+		if !ok {
+			return nil, errorf(path, "expected a map, got %s", describeJSON(raw))
+		}
+
+		value := make(map[string]interface{}, len(object))
+		for _, key := range sortedKeys(object) {
+			var err error
+			value[key], err = fromJSON(schema.Values, object[key], keyPath(path, key), wrappedUnions)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return value, nil
+	case Record:
+		object, ok := raw.(map[string]interface{})
+		if !ok {
+			return nil, errorf(path, "expected a record %s, got %s", schema.Name, describeJSON(raw))
+		}
+
+		value := make(map[string]interface{}, len(schema.Fields))
+		for _, field := range schema.Fields {
+			rawField, ok := object[field.Name]
+			if !ok {
+				if !field.HasDefault {
+					return nil, errorf(fieldPath(path, field.Name), "missing field")
+				}
+
+				value[field.Name] = field.Default
+				continue
+			}
+
+			var err error
+			value[field.Name], err = fromJSON(field.Type, rawField, fieldPath(path, field.Name), wrappedUnions)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		for _, key := range sortedKeys(object) {
+			if schema.Field(key) == nil {
+				return nil, errorf(fieldPath(path, key), "unknown field of the record %s", schema.Name)
+			}
+		}
+
+		return value, nil
+	case Union:
+		if !wrappedUnions {
+			if len(schema.Branches) == 0 {
+				return nil, errorf(path, "the union has no branch")
+			}
+
+			value, err := fromJSON(schema.Branches[0], raw, path, wrappedUnions)
+			if err != nil {
+				return nil, err
+			}
+
+			return UnionValue{Index: 0, Value: value}, nil
+		}
+
+		if raw == nil {
+			for i, branch := range schema.Branches {
+				if branch.Type == Null {
+					return UnionValue{Index: i, Value: nil}, nil
+				}
+			}
+
+			return nil, errorf(path, "null is not a branch of the union")
+		}
+
+		object, ok := raw.(map[string]interface{})
+		if !ok || len(object) != 1 {
+			return nil, errorf(path, "expected an object with the union branch as single key, got %s", describeJSON(raw))
+		}
+
+		for name, rawValue := range object {
+			for i, branch := range schema.Branches {
+				if branch.TypeName() != name {
+					continue
+				}
+
+				value, err := fromJSON(branch, rawValue, path, wrappedUnions)
+				if err != nil {
+					return nil, err
+				}
+
+				return UnionValue{Index: i, Value: value}, nil
+			}
+
+			return nil, errorf(path, "%q is not a branch of the union", name)
+		}
+	}
+
+	return nil, errorf(path, "unsupported type %q", schema.Type)
+}
+
+// toJSON return the value with the Avro JSON encoding, ready to be marshaled.
+func toJSON(schema *Schema, value interface{}, path string) (interface{}, error) {
+	switch schema.Type {
+	case Bytes, Fixed:
+		data, ok := value.([]byte)
+		if !ok {
+			return nil, errorf(path, "expected %s, got %s", article(schema.Type), describe(value))
+		}
+
+		if schema.Type == Fixed && len(data) != schema.Size {
+			return nil, errorf(path, "expected %d bytes, got %d", schema.Size, len(data))
+		}
+
+		var str strings.Builder
+		for _, b := range data {
+			str.WriteRune(rune(b))
+		}
+
+		return str.String(), nil
+	case Float, Double:
+		err := checkPrimitive(schema, value, path)
+		if err != nil {
+			return nil, err
+		}
+
+		f, ok := value.(float64)
+		if !ok {
+			f = float64(value.(float32))
+		}
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, errorf(path, "the value %v can't be encoded in JSON", f)
+		}
+
+		return value, nil
+	case Array:
+		items, ok := value.([]interface{})
+		if !ok {
+			return nil, errorf(path, "expected an array, got %s", describe(value))
+		}
+
+		res := make([]interface{}, len(items))
+		for i, item := range items {
+			var err error
+			res[i], err = toJSON(schema.Items, item, itemPath(path, i))
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return res, nil
+	case Map:
+		values, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, errorf(path, "expected a map, got %s", describe(value))
+		}
+
+		res := make(jsonObject, 0, len(values))
+		for _, key := range sortedKeys(values) {
+			item, err := toJSON(schema.Values, values[key], keyPath(path, key))
+			if err != nil {
+				return nil, err
+			}
+
+			res = append(res, jsonMember{key: key, value: item})
+		}
+
+		return res, nil
+	case Record:
+		values, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, errorf(path, "expected a record %s, got %s", schema.Name, describe(value))
+		}
+
+		err := checkRecordKeys(schema, values, path)
+		if err != nil {
+			return nil, err
+		}
+
+		res := make(jsonObject, 0, len(schema.Fields))
+		for _, field := range schema.Fields {
+			fieldValue, err := recordField(field, values, path)
+			if err != nil {
+				return nil, err
+			}
+
+			item, err := toJSON(field.Type, fieldValue, fieldPath(path, field.Name))
+			if err != nil {
+				return nil, err
+			}
+
+			res = append(res, jsonMember{key: field.Name, value: item})
+		}
+
+		return res, nil
+	case Union:
+		idx, value, err := unionBranch(schema, value, path)
+		if err != nil {
+			return nil, err
+		}
+
+		branch := schema.Branches[idx]
+		if branch.Type == Null {
+			return nil, nil
+		}
+
+		item, err := toJSON(branch, value, path)
+		if err != nil {
+			return nil, err
+		}
+
+		return jsonObject{{key: branch.TypeName(), value: item}}, nil
+	default:
+		err := checkPrimitive(schema, value, path)
+		if err != nil {
+			return nil, err
+		}
+
+		return value, nil
+	}
+}
+
+// jsonObject is a JSON object keeping the order of its members.
+type jsonObject []jsonMember
+
+type jsonMember struct {
+	key   string
+	value interface{}
+}
+
+// MarshalJSON is an implementation of json.Marshaler.
+func (t jsonObject) MarshalJSON() ([]byte, error) {
+	buf := bytes.NewBufferString("{")
+	for i, member := range t {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		key, err := json.Marshal(member.key)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := json.Marshal(member.value)
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+func sortedKeys(values map[string]interface{}) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func article(schemaType Type) string {
+	switch schemaType {
+	case Int, Array, Enum:
+		return "an " + string(schemaType)
+	default:
+		return "a " + string(schemaType)
+	}
+}
@@ -0,0 +1,119 @@
+package avro
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const someUserJSON = `{
+	"name": "Alice",
+	"age": {"int": 42},
+	"emails": ["alice@example.com"],
+	"status": "DELETED",
+	"address": {"com.example.Address": {"street": "Main", "tags": {"a": -1}}},
+	"id": "\u0001\u0002\u0003\u0004",
+	"previous": null
+}`
+
+func Test_DecodeJSON_success(t *testing.T) {
+	schema, err := Parse(userSchema)
+	require.NoError(t, err)
+
+	value, err := DecodeJSON(schema, []byte(someUserJSON))
+
+	assert.NoError(t, err)
+	assert.Equal(t, someDecodedUser(), value)
+}
+
+func Test_DecodeJSON_with_the_default_values(t *testing.T) {
+	schema, err := Parse(userSchema)
+	require.NoError(t, err)
+
+	value, err := DecodeJSON(schema, []byte(`{"name": "Alice", "status": "ACTIVE", "id": "abcd"}`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"name":     "Alice",
+		"age":      UnionValue{Index: 0, Value: nil},
+		"emails":   []interface{}{},
+		"status":   "ACTIVE",
+		"address":  UnionValue{Index: 0, Value: nil},
+		"id":       []byte("abcd"),
+		"previous": UnionValue{Index: 0, Value: nil},
+	}, value)
+}
+
+func Test_EncodeJSON_success(t *testing.T) {
+	schema, err := Parse(userSchema)
+	require.NoError(t, err)
+
+	data, err := EncodeJSON(schema, someUser())
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, someUserJSON, string(data))
+	// The fields are kept in the schema order.
+	assert.Equal(t, `{"name":"Alice","age":{"int":42},`, string(data[:33]))
+}
+
+func Test_JSON_bytes(t *testing.T) {
+	schema, err := Parse(`"bytes"`)
+	require.NoError(t, err)
+
+	data, err := EncodeJSON(schema, []byte{0x00, 0x41, 0xff})
+	require.NoError(t, err)
+	assert.Equal(t, `"\u0000Aÿ"`, string(data))
+
+	value, err := DecodeJSON(schema, data)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x00, 0x41, 0xff}, value)
+}
+
+func Test_DecodeJSON_errors(t *testing.T) {
+	schema, err := Parse(userSchema)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		data string
+		err  string
+	}{
+		{"invalid json", `{`, "$: invalid JSON: unexpected EOF"},
+		{"trailing data", `{} {}`, "$: unexpected data after the value"},
+		{"not an object", `[]`, "$: expected a record com.example.User, got array"},
+		{"missing field", `{}`, "$.name: missing field"},
+		{"unknown field", `{"name": "a", "status": "ACTIVE", "id": "abcd", "foo": 1}`, "$.foo: unknown field of the record com.example.User"},
+		{"unwrapped union", `{"name": "a", "age": 42}`, "$.age: expected an object with the union branch as single key, got number"},
+		{"unknown branch", `{"name": "a", "age": {"long": 42}}`, `$.age: "long" is not a branch of the union`},
+		{"int overflow", `{"name": "a", "age": {"int": 4294967296}}`, "$.age: the value 4294967296 overflow an int"},
+		{"float int", `{"name": "a", "age": {"int": 1.5}}`, "$.age: expected an int, got 1.5"},
+		{"invalid item", `{"name": "a", "emails": [1]}`, "$.emails[0]: expected a string, got number"},
+		{"unknown symbol", `{"name": "a", "status": "FOO"}`, `$.status: unknown symbol "FOO" of com.example.Status`},
+		{"invalid map value", `{"name": "a", "status": "ACTIVE", "address": {"com.example.Address": {"street": "b", "tags": {"x": "1"}}}}`, `$.address.tags["x"]: expected a long, got string`},
+		{"invalid fixed", `{"name": "a", "status": "ACTIVE", "id": "ab"}`, "$.id: expected 4 bytes, got 2"},
+		{"invalid bytes", `{"name": "a", "status": "ACTIVE", "id": "ab€d"}`, `$.id: invalid character '€' in a string of bytes`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			value, err := DecodeJSON(schema, []byte(test.data))
+
+			assert.Nil(t, value)
+			assert.EqualError(t, err, test.err)
+		})
+	}
+}
+
+func Test_EncodeJSON_with_an_invalid_value(t *testing.T) {
+	schema, err := Parse(userSchema)
+	require.NoError(t, err)
+
+	user := someUser()
+	user["emails"] = []interface{}{"a", int64(1)}
+
+	data, err := EncodeJSON(schema, user)
+
+	assert.Nil(t, data)
+	assert.EqualError(t, err, "$.emails[1]: expected a string, got long")
+}
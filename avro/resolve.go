@@ -0,0 +1,400 @@
+package avro
+
+import "strings"
+
+// DecodeResolved decode a value written with the writer schema and return it
+// as a value of the reader schema, following the Avro schema resolution:
+//   - the record fields are matched by name or by the reader aliases, the
+//     fields unknown by the reader are skipped and the fields unknown by the
+//     writer take their default value,
+//   - an int can be read as a long, a float or a double, a long as a float or
+//     a double, a float as a double and a string as bytes or the reverse,
+//   - a symbol unknown by the reader enum is read as the enum default,
+//   - a writer union is resolved with the written branch and a reader union
+//     with its branch matching the writer schema, the branches of the same
+//     type being preferred to the promotions.
+func DecodeResolved(writer *Schema, reader *Schema, data []byte) (interface{}, error) {
+	d := &decoder{data: data}
+
+	value, err := d.decodeResolved(writer, reader, rootLocation)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.pos != len(d.data) {
+		return nil, errorf("$", "%d unexpected bytes after the value", len(d.data)-d.pos)
+	}
+
+	return value, nil
+}
+
+// CheckResolution check that any value written with the writer schema can be
+// read with the reader schema.
+//
+// It's stricter than DecodeResolved as each branch of a writer union must be
+// readable, even if the written values never use it.
+func CheckResolution(writer *Schema, reader *Schema) error {
+	return checkResolution(writer, reader, rootLocation, map[[2]*Schema]bool{})
+}
+
+func checkResolution(writer *Schema, reader *Schema, loc *location, seen map[[2]*Schema]bool) error {
+	if writer.Type == Union {
+		for _, branch := range writer.Branches {
+			err := checkResolution(branch, reader, loc, seen)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	reader, err := resolveReader(writer, reader, loc)
+	if err != nil {
+		return err
+	}
+
+	switch writer.Type {
+	case Record:
+		// The recursive types are checked only once.
+		if seen[[2]*Schema{writer, reader}] {
+			return nil
+		}
+		seen[[2]*Schema{writer, reader}] = true
+
+		for _, readerField := range reader.Fields {
+			writerField := writerFieldOf(writer, reader, readerField)
+			if writerField == nil {
+				if !readerField.HasDefault {
+					return errorf(loc.field(readerField.Name).String(), "the field is missing from the writer schema and has no default value")
+				}
+
+				continue
+			}
+
+			err = checkResolution(writerField.Type, readerField.Type, loc.field(readerField.Name), seen)
+			if err != nil {
+				return err
+			}
+		}
+	case Enum:
+		if reader.EnumDefault != "" {
+			return nil
+		}
+
+		for _, symbol := range writer.Symbols {
+			if !hasSymbol(reader, symbol) {
+				return errorf(loc.String(), "the symbol %q is unknown by the reader enum %s", symbol, reader.Name)
+			}
+		}
+	case Array:
+		return checkResolution(writer.Items, reader.Items, loc.item(0), seen)
+	case Map:
+		return checkResolution(writer.Values, reader.Values, loc.key(""), seen)
+	}
+
+	return nil
+}
+
+func (t *decoder) decodeResolved(writer *Schema, reader *Schema, loc *location) (interface{}, error) {
+	if writer.Type == Union {
+		idx, err := t.readLong(loc)
+		if err != nil {
+			return nil, err
+		}
+
+		if idx < 0 || idx >= int64(len(writer.Branches)) {
+			return nil, errorf(loc.String(), "invalid union branch index %d", idx)
+		}
+
+		return t.decodeResolved(writer.Branches[idx], reader, loc)
+	}
+
+	branch, err := resolveReader(writer, reader, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := t.decodeBranch(writer, branch, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	if reader.Type == Union {
+		// The value keep the reader branch, not the writer one.
+		return UnionValue{Index: branchIndex(reader, branch), Value: value}, nil
+	}
+
+	return value, nil
+}
+
+// decodeBranch decode a value of the writer schema, which must not be an union,
+// as a value of the reader schema returned by resolveReader.
+func (t *decoder) decodeBranch(writer *Schema, reader *Schema, loc *location) (interface{}, error) {
+	leave, err := t.enter(writer, loc)
+	if err != nil {
+		return nil, err
+	}
+	defer leave()
+
+	switch writer.Type {
+	case Record:
+		return t.decodeRecord(writer, reader, loc)
+	case Enum:
+		value, err := t.decode(writer, loc)
+		if err != nil {
+			return nil, err
+		}
+
+		if hasSymbol(reader, value.(string)) {
+			return value, nil
+		}
+
+		if reader.EnumDefault == "" {
+			return nil, errorf(loc.String(), "the symbol %q is unknown by the reader enum %s", value, reader.Name)
+		}
+
+		return reader.EnumDefault, nil
+	case Array:
+		value := []interface{}{}
+		err := t.readBlocks(writer.Items, loc, func() error {
+			item, err := t.decodeResolved(writer.Items, reader.Items, loc.item(len(value)))
+			value = append(value, item)
+
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return value, nil
+	case Map:
+		value := map[string]interface{}{}
+		err := t.readBlocks(writer.Values, loc, func() error {
+			key, err := t.decode(&Schema{Type: String}, loc.key(""))
+			if err != nil {
+				return err
+			}
+
+			value[key.(string)], err = t.decodeResolved(writer.Values, reader.Values, loc.key(key.(string)))
+
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return value, nil
+	}
+
+	value, err := t.decode(writer, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	return promote(value, reader.Type), nil
+}
+
+func (t *decoder) decodeRecord(writer *Schema, reader *Schema, loc *location) (interface{}, error) {
+	value := make(map[string]interface{}, len(reader.Fields))
+	for _, writerField := range writer.Fields {
+		readerField := readerFieldOf(writer, reader, writerField)
+		if readerField == nil {
+			// The field is unknown by the reader: it's read then dropped.
+			_, err := t.decode(writerField.Type, loc.field(writerField.Name))
+			if err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		var err error
+		value[readerField.Name], err = t.decodeResolved(writerField.Type, readerField.Type, loc.field(readerField.Name))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, readerField := range reader.Fields {
+		if _, ok := value[readerField.Name]; ok {
+			continue
+		}
+
+		if !readerField.HasDefault {
+			return nil, errorf(loc.field(readerField.Name).String(), "the field is missing from the writer schema and has no default value")
+		}
+
+		value[readerField.Name] = readerField.Default
+	}
+
+	return value, nil
+}
+
+// resolveReader return the reader schema used to read a value of the writer
+// schema, which must not be an union.
+func resolveReader(writer *Schema, reader *Schema, loc *location) (*Schema, error) {
+	if reader.Type != Union {
+		if !matches(writer, reader) {
+			return nil, errorf(loc.String(), "the writer type %s can't be read as %s", writer.TypeName(), reader.TypeName())
+		}
+
+		return reader, nil
+	}
+
+	// Like the Java implementation, a branch of the same type is preferred to
+	// a promotion: a long is read as the long of ["double", "long"].
+	for _, branch := range reader.Branches {
+		if branch.Type == writer.Type && matches(writer, branch) {
+			return branch, nil
+		}
+	}
+
+	for _, branch := range reader.Branches {
+		if matches(writer, branch) {
+			return branch, nil
+		}
+	}
+
+	return nil, errorf(loc.String(), "the writer type %s doesn't match any branch of the reader union", writer.TypeName())
+}
+
+// branchIndex return the index of the branch in the union.
+func branchIndex(union *Schema, branch *Schema) int {
+	for i, candidate := range union.Branches {
+		if candidate == branch {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// readerFieldOf return the reader field reading the writer field, or nil. A
+// reader field reads a single writer field, the one returned by writerFieldOf.
+func readerFieldOf(writer *Schema, reader *Schema, writerField *Field) *Field {
+	field := reader.Field(writerField.Name)
+	if field == nil {
+		for _, candidate := range reader.Fields {
+			if contains(candidate.Aliases, writerField.Name) {
+				field = candidate
+				break
+			}
+		}
+	}
+
+	if field == nil || writerFieldOf(writer, reader, field) != writerField {
+		return nil
+	}
+
+	return field
+}
+
+// writerFieldOf return the writer field read by the reader field, or nil. The
+// field of the same name is preferred to an alias, and the writer fields
+// having the name of a reader field are never read through an alias.
+func writerFieldOf(writer *Schema, reader *Schema, readerField *Field) *Field {
+	field := writer.Field(readerField.Name)
+	if field != nil {
+		return field
+	}
+
+	for _, field := range writer.Fields {
+		if contains(readerField.Aliases, field.Name) && reader.Field(field.Name) == nil {
+			return field
+		}
+	}
+
+	return nil
+}
+
+// matches return true if a value of the writer schema can be read with the
+// reader schema, without looking into the children.
+func matches(writer *Schema, reader *Schema) bool {
+	switch {
+	case writer.Type == reader.Type:
+		switch writer.Type {
+		case Record, Enum:
+			return namesMatch(writer, reader)
+		case Fixed:
+			return namesMatch(writer, reader) && writer.Size == reader.Size
+		default:
+			return true
+		}
+	case writer.Type == Int:
+		return reader.Type == Long || reader.Type == Float || reader.Type == Double
+	case writer.Type == Long:
+		return reader.Type == Float || reader.Type == Double
+	case writer.Type == Float:
+		return reader.Type == Double
+	case writer.Type == String:
+		return reader.Type == Bytes
+	case writer.Type == Bytes:
+		return reader.Type == String
+	default:
+		return false
+	}
+}
+
+// namesMatch compare the unqualified names of two named types, the reader
+// aliases being accepted as its name.
+func namesMatch(writer *Schema, reader *Schema) bool {
+	name := writer.ShortName()
+	if name == reader.ShortName() {
+		return true
+	}
+
+	for _, alias := range reader.Aliases {
+		if alias[strings.LastIndex(alias, ".")+1:] == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// promote convert a primitive value to the reader type.
+func promote(value interface{}, readerType Type) interface{} {
+	switch v := value.(type) {
+	case int32:
+		switch readerType {
+		case Long:
+			return int64(v)
+		case Float:
+			return float32(v)
+		case Double:
+			return float64(v)
+		}
+	case int64:
+		switch readerType {
+		case Float:
+			return float32(v)
+		case Double:
+			return float64(v)
+		}
+	case float32:
+		if readerType == Double {
+			return float64(v)
+		}
+	case string:
+		if readerType == Bytes {
+			return []byte(v)
+		}
+	case []byte:
+		if readerType == String {
+			return string(v)
+		}
+	}
+
+	return value
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+
+	return false
+}
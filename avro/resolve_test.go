@@ -0,0 +1,280 @@
+package avro
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const userReaderSchema = `{
+	"type": "record",
+	"name": "User",
+	"namespace": "com.example.reader",
+	"fields": [
+		{"name": "name", "type": "bytes"},
+		{"name": "age", "type": ["null", "double"]},
+		{"name": "status", "type": {"type": "enum", "name": "Status", "symbols": ["DELETED", "ACTIVE"]}},
+		{"name": "nickname", "type": "string", "default": "none"},
+		{"name": "id", "type": {"type": "fixed", "name": "ID", "size": 4}}
+	]
+}`
+
+func Test_DecodeResolved_success(t *testing.T) {
+	writer, err := Parse(userSchema)
+	require.NoError(t, err)
+	reader, err := Parse(userReaderSchema)
+	require.NoError(t, err)
+
+	value, err := DecodeResolved(writer, reader, someUserBinary)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"name":     []byte("Alice"),
+		"age":      UnionValue{Index: 1, Value: float64(42)},
+		"status":   "DELETED",
+		"nickname": "none",
+		"id":       []byte{1, 2, 3, 4},
+	}, value)
+}
+
+func Test_DecodeResolved_with_the_same_schema(t *testing.T) {
+	schema, err := Parse(userSchema)
+	require.NoError(t, err)
+
+	value, err := DecodeResolved(schema, schema, someUserBinary)
+
+	assert.NoError(t, err)
+	assert.Equal(t, someDecodedUser(), value)
+}
+
+func Test_DecodeResolved(t *testing.T) {
+	tests := []struct {
+		name     string
+		writer   string
+		reader   string
+		value    interface{}
+		expected interface{}
+	}{
+		// Primitives and promotions.
+		{"null", `"null"`, `"null"`, nil, nil},
+		{"boolean", `"boolean"`, `"boolean"`, true, true},
+		{"int", `"int"`, `"int"`, int32(-3), int32(-3)},
+		{"int as long", `"int"`, `"long"`, int32(-3), int64(-3)},
+		{"int as float", `"int"`, `"float"`, int32(-3), float32(-3)},
+		{"int as double", `"int"`, `"double"`, int32(-3), float64(-3)},
+		{"long as float", `"long"`, `"float"`, int64(1 << 40), float32(1 << 40)},
+		{"long as double", `"long"`, `"double"`, int64(1 << 40), float64(1 << 40)},
+		{"float as double", `"float"`, `"double"`, float32(1.5), float64(1.5)},
+		{"string as bytes", `"string"`, `"bytes"`, "foo", []byte("foo")},
+		{"bytes as string", `"bytes"`, `"string"`, []byte("foo"), "foo"},
+		{"logical type", `{"type": "int", "logicalType": "date"}`, `"int"`, int32(1), int32(1)},
+
+		// Named types.
+		{"fixed", `{"type": "fixed", "name": "a.F", "size": 2}`, `{"type": "fixed", "name": "b.F", "size": 2}`, []byte{1, 2}, []byte{1, 2}},
+		{"fixed alias", `{"type": "fixed", "name": "Old", "size": 2}`, `{"type": "fixed", "name": "New", "aliases": ["x.Old"], "size": 2}`, []byte{1, 2}, []byte{1, 2}},
+		{"enum", `{"type": "enum", "name": "E", "symbols": ["A", "B"]}`, `{"type": "enum", "name": "E", "symbols": ["B", "C", "A"]}`, "A", "A"},
+		{"enum default", `{"type": "enum", "name": "E", "symbols": ["A", "B"]}`, `{"type": "enum", "name": "E", "symbols": ["A", "C"], "default": "C"}`, "B", "C"},
+		{"enum alias", `{"type": "enum", "name": "Old", "symbols": ["A"]}`, `{"type": "enum", "name": "New", "aliases": ["Old"], "symbols": ["A"]}`, "A", "A"},
+
+		// Records.
+		{
+			"removed field",
+			`{"type": "record", "name": "R", "fields": [{"name": "a", "type": "int"}, {"name": "b", "type": {"type": "array", "items": "string"}}]}`,
+			`{"type": "record", "name": "R", "fields": [{"name": "a", "type": "int"}]}`,
+			map[string]interface{}{"a": int32(1), "b": []interface{}{"x", "y"}},
+			map[string]interface{}{"a": int32(1)},
+		},
+		{
+			"added field",
+			`{"type": "record", "name": "R", "fields": [{"name": "a", "type": "int"}]}`,
+			`{"type": "record", "name": "R", "fields": [{"name": "a", "type": "int"}, {"name": "b", "type": {"type": "map", "values": "int"}, "default": {"x": 1}}]}`,
+			map[string]interface{}{"a": int32(1)},
+			map[string]interface{}{"a": int32(1), "b": map[string]interface{}{"x": int32(1)}},
+		},
+		{
+			"reordered fields",
+			`{"type": "record", "name": "R", "fields": [{"name": "a", "type": "int"}, {"name": "b", "type": "string"}]}`,
+			`{"type": "record", "name": "R", "fields": [{"name": "b", "type": "string"}, {"name": "a", "type": "long"}]}`,
+			map[string]interface{}{"a": int32(1), "b": "x"},
+			map[string]interface{}{"a": int64(1), "b": "x"},
+		},
+		{
+			"renamed field",
+			`{"type": "record", "name": "R", "fields": [{"name": "old", "type": "int"}]}`,
+			`{"type": "record", "name": "R", "fields": [{"name": "new", "aliases": ["old"], "type": "int"}]}`,
+			map[string]interface{}{"old": int32(1)},
+			map[string]interface{}{"new": int32(1)},
+		},
+		{
+			"alias of an other writer field",
+			`{"type": "record", "name": "R", "fields": [{"name": "new", "type": "string"}, {"name": "old", "type": "string"}]}`,
+			`{"type": "record", "name": "R", "fields": [{"name": "new", "aliases": ["old"], "type": "string"}]}`,
+			map[string]interface{}{"old": "a", "new": "b"},
+			map[string]interface{}{"new": "b"},
+		},
+		{
+			"alias of a reader field",
+			`{"type": "record", "name": "R", "fields": [{"name": "a", "type": "string"}]}`,
+			`{"type": "record", "name": "R", "fields": [{"name": "a", "type": "string"}, {"name": "b", "aliases": ["a"], "type": "string", "default": "x"}]}`,
+			map[string]interface{}{"a": "a"},
+			map[string]interface{}{"a": "a", "b": "x"},
+		},
+		{
+			"renamed record",
+			`{"type": "record", "name": "a.Old", "fields": []}`,
+			`{"type": "record", "name": "b.New", "aliases": ["Old"], "fields": []}`,
+			map[string]interface{}{},
+			map[string]interface{}{},
+		},
+		{
+			"recursive record",
+			`{"type": "record", "name": "Node", "fields": [{"name": "v", "type": "int"}, {"name": "next", "type": ["null", "Node"]}]}`,
+			`{"type": "record", "name": "Node", "fields": [{"name": "v", "type": "long"}, {"name": "next", "type": ["null", "Node"]}]}`,
+			map[string]interface{}{"v": int32(1), "next": map[string]interface{}{"v": int32(2), "next": nil}},
+			map[string]interface{}{"v": int64(1), "next": UnionValue{Index: 1, Value: map[string]interface{}{"v": int64(2), "next": UnionValue{Index: 0, Value: nil}}}},
+		},
+
+		// Arrays and maps.
+		{"array", `{"type": "array", "items": "int"}`, `{"type": "array", "items": "double"}`, []interface{}{int32(1), int32(2)}, []interface{}{float64(1), float64(2)}},
+		{"map", `{"type": "map", "values": "float"}`, `{"type": "map", "values": "double"}`, map[string]interface{}{"a": float32(1)}, map[string]interface{}{"a": float64(1)}},
+
+		// Unions.
+		{"writer union", `["int", "long"]`, `"long"`, int32(1), int64(1)},
+		{"reader union", `"int"`, `["null", "int"]`, int32(1), UnionValue{Index: 1, Value: int32(1)}},
+		{"reader union with promotion", `"int"`, `["null", "string", "double"]`, int32(1), UnionValue{Index: 2, Value: float64(1)}},
+		{"reader union same type first", `"long"`, `["double", "long"]`, int64(1), UnionValue{Index: 1, Value: int64(1)}},
+		{"both unions", `["null", "string"]`, `["bytes", "null"]`, "x", UnionValue{Index: 0, Value: []byte("x")}},
+		{"both unions null", `["null", "string"]`, `["string", "null"]`, nil, UnionValue{Index: 1, Value: nil}},
+		{
+			"reader union of records",
+			`{"type": "record", "name": "B", "fields": []}`,
+			`[{"type": "record", "name": "A", "fields": []}, {"type": "record", "name": "B", "fields": [{"name": "f", "type": "int", "default": 3}]}]`,
+			map[string]interface{}{},
+			UnionValue{Index: 1, Value: map[string]interface{}{"f": int32(3)}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			writer, err := Parse(test.writer)
+			require.NoError(t, err)
+			reader, err := Parse(test.reader)
+			require.NoError(t, err)
+			data, err := EncodeBinary(writer, test.value)
+			require.NoError(t, err)
+
+			value, err := DecodeResolved(writer, reader, data)
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, value)
+			assert.NoError(t, CheckResolution(writer, reader))
+		})
+	}
+}
+
+func Test_DecodeResolved_errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		writer string
+		reader string
+		data   []byte
+		err    string
+	}{
+		{"no promotion", `"long"`, `"int"`, []byte{0x02}, "$: the writer type long can't be read as int"},
+		{"no union branch", `"string"`, `["null", "int"]`, []byte{0x00}, "$: the writer type string doesn't match any branch of the reader union"},
+		{"other record name", `{"type": "record", "name": "A", "fields": []}`, `{"type": "record", "name": "B", "fields": []}`, []byte{}, "$: the writer type A can't be read as B"},
+		{
+			"missing field without default",
+			`{"type": "record", "name": "A", "fields": []}`,
+			`{"type": "record", "name": "A", "fields": [{"name": "f", "type": "int"}]}`,
+			[]byte{},
+			"$.f: the field is missing from the writer schema and has no default value",
+		},
+		{
+			"unknown symbol",
+			`{"type": "enum", "name": "E", "symbols": ["A", "B"]}`,
+			`{"type": "enum", "name": "E", "symbols": ["A"]}`,
+			[]byte{0x02},
+			`$: the symbol "B" is unknown by the reader enum E`,
+		},
+		{"other fixed size", `{"type": "fixed", "name": "F", "size": 1}`, `{"type": "fixed", "name": "F", "size": 2}`, []byte{0x00}, "$: the writer type F can't be read as F"},
+		{"no demotion", `"double"`, `"float"`, []byte{0, 0, 0, 0, 0, 0, 0, 0}, "$: the writer type double can't be read as float"},
+		{"written union branch", `["null", "string"]`, `"string"`, []byte{0x00}, "$: the writer type null can't be read as string"},
+		{"nested error", `{"type": "array", "items": "string"}`, `{"type": "array", "items": "int"}`, []byte{0x02, 0x02, 'a', 0x00}, "$[0]: the writer type string can't be read as int"},
+		{"invalid data", `"string"`, `"string"`, []byte{0x01}, "$: invalid negative length -1"},
+		{"invalid skipped field", `{"type": "record", "name": "A", "fields": [{"name": "f", "type": "string"}]}`, `{"type": "record", "name": "A", "fields": []}`, []byte{0x04}, "$.f: unexpected end of data"},
+		{"trailing bytes", `"int"`, `"long"`, []byte{0x02, 0x00}, "$: 1 unexpected bytes after the value"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			writer, err := Parse(test.writer)
+			require.NoError(t, err)
+			reader, err := Parse(test.reader)
+			require.NoError(t, err)
+
+			value, err := DecodeResolved(writer, reader, test.data)
+
+			assert.Nil(t, value)
+			assert.EqualError(t, err, test.err)
+		})
+	}
+}
+
+func Test_DecodeResolved_with_a_deep_value(t *testing.T) {
+	writer, err := Parse(`{"type": "record", "name": "N", "fields": [{"name": "n", "type": ["null", "N"]}]}`)
+	require.NoError(t, err)
+	reader, err := Parse(`{"type": "record", "name": "N", "fields": [{"name": "n", "type": ["null", "N"]}, {"name": "v", "type": "int", "default": 1}]}`)
+	require.NoError(t, err)
+
+	_, err = DecodeResolved(writer, reader, append(bytes.Repeat([]byte{0x02}, maxDepth-1), 0x00))
+	assert.NoError(t, err)
+
+	_, err = DecodeResolved(writer, reader, append(bytes.Repeat([]byte{0x02}, 80000), 0x00))
+	assert.EqualError(t, err, "$"+strings.Repeat(".n", maxDepth)+": the value is nested deeper than 1000 levels")
+}
+
+func Test_CheckResolution_errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		writer string
+		reader string
+		err    string
+	}{
+		{"no promotion", `"long"`, `"int"`, "$: the writer type long can't be read as int"},
+		{"unreadable writer branch", `["null", "string"]`, `"string"`, "$: the writer type null can't be read as string"},
+		{"no reader branch", `"boolean"`, `["null", "int"]`, "$: the writer type boolean doesn't match any branch of the reader union"},
+		{
+			"missing field without default",
+			`{"type": "record", "name": "A", "fields": []}`,
+			`{"type": "record", "name": "A", "fields": [{"name": "f", "type": "int"}]}`,
+			"$.f: the field is missing from the writer schema and has no default value",
+		},
+		{
+			"nested field",
+			`{"type": "record", "name": "A", "fields": [{"name": "f", "type": {"type": "map", "values": "string"}}]}`,
+			`{"type": "record", "name": "A", "fields": [{"name": "f", "type": {"type": "map", "values": "int"}}]}`,
+			`$.f[""]: the writer type string can't be read as int`,
+		},
+		{
+			"removed symbol",
+			`{"type": "enum", "name": "E", "symbols": ["A", "B"]}`,
+			`{"type": "enum", "name": "E", "symbols": ["A"]}`,
+			`$: the symbol "B" is unknown by the reader enum E`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			writer, err := Parse(test.writer)
+			require.NoError(t, err)
+			reader, err := Parse(test.reader)
+			require.NoError(t, err)
+
+			assert.EqualError(t, CheckResolution(writer, reader), test.err)
+		})
+	}
+}
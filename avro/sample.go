@@ -0,0 +1,269 @@
+package avro
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/rand"
+)
+
+// sampleMaxDepth is the depth after which the samples stop to grow: the arrays
+// and maps are empty, the record fields use their default value and the unions
+// use their branch with the smallest value. It stops the recursive types.
+const sampleMaxDepth = 8
+
+// The timestamps samples are between 2000-01-01 and 2030-01-01.
+const (
+	sampleMinDays = 10957
+	sampleMaxDays = 21915
+)
+
+const sampleLetters = "abcdefghijklmnopqrstuvwxyz"
+
+// Sample return a random value of the schema. The same random source state
+// always return the same value.
+//
+// The enums use their symbols, the fixed their size and the unions a random
+// branch. The fields with a default value use it one time out of two. The
+// logical types have realistic values: timestamps and dates between 2000 and
+// 2030, times of the day, decimals matching their precision and UUIDs.
+func Sample(schema *Schema, rnd *rand.Rand) interface{} {
+	return sample(schema, rnd, 0)
+}
+
+func sample(schema *Schema, rnd *rand.Rand, depth int) interface{} {
+	if schema.LogicalType != "" {
+		value, ok := sampleLogical(schema, rnd)
+		if ok {
+			return value
+		}
+	}
+
+	switch schema.Type {
+	case Null:
+		return nil
+	case Boolean:
+		return rnd.Intn(2) == 0
+	case Int:
+		return int32(rnd.Intn(2001) - 1000)
+	case Long:
+		return rnd.Int63n(2000001) - 1000000
+	case Float:
+		return float32(math.Round(rnd.Float64()*100000) / 100)
+	case Double:
+		return math.Round(rnd.Float64()*100000) / 100
+	case Bytes:
+		return sampleBytes(rnd, rnd.Intn(17))
+	case String:
+		return sampleWord(rnd)
+	case Record:
+		value := make(map[string]interface{}, len(schema.Fields))
+		for _, field := range schema.Fields {
+			if field.HasDefault && (depth >= sampleMaxDepth || rnd.Intn(2) == 0) {
+				value[field.Name] = field.Default
+				continue
+			}
+
+			value[field.Name] = sample(field.Type, rnd, depth+1)
+		}
+
+		return value
+	case Enum:
+		if len(schema.Symbols) == 0 {
+			// There is no valid value, the encoding will fail.
+			return nil
+		}
+
+		return schema.Symbols[rnd.Intn(len(schema.Symbols))]
+	case Fixed:
+		return sampleBytes(rnd, schema.Size)
+	case Array:
+		value := []interface{}{}
+		if depth < sampleMaxDepth {
+			for i := rnd.Intn(4); i > 0; i-- {
+				value = append(value, sample(schema.Items, rnd, depth+1))
+			}
+		}
+
+		return value
+	case Map:
+		value := map[string]interface{}{}
+		if depth < sampleMaxDepth {
+			for i := rnd.Intn(4); i > 0; i-- {
+				value[sampleWord(rnd)] = sample(schema.Values, rnd, depth+1)
+			}
+		}
+
+		return value
+	case Union:
+		if len(schema.Branches) == 0 {
+			return nil
+		}
+
+		idx := rnd.Intn(len(schema.Branches))
+		if depth >= sampleMaxDepth {
+			// The branch with the smallest value is used to stop the
+			// recursion, the first one on a tie.
+			min := -1
+			for i, branch := range schema.Branches {
+				size := sampleSize(branch, map[*Schema]bool{})
+				if size >= 0 && (min < 0 || size < min) {
+					idx, min = i, size
+				}
+			}
+		}
+
+		return UnionValue{Index: idx, Value: sample(schema.Branches[idx], rnd, depth)}
+	default:
+		return nil
+	}
+}
+
+// sampleSize return the number of nested records of the smallest value of the
+// schema, after the max depth, or -1 if it has no finite value. The records
+// being visited are infinite.
+func sampleSize(schema *Schema, visiting map[*Schema]bool) int {
+	switch schema.Type {
+	case Record:
+		if visiting[schema] {
+			return -1
+		}
+		visiting[schema] = true
+		defer delete(visiting, schema)
+
+		size := 1
+		for _, field := range schema.Fields {
+			if field.HasDefault {
+				continue
+			}
+
+			fieldSize := sampleSize(field.Type, visiting)
+			if fieldSize < 0 {
+				return -1
+			}
+			size += fieldSize
+		}
+
+		return size
+	case Union:
+		min := -1
+		for _, branch := range schema.Branches {
+			size := sampleSize(branch, visiting)
+			if size >= 0 && (min < 0 || size < min) {
+				min = size
+			}
+		}
+
+		return min
+	default:
+		// The arrays and maps are empty.
+		return 0
+	}
+}
+
+// sampleLogical return a value of a logical type, or false if the logical
+// type is unknown or doesn't match the type.
+func sampleLogical(schema *Schema, rnd *rand.Rand) (interface{}, bool) {
+	days := int64(sampleMinDays + rnd.Intn(sampleMaxDays-sampleMinDays))
+	millis := days*86400000 + rnd.Int63n(86400000)
+
+	switch {
+	case schema.LogicalType == "date" && schema.Type == Int:
+		return int32(days), true
+	case schema.LogicalType == "time-millis" && schema.Type == Int:
+		return int32(millis % 86400000), true
+	case schema.LogicalType == "time-micros" && schema.Type == Long:
+		return millis%86400000*1000 + rnd.Int63n(1000), true
+	case (schema.LogicalType == "timestamp-millis" || schema.LogicalType == "local-timestamp-millis") && schema.Type == Long:
+		return millis, true
+	case (schema.LogicalType == "timestamp-micros" || schema.LogicalType == "local-timestamp-micros") && schema.Type == Long:
+		return millis*1000 + rnd.Int63n(1000), true
+	case schema.LogicalType == "uuid" && schema.Type == String:
+		b := sampleBytes(rnd, 16)
+		// Version 4, variant 1.
+		b[6] = b[6]&0x0f | 0x40
+		b[8] = b[8]&0x3f | 0x80
+
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), true
+	case schema.LogicalType == "decimal" && schema.Type == Bytes:
+		return decimalBytes(sampleUnscaled(rnd, schema.Precision, 0), 0), true
+	case schema.LogicalType == "decimal" && schema.Type == Fixed:
+		return decimalBytes(sampleUnscaled(rnd, schema.Precision, schema.Size), schema.Size), true
+	default:
+		return nil, false
+	}
+}
+
+// sampleUnscaled return a random unscaled decimal value with at most
+// precision digits, fitting into size bytes if not 0.
+func sampleUnscaled(rnd *rand.Rand, precision int, size int) int64 {
+	digits := precision
+	if size > 0 {
+		// A signed value of n bytes has at least (8n-1)*log10(2) digits.
+		max := int(float64(8*size-1) * math.Log10(2))
+		if digits > max {
+			digits = max
+		}
+	}
+	if digits > 18 {
+		digits = 18
+	}
+	if digits < 1 {
+		return 0
+	}
+
+	bound := int64(math.Pow10(digits))
+
+	return rnd.Int63n(2*bound-1) - (bound - 1)
+}
+
+// decimalBytes return the big-endian two's complement of the value, on size
+// bytes or the minimal number of bytes if size is 0.
+func decimalBytes(value int64, size int) []byte {
+	n := big.NewInt(value)
+	if value < 0 {
+		// The two's complement of a negative value is 2^(8*len) + value.
+		length := size
+		if length == 0 {
+			length = big.NewInt(-value-1).BitLen()/8 + 1
+		}
+		n.Add(n, new(big.Int).Lsh(big.NewInt(1), uint(8*length)))
+	}
+
+	b := n.Bytes()
+	if size == 0 && (len(b) == 0 || value >= 0 && b[0]&0x80 != 0) {
+		// A positive value need a leading zero if its high bit is set.
+		b = append([]byte{0}, b...)
+	}
+
+	if len(b) < size {
+		pad := byte(0)
+		if value < 0 {
+			pad = 0xff
+		}
+
+		for len(b) < size {
+			b = append([]byte{pad}, b...)
+		}
+	}
+
+	return b
+}
+
+func sampleBytes(rnd *rand.Rand, size int) []byte {
+	b := make([]byte, size)
+	for i := range b {
+		b[i] = byte(rnd.Intn(256))
+	}
+
+	return b
+}
+
+func sampleWord(rnd *rand.Rand) string {
+	b := make([]byte, 4+rnd.Intn(9))
+	for i := range b {
+		b[i] = sampleLetters[rnd.Intn(len(sampleLetters))]
+	}
+
+	return string(b)
+}
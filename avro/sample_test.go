@@ -0,0 +1,141 @@
+package avro
+
+import (
+	"math/big"
+	"math/rand"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const logicalSchema = `{
+	"type": "record",
+	"name": "Logical",
+	"fields": [
+		{"name": "date", "type": {"type": "int", "logicalType": "date"}},
+		{"name": "time", "type": {"type": "int", "logicalType": "time-millis"}},
+		{"name": "timestamp", "type": {"type": "long", "logicalType": "timestamp-micros"}},
+		{"name": "id", "type": {"type": "string", "logicalType": "uuid"}},
+		{"name": "amount", "type": {"type": "bytes", "logicalType": "decimal", "precision": 6, "scale": 2}},
+		{"name": "price", "type": {"type": "fixed", "name": "Price", "size": 2, "logicalType": "decimal", "precision": 9, "scale": 2}}
+	]
+}`
+
+func Test_Sample_is_valid(t *testing.T) {
+	for _, raw := range []string{userSchema, logicalSchema} {
+		schema, err := Parse(raw)
+		require.NoError(t, err)
+
+		rnd := rand.New(rand.NewSource(42))
+		for i := 0; i < 100; i++ {
+			value := Sample(schema, rnd)
+
+			assert.NoError(t, Validate(schema, value))
+		}
+	}
+}
+
+func Test_Sample_is_deterministic(t *testing.T) {
+	schema, err := Parse(userSchema)
+	require.NoError(t, err)
+
+	first := Sample(schema, rand.New(rand.NewSource(42)))
+	second := Sample(schema, rand.New(rand.NewSource(42)))
+	other := Sample(schema, rand.New(rand.NewSource(43)))
+
+	assert.Equal(t, first, second)
+	assert.NotEqual(t, first, other)
+}
+
+func Test_Sample_with_the_logical_types(t *testing.T) {
+	schema, err := Parse(logicalSchema)
+	require.NoError(t, err)
+
+	rnd := rand.New(rand.NewSource(42))
+	for i := 0; i < 100; i++ {
+		value := Sample(schema, rnd).(map[string]interface{})
+
+		assert.True(t, value["date"].(int32) >= sampleMinDays && value["date"].(int32) < sampleMaxDays)
+		assert.True(t, value["time"].(int32) >= 0 && value["time"].(int32) < 86400000)
+		assert.True(t, value["timestamp"].(int64) >= sampleMinDays*86400000000 && value["timestamp"].(int64) < sampleMaxDays*86400000000)
+		assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), value["id"])
+
+		amount := unscaled(value["amount"].([]byte))
+		assert.True(t, amount.CmpAbs(big.NewInt(999999)) <= 0)
+
+		// 2 bytes hold 4 digits.
+		assert.Len(t, value["price"], 2)
+		assert.True(t, unscaled(value["price"].([]byte)).CmpAbs(big.NewInt(9999)) <= 0)
+	}
+}
+
+func Test_Sample_with_a_recursive_type(t *testing.T) {
+	schema, err := Parse(`{
+		"type": "record",
+		"name": "Node",
+		"fields": [{"name": "children", "type": {"type": "array", "items": "Node"}}]
+	}`)
+	require.NoError(t, err)
+
+	rnd := rand.New(rand.NewSource(42))
+	for i := 0; i < 20; i++ {
+		assert.NoError(t, Validate(schema, Sample(schema, rnd)))
+	}
+}
+
+func Test_Sample_with_an_union_of_recursive_records(t *testing.T) {
+	schema, err := Parse(`{
+		"type": "record",
+		"name": "A",
+		"fields": [{"name": "next", "type": [
+			"A",
+			{"type": "record", "name": "B", "fields": [{"name": "a", "type": "A"}]},
+			{"type": "record", "name": "C", "fields": [{"name": "n", "type": "int"}]}
+		]}]
+	}`)
+	require.NoError(t, err)
+
+	rnd := rand.New(rand.NewSource(42))
+	for i := 0; i < 20; i++ {
+		assert.NoError(t, Validate(schema, Sample(schema, rnd)))
+	}
+
+	// After the max depth, C is used as its value is the smallest.
+	value := sample(schema.Field("next").Type, rnd, sampleMaxDepth)
+	assert.Equal(t, 2, value.(UnionValue).Index)
+}
+
+func Test_decimalBytes(t *testing.T) {
+	tests := []struct {
+		value    int64
+		size     int
+		expected []byte
+	}{
+		{0, 0, []byte{0x00}},
+		{1, 0, []byte{0x01}},
+		{127, 0, []byte{0x7f}},
+		{128, 0, []byte{0x00, 0x80}},
+		{-1, 0, []byte{0xff}},
+		{-128, 0, []byte{0x80}},
+		{-129, 0, []byte{0xff, 0x7f}},
+		{1, 3, []byte{0x00, 0x00, 0x01}},
+		{-2, 3, []byte{0xff, 0xff, 0xfe}},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, decimalBytes(test.value, test.size), "%d on %d bytes", test.value, test.size)
+		assert.Equal(t, test.value, unscaled(test.expected).Int64())
+	}
+}
+
+// unscaled decode a big-endian two's complement.
+func unscaled(b []byte) *big.Int {
+	n := new(big.Int).SetBytes(b)
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		n.Sub(n, new(big.Int).Lsh(big.NewInt(1), uint(8*len(b))))
+	}
+
+	return n
+}
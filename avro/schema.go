@@ -0,0 +1,537 @@
+package avro
+
+import (
+	"encoding/json"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Peltoche/avro-gateway/internal"
+)
+
+// Type of an Avro schema.
+type Type string
+
+// All the Avro types.
+const (
+	Null    Type = "null"
+	Boolean Type = "boolean"
+	Int     Type = "int"
+	Long    Type = "long"
+	Float   Type = "float"
+	Double  Type = "double"
+	Bytes   Type = "bytes"
+	String  Type = "string"
+	Record  Type = "record"
+	Enum    Type = "enum"
+	Array   Type = "array"
+	Map     Type = "map"
+	Union   Type = "union"
+	Fixed   Type = "fixed"
+)
+
+var primitives = map[Type]bool{
+	Null: true, Boolean: true, Int: true, Long: true, Float: true, Double: true, Bytes: true, String: true,
+}
+
+var nameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Schema is a parsed Avro schema.
+//
+// The values matching a schema are represented with:
+//   - null: nil
+//   - boolean: bool
+//   - int: int32
+//   - long: int64
+//   - float: float32
+//   - double: float64
+//   - bytes and fixed: []byte
+//   - string and enum: string
+//   - record and map: map[string]interface{}
+//   - array: []interface{}
+//   - union: an UnionValue with the branch index and its value
+//
+// The encoders also accept the value of a branch without UnionValue, the first
+// branch matching its Go type being used.
+type Schema struct {
+	Type Type
+	// Name is the full name of the named types: record, enum and fixed.
+	Name    string
+	Aliases []string
+	Doc     string
+	// Fields of a record.
+	Fields []*Field
+	// Symbols of an enum and EnumDefault, the symbol used when reading an
+	// unknown symbol. EnumDefault is empty if not set.
+	Symbols     []string
+	EnumDefault string
+	// Items of an array.
+	Items *Schema
+	// Values of a map.
+	Values *Schema
+	// Branches of an union.
+	Branches []*Schema
+	// Size of a fixed.
+	Size int
+	// LogicalType annotate a primitive or fixed type. It doesn't change the
+	// encoding.
+	LogicalType string
+	Precision   int
+	Scale       int
+}
+
+// Field of a record.
+type Field struct {
+	Name    string
+	Aliases []string
+	Doc     string
+	Type    *Schema
+	// Default is the default value converted like the field values and
+	// HasDefault is set if the field has a default value.
+	Default    interface{}
+	HasDefault bool
+	Order      string
+}
+
+// TypeName return the full name of a named type or the type.
+func (t *Schema) TypeName() string {
+	if t.Name != "" {
+		return t.Name
+	}
+
+	return string(t.Type)
+}
+
+// Namespace return the namespace of a named type.
+func (t *Schema) Namespace() string {
+	idx := strings.LastIndex(t.Name, ".")
+	if idx < 0 {
+		return ""
+	}
+
+	return t.Name[:idx]
+}
+
+// ShortName return the name of a named type without its namespace.
+func (t *Schema) ShortName() string {
+	return t.Name[strings.LastIndex(t.Name, ".")+1:]
+}
+
+// Field return the record field with the given name or nil.
+func (t *Schema) Field(name string) *Field {
+	for _, field := range t.Fields {
+		if field.Name == name {
+			return field
+		}
+	}
+
+	return nil
+}
+
+// Parse an Avro schema in its JSON form.
+func Parse(schema string) (*Schema, error) {
+	decoder := json.NewDecoder(strings.NewReader(schema))
+	decoder.UseNumber()
+
+	var raw interface{}
+	err := decoder.Decode(&raw)
+	if err != nil {
+		return nil, internal.Errorf(internal.ValidationError, "invalid schema: %s", err)
+	}
+
+	if _, err = decoder.Token(); err != io.EOF {
+		return nil, internal.NewError(internal.ValidationError, "invalid schema: unexpected data after the schema")
+	}
+
+	p := &parser{names: map[string]*Schema{}}
+
+	res, err := p.parse(raw, "")
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.checkFinite()
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+type parser struct {
+	names map[string]*Schema
+}
+
+func (t *parser) parse(raw interface{}, namespace string) (*Schema, error) {
+	switch value := raw.(type) {
+	case string:
+		return t.parseName(value, namespace)
+	case []interface{}:
+		return t.parseUnion(value, namespace)
+	case map[string]interface{}:
+		return t.parseObject(value, namespace)
+	default:
+		return nil, internal.Errorf(internal.ValidationError, "invalid schema: unexpected %s", describeJSON(raw))
+	}
+}
+
+// parseName return the primitive type or the named type with this name.
+func (t *parser) parseName(name string, namespace string) (*Schema, error) {
+	if primitives[Type(name)] {
+		return &Schema{Type: Type(name)}, nil
+	}
+
+	if !strings.Contains(name, ".") && namespace != "" {
+		schema, ok := t.names[namespace+"."+name]
+		if ok {
+			return schema, nil
+		}
+	}
+
+	schema, ok := t.names[name]
+	if !ok {
+		return nil, internal.Errorf(internal.ValidationError, "invalid schema: unknown type %q", name)
+	}
+
+	return schema, nil
+}
+
+func (t *parser) parseUnion(raw []interface{}, namespace string) (*Schema, error) {
+	if len(raw) == 0 {
+		return nil, internal.NewError(internal.ValidationError, "invalid schema: an union must have at least one branch")
+	}
+
+	schema := &Schema{Type: Union}
+	seen := map[string]bool{}
+
+	for _, rawBranch := range raw {
+		branch, err := t.parse(rawBranch, namespace)
+		if err != nil {
+			return nil, err
+		}
+
+		if branch.Type == Union {
+			return nil, internal.NewError(internal.ValidationError, "invalid schema: an union can't contain an union")
+		}
+
+		if seen[branch.TypeName()] {
+			return nil, internal.Errorf(internal.ValidationError, "invalid schema: the type %q appear twice in an union", branch.TypeName())
+		}
+		seen[branch.TypeName()] = true
+
+		schema.Branches = append(schema.Branches, branch)
+	}
+
+	return schema, nil
+}
+
+func (t *parser) parseObject(raw map[string]interface{}, namespace string) (*Schema, error) {
+	rawType, ok := raw["type"]
+	if !ok {
+		return nil, internal.NewError(internal.ValidationError, `invalid schema: missing attribute "type"`)
+	}
+
+	typeName, ok := rawType.(string)
+	if !ok {
+		return t.parse(rawType, namespace)
+	}
+
+	switch Type(typeName) {
+	case Record, "error":
+		return t.parseRecord(raw, namespace)
+	case Enum:
+		return t.parseEnum(raw, namespace)
+	case Fixed:
+		return t.parseFixed(raw, namespace)
+	case Array:
+		items, ok := raw["items"]
+		if !ok {
+			return nil, internal.NewError(internal.ValidationError, `invalid schema: missing attribute "items" of an array`)
+		}
+
+		itemsSchema, err := t.parse(items, namespace)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Schema{Type: Array, Items: itemsSchema}, nil
+	case Map:
+		values, ok := raw["values"]
+		if !ok {
+			return nil, internal.NewError(internal.ValidationError, `invalid schema: missing attribute "values" of a map`)
+		}
+
+		valuesSchema, err := t.parse(values, namespace)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Schema{Type: Map, Values: valuesSchema}, nil
+	}
+
+	schema, err := t.parseName(typeName, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	if !primitives[schema.Type] {
+		return schema, nil
+	}
+
+	// The attributes of a primitive type are kept only for the logical types.
+	schema.LogicalType, _ = raw["logicalType"].(string)
+	schema.Precision, _ = intAttribute(raw, "precision")
+	schema.Scale, _ = intAttribute(raw, "scale")
+
+	return schema, nil
+}
+
+func (t *parser) parseRecord(raw map[string]interface{}, namespace string) (*Schema, error) {
+	schema, err := t.register(raw, Record, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	rawFields, ok := raw["fields"].([]interface{})
+	if !ok {
+		return nil, internal.Errorf(internal.ValidationError, `invalid schema: missing attribute "fields" of the record %q`, schema.Name)
+	}
+
+	schema.Fields = []*Field{}
+	for _, rawField := range rawFields {
+		field, err := t.parseField(rawField, schema)
+		if err != nil {
+			return nil, err
+		}
+
+		if schema.Field(field.Name) != nil {
+			return nil, internal.Errorf(internal.ValidationError, "invalid schema: the field %q appear twice in the record %q", field.Name, schema.Name)
+		}
+
+		schema.Fields = append(schema.Fields, field)
+	}
+
+	return schema, nil
+}
+
+func (t *parser) parseField(raw interface{}, record *Schema) (*Field, error) {
+	fieldAttributes, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, internal.Errorf(internal.ValidationError, "invalid schema: invalid field in the record %q", record.Name)
+	}
+
+	name, _ := fieldAttributes["name"].(string)
+	if !nameRegexp.MatchString(name) {
+		return nil, internal.Errorf(internal.ValidationError, "invalid schema: invalid field name %q in the record %q", name, record.Name)
+	}
+
+	rawType, ok := fieldAttributes["type"]
+	if !ok {
+		return nil, internal.Errorf(internal.ValidationError, `invalid schema: missing attribute "type" of the field %q in the record %q`, name, record.Name)
+	}
+
+	fieldType, err := t.parse(rawType, record.Namespace())
+	if err != nil {
+		return nil, err
+	}
+
+	field := &Field{
+		Name:    name,
+		Aliases: stringsAttribute(fieldAttributes, "aliases"),
+		Type:    fieldType,
+	}
+	field.Doc, _ = fieldAttributes["doc"].(string)
+	field.Order, _ = fieldAttributes["order"].(string)
+
+	rawDefault, ok := fieldAttributes["default"]
+	if ok {
+		field.Default, err = fromJSON(fieldType, rawDefault, "$", false)
+		if err != nil {
+			return nil, internal.Errorf(internal.ValidationError, "invalid schema: invalid default value of the field %q in the record %q: %s", name, record.Name, err)
+		}
+		field.HasDefault = true
+	}
+
+	return field, nil
+}
+
+func (t *parser) parseEnum(raw map[string]interface{}, namespace string) (*Schema, error) {
+	schema, err := t.register(raw, Enum, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := raw["symbols"].([]interface{}); !ok {
+		return nil, internal.Errorf(internal.ValidationError, `invalid schema: missing attribute "symbols" of the enum %q`, schema.Name)
+	}
+
+	schema.Symbols = stringsAttribute(raw, "symbols")
+	seen := map[string]bool{}
+	for _, symbol := range schema.Symbols {
+		if !nameRegexp.MatchString(symbol) || seen[symbol] {
+			return nil, internal.Errorf(internal.ValidationError, "invalid schema: invalid or duplicated symbol %q in the enum %q", symbol, schema.Name)
+		}
+		seen[symbol] = true
+	}
+
+	rawDefault, ok := raw["default"]
+	if ok {
+		schema.EnumDefault, _ = rawDefault.(string)
+		if !seen[schema.EnumDefault] {
+			return nil, internal.Errorf(internal.ValidationError, "invalid schema: the default of the enum %q is not one of its symbols", schema.Name)
+		}
+	}
+
+	return schema, nil
+}
+
+func (t *parser) parseFixed(raw map[string]interface{}, namespace string) (*Schema, error) {
+	schema, err := t.register(raw, Fixed, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	size, ok := intAttribute(raw, "size")
+	if !ok || size < 0 {
+		return nil, internal.Errorf(internal.ValidationError, `invalid schema: missing or invalid attribute "size" of the fixed %q`, schema.Name)
+	}
+
+	schema.Size = size
+	schema.LogicalType, _ = raw["logicalType"].(string)
+	schema.Precision, _ = intAttribute(raw, "precision")
+	schema.Scale, _ = intAttribute(raw, "scale")
+
+	return schema, nil
+}
+
+// register create a named type. It's registered before parsing its content
+// so it can be used recursively.
+func (t *parser) register(raw map[string]interface{}, schemaType Type, namespace string) (*Schema, error) {
+	name, _ := raw["name"].(string)
+	if rawNamespace, ok := raw["namespace"].(string); ok {
+		namespace = rawNamespace
+	}
+
+	fullName := name
+	if !strings.Contains(name, ".") && namespace != "" {
+		fullName = namespace + "." + name
+	}
+
+	for _, part := range strings.Split(fullName, ".") {
+		if !nameRegexp.MatchString(part) {
+			return nil, internal.Errorf(internal.ValidationError, "invalid schema: invalid %s name %q", schemaType, fullName)
+		}
+	}
+
+	if primitives[Type(fullName)] {
+		return nil, internal.Errorf(internal.ValidationError, "invalid schema: the primitive type %q can't be redefined", fullName)
+	}
+
+	if _, taken := t.names[fullName]; taken {
+		return nil, internal.Errorf(internal.ValidationError, "invalid schema: the type %q is defined twice", fullName)
+	}
+
+	schema := &Schema{
+		Type:    schemaType,
+		Name:    fullName,
+		Aliases: stringsAttribute(raw, "aliases"),
+	}
+	schema.Doc, _ = raw["doc"].(string)
+
+	t.names[fullName] = schema
+
+	return schema, nil
+}
+
+// checkFinite refuse the records without any finite value, like a record
+// containing itself without an union to stop the recursion. Their values can't
+// be written and reading them never ends.
+func (t *parser) checkFinite() error {
+	finite := map[*Schema]bool{}
+
+	// A record is finite once all its fields are, so the finite records are
+	// found one level at a time until nothing change.
+	for changed := true; changed; {
+		changed = false
+		for _, schema := range t.names {
+			if schema.Type != Record || finite[schema] {
+				continue
+			}
+
+			ok := true
+			for _, field := range schema.Fields {
+				if !isFinite(field.Type, finite) {
+					ok = false
+					break
+				}
+			}
+
+			if ok {
+				finite[schema] = true
+				changed = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(t.names))
+	for name, schema := range t.names {
+		if schema.Type == Record && !finite[schema] {
+			names = append(names, name)
+		}
+	}
+
+	if len(names) > 0 {
+		sort.Strings(names)
+		return internal.Errorf(internal.ValidationError, "invalid schema: the record %q has no finite value, a recursion must go through an union, an array or a map", names[0])
+	}
+
+	return nil
+}
+
+// isFinite return true if the schema has a finite value, given the records
+// already known as finite.
+func isFinite(schema *Schema, finite map[*Schema]bool) bool {
+	switch schema.Type {
+	case Record:
+		return finite[schema]
+	case Union:
+		for _, branch := range schema.Branches {
+			if isFinite(branch, finite) {
+				return true
+			}
+		}
+
+		return false
+	default:
+		// The arrays and maps can be empty.
+		return true
+	}
+}
+
+func intAttribute(raw map[string]interface{}, key string) (int, bool) {
+	number, ok := raw[key].(json.Number)
+	if !ok {
+		return 0, false
+	}
+
+	value, err := number.Int64()
+	if err != nil {
+		return 0, false
+	}
+
+	return int(value), true
+}
+
+func stringsAttribute(raw map[string]interface{}, key string) []string {
+	values, _ := raw[key].([]interface{})
+
+	res := []string{}
+	for _, value := range values {
+		if str, ok := value.(string); ok {
+			res = append(res, str)
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,143 @@
+package avro
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const userSchema = `{
+	"type": "record",
+	"name": "User",
+	"namespace": "com.example",
+	"doc": "A user.",
+	"fields": [
+		{"name": "name", "type": "string"},
+		{"name": "age", "type": ["null", "int"], "default": null},
+		{"name": "emails", "type": {"type": "array", "items": "string"}, "default": []},
+		{"name": "status", "type": {"type": "enum", "name": "Status", "symbols": ["ACTIVE", "DELETED"], "default": "ACTIVE"}},
+		{"name": "address", "type": ["null", {
+			"type": "record",
+			"name": "Address",
+			"fields": [
+				{"name": "street", "type": "string"},
+				{"name": "tags", "type": {"type": "map", "values": "long"}}
+			]
+		}], "default": null},
+		{"name": "id", "type": {"type": "fixed", "name": "ID", "size": 4}},
+		{"name": "previous", "type": ["null", "User"], "default": null}
+	]
+}`
+
+func Test_Parse_success(t *testing.T) {
+	schema, err := Parse(userSchema)
+	require.NoError(t, err)
+
+	assert.Equal(t, Record, schema.Type)
+	assert.Equal(t, "com.example.User", schema.Name)
+	assert.Equal(t, "com.example", schema.Namespace())
+	assert.Equal(t, "User", schema.ShortName())
+	assert.Equal(t, "A user.", schema.Doc)
+	require.Len(t, schema.Fields, 7)
+
+	age := schema.Field("age")
+	assert.Equal(t, Union, age.Type.Type)
+	assert.True(t, age.HasDefault)
+	assert.Equal(t, UnionValue{Index: 0, Value: nil}, age.Default)
+
+	emails := schema.Field("emails")
+	assert.Equal(t, Array, emails.Type.Type)
+	assert.Equal(t, String, emails.Type.Items.Type)
+	assert.Equal(t, []interface{}{}, emails.Default)
+
+	status := schema.Field("status").Type
+	assert.Equal(t, "com.example.Status", status.Name)
+	assert.Equal(t, []string{"ACTIVE", "DELETED"}, status.Symbols)
+	assert.Equal(t, "ACTIVE", status.EnumDefault)
+
+	address := schema.Field("address").Type.Branches[1]
+	assert.Equal(t, "com.example.Address", address.Name)
+	assert.Equal(t, Long, address.Field("tags").Type.Values.Type)
+
+	assert.Equal(t, 4, schema.Field("id").Type.Size)
+
+	// The recursive reference use the same schema.
+	assert.True(t, schema == schema.Field("previous").Type.Branches[1])
+}
+
+func Test_Parse_primitives(t *testing.T) {
+	schema, err := Parse(`"long"`)
+	require.NoError(t, err)
+	assert.Equal(t, &Schema{Type: Long}, schema)
+
+	schema, err = Parse(`{"type": "int", "logicalType": "date"}`)
+	require.NoError(t, err)
+	assert.Equal(t, &Schema{Type: Int, LogicalType: "date"}, schema)
+
+	schema, err = Parse(`{"type": "bytes", "logicalType": "decimal", "precision": 4, "scale": 2}`)
+	require.NoError(t, err)
+	assert.Equal(t, &Schema{Type: Bytes, LogicalType: "decimal", Precision: 4, Scale: 2}, schema)
+}
+
+func Test_Parse_namespaces(t *testing.T) {
+	schema, err := Parse(`{
+		"type": "record",
+		"name": "a.b.Outer",
+		"fields": [
+			{"name": "inner", "type": {"type": "record", "name": "Inner", "fields": []}},
+			{"name": "other", "type": {"type": "record", "name": "Other", "namespace": "c", "fields": [
+				{"name": "back", "type": "a.b.Inner"}
+			]}},
+			{"name": "again", "type": "Inner"}
+		]
+	}`)
+	require.NoError(t, err)
+
+	assert.Equal(t, "a.b.Inner", schema.Field("inner").Type.Name)
+	assert.Equal(t, "c.Other", schema.Field("other").Type.Name)
+	assert.True(t, schema.Field("inner").Type == schema.Field("again").Type)
+}
+
+func Test_Parse_errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		err    string
+	}{
+		{"invalid json", `{`, "validation error: invalid schema: unexpected EOF"},
+		{"trailing data", `"int" "int"`, "validation error: invalid schema: unexpected data after the schema"},
+		{"unknown type", `"foo"`, `validation error: invalid schema: unknown type "foo"`},
+		{"missing type", `{}`, `validation error: invalid schema: missing attribute "type"`},
+		{"number", `12`, "validation error: invalid schema: unexpected number"},
+		{"nested union", `["int", ["string"]]`, "validation error: invalid schema: an union can't contain an union"},
+		{"empty union", `{"type": "record", "name": "A", "fields": [{"name": "a", "type": [], "default": null}]}`, "validation error: invalid schema: an union must have at least one branch"},
+		{"duplicated branch", `["int", "int"]`, `validation error: invalid schema: the type "int" appear twice in an union`},
+		{"missing items", `{"type": "array"}`, `validation error: invalid schema: missing attribute "items" of an array`},
+		{"missing values", `{"type": "map"}`, `validation error: invalid schema: missing attribute "values" of a map`},
+		{"invalid name", `{"type": "record", "name": "1foo", "fields": []}`, `validation error: invalid schema: invalid record name "1foo"`},
+		{"redefined primitive", `{"type": "fixed", "name": "int", "size": 1}`, `validation error: invalid schema: the primitive type "int" can't be redefined`},
+		{"used before its definition", `["A", {"type": "fixed", "name": "A", "size": 1}]`, `validation error: invalid schema: unknown type "A"`},
+		{"redefined type", `[{"type": "fixed", "name": "A", "size": 1}, {"type": "enum", "name": "A", "symbols": []}]`, `validation error: invalid schema: the type "A" is defined twice`},
+		{"missing fields", `{"type": "record", "name": "A"}`, `validation error: invalid schema: missing attribute "fields" of the record "A"`},
+		{"invalid field name", `{"type": "record", "name": "A", "fields": [{"name": "a-b", "type": "int"}]}`, `validation error: invalid schema: invalid field name "a-b" in the record "A"`},
+		{"missing field type", `{"type": "record", "name": "A", "fields": [{"name": "a"}]}`, `validation error: invalid schema: missing attribute "type" of the field "a" in the record "A"`},
+		{"duplicated field", `{"type": "record", "name": "A", "fields": [{"name": "a", "type": "int"}, {"name": "a", "type": "int"}]}`, `validation error: invalid schema: the field "a" appear twice in the record "A"`},
+		{"invalid default", `{"type": "record", "name": "A", "fields": [{"name": "a", "type": ["null", "int"], "default": 1}]}`, `validation error: invalid schema: invalid default value of the field "a" in the record "A": $: expected null, got number`},
+		{"infinite record", `{"type": "record", "name": "A", "fields": [{"name": "a", "type": "A"}]}`, `validation error: invalid schema: the record "A" has no finite value, a recursion must go through an union, an array or a map`},
+		{"infinite mutual records", `{"type": "record", "name": "A", "fields": [{"name": "b", "type": {"type": "record", "name": "B", "fields": [{"name": "a", "type": ["A"]}]}}]}`, `validation error: invalid schema: the record "A" has no finite value, a recursion must go through an union, an array or a map`},
+		{"missing symbols", `{"type": "enum", "name": "A"}`, `validation error: invalid schema: missing attribute "symbols" of the enum "A"`},
+		{"duplicated symbol", `{"type": "enum", "name": "A", "symbols": ["B", "B"]}`, `validation error: invalid schema: invalid or duplicated symbol "B" in the enum "A"`},
+		{"unknown enum default", `{"type": "enum", "name": "A", "symbols": ["B"], "default": "C"}`, `validation error: invalid schema: the default of the enum "A" is not one of its symbols`},
+		{"missing size", `{"type": "fixed", "name": "A"}`, `validation error: invalid schema: missing or invalid attribute "size" of the fixed "A"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			schema, err := Parse(test.schema)
+
+			assert.Nil(t, schema)
+			assert.EqualError(t, err, test.err)
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package avro
+
+// UnionValue is the value of an union. The branch is kept as the values of
+// some branches have the same Go type, like a string and an enum.
+type UnionValue struct {
+	// Index of the branch in the union.
+	Index int
+	Value interface{}
+}
+
+// Validate check that the value match the schema.
+func Validate(schema *Schema, value interface{}) error {
+	return new(encoder).encode(schema, value, "$")
+}
+
+// checkPrimitive check the value of a type without any children.
+func checkPrimitive(schema *Schema, value interface{}, path string) error {
+	var ok bool
+	switch schema.Type {
+	case Null:
+		ok = value == nil
+	case Boolean:
+		_, ok = value.(bool)
+	case Int:
+		_, ok = value.(int32)
+	case Long:
+		_, ok = value.(int64)
+	case Float:
+		_, ok = value.(float32)
+	case Double:
+		_, ok = value.(float64)
+	case String:
+		_, ok = value.(string)
+	case Enum:
+		symbol, isString := value.(string)
+		if isString && !hasSymbol(schema, symbol) {
+			return errorf(path, "unknown symbol %q of %s", symbol, schema.Name)
+		}
+		ok = isString
+	default:
+		return errorf(path, "unsupported type %q", schema.Type)
+	}
+
+	if !ok {
+		return errorf(path, "expected %s, got %s", article(schema.Type), describe(value))
+	}
+
+	return nil
+}
+
+// checkRecordKeys refuse the values which are not fields of the record.
+func checkRecordKeys(schema *Schema, values map[string]interface{}, path string) error {
+	for _, key := range sortedKeys(values) {
+		if schema.Field(key) == nil {
+			return errorf(fieldPath(path, key), "unknown field of the record %s", schema.Name)
+		}
+	}
+
+	return nil
+}
+
+// recordField return the value of a record field, or its default value if
+// missing.
+func recordField(field *Field, values map[string]interface{}, path string) (interface{}, error) {
+	value, ok := values[field.Name]
+	if ok {
+		return value, nil
+	}
+
+	if !field.HasDefault {
+		return nil, errorf(fieldPath(path, field.Name), "missing field")
+	}
+
+	return field.Default, nil
+}
+
+// unionBranch return the branch index and the branch value of an union value.
+// The branch of a value without UnionValue is found with branchOf.
+func unionBranch(schema *Schema, value interface{}, path string) (int, interface{}, error) {
+	union, ok := value.(UnionValue)
+	if !ok {
+		idx, err := branchOf(schema, value, path)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		return idx, value, nil
+	}
+
+	if union.Index < 0 || union.Index >= len(schema.Branches) {
+		return 0, nil, errorf(path, "invalid union branch index %d", union.Index)
+	}
+
+	return union.Index, union.Value, nil
+}
+
+// branchOf return the index of the first union branch matching the Go type of
+// the value. The content of the arrays, maps and records is not checked, so a
+// map matching the fields of a record use the first of them.
+func branchOf(schema *Schema, value interface{}, path string) (int, error) {
+	for i, branch := range schema.Branches {
+		if matchesType(branch, value) {
+			return i, nil
+		}
+	}
+
+	return 0, errorf(path, "the %s value doesn't match any branch of the union", describe(value))
+}
+
+// matchesType return true if the Go type of the value match the schema,
+// without checking its content.
+func matchesType(schema *Schema, value interface{}) bool {
+	switch schema.Type {
+	case Bytes:
+		_, ok := value.([]byte)
+		return ok
+	case Fixed:
+		data, ok := value.([]byte)
+		return ok && len(data) == schema.Size
+	case Record:
+		values, ok := value.(map[string]interface{})
+		return ok && checkRecordKeys(schema, values, "$") == nil
+	case Map:
+		_, ok := value.(map[string]interface{})
+		return ok
+	case Array:
+		_, ok := value.([]interface{})
+		return ok
+	case Union:
+		return false
+	default:
+		return checkPrimitive(schema, value, "$") == nil
+	}
+}
+
+func hasSymbol(schema *Schema, symbol string) bool {
+	return symbolIndex(schema, symbol) >= 0
+}
+
+func symbolIndex(schema *Schema, symbol string) int {
+	for i, candidate := range schema.Symbols {
+		if candidate == symbol {
+			return i
+		}
+	}
+
+	return -1
+}
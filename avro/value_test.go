@@ -0,0 +1,92 @@
+package avro
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Validate_success(t *testing.T) {
+	schema, err := Parse(userSchema)
+	require.NoError(t, err)
+
+	assert.NoError(t, Validate(schema, someUser()))
+}
+
+func Test_Validate_with_an_invalid_value(t *testing.T) {
+	schema, err := Parse(userSchema)
+	require.NoError(t, err)
+
+	user := someUser()
+	user["address"].(map[string]interface{})["street"] = nil
+
+	assert.EqualError(t, Validate(schema, user), "$.address.street: expected a string, got null")
+}
+
+func Test_branchOf(t *testing.T) {
+	schema, err := Parse(`["null", "int", "long", {"type": "map", "values": "int"}]`)
+	require.NoError(t, err)
+
+	for value, expected := range map[interface{}]int{nil: 0, int32(1): 1, int64(1): 2} {
+		idx, err := branchOf(schema, value, "$")
+		assert.NoError(t, err)
+		assert.Equal(t, expected, idx)
+	}
+
+	idx, err := branchOf(schema, map[string]interface{}{"a": int32(1)}, "$")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, idx)
+}
+
+func Test_branchOf_without_match(t *testing.T) {
+	schema, err := Parse(`["null", "int"]`)
+	require.NoError(t, err)
+
+	_, err = branchOf(schema, "foo", "$")
+
+	assert.EqualError(t, err, "$: the string value doesn't match any branch of the union")
+}
+
+func Test_UnionValue_keep_the_branch(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		json   string
+		data   []byte
+	}{
+		{"string and enum", `["string", {"type": "enum", "name": "E", "symbols": ["A"]}]`, `{"E":"A"}`, []byte{0x02, 0x00}},
+		{"bytes and fixed", `["bytes", {"type": "fixed", "name": "F", "size": 1}]`, `{"F":"a"}`, []byte{0x02, 'a'}},
+		{"map and record", `[{"type": "map", "values": "int"}, {"type": "record", "name": "R", "fields": [{"name": "a", "type": "int"}]}]`, `{"R":{"a":1}}`, []byte{0x02, 0x02}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			schema, err := Parse(test.schema)
+			require.NoError(t, err)
+
+			value, err := DecodeJSON(schema, []byte(test.json))
+			require.NoError(t, err)
+
+			data, err := EncodeBinary(schema, value)
+			require.NoError(t, err)
+			assert.Equal(t, test.data, data)
+
+			value, err = DecodeBinary(schema, data)
+			require.NoError(t, err)
+
+			res, err := EncodeJSON(schema, value)
+			require.NoError(t, err)
+			assert.Equal(t, test.json, string(res))
+		})
+	}
+}
+
+func Test_EncodeJSON_with_an_invalid_branch_index(t *testing.T) {
+	schema, err := Parse(`["null", "int"]`)
+	require.NoError(t, err)
+
+	_, err = EncodeJSON(schema, UnionValue{Index: 2, Value: int32(1)})
+
+	assert.EqualError(t, err, "$: invalid union branch index 2")
+}
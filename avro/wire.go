@@ -0,0 +1,35 @@
+package avro
+
+import (
+	"encoding/binary"
+)
+
+// MagicByte is the first byte of the Confluent wire format.
+const MagicByte = 0
+
+// wireHeaderSize is the size of the magic byte and the schema id.
+const wireHeaderSize = 5
+
+// ParseWireFormat split a message using the Confluent wire format into the
+// schema id and the Avro binary payload.
+func ParseWireFormat(data []byte) (int, []byte, error) {
+	if len(data) < wireHeaderSize {
+		return 0, nil, errorf("$", "the message is too short for the Confluent wire format: %d bytes", len(data))
+	}
+
+	if data[0] != MagicByte {
+		return 0, nil, errorf("$", "unknown magic byte %d", data[0])
+	}
+
+	return int(binary.BigEndian.Uint32(data[1:wireHeaderSize])), data[wireHeaderSize:], nil
+}
+
+// EncodeWireFormat prefix the Avro binary payload with the magic byte and the
+// schema id.
+func EncodeWireFormat(schemaID int, payload []byte) []byte {
+	data := make([]byte, wireHeaderSize, wireHeaderSize+len(payload))
+	data[0] = MagicByte
+	binary.BigEndian.PutUint32(data[1:wireHeaderSize], uint32(schemaID))
+
+	return append(data, payload...)
+}
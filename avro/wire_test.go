@@ -0,0 +1,31 @@
+package avro
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_EncodeWireFormat(t *testing.T) {
+	assert.Equal(t, []byte{0, 0, 0, 1, 2, 42}, EncodeWireFormat(258, []byte{42}))
+}
+
+func Test_ParseWireFormat_success(t *testing.T) {
+	schemaID, payload, err := ParseWireFormat([]byte{0, 0, 0, 1, 2, 42})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 258, schemaID)
+	assert.Equal(t, []byte{42}, payload)
+}
+
+func Test_ParseWireFormat_with_a_short_message(t *testing.T) {
+	_, _, err := ParseWireFormat([]byte{0, 0, 1})
+
+	assert.EqualError(t, err, "$: the message is too short for the Confluent wire format: 3 bytes")
+}
+
+func Test_ParseWireFormat_with_an_unknown_magic_byte(t *testing.T) {
+	_, _, err := ParseWireFormat([]byte{1, 0, 0, 1, 2, 42})
+
+	assert.EqualError(t, err, "$: unknown magic byte 1")
+}
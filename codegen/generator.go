@@ -0,0 +1,360 @@
+package codegen
+
+import (
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/Peltoche/avro-gateway/avro"
+)
+
+// initialisms are written in upper case in the Go names, like "userID".
+var initialisms = map[string]bool{
+	"API":  true,
+	"HTTP": true,
+	"ID":   true,
+	"IP":   true,
+	"JSON": true,
+	"SQL":  true,
+	"URL":  true,
+	"UUID": true,
+}
+
+// Generate return the Go source of the types matching the schema, formatted
+// with gofmt.
+//
+// The records are generated as structs with an "avro" tag per field, the enums
+// as string types with a constant per symbol and the fixed as byte arrays. The
+// unions with null and a single other branch are generated as pointers, the
+// other ones as a struct wrapper with a field per branch. The timestamps and
+// dates are mapped to time.Time, the times to time.Duration and the decimals
+// to *big.Rat.
+//
+// Source describe the schema origin in the generated file header.
+func Generate(schema *avro.Schema, pkg string, source string) ([]byte, error) {
+	g := generator{
+		imports: map[string]bool{},
+		used:    map[string]bool{},
+		types:   map[string]string{},
+	}
+
+	// A root type without declaration, like an array, is aliased to be
+	// usable.
+	root := g.goType(schema, "Root")
+	if !g.used[root] {
+		name := g.reserve("Root")
+		g.decls = append([]string{fmt.Sprintf("// %s is the type of the schema.\ntype %s = %s\n", name, name, root)}, g.decls...)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "// Code generated by avro-gateway from %s. DO NOT EDIT.\n\n", source)
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+
+	if len(g.imports) > 0 {
+		imports := make([]string, 0, len(g.imports))
+		for path := range g.imports {
+			imports = append(imports, fmt.Sprintf("%q", path))
+		}
+		sort.Strings(imports)
+
+		fmt.Fprintf(&b, "import (\n%s\n)\n\n", strings.Join(imports, "\n"))
+	}
+
+	b.WriteString(strings.Join(g.decls, "\n"))
+
+	return format.Source([]byte(b.String()))
+}
+
+type generator struct {
+	// decls contains the type declarations, in the order of the schema.
+	decls   []string
+	imports map[string]bool
+	// used contains the Go type names already declared.
+	used map[string]bool
+	// types contains the Go type name of each Avro named type already
+	// generated, by full name.
+	types map[string]string
+}
+
+// goType return the Go type of a schema, generating its declaration if
+// needed. The hint is used to name the union wrappers.
+func (t *generator) goType(schema *avro.Schema, hint string) string {
+	typ := t.logicalType(schema)
+	if typ != "" {
+		return typ
+	}
+
+	switch schema.Type {
+	case avro.Null:
+		return "interface{}"
+	case avro.Boolean:
+		return "bool"
+	case avro.Int:
+		return "int32"
+	case avro.Long:
+		return "int64"
+	case avro.Float:
+		return "float32"
+	case avro.Double:
+		return "float64"
+	case avro.Bytes:
+		return "[]byte"
+	case avro.String:
+		return "string"
+	case avro.Record, avro.Enum, avro.Fixed:
+		return t.namedType(schema)
+	case avro.Array:
+		return "[]" + t.goType(schema.Items, hint+"Item")
+	case avro.Map:
+		return "map[string]" + t.goType(schema.Values, hint+"Value")
+	case avro.Union:
+		return t.unionType(schema, hint)
+	default:
+		return "interface{}"
+	}
+}
+
+// logicalType return the Go type of a logical type or an empty string if it
+// has no specific mapping.
+func (t *generator) logicalType(schema *avro.Schema) string {
+	switch {
+	case schema.LogicalType == "decimal" && (schema.Type == avro.Bytes || schema.Type == avro.Fixed):
+		t.imports["math/big"] = true
+		return "*big.Rat"
+	case schema.LogicalType == "date" && schema.Type == avro.Int,
+		(schema.LogicalType == "timestamp-millis" || schema.LogicalType == "timestamp-micros" ||
+			schema.LogicalType == "local-timestamp-millis" || schema.LogicalType == "local-timestamp-micros") && schema.Type == avro.Long:
+		t.imports["time"] = true
+		return "time.Time"
+	case schema.LogicalType == "time-millis" && schema.Type == avro.Int,
+		schema.LogicalType == "time-micros" && schema.Type == avro.Long:
+		t.imports["time"] = true
+		return "time.Duration"
+	default:
+		return ""
+	}
+}
+
+func (t *generator) namedType(schema *avro.Schema) string {
+	name, ok := t.types[schema.Name]
+	if ok {
+		return name
+	}
+
+	// The short name is used unless an other namespace already use it.
+	name = t.reserve(goName(schema.ShortName()), goName(schema.Name))
+	t.types[schema.Name] = name
+
+	// The slot is reserved before generating the children in order to keep
+	// the parents first.
+	idx := len(t.decls)
+	t.decls = append(t.decls, "")
+
+	var b strings.Builder
+	switch schema.Type {
+	case avro.Record:
+		fmt.Fprintf(&b, "// %s is the Avro record %q.\n", name, schema.Name)
+		writeDoc(&b, schema.Doc, true)
+		fmt.Fprintf(&b, "type %s struct {\n", name)
+
+		fieldNames := map[string]bool{}
+		for _, field := range schema.Fields {
+			fieldName := uniqueName(goName(field.Name), fieldNames)
+			typ := t.goType(field.Type, name+fieldName)
+
+			writeDoc(&b, field.Doc, false)
+			fmt.Fprintf(&b, "%s %s `avro:%q json:%q`\n", fieldName, typ, field.Name, field.Name)
+		}
+
+		b.WriteString("}\n")
+	case avro.Enum:
+		fmt.Fprintf(&b, "// %s is the Avro enum %q.\n", name, schema.Name)
+		writeDoc(&b, schema.Doc, true)
+		fmt.Fprintf(&b, "type %s string\n\n", name)
+
+		fmt.Fprintf(&b, "// The symbols of %s.\nconst (\n", name)
+		symbolNames := map[string]bool{}
+		for _, symbol := range schema.Symbols {
+			fmt.Fprintf(&b, "%s %s = %q\n", uniqueName(name+goName(symbol), symbolNames), name, symbol)
+		}
+		b.WriteString(")\n")
+	case avro.Fixed:
+		fmt.Fprintf(&b, "// %s is the Avro fixed %q.\n", name, schema.Name)
+		writeDoc(&b, schema.Doc, true)
+		fmt.Fprintf(&b, "type %s [%d]byte\n", name, schema.Size)
+	}
+
+	t.decls[idx] = b.String()
+
+	return name
+}
+
+// unionType return a pointer for the unions of null and an other type, or a
+// wrapper struct with a pointer field per branch.
+func (t *generator) unionType(schema *avro.Schema, hint string) string {
+	var branches []*avro.Schema
+	nullable := false
+	for _, branch := range schema.Branches {
+		if branch.Type == avro.Null {
+			nullable = true
+			continue
+		}
+
+		branches = append(branches, branch)
+	}
+
+	switch len(branches) {
+	case 0:
+		return "interface{}"
+	case 1:
+		typ := t.goType(branches[0], hint)
+		if nullable {
+			typ = pointerTo(typ)
+		}
+
+		return typ
+	}
+
+	name := t.reserve(hint + "Union")
+	idx := len(t.decls)
+	t.decls = append(t.decls, "")
+
+	var b strings.Builder
+	names := make([]string, len(schema.Branches))
+	for i, branch := range schema.Branches {
+		names[i] = branch.TypeName()
+	}
+	fmt.Fprintf(&b, "// %s is the Avro union [%s].\n", name, strings.Join(names, ", "))
+	if nullable {
+		b.WriteString("// At most one field is set, none for null.\n")
+	} else {
+		b.WriteString("// Exactly one field is set.\n")
+	}
+	fmt.Fprintf(&b, "type %s struct {\n", name)
+
+	memberNames := map[string]bool{}
+	for _, branch := range branches {
+		member := branch.LogicalType
+		if member == "" {
+			member = string(branch.Type)
+		}
+		if branch.Name != "" {
+			member = branch.ShortName()
+		}
+
+		memberName := uniqueName(goName(member), memberNames)
+		fmt.Fprintf(&b, "%s %s `avro:%q`\n", memberName, pointerTo(t.goType(branch, name+memberName)), branch.TypeName())
+	}
+
+	b.WriteString("}\n")
+	t.decls[idx] = b.String()
+
+	return name
+}
+
+// reserve return the first unused name, or the last one with a number suffix.
+func (t *generator) reserve(names ...string) string {
+	for _, name := range names {
+		if !t.used[name] {
+			t.used[name] = true
+			return name
+		}
+	}
+
+	return uniqueName(names[len(names)-1], t.used)
+}
+
+// uniqueName add the name, with a number suffix if already used.
+func uniqueName(name string, used map[string]bool) string {
+	res := name
+	for i := 2; used[res]; i++ {
+		res = fmt.Sprintf("%s%d", name, i)
+	}
+	used[res] = true
+
+	return res
+}
+
+// pointerTo return a pointer to the type, or the type itself if it already
+// accept nil.
+func pointerTo(typ string) string {
+	if strings.HasPrefix(typ, "*") || strings.HasPrefix(typ, "[]") || strings.HasPrefix(typ, "map[") || typ == "interface{}" {
+		return typ
+	}
+
+	return "*" + typ
+}
+
+// goName convert an Avro name to an exported Go name: "user_id",
+// "userId" and "USER_ID" are converted to "UserID".
+func goName(name string) string {
+	var b strings.Builder
+	for _, word := range splitWords(name) {
+		if initialisms[strings.ToUpper(word)] {
+			b.WriteString(strings.ToUpper(word))
+			continue
+		}
+
+		runes := []rune(word)
+		if strings.ToUpper(word) == word {
+			// The upper case words like the enum symbols are capitalized.
+			runes = []rune(strings.ToLower(word))
+		}
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+
+	if b.Len() == 0 || !unicode.IsLetter([]rune(b.String())[0]) {
+		return "X" + b.String()
+	}
+
+	return b.String()
+}
+
+// splitWords split a name on the underscores, the dots and the lower to upper
+// case transitions.
+func splitWords(name string) []string {
+	var words []string
+	var current []rune
+	for i, r := range name {
+		if r == '_' || r == '.' || r == '-' {
+			if len(current) > 0 {
+				words = append(words, string(current))
+			}
+			current = nil
+			continue
+		}
+
+		if i > 0 && unicode.IsUpper(r) && len(current) > 0 && !unicode.IsUpper(current[len(current)-1]) {
+			words = append(words, string(current))
+			current = nil
+		}
+
+		current = append(current, r)
+	}
+
+	if len(current) > 0 {
+		words = append(words, string(current))
+	}
+
+	return words
+}
+
+// writeDoc write a doc as a comment. A separator line is added before the
+// doc of the types.
+func writeDoc(b *strings.Builder, doc string, separator bool) {
+	if doc == "" {
+		return
+	}
+
+	if separator {
+		b.WriteString("//\n")
+	}
+
+	for _, line := range strings.Split(doc, "\n") {
+		b.WriteString(strings.TrimRight("// "+line, " "))
+		b.WriteByte('\n')
+	}
+}
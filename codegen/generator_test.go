@@ -0,0 +1,161 @@
+package codegen
+
+import (
+	"testing"
+
+	"github.com/Peltoche/avro-gateway/avro"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Generate_success(t *testing.T) {
+	schema, err := avro.Parse(`{
+		"type": "record",
+		"name": "com.example.Payment",
+		"doc": "A payment.",
+		"fields": [
+			{"name": "id", "type": "string", "doc": "The payment id."},
+			{"name": "amount", "type": {"type": "bytes", "logicalType": "decimal", "precision": 10, "scale": 2}},
+			{"name": "created_at", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+			{"name": "note", "type": ["null", "string"], "default": null},
+			{"name": "status", "type": {"type": "enum", "name": "Status", "symbols": ["IN_PROGRESS", "DONE"]}},
+			{"name": "hash", "type": {"type": "fixed", "name": "Hash", "size": 16}},
+			{"name": "method", "type": ["null", "string", {"type": "record", "name": "Card", "fields": [{"name": "number", "type": "string"}]}]},
+			{"name": "tags", "type": {"type": "map", "values": ["int", "string"]}},
+			{"name": "next", "type": ["null", "Payment"], "default": null}
+		]
+	}`)
+	require.NoError(t, err)
+
+	res, err := Generate(schema, "payments", `the schema "payments-value/1"`)
+
+	assert.NoError(t, err)
+	assert.Equal(t, `// Code generated by avro-gateway from the schema "payments-value/1". DO NOT EDIT.
+
+package payments
+
+import (
+	"math/big"
+	"time"
+)
+
+// Payment is the Avro record "com.example.Payment".
+//
+// A payment.
+type Payment struct {
+	// The payment id.
+	ID        string                           `+"`avro:\"id\" json:\"id\"`"+`
+	Amount    *big.Rat                         `+"`avro:\"amount\" json:\"amount\"`"+`
+	CreatedAt time.Time                        `+"`avro:\"created_at\" json:\"created_at\"`"+`
+	Note      *string                          `+"`avro:\"note\" json:\"note\"`"+`
+	Status    Status                           `+"`avro:\"status\" json:\"status\"`"+`
+	Hash      Hash                             `+"`avro:\"hash\" json:\"hash\"`"+`
+	Method    PaymentMethodUnion               `+"`avro:\"method\" json:\"method\"`"+`
+	Tags      map[string]PaymentTagsValueUnion `+"`avro:\"tags\" json:\"tags\"`"+`
+	Next      *Payment                         `+"`avro:\"next\" json:\"next\"`"+`
+}
+
+// Status is the Avro enum "com.example.Status".
+type Status string
+
+// The symbols of Status.
+const (
+	StatusInProgress Status = "IN_PROGRESS"
+	StatusDone       Status = "DONE"
+)
+
+// Hash is the Avro fixed "com.example.Hash".
+type Hash [16]byte
+
+// PaymentMethodUnion is the Avro union [null, string, com.example.Card].
+// At most one field is set, none for null.
+type PaymentMethodUnion struct {
+	String *string `+"`avro:\"string\"`"+`
+	Card   *Card   `+"`avro:\"com.example.Card\"`"+`
+}
+
+// Card is the Avro record "com.example.Card".
+type Card struct {
+	Number string `+"`avro:\"number\" json:\"number\"`"+`
+}
+
+// PaymentTagsValueUnion is the Avro union [int, string].
+// Exactly one field is set.
+type PaymentTagsValueUnion struct {
+	Int    *int32  `+"`avro:\"int\"`"+`
+	String *string `+"`avro:\"string\"`"+`
+}
+`, string(res))
+}
+
+func Test_Generate_with_an_unnamed_root_type(t *testing.T) {
+	schema, err := avro.Parse(`{"type": "array", "items": {"type": "int", "logicalType": "date"}}`)
+	require.NoError(t, err)
+
+	res, err := Generate(schema, "dates", `the schema "dates-value/2"`)
+
+	assert.NoError(t, err)
+	assert.Equal(t, `// Code generated by avro-gateway from the schema "dates-value/2". DO NOT EDIT.
+
+package dates
+
+import (
+	"time"
+)
+
+// Root is the type of the schema.
+type Root = []time.Time
+`, string(res))
+}
+
+func Test_Generate_with_a_name_conflict(t *testing.T) {
+	schema, err := avro.Parse(`{
+		"type": "record",
+		"name": "com.example.User",
+		"fields": [
+			{"name": "user", "type": {"type": "record", "name": "org.other.User", "fields": []}},
+			{"name": "user_id", "type": "long"},
+			{"name": "userId", "type": "long"}
+		]
+	}`)
+	require.NoError(t, err)
+
+	res, err := Generate(schema, "users", `the schema "users-value/1"`)
+
+	assert.NoError(t, err)
+	assert.Equal(t, `// Code generated by avro-gateway from the schema "users-value/1". DO NOT EDIT.
+
+package users
+
+// User is the Avro record "com.example.User".
+type User struct {
+	User    OrgOtherUser `+"`avro:\"user\" json:\"user\"`"+`
+	UserID  int64        `+"`avro:\"user_id\" json:\"user_id\"`"+`
+	UserID2 int64        `+"`avro:\"userId\" json:\"userId\"`"+`
+}
+
+// OrgOtherUser is the Avro record "org.other.User".
+type OrgOtherUser struct {
+}
+`, string(res))
+}
+
+func Test_goName(t *testing.T) {
+	tests := map[string]string{
+		"name":        "Name",
+		"user_id":     "UserID",
+		"userId":      "UserID",
+		"USER_ID":     "UserID",
+		"IN_PROGRESS": "InProgress",
+		"apiURL":      "APIURL",
+		"HTTPServer":  "HTTPServer",
+		"_private":    "Private",
+		"2fa":         "X2fa",
+	}
+
+	for name, expected := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, expected, goName(name))
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package codegen
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/Peltoche/avro-gateway/logging"
+	"github.com/gorilla/mux"
+)
+
+// HTTPHandler handling all the code generation related HTTP requests.
+type HTTPHandler struct {
+	usecase usecase
+}
+
+type usecase interface {
+	Generate(ctx context.Context, cmd *GenerateCmd) ([]byte, error)
+}
+
+// NewHTTPHandler instantiate a new HTTPHandler.
+func NewHTTPHandler(usecase usecase) *HTTPHandler {
+	return &HTTPHandler{
+		usecase: usecase,
+	}
+}
+
+// RegisterRoutes into the givem mux.Router.
+func (t *HTTPHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/subjects/{subject}/versions/{version}/go", t.Get).Methods("GET")
+}
+
+// Get /subjects/{subject}/versions/{version}/go?package={package}
+func (t *HTTPHandler) Get(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	res, err := t.usecase.Generate(r.Context(), &GenerateCmd{
+		Subject: vars["subject"],
+		Version: vars["version"],
+		Package: r.URL.Query().Get("package"),
+	})
+	if err != nil {
+		internal.WriteErrorIntoResponse(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/x-go; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+
+	_, err = w.Write(res)
+	if err != nil {
+		logging.Warn(r.Context(), "failed to write the response", logging.Fields{"error": err})
+	}
+}
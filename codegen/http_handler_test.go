@@ -0,0 +1,48 @@
+package codegen
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_HTTPHandler_Get_success(t *testing.T) {
+	usecaseMock := new(UsecaseMock)
+	handler := NewHTTPHandler(usecaseMock)
+
+	usecaseMock.On("Generate", &GenerateCmd{Subject: "users-value", Version: "3", Package: "users"}).Return([]byte("package users\n"), nil).Once()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com/subjects/users-value/versions/3/go?package=users", nil)
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/x-go; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "package users\n", w.Body.String())
+	usecaseMock.AssertExpectations(t)
+}
+
+func Test_HTTPHandler_Get_with_an_usecase_error(t *testing.T) {
+	usecaseMock := new(UsecaseMock)
+	handler := NewHTTPHandler(usecaseMock)
+
+	usecaseMock.On("Generate", &GenerateCmd{Subject: "users-value", Version: "latest"}).Return(nil, internal.NewError(internal.NotFound, "failed to fetch the schema: version not found")).Once()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com/subjects/users-value/versions/latest/go", nil)
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"kind": "not found", "message": "failed to fetch the schema: version not found"}`, w.Body.String())
+	usecaseMock.AssertExpectations(t)
+}
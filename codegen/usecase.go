@@ -0,0 +1,107 @@
+package codegen
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/Peltoche/avro-gateway/avro"
+	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/Peltoche/avro-gateway/tracing"
+)
+
+// DefaultPackage is the package of the generated code if not specified.
+const DefaultPackage = "schema"
+
+var packagePattern = regexp.MustCompile(`^[a-z][a-z0-9]*$`)
+
+// Usecase handling all the logic about the code generation.
+type Usecase struct {
+	registry Registry
+}
+
+// Registry is used to fetch schema from any Schema Registry.
+type Registry interface {
+	FetchSchema(ctx context.Context, subject string, version string) (string, error)
+}
+
+// NewUsecase instantiate a new Usecase.
+func NewUsecase(registry Registry) *Usecase {
+	return &Usecase{
+		registry: registry,
+	}
+}
+
+// GenerateCmd is the requests parameters for the Generate method.
+type GenerateCmd struct {
+	Subject string
+	Version string
+	// Package of the generated code, DefaultPackage if empty.
+	Package string
+}
+
+// Generate return the Go source of the types of a schema version.
+func (t *Usecase) Generate(ctx context.Context, cmd *GenerateCmd) ([]byte, error) {
+	ctx, span := tracing.Start(ctx, "codegen.Usecase.Generate")
+	defer span.End()
+
+	span.SetAttribute("codegen.subject", cmd.Subject)
+	span.SetAttribute("codegen.version", cmd.Version)
+
+	res, err := t.generate(ctx, cmd)
+	span.SetError(err)
+
+	return res, err
+}
+
+func (t *Usecase) generate(ctx context.Context, cmd *GenerateCmd) ([]byte, error) {
+	err := validateGenerateCmd(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg := cmd.Package
+	if pkg == "" {
+		pkg = DefaultPackage
+	}
+
+	raw, err := t.registry.FetchSchema(ctx, cmd.Subject, cmd.Version)
+	if err != nil {
+		return nil, internal.Wrap(err, "failed to fetch the schema")
+	}
+
+	schema, err := avro.Parse(raw)
+	if err != nil {
+		return nil, internal.Wrap(err, "failed to parse the registry schema")
+	}
+
+	res, err := Generate(schema, pkg, fmt.Sprintf("the schema \"%s/%s\"", cmd.Subject, cmd.Version))
+	if err != nil {
+		return nil, internal.Errorf(internal.InternalError, "failed to format the generated code: %s", err)
+	}
+
+	return res, nil
+}
+
+func validateGenerateCmd(cmd *GenerateCmd) error {
+	if cmd.Subject == "" {
+		return internal.NewError(internal.ValidationError, `missing field "subject"`)
+	}
+
+	if cmd.Version == "" {
+		return internal.NewError(internal.ValidationError, `missing field "version"`)
+	}
+	if cmd.Version != "latest" {
+		val, err := strconv.Atoi(cmd.Version)
+		if err != nil || val < 1 {
+			return internal.NewError(internal.ValidationError, `invalid input for field "version"`)
+		}
+	}
+
+	if cmd.Package != "" && !packagePattern.MatchString(cmd.Package) {
+		return internal.NewError(internal.ValidationError, `invalid input for parameter "package"`)
+	}
+
+	return nil
+}
@@ -0,0 +1,23 @@
+package codegen
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// UsecaseMock is a mock implementation of codegen.Usecase.
+type UsecaseMock struct {
+	mock.Mock
+}
+
+// Generate method mock.
+func (t *UsecaseMock) Generate(ctx context.Context, cmd *GenerateCmd) ([]byte, error) {
+	args := t.Called(cmd)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]byte), args.Error(1)
+}
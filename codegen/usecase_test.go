@@ -0,0 +1,110 @@
+package codegen
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/Peltoche/avro-gateway/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Usecase_Generate_success(t *testing.T) {
+	registryMock := new(registry.Mock)
+	usecase := NewUsecase(registryMock)
+
+	registryMock.On("FetchSchema", "users-value", "latest").Return(`{"type": "enum", "name": "Color", "symbols": ["RED"]}`, nil).Once()
+
+	res, err := usecase.Generate(context.Background(), &GenerateCmd{
+		Subject: "users-value",
+		Version: "latest",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, `// Code generated by avro-gateway from the schema "users-value/latest". DO NOT EDIT.
+
+package schema
+
+// Color is the Avro enum "Color".
+type Color string
+
+// The symbols of Color.
+const (
+	ColorRed Color = "RED"
+)
+`, string(res))
+	registryMock.AssertExpectations(t)
+}
+
+func Test_Usecase_Generate_with_a_package(t *testing.T) {
+	registryMock := new(registry.Mock)
+	usecase := NewUsecase(registryMock)
+
+	registryMock.On("FetchSchema", "users-value", "2").Return(`"string"`, nil).Once()
+
+	res, err := usecase.Generate(context.Background(), &GenerateCmd{
+		Subject: "users-value",
+		Version: "2",
+		Package: "users",
+	})
+
+	assert.NoError(t, err)
+	assert.Contains(t, string(res), "package users\n")
+	registryMock.AssertExpectations(t)
+}
+
+func Test_Usecase_Generate_with_a_fetch_error(t *testing.T) {
+	registryMock := new(registry.Mock)
+	usecase := NewUsecase(registryMock)
+
+	registryMock.On("FetchSchema", "users-value", "9").Return("", internal.NewError(internal.NotFound, "version not found")).Once()
+
+	res, err := usecase.Generate(context.Background(), &GenerateCmd{
+		Subject: "users-value",
+		Version: "9",
+	})
+
+	assert.Nil(t, res)
+	assert.EqualError(t, err, "not found: failed to fetch the schema: version not found")
+	registryMock.AssertExpectations(t)
+}
+
+func Test_Usecase_Generate_with_a_non_avro_schema(t *testing.T) {
+	registryMock := new(registry.Mock)
+	usecase := NewUsecase(registryMock)
+
+	registryMock.On("FetchSchema", "users-value", "1").Return(`syntax = "proto3";`, nil).Once()
+
+	res, err := usecase.Generate(context.Background(), &GenerateCmd{
+		Subject: "users-value",
+		Version: "1",
+	})
+
+	assert.Nil(t, res)
+	assert.True(t, internal.IsKind(internal.ValidationError, err))
+	registryMock.AssertExpectations(t)
+}
+
+func Test_Usecase_Generate_with_invalid_inputs(t *testing.T) {
+	usecase := NewUsecase(new(registry.Mock))
+
+	tests := []struct {
+		name string
+		cmd  GenerateCmd
+		err  string
+	}{
+		{"missing subject", GenerateCmd{Version: "1"}, `validation error: missing field "subject"`},
+		{"missing version", GenerateCmd{Subject: "users-value"}, `validation error: missing field "version"`},
+		{"invalid version", GenerateCmd{Subject: "users-value", Version: "0"}, `validation error: invalid input for field "version"`},
+		{"invalid package", GenerateCmd{Subject: "users-value", Version: "1", Package: "my-users"}, `validation error: invalid input for parameter "package"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			res, err := usecase.Generate(context.Background(), &test.cmd)
+
+			assert.Nil(t, res)
+			assert.EqualError(t, err, test.err)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+
+	"github.com/Peltoche/avro-gateway/codegen"
+	"github.com/Peltoche/avro-gateway/registry"
+)
+
+// runCodegenCommand run the "codegen" command and return the exit code.
+func runCodegenCommand(args []string) int {
+	err := runCodegen(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	return 0
+}
+
+func runCodegen(args []string) error {
+	flags := flag.NewFlagSet("codegen", flag.ExitOnError)
+	registryURL := flags.String("registry-url", "http://localhost:8081", "url of the Schema Registry")
+	subject := flags.String("subject", "", "subject of the schema")
+	version := flags.String("version", "latest", "version of the schema")
+	pkg := flags.String("package", codegen.DefaultPackage, "package of the generated code")
+	output := flags.String("o", "-", "generated file, stdout if \"-\"")
+	_ = flags.Parse(args)
+
+	if *subject == "" {
+		return fmt.Errorf("missing flag -subject")
+	}
+
+	parsedURL, err := url.Parse(*registryURL)
+	if err != nil {
+		return err
+	}
+
+	usecase := codegen.NewUsecase(registry.NewClient(parsedURL))
+	res, err := usecase.Generate(context.Background(), &codegen.GenerateCmd{
+		Subject: *subject,
+		Version: *version,
+		Package: *pkg,
+	})
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "-" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		w = file
+	}
+
+	_, err = w.Write(res)
+
+	return err
+}
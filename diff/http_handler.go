@@ -0,0 +1,86 @@
+package diff
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/Peltoche/avro-gateway/logging"
+	"github.com/gorilla/mux"
+)
+
+// HTTPHandler handling all the diff related HTTP requests.
+type HTTPHandler struct {
+	usecase usecase
+}
+
+type usecase interface {
+	Diff(ctx context.Context, cmd *DiffCmd) (*Diff, error)
+}
+
+// NewHTTPHandler instantiate a new HTTPHandler.
+func NewHTTPHandler(usecase usecase) *HTTPHandler {
+	return &HTTPHandler{
+		usecase: usecase,
+	}
+}
+
+// RegisterRoutes into the givem mux.Router.
+func (t *HTTPHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/subjects/{subject}/diff", t.Get).Methods("GET")
+}
+
+// Get /subjects/{subject}/diff?from={version}&to={version}&format={json|text}
+func (t *HTTPHandler) Get(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	format := query.Get("format")
+	if format != "" && format != "json" && format != "text" {
+		internal.WriteErrorIntoResponse(w, internal.NewError(internal.ValidationError, `invalid input for parameter "format"`))
+		return
+	}
+
+	diff, err := t.usecase.Diff(r.Context(), &DiffCmd{
+		Subject: mux.Vars(r)["subject"],
+		From:    query.Get("from"),
+		To:      query.Get("to"),
+	})
+	if err != nil {
+		internal.WriteErrorIntoResponse(w, err)
+		return
+	}
+
+	if format == "text" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		_, err = w.Write([]byte(formatText(diff)))
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		err = json.NewEncoder(w).Encode(diff)
+	}
+	if err != nil {
+		logging.Warn(r.Context(), "failed to write the response", logging.Fields{"error": err})
+	}
+}
+
+// formatText return the human readable diff: a summary line then a line per
+// change.
+func formatText(diff *Diff) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: version %s to %s (%s)\n", diff.Subject, diff.From, diff.To, diff.Compatibility)
+
+	if len(diff.Changes) == 0 {
+		b.WriteString("no changes\n")
+	}
+
+	for _, change := range diff.Changes {
+		b.WriteString(change.String())
+		b.WriteByte('\n')
+	}
+
+	return b.String()
+}
@@ -0,0 +1,129 @@
+package diff
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Peltoche/avro-gateway/avro"
+	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func someDiff() *Diff {
+	return &Diff{
+		Subject:       "users-value",
+		From:          "3",
+		To:            "5",
+		Compatibility: avro.Breaking,
+		Changes: []avro.Change{
+			{Kind: avro.FieldAdded, Path: "$.email", To: "string", Compatibility: avro.ForwardCompatibility},
+			{Kind: avro.FieldRemoved, Path: "$.age", From: "int", Compatibility: avro.BackwardCompatibility},
+		},
+	}
+}
+
+func Test_HTTPHandler_Get_json(t *testing.T) {
+	usecaseMock := new(UsecaseMock)
+	handler := NewHTTPHandler(usecaseMock)
+
+	usecaseMock.On("Diff", &DiffCmd{Subject: "users-value", From: "3", To: "5"}).Return(someDiff(), nil).Once()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com/subjects/users-value/diff?from=3&to=5", nil)
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"subject": "users-value",
+		"from": "3",
+		"to": "5",
+		"compatibility": "breaking",
+		"changes": [
+			{"kind": "field_added", "path": "$.email", "to": "string", "compatibility": "forward"},
+			{"kind": "field_removed", "path": "$.age", "from": "int", "compatibility": "backward"}
+		]
+	}`, w.Body.String())
+	usecaseMock.AssertExpectations(t)
+}
+
+func Test_HTTPHandler_Get_text(t *testing.T) {
+	usecaseMock := new(UsecaseMock)
+	handler := NewHTTPHandler(usecaseMock)
+
+	usecaseMock.On("Diff", &DiffCmd{Subject: "users-value", From: "3", To: "5"}).Return(someDiff(), nil).Once()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com/subjects/users-value/diff?from=3&to=5&format=text", nil)
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, `users-value: version 3 to 5 (breaking)
+$.email: field added with the type string (forward)
+$.age: field removed, it had the type int (backward)
+`, w.Body.String())
+	usecaseMock.AssertExpectations(t)
+}
+
+func Test_HTTPHandler_Get_text_without_changes(t *testing.T) {
+	usecaseMock := new(UsecaseMock)
+	handler := NewHTTPHandler(usecaseMock)
+
+	usecaseMock.On("Diff", &DiffCmd{Subject: "users-value", From: "3", To: "3"}).Return(&Diff{
+		Subject:       "users-value",
+		From:          "3",
+		To:            "3",
+		Compatibility: avro.FullCompatibility,
+		Changes:       []avro.Change{},
+	}, nil).Once()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com/subjects/users-value/diff?from=3&to=3&format=text", nil)
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "users-value: version 3 to 3 (full)\nno changes\n", w.Body.String())
+}
+
+func Test_HTTPHandler_Get_with_an_invalid_format(t *testing.T) {
+	handler := NewHTTPHandler(new(UsecaseMock))
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com/subjects/users-value/diff?from=3&to=5&format=xml", nil)
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.JSONEq(t, `{"kind": "validation error", "message": "invalid input for parameter \"format\""}`, w.Body.String())
+}
+
+func Test_HTTPHandler_Get_with_an_usecase_error(t *testing.T) {
+	usecaseMock := new(UsecaseMock)
+	handler := NewHTTPHandler(usecaseMock)
+
+	usecaseMock.On("Diff", &DiffCmd{Subject: "users-value", From: "3", To: "9"}).Return(nil, internal.NewError(internal.NotFound, "failed to fetch the version 9: version not found")).Once()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com/subjects/users-value/diff?from=3&to=9", nil)
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	usecaseMock.AssertExpectations(t)
+}
@@ -0,0 +1,129 @@
+package diff
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/Peltoche/avro-gateway/avro"
+	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/Peltoche/avro-gateway/tracing"
+)
+
+// Usecase handling all the logic about the schema diffs.
+type Usecase struct {
+	registry Registry
+}
+
+// Registry is used to fetch schema from any Schema Registry.
+type Registry interface {
+	FetchSchema(ctx context.Context, subject string, version string) (string, error)
+}
+
+// NewUsecase instantiate a new Usecase.
+func NewUsecase(registry Registry) *Usecase {
+	return &Usecase{
+		registry: registry,
+	}
+}
+
+// DiffCmd is the requests parameters for the Diff method.
+type DiffCmd struct {
+	Subject string
+	From    string
+	To      string
+}
+
+// Diff between two versions of a subject.
+//
+// Compatibility is the compatibility of the whole new version, which can be
+// stricter than the one of each change.
+type Diff struct {
+	Subject       string             `json:"subject"`
+	From          string             `json:"from"`
+	To            string             `json:"to"`
+	Compatibility avro.Compatibility `json:"compatibility"`
+	Changes       []avro.Change      `json:"changes"`
+}
+
+// Diff return the changes made between two versions of a subject.
+func (t *Usecase) Diff(ctx context.Context, cmd *DiffCmd) (*Diff, error) {
+	ctx, span := tracing.Start(ctx, "diff.Usecase.Diff")
+	defer span.End()
+
+	span.SetAttribute("diff.subject", cmd.Subject)
+	span.SetAttribute("diff.from", cmd.From)
+	span.SetAttribute("diff.to", cmd.To)
+
+	diff, err := t.diff(ctx, cmd)
+	span.SetError(err)
+
+	return diff, err
+}
+
+func (t *Usecase) diff(ctx context.Context, cmd *DiffCmd) (*Diff, error) {
+	err := validateDiffCmd(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := t.fetchSchema(ctx, cmd.Subject, cmd.From)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := t.fetchSchema(ctx, cmd.Subject, cmd.To)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Diff{
+		Subject:       cmd.Subject,
+		From:          cmd.From,
+		To:            cmd.To,
+		Compatibility: avro.CompatibilityOf(from, to),
+		Changes:       avro.Diff(from, to),
+	}, nil
+}
+
+func (t *Usecase) fetchSchema(ctx context.Context, subject string, version string) (*avro.Schema, error) {
+	raw, err := t.registry.FetchSchema(ctx, subject, version)
+	if err != nil {
+		return nil, internal.Wrapf(err, "failed to fetch the version %s", version)
+	}
+
+	schema, err := avro.Parse(raw)
+	if err != nil {
+		return nil, internal.Wrapf(err, "the version %s", version)
+	}
+
+	return schema, nil
+}
+
+func validateDiffCmd(cmd *DiffCmd) error {
+	if cmd.Subject == "" {
+		return internal.NewError(internal.ValidationError, `missing field "subject"`)
+	}
+
+	for _, param := range []struct {
+		name  string
+		value string
+	}{
+		{"from", cmd.From},
+		{"to", cmd.To},
+	} {
+		if param.value == "" {
+			return internal.Errorf(internal.ValidationError, "missing parameter %q", param.name)
+		}
+
+		if param.value == "latest" {
+			continue
+		}
+
+		version, err := strconv.Atoi(param.value)
+		if err != nil || version < 1 {
+			return internal.Errorf(internal.ValidationError, "invalid input for parameter %q", param.name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,23 @@
+package diff
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// UsecaseMock is a mock implementation of diff.Usecase.
+type UsecaseMock struct {
+	mock.Mock
+}
+
+// Diff method mock.
+func (t *UsecaseMock) Diff(ctx context.Context, cmd *DiffCmd) (*Diff, error) {
+	args := t.Called(cmd)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*Diff), args.Error(1)
+}
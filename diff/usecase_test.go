@@ -0,0 +1,118 @@
+package diff
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Peltoche/avro-gateway/avro"
+	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/Peltoche/avro-gateway/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+const someSchemaV1 = `{
+	"type": "record",
+	"name": "User",
+	"fields": [
+		{"name": "name", "type": "string"},
+		{"name": "age", "type": "int"}
+	]
+}`
+
+const someSchemaV2 = `{
+	"type": "record",
+	"name": "User",
+	"fields": [
+		{"name": "name", "type": "string"},
+		{"name": "age", "type": "long"},
+		{"name": "email", "type": ["null", "string"], "default": null}
+	]
+}`
+
+func Test_Usecase_Diff_success(t *testing.T) {
+	registryMock := new(registry.Mock)
+	usecase := NewUsecase(registryMock)
+
+	registryMock.On("FetchSchema", "users-value", "1").Return(someSchemaV1, nil).Once()
+	registryMock.On("FetchSchema", "users-value", "latest").Return(someSchemaV2, nil).Once()
+
+	diff, err := usecase.Diff(context.Background(), &DiffCmd{
+		Subject: "users-value",
+		From:    "1",
+		To:      "latest",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, &Diff{
+		Subject:       "users-value",
+		From:          "1",
+		To:            "latest",
+		Compatibility: avro.BackwardCompatibility,
+		Changes: []avro.Change{
+			{Kind: avro.TypeChanged, Path: "$.age", From: "int", To: "long", Compatibility: avro.BackwardCompatibility},
+			{Kind: avro.FieldAdded, Path: "$.email", To: "union{null,string}", Compatibility: avro.FullCompatibility},
+		},
+	}, diff)
+	registryMock.AssertExpectations(t)
+}
+
+func Test_Usecase_Diff_with_a_version_not_found(t *testing.T) {
+	registryMock := new(registry.Mock)
+	usecase := NewUsecase(registryMock)
+
+	registryMock.On("FetchSchema", "users-value", "1").Return(someSchemaV1, nil).Once()
+	registryMock.On("FetchSchema", "users-value", "9").Return("", internal.NewError(internal.NotFound, "version not found")).Once()
+
+	diff, err := usecase.Diff(context.Background(), &DiffCmd{
+		Subject: "users-value",
+		From:    "1",
+		To:      "9",
+	})
+
+	assert.Nil(t, diff)
+	assert.EqualError(t, err, "not found: failed to fetch the version 9: version not found")
+	registryMock.AssertExpectations(t)
+}
+
+func Test_Usecase_Diff_with_a_non_avro_schema(t *testing.T) {
+	registryMock := new(registry.Mock)
+	usecase := NewUsecase(registryMock)
+
+	registryMock.On("FetchSchema", "users-value", "1").Return(`syntax = "proto3";`, nil).Once()
+
+	diff, err := usecase.Diff(context.Background(), &DiffCmd{
+		Subject: "users-value",
+		From:    "1",
+		To:      "2",
+	})
+
+	assert.Nil(t, diff)
+	assert.Error(t, err)
+	assert.True(t, internal.IsKind(internal.ValidationError, err))
+	registryMock.AssertExpectations(t)
+}
+
+func Test_Usecase_Diff_with_invalid_inputs(t *testing.T) {
+	usecase := NewUsecase(new(registry.Mock))
+
+	tests := []struct {
+		name string
+		cmd  DiffCmd
+		err  string
+	}{
+		{"missing subject", DiffCmd{From: "1", To: "2"}, `validation error: missing field "subject"`},
+		{"missing from", DiffCmd{Subject: "users-value", To: "2"}, `validation error: missing parameter "from"`},
+		{"missing to", DiffCmd{Subject: "users-value", From: "1"}, `validation error: missing parameter "to"`},
+		{"invalid from", DiffCmd{Subject: "users-value", From: "0", To: "2"}, `validation error: invalid input for parameter "from"`},
+		{"invalid to", DiffCmd{Subject: "users-value", From: "1", To: "last"}, `validation error: invalid input for parameter "to"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			diff, err := usecase.Diff(context.Background(), &test.cmd)
+
+			assert.Nil(t, diff)
+			assert.EqualError(t, err, test.err)
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package event
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/Peltoche/avro-gateway/model"
+)
+
+// Bus record the events and dispatch them to all the subscribers.
+//
+// Only the last events are kept in memory, the older ones are dropped once the
+// capacity is reached.
+type Bus struct {
+	mutex       *sync.Mutex
+	capacity    int
+	lastID      uint64
+	history     []model.Event
+	subscribers map[chan model.Event]struct{}
+	closed      bool
+	// Set the time function as an attribute in order to be able to mock the
+	// event time.
+	now func() time.Time
+}
+
+// NewBus instantiate a new Bus keeping the last capacity events.
+func NewBus(capacity int) *Bus {
+	return &Bus{
+		mutex:       new(sync.Mutex),
+		capacity:    capacity,
+		history:     []model.Event{},
+		subscribers: map[chan model.Event]struct{}{},
+		now:         time.Now,
+	}
+}
+
+// Publish set an unique ID and the time to the event, record it, then send it
+// to all the subscribers.
+//
+// A subscriber which doesn't consume its events fast enough misses the events
+// sent while its buffer is full.
+func (t *Bus) Publish(ctx context.Context, evt model.Event) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.lastID++
+	evt.ID = t.lastID
+	if evt.Time.IsZero() {
+		evt.Time = t.now().UTC()
+	}
+
+	t.history = append(t.history, evt)
+	if len(t.history) > t.capacity {
+		t.history = t.history[len(t.history)-t.capacity:]
+	}
+
+	for subscriber := range t.subscribers {
+		select {
+		case subscriber <- evt:
+		default:
+		}
+	}
+}
+
+// History return all the recorded events, oldest first.
+func (t *Bus) History() []model.Event {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	res := make([]model.Event, len(t.history))
+	copy(res, t.history)
+
+	return res
+}
+
+// Subscribe return a channel receiving all the events published from now on
+// and a function to call in order to stop the subscription.
+func (t *Bus) Subscribe(bufferSize int) (<-chan model.Event, func()) {
+	// No recorded event can be after the greatest id.
+	_, subscriber, unsubscribe := t.SubscribeFrom(math.MaxUint64, bufferSize)
+
+	return subscriber, unsubscribe
+}
+
+// SubscribeFrom works like Subscribe but also return the recorded events
+// published after the event lastID, allowing a subscriber to resume from
+// where it stopped. The events dropped from the history are lost.
+func (t *Bus) SubscribeFrom(lastID uint64, bufferSize int) ([]model.Event, <-chan model.Event, func()) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	missed := []model.Event{}
+	for _, evt := range t.history {
+		if evt.ID > lastID {
+			missed = append(missed, evt)
+		}
+	}
+
+	subscriber := make(chan model.Event, bufferSize)
+	if t.closed {
+		close(subscriber)
+		return missed, subscriber, func() {}
+	}
+	t.subscribers[subscriber] = struct{}{}
+
+	return missed, subscriber, func() {
+		t.mutex.Lock()
+		defer t.mutex.Unlock()
+
+		// The channel is already closed if the bus is closed.
+		if _, present := t.subscribers[subscriber]; present {
+			delete(t.subscribers, subscriber)
+			close(subscriber)
+		}
+	}
+}
+
+// Close the channels of all the subscribers, letting them know that no more
+// events will be sent. The events published afterward are only recorded.
+func (t *Bus) Close() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.closed = true
+	for subscriber := range t.subscribers {
+		delete(t.subscribers, subscriber)
+		close(subscriber)
+	}
+}
@@ -0,0 +1,113 @@
+package event
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Peltoche/avro-gateway/model"
+	"github.com/stretchr/testify/assert"
+)
+
+var someDate = time.Date(2019, time.February, 3, 10, 0, 0, 0, time.UTC)
+
+func Test_Bus_Publish_History_success(t *testing.T) {
+	bus := NewBus(10)
+	bus.now = func() time.Time { return someDate }
+
+	bus.Publish(context.Background(), model.Event{Type: model.EventVersionCreated, Topic: "some-topic"})
+	bus.Publish(context.Background(), model.Event{Type: model.EventVersionSoftDeleted, Topic: "some-topic"})
+
+	assert.Equal(t, []model.Event{
+		{ID: 1, Type: model.EventVersionCreated, Time: someDate, Topic: "some-topic"},
+		{ID: 2, Type: model.EventVersionSoftDeleted, Time: someDate, Topic: "some-topic"},
+	}, bus.History())
+}
+
+func Test_Bus_History_is_bounded(t *testing.T) {
+	bus := NewBus(2)
+
+	for i := 0; i < 5; i++ {
+		bus.Publish(context.Background(), model.Event{Type: model.EventVersionCreated})
+	}
+
+	history := bus.History()
+	assert.Len(t, history, 2)
+	assert.Equal(t, uint64(4), history[0].ID)
+	assert.Equal(t, uint64(5), history[1].ID)
+}
+
+func Test_Bus_Subscribe_success(t *testing.T) {
+	bus := NewBus(10)
+	bus.now = func() time.Time { return someDate }
+
+	events, unsubscribe := bus.Subscribe(10)
+
+	bus.Publish(context.Background(), model.Event{Type: model.EventVersionCreated, Topic: "some-topic"})
+
+	assert.Equal(t, model.Event{ID: 1, Type: model.EventVersionCreated, Time: someDate, Topic: "some-topic"}, <-events)
+
+	unsubscribe()
+	unsubscribe()
+
+	_, open := <-events
+	assert.False(t, open)
+
+	// Must not block nor panic once unsubscribed.
+	bus.Publish(context.Background(), model.Event{Type: model.EventVersionCreated})
+}
+
+func Test_Bus_Subscribe_with_a_full_buffer(t *testing.T) {
+	bus := NewBus(10)
+
+	events, unsubscribe := bus.Subscribe(1)
+	defer unsubscribe()
+
+	bus.Publish(context.Background(), model.Event{Type: model.EventVersionCreated})
+	bus.Publish(context.Background(), model.Event{Type: model.EventVersionSoftDeleted})
+
+	assert.Equal(t, uint64(1), (<-events).ID)
+	assert.Len(t, events, 0)
+}
+
+func Test_Bus_SubscribeFrom_success(t *testing.T) {
+	bus := NewBus(10)
+
+	for i := 0; i < 3; i++ {
+		bus.Publish(context.Background(), model.Event{Type: model.EventVersionCreated})
+	}
+
+	missed, events, unsubscribe := bus.SubscribeFrom(1, 10)
+	defer unsubscribe()
+
+	assert.Len(t, missed, 2)
+	assert.Equal(t, uint64(2), missed[0].ID)
+	assert.Equal(t, uint64(3), missed[1].ID)
+
+	bus.Publish(context.Background(), model.Event{Type: model.EventVersionCreated})
+	assert.Equal(t, uint64(4), (<-events).ID)
+}
+
+func Test_Bus_Close_success(t *testing.T) {
+	bus := NewBus(10)
+
+	events, unsubscribe := bus.Subscribe(10)
+
+	bus.Close()
+
+	_, open := <-events
+	assert.False(t, open)
+
+	// Must not panic once the channel closed by the bus.
+	unsubscribe()
+
+	// The events are still recorded.
+	bus.Publish(context.Background(), model.Event{Type: model.EventVersionCreated})
+	assert.Len(t, bus.History(), 1)
+
+	// The new subscribers receive a closed channel.
+	lateEvents, lateUnsubscribe := bus.Subscribe(10)
+	_, open = <-lateEvents
+	assert.False(t, open)
+	lateUnsubscribe()
+}
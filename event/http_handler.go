@@ -0,0 +1,158 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/Peltoche/avro-gateway/model"
+	"github.com/gorilla/mux"
+)
+
+// HTTPHandler streaming the events as Server-Sent Events.
+type HTTPHandler struct {
+	bus bus
+	// keepAliveInterval is the interval between two keep-alive comments sent
+	// in order to prevent the proxies from closing an idle stream.
+	keepAliveInterval time.Duration
+	closeOnce         *sync.Once
+	closed            chan struct{}
+}
+
+type bus interface {
+	SubscribeFrom(lastID uint64, bufferSize int) ([]model.Event, <-chan model.Event, func())
+}
+
+// NewHTTPHandler instantiate a new HTTPHandler.
+func NewHTTPHandler(bus bus) *HTTPHandler {
+	return &HTTPHandler{
+		bus:               bus,
+		keepAliveInterval: 15 * time.Second,
+		closeOnce:         new(sync.Once),
+		closed:            make(chan struct{}),
+	}
+}
+
+// Close end all the running streams. It's used at shutdown as the streams
+// never end by themselves.
+func (t *HTTPHandler) Close() {
+	t.closeOnce.Do(func() {
+		close(t.closed)
+	})
+}
+
+// RegisterRoutes into the givem mux.Router.
+func (t *HTTPHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/events", t.Get).Methods("GET")
+}
+
+// Get /events?topic={topic}&namespace={namespace}
+//
+// An empty namespace parameter select the default namespace, all the
+// namespaces are streamed without the parameter.
+//
+// A first connection only receive the live events. The stream can be resumed
+// with the "Last-Event-ID" header as long as the missed events are still in
+// the bus history.
+func (t *HTTPHandler) Get(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		internal.WriteErrorIntoResponse(w, internal.NewError(internal.InternalError, "streaming not supported"))
+		return
+	}
+
+	// No recorded event can be after the greatest id.
+	var lastID uint64 = math.MaxUint64
+	if rawLastID := r.Header.Get("Last-Event-ID"); rawLastID != "" {
+		var err error
+		lastID, err = strconv.ParseUint(rawLastID, 10, 64)
+		if err != nil {
+			internal.WriteErrorIntoResponse(w, internal.NewError(internal.ValidationError, `invalid input for header "Last-Event-ID"`))
+			return
+		}
+	}
+
+	filter := eventFilter{topic: r.URL.Query().Get("topic")}
+	if namespaces, ok := r.URL.Query()["namespace"]; ok {
+		filter.namespace = &namespaces[0]
+	}
+
+	missed, events, unsubscribe := t.bus.SubscribeFrom(lastID, 100)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	for i := range missed {
+		err := writeEvent(w, &filter, &missed[i])
+		if err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(t.keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		var err error
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-t.closed:
+			return
+		case <-keepAlive.C:
+			_, err = fmt.Fprint(w, ": keep-alive\n\n")
+		case evt, open := <-events:
+			if !open {
+				return
+			}
+
+			err = writeEvent(w, &filter, &evt)
+		}
+
+		if err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// eventFilter select the streamed events. The zero values match everything.
+type eventFilter struct {
+	topic     string
+	namespace *string
+}
+
+func (t *eventFilter) match(evt *model.Event) bool {
+	if t.topic != "" && evt.Topic != t.topic {
+		return false
+	}
+
+	return t.namespace == nil || evt.Namespace == *t.namespace
+}
+
+// writeEvent write the event in the Server-Sent Events format if it match the
+// filter.
+func writeEvent(w http.ResponseWriter, filter *eventFilter, evt *model.Event) error {
+	if !filter.match(evt) {
+		return nil
+	}
+
+	data, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", evt.ID, evt.Type, data)
+
+	return err
+}
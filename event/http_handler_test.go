@@ -0,0 +1,235 @@
+package event
+
+import (
+	"bufio"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Peltoche/avro-gateway/model"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func startEventServer(bus *Bus) *httptest.Server {
+	handler := NewHTTPHandler(bus)
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+
+	return httptest.NewServer(router)
+}
+
+// readSSEEvent read the lines of the next event, skipping the comments.
+func readSSEEvent(t *testing.T, reader *bufio.Reader) []string {
+	lines := []string{}
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "" && len(lines) > 0:
+			return lines
+		case line == "" || strings.HasPrefix(line, ":"):
+			continue
+		default:
+			lines = append(lines, line)
+		}
+	}
+}
+
+func Test_HTTPHandler_Get_success(t *testing.T) {
+	bus := NewBus(10)
+	bus.now = func() time.Time { return someDate }
+
+	ts := startEventServer(bus)
+	defer ts.Close()
+
+	bus.Publish(context.Background(), model.Event{Type: model.EventClientRegistered, Topic: "some-topic"})
+	bus.Publish(context.Background(), model.Event{Type: model.EventClientRegistered, Topic: "some-other-topic"})
+	bus.Publish(context.Background(), model.Event{Type: model.EventClientUpgraded, Topic: "some-topic"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	req, err := http.NewRequest("GET", ts.URL+"/events?topic=some-topic", nil)
+	require.NoError(t, err)
+	req.Header.Set("Last-Event-ID", "1")
+
+	res, err := http.DefaultClient.Do(req.WithContext(ctx))
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(res.Body)
+
+	// Replayed from the history, the event 2 is filtered out.
+	assert.Equal(t, []string{
+		"id: 3",
+		"event: client_upgraded",
+		`data: {"id":3,"type":"client_upgraded","time":"2019-02-03T10:00:00Z","topic":"some-topic"}`,
+	}, readSSEEvent(t, reader))
+
+	// Live events.
+	bus.Publish(context.Background(), model.Event{Type: model.EventClientRegistered, Topic: "some-other-topic"})
+	bus.Publish(context.Background(), model.Event{Type: model.EventRequestRefused, Topic: "some-topic"})
+
+	assert.Equal(t, []string{
+		"id: 5",
+		"event: request_refused",
+		`data: {"id":5,"type":"request_refused","time":"2019-02-03T10:00:00Z","topic":"some-topic"}`,
+	}, readSSEEvent(t, reader))
+}
+
+func Test_HTTPHandler_Get_first_connection(t *testing.T) {
+	bus := NewBus(10)
+	bus.now = func() time.Time { return someDate }
+
+	ts := startEventServer(bus)
+	defer ts.Close()
+
+	bus.Publish(context.Background(), model.Event{Type: model.EventClientRegistered, Topic: "some-topic"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	req, err := http.NewRequest("GET", ts.URL+"/events", nil)
+	require.NoError(t, err)
+
+	res, err := http.DefaultClient.Do(req.WithContext(ctx))
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	bus.Publish(context.Background(), model.Event{Type: model.EventClientUpgraded, Topic: "some-topic"})
+
+	// Without "Last-Event-ID" the history is not replayed.
+	assert.Equal(t, []string{
+		"id: 2",
+		"event: client_upgraded",
+		`data: {"id":2,"type":"client_upgraded","time":"2019-02-03T10:00:00Z","topic":"some-topic"}`,
+	}, readSSEEvent(t, bufio.NewReader(res.Body)))
+}
+
+func Test_HTTPHandler_Get_with_a_namespace(t *testing.T) {
+	bus := NewBus(10)
+	bus.now = func() time.Time { return someDate }
+
+	ts := startEventServer(bus)
+	defer ts.Close()
+
+	bus.Publish(context.Background(), model.Event{Type: model.EventClientRegistered, Topic: "some-topic"})
+	bus.Publish(context.Background(), model.Event{Type: model.EventClientRegistered, Namespace: "staging", Topic: "some-topic"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	req, err := http.NewRequest("GET", ts.URL+"/events?namespace=staging", nil)
+	require.NoError(t, err)
+	req.Header.Set("Last-Event-ID", "0")
+
+	res, err := http.DefaultClient.Do(req.WithContext(ctx))
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	reader := bufio.NewReader(res.Body)
+
+	assert.Equal(t, []string{
+		"id: 2",
+		"event: client_registered",
+		`data: {"id":2,"type":"client_registered","time":"2019-02-03T10:00:00Z","namespace":"staging","topic":"some-topic"}`,
+	}, readSSEEvent(t, reader))
+
+	// The default namespace only.
+	req, err = http.NewRequest("GET", ts.URL+"/events?namespace=", nil)
+	require.NoError(t, err)
+	req.Header.Set("Last-Event-ID", "0")
+
+	res2, err := http.DefaultClient.Do(req.WithContext(ctx))
+	require.NoError(t, err)
+	defer res2.Body.Close()
+
+	bus.Publish(context.Background(), model.Event{Type: model.EventClientUpgraded, Namespace: "staging", Topic: "some-topic"})
+
+	reader = bufio.NewReader(res2.Body)
+
+	assert.Equal(t, []string{
+		"id: 1",
+		"event: client_registered",
+		`data: {"id":1,"type":"client_registered","time":"2019-02-03T10:00:00Z","topic":"some-topic"}`,
+	}, readSSEEvent(t, reader))
+}
+
+func Test_HTTPHandler_Get_with_an_invalid_last_event_id(t *testing.T) {
+	bus := NewBus(10)
+
+	ts := startEventServer(bus)
+	defer ts.Close()
+
+	req, err := http.NewRequest("GET", ts.URL+"/events", nil)
+	require.NoError(t, err)
+	req.Header.Set("Last-Event-ID", "foobar")
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+	assert.JSONEq(t, `{
+		"kind": "validation error",
+		"message": "invalid input for header \"Last-Event-ID\""
+	}`, string(body))
+}
+
+func Test_HTTPHandler_Get_keep_alive(t *testing.T) {
+	bus := NewBus(10)
+
+	handler := NewHTTPHandler(bus)
+	handler.keepAliveInterval = time.Millisecond
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + "/events")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	line, err := bufio.NewReader(res.Body).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, ": keep-alive\n", line)
+}
+
+func Test_HTTPHandler_Close_end_the_streams(t *testing.T) {
+	bus := NewBus(10)
+
+	handler := NewHTTPHandler(bus)
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+
+	ts := httptest.NewServer(router)
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + "/events")
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	handler.Close()
+	handler.Close()
+
+	_, err = ioutil.ReadAll(res.Body)
+	assert.NoError(t, err)
+}
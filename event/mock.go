@@ -0,0 +1,18 @@
+package event
+
+import (
+	"context"
+
+	"github.com/Peltoche/avro-gateway/model"
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock implementation of a Publisher.
+type Mock struct {
+	mock.Mock
+}
+
+// Publish method mock.
+func (t *Mock) Publish(ctx context.Context, evt model.Event) {
+	t.Called(evt)
+}
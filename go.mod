@@ -1,7 +1,15 @@
 module github.com/Peltoche/avro-gateway
 
+go 1.27.1
+
 require (
 	github.com/gorilla/mux v1.7.0
 	github.com/satori/go.uuid v1.2.0
 	github.com/stretchr/testify v1.3.0
 )
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/stretchr/objx v0.1.0 // indirect
+)
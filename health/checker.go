@@ -0,0 +1,219 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Pinger check if a dependency is reachable.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// RegistryStatus is the state of the Schema Registry of a namespace.
+//
+// Checked is false until the first check is done. LastFetch is the time of
+// the last schema successfully fetched through the gateway.
+type RegistryStatus struct {
+	Namespace string     `json:"namespace"`
+	URL       string     `json:"url"`
+	Checked   bool       `json:"checked"`
+	Reachable bool       `json:"reachable"`
+	Latency   float64    `json:"latency_seconds"`
+	LastCheck *time.Time `json:"last_check,omitempty"`
+	LastError string     `json:"last_error,omitempty"`
+	LastFetch *time.Time `json:"last_fetch,omitempty"`
+}
+
+// StorageStatus is the storage backend of a namespace.
+type StorageStatus struct {
+	Namespace string `json:"namespace"`
+	Backend   string `json:"backend"`
+	Path      string `json:"path,omitempty"`
+}
+
+// Status is the state of all the dependencies.
+type Status struct {
+	Ready      bool             `json:"ready"`
+	Reasons    []string         `json:"reasons,omitempty"`
+	Registries []RegistryStatus `json:"registries"`
+	Storages   []StorageStatus  `json:"storages"`
+}
+
+// Checker follow the state of the dependencies of all the namespaces.
+//
+// The registries are checked periodically by Run and the result is kept, so
+// the probes never wait for the registries.
+type Checker struct {
+	mutex      *sync.Mutex
+	registries []*registryCheck
+	storages   []StorageStatus
+	timeout    time.Duration
+	draining   bool
+	// Set the time function as an attribute in order to be able to mock it.
+	now func() time.Time
+}
+
+type registryCheck struct {
+	pinger Pinger
+	status RegistryStatus
+}
+
+// NewChecker instantiate a new Checker. A registry not answering to a check
+// before the timeout is considered unreachable.
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{
+		mutex:      new(sync.Mutex),
+		registries: []*registryCheck{},
+		storages:   []StorageStatus{},
+		timeout:    timeout,
+		now:        time.Now,
+	}
+}
+
+// AddRegistry add the registry of a namespace to the checks. The returned
+// client must be used for the calls to the registry in order to record the
+// last successful fetch.
+func (t *Checker) AddRegistry(namespace string, url string, client RegistryClient) *TrackedRegistry {
+	check := &registryCheck{
+		pinger: client,
+		status: RegistryStatus{Namespace: namespace, URL: url},
+	}
+
+	t.mutex.Lock()
+	t.registries = append(t.registries, check)
+	t.mutex.Unlock()
+
+	return &TrackedRegistry{
+		next:    client,
+		checker: t,
+		check:   check,
+	}
+}
+
+// AddStorage add the loaded storage of a namespace to the status. The path is
+// empty for an in memory storage.
+func (t *Checker) AddStorage(namespace string, path string) {
+	status := StorageStatus{Namespace: namespace, Backend: "memory"}
+	if path != "" {
+		status.Backend = "file"
+		status.Path = path
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.storages = append(t.storages, status)
+}
+
+// Run check the registries at each interval until the context is canceled.
+func (t *Checker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		t.CheckRegistries(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckRegistries ping all the registries concurrently and save the results.
+func (t *Checker) CheckRegistries(ctx context.Context) {
+	t.mutex.Lock()
+	checks := make([]*registryCheck, len(t.registries))
+	copy(checks, t.registries)
+	t.mutex.Unlock()
+
+	wg := new(sync.WaitGroup)
+	for _, check := range checks {
+		wg.Add(1)
+		go func(check *registryCheck) {
+			defer wg.Done()
+			t.checkRegistry(ctx, check)
+		}(check)
+	}
+
+	wg.Wait()
+}
+
+func (t *Checker) checkRegistry(ctx context.Context, check *registryCheck) {
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	start := t.now()
+	err := check.pinger.Ping(ctx)
+	end := t.now()
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	check.status.Checked = true
+	check.status.Reachable = err == nil
+	check.status.Latency = end.Sub(start).Seconds()
+	check.status.LastCheck = &end
+	check.status.LastError = ""
+	if err != nil {
+		check.status.LastError = err.Error()
+	}
+}
+
+// SetDraining mark the gateway as not ready anymore because it's shutting
+// down, so the orchestrator stop sending it new requests.
+func (t *Checker) SetDraining() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.draining = true
+}
+
+// Status return the state of all the dependencies.
+//
+// The gateway is ready once all the registries have been checked and are
+// reachable, until it starts to shut down.
+func (t *Checker) Status() *Status {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	status := Status{
+		Ready:      true,
+		Registries: make([]RegistryStatus, len(t.registries)),
+		Storages:   make([]StorageStatus, len(t.storages)),
+	}
+	copy(status.Storages, t.storages)
+
+	if t.draining {
+		status.Ready = false
+		status.Reasons = append(status.Reasons, "the gateway is shutting down")
+	}
+
+	for i, check := range t.registries {
+		status.Registries[i] = check.status
+
+		switch {
+		case !check.status.Checked:
+			status.Ready = false
+			status.Reasons = append(status.Reasons, fmt.Sprintf("the registry %q has not been checked yet", check.status.URL))
+		case !check.status.Reachable:
+			status.Ready = false
+			status.Reasons = append(status.Reasons, fmt.Sprintf("the registry %q is unreachable: %s", check.status.URL, check.status.LastError))
+		}
+	}
+
+	return &status
+}
+
+func (t *Checker) recordFetch(check *registryCheck) {
+	now := t.now()
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	check.status.LastFetch = &now
+}
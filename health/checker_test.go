@@ -0,0 +1,132 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Peltoche/avro-gateway/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestChecker() *Checker {
+	checker := NewChecker(time.Second)
+
+	now := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	checker.now = func() time.Time {
+		now = now.Add(50 * time.Millisecond)
+		return now
+	}
+
+	return checker
+}
+
+func Test_Checker_Status_success(t *testing.T) {
+	checker := newTestChecker()
+
+	registryMock := new(registry.Mock)
+	registryMock.On("Ping").Return(nil).Once()
+	registryMock.On("FetchSchema", "some-subject", "1").Return("{}", nil).Once()
+
+	tracked := checker.AddRegistry("", "http://registry:8081", registryMock)
+	checker.AddStorage("", "/var/lib/avro-gateway/clients.dump")
+	checker.AddStorage("prod", "")
+
+	checker.CheckRegistries(context.Background())
+	_, err := tracked.FetchSchema(context.Background(), "some-subject", "1")
+	require.NoError(t, err)
+
+	lastCheck := time.Date(2019, 1, 1, 0, 0, 0, int(100*time.Millisecond), time.UTC)
+	lastFetch := time.Date(2019, 1, 1, 0, 0, 0, int(150*time.Millisecond), time.UTC)
+	assert.Equal(t, &Status{
+		Ready: true,
+		Registries: []RegistryStatus{{
+			URL:       "http://registry:8081",
+			Checked:   true,
+			Reachable: true,
+			Latency:   0.05,
+			LastCheck: &lastCheck,
+			LastFetch: &lastFetch,
+		}},
+		Storages: []StorageStatus{
+			{Backend: "file", Path: "/var/lib/avro-gateway/clients.dump"},
+			{Namespace: "prod", Backend: "memory"},
+		},
+	}, checker.Status())
+
+	registryMock.AssertExpectations(t)
+}
+
+func Test_Checker_Status_not_checked_yet(t *testing.T) {
+	checker := newTestChecker()
+	checker.AddRegistry("", "http://registry:8081", new(registry.Mock))
+
+	status := checker.Status()
+
+	assert.False(t, status.Ready)
+	assert.Equal(t, []string{`the registry "http://registry:8081" has not been checked yet`}, status.Reasons)
+}
+
+func Test_Checker_Status_with_an_unreachable_registry(t *testing.T) {
+	checker := newTestChecker()
+
+	registryMock := new(registry.Mock)
+	registryMock.On("Ping").Return(errors.New("connection refused")).Once()
+
+	checker.AddRegistry("prod", "http://registry:8081", registryMock)
+	checker.CheckRegistries(context.Background())
+
+	status := checker.Status()
+
+	assert.False(t, status.Ready)
+	assert.Equal(t, []string{`the registry "http://registry:8081" is unreachable: connection refused`}, status.Reasons)
+	assert.Equal(t, "connection refused", status.Registries[0].LastError)
+	registryMock.AssertExpectations(t)
+}
+
+func Test_Checker_Status_registry_back_online(t *testing.T) {
+	checker := newTestChecker()
+
+	registryMock := new(registry.Mock)
+	registryMock.On("Ping").Return(errors.New("connection refused")).Once()
+	registryMock.On("Ping").Return(nil).Once()
+
+	checker.AddRegistry("", "http://registry:8081", registryMock)
+
+	checker.CheckRegistries(context.Background())
+	assert.False(t, checker.Status().Ready)
+
+	checker.CheckRegistries(context.Background())
+	status := checker.Status()
+	assert.True(t, status.Ready)
+	assert.Empty(t, status.Registries[0].LastError)
+
+	registryMock.AssertExpectations(t)
+}
+
+func Test_TrackedRegistry_FetchSchema_with_error(t *testing.T) {
+	checker := newTestChecker()
+
+	registryMock := new(registry.Mock)
+	registryMock.On("FetchSchema", "some-subject", "1").Return("", errors.New("some-error")).Once()
+
+	tracked := checker.AddRegistry("", "http://registry:8081", registryMock)
+
+	_, err := tracked.FetchSchema(context.Background(), "some-subject", "1")
+
+	assert.EqualError(t, err, "some-error")
+	assert.Nil(t, checker.Status().Registries[0].LastFetch)
+	registryMock.AssertExpectations(t)
+}
+
+func Test_Checker_Status_draining(t *testing.T) {
+	checker := newTestChecker()
+
+	checker.SetDraining()
+
+	status := checker.Status()
+	assert.False(t, status.Ready)
+	assert.Equal(t, []string{"the gateway is shutting down"}, status.Reasons)
+}
@@ -0,0 +1,60 @@
+package health
+
+import (
+	"net/http"
+
+	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/gorilla/mux"
+)
+
+// HTTPHandler serve the probes and the dependencies status.
+type HTTPHandler struct {
+	checker *Checker
+}
+
+// NewHTTPHandler instantiate a new HTTPHandler.
+func NewHTTPHandler(checker *Checker) *HTTPHandler {
+	return &HTTPHandler{
+		checker: checker,
+	}
+}
+
+// RegisterRoutes register the status route into the router.
+func (t *HTTPHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/status", t.GetStatus).Methods("GET")
+}
+
+// RegisterProbes register the liveness and readiness probes into the router.
+// They are kept apart as the orchestrator call them without credentials.
+func (t *HTTPHandler) RegisterProbes(router *mux.Router) {
+	router.HandleFunc("/healthz", t.GetHealth).Methods("GET")
+	router.HandleFunc("/readyz", t.GetReadiness).Methods("GET")
+}
+
+// GetHealth /healthz
+//
+// Always succeed while the process is able to answer.
+func (t *HTTPHandler) GetHealth(w http.ResponseWriter, r *http.Request) {
+	internal.WriteJSON(r.Context(), w, http.StatusOK, map[string]interface{}{"status": "ok"})
+}
+
+// GetReadiness /readyz
+//
+// Answer 503 Service Unavailable while the gateway can't take traffic.
+func (t *HTTPHandler) GetReadiness(w http.ResponseWriter, r *http.Request) {
+	status := t.checker.Status()
+	if !status.Ready {
+		internal.WriteJSON(r.Context(), w, http.StatusServiceUnavailable, map[string]interface{}{
+			"status":  "not ready",
+			"reasons": status.Reasons,
+		})
+		return
+	}
+
+	internal.WriteJSON(r.Context(), w, http.StatusOK, map[string]interface{}{"status": "ready"})
+}
+
+// GetStatus /status
+func (t *HTTPHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	internal.WriteJSON(r.Context(), w, http.StatusOK, t.checker.Status())
+}
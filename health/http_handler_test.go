@@ -0,0 +1,131 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Peltoche/avro-gateway/registry"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_HTTPHandler_GetHealth_success(t *testing.T) {
+	handler := NewHTTPHandler(newTestChecker())
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com/healthz", nil)
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	handler.RegisterProbes(router)
+	router.ServeHTTP(w, r)
+
+	res := w.Result()
+	body, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "application/json", res.Header.Get("Content-Type"))
+	assert.JSONEq(t, `{"status": "ok"}`, string(body))
+}
+
+func Test_HTTPHandler_GetReadiness_success(t *testing.T) {
+	checker := newTestChecker()
+
+	registryMock := new(registry.Mock)
+	registryMock.On("Ping").Return(nil).Once()
+	checker.AddRegistry("", "http://registry:8081", registryMock)
+	checker.CheckRegistries(context.Background())
+
+	handler := NewHTTPHandler(checker)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com/readyz", nil)
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	handler.RegisterProbes(router)
+	router.ServeHTTP(w, r)
+
+	res := w.Result()
+	body, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "application/json", res.Header.Get("Content-Type"))
+	assert.JSONEq(t, `{"status": "ready"}`, string(body))
+}
+
+func Test_HTTPHandler_GetReadiness_not_ready(t *testing.T) {
+	checker := newTestChecker()
+
+	registryMock := new(registry.Mock)
+	registryMock.On("Ping").Return(errors.New("connection refused")).Once()
+	checker.AddRegistry("", "http://registry:8081", registryMock)
+	checker.CheckRegistries(context.Background())
+
+	handler := NewHTTPHandler(checker)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com/readyz", nil)
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	handler.RegisterProbes(router)
+	router.ServeHTTP(w, r)
+
+	res := w.Result()
+	body, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+	assert.Equal(t, "application/json", res.Header.Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"status": "not ready",
+		"reasons": ["the registry \"http://registry:8081\" is unreachable: connection refused"]
+	}`, string(body))
+}
+
+func Test_HTTPHandler_GetStatus_success(t *testing.T) {
+	checker := newTestChecker()
+
+	registryMock := new(registry.Mock)
+	registryMock.On("Ping").Return(nil).Once()
+	checker.AddRegistry("", "http://registry:8081", registryMock)
+	checker.AddStorage("", "")
+	checker.CheckRegistries(context.Background())
+
+	handler := NewHTTPHandler(checker)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com/status", nil)
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	handler.RegisterProbes(router)
+	router.ServeHTTP(w, r)
+
+	res := w.Result()
+	body, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "application/json", res.Header.Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"ready": true,
+		"registries": [{
+			"namespace": "",
+			"url": "http://registry:8081",
+			"checked": true,
+			"reachable": true,
+			"latency_seconds": 0.05,
+			"last_check": "2019-01-01T00:00:00.1Z"
+		}],
+		"storages": [{"namespace": "", "backend": "memory"}]
+	}`, string(body))
+}
@@ -0,0 +1,50 @@
+package health
+
+import "context"
+
+// RegistryClient is the Schema Registry client followed by the Checker.
+type RegistryClient interface {
+	Ping(ctx context.Context) error
+	FetchSchema(ctx context.Context, subject string, version string) (string, error)
+	FetchSchemaByID(ctx context.Context, id int) (string, error)
+	FetchSchemaID(ctx context.Context, subject string, version string) (int, error)
+	ListVersions(ctx context.Context, subject string, includeDeleted bool) ([]int, error)
+}
+
+// TrackedRegistry is a registry client recording the last successful fetch.
+type TrackedRegistry struct {
+	next    RegistryClient
+	checker *Checker
+	check   *registryCheck
+}
+
+// FetchSchema corresponding to the subject/version.
+func (t *TrackedRegistry) FetchSchema(ctx context.Context, subject string, version string) (string, error) {
+	schema, err := t.next.FetchSchema(ctx, subject, version)
+	if err == nil {
+		t.checker.recordFetch(t.check)
+	}
+
+	return schema, err
+}
+
+// FetchSchemaByID return the schema registered with the id.
+func (t *TrackedRegistry) FetchSchemaByID(ctx context.Context, id int) (string, error) {
+	schema, err := t.next.FetchSchemaByID(ctx, id)
+	if err == nil {
+		t.checker.recordFetch(t.check)
+	}
+
+	return schema, err
+}
+
+// FetchSchemaID return the id of the schema registered under the
+// subject/version.
+func (t *TrackedRegistry) FetchSchemaID(ctx context.Context, subject string, version string) (int, error) {
+	return t.next.FetchSchemaID(ctx, subject, version)
+}
+
+// ListVersions return all the versions registered for the subject.
+func (t *TrackedRegistry) ListVersions(ctx context.Context, subject string, includeDeleted bool) ([]int, error) {
+	return t.next.ListVersions(ctx, subject, includeDeleted)
+}
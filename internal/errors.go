@@ -1,10 +1,12 @@
 package internal
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
+
+	"github.com/Peltoche/avro-gateway/logging"
 )
 
 // ErrorKind is the exostive list of all the possible type of error.
@@ -24,6 +26,12 @@ var (
 	InvalidJSONBody ErrorKind = "invalid json body"
 	// BadRequest is returned when the client request is invalid.
 	BadRequest ErrorKind = "bad request"
+	// Unauthorized is returned when the caller credentials are missing or invalid.
+	Unauthorized ErrorKind = "unauthorized"
+	// Forbidden is returned when the caller is not allowed to do the action.
+	Forbidden ErrorKind = "forbidden"
+	// TooManyRequests is returned when the caller exceeded its rate limit.
+	TooManyRequests ErrorKind = "too many requests"
 )
 
 // Error returned a the differents components.
@@ -99,27 +107,55 @@ func IsKind(errorKind ErrorKind, err error) bool {
 }
 
 // WriteErrorIntoResponse will take the error and generate the correct response.
+//
+// The internal errors are logged with the request id set on the response by
+// the logging middleware.
 func WriteErrorIntoResponse(w http.ResponseWriter, err error) {
 	innerError, ok := err.(*Error)
 	if !ok {
 		innerError = Errorf(InternalError, "unhandled error: %s", err).(*Error)
 	}
 
+	ctx := context.Background()
+	if requestID := w.Header().Get(logging.RequestIDHeader); requestID != "" {
+		ctx = logging.WithRequestID(ctx, requestID)
+	}
+
+	status := StatusCode(innerError)
+	if status == http.StatusInternalServerError {
+		logging.Error(ctx, "internal error", logging.Fields{"error": innerError})
+	}
+	w.WriteHeader(status)
+
+	err = json.NewEncoder(w).Encode(innerError)
+	if err != nil {
+		logging.Warn(ctx, "failed to write the response", logging.Fields{"error": err})
+	}
+}
+
+// StatusCode return the HTTP status corresponding to the error kind.
+func StatusCode(err error) int {
+	innerError, ok := err.(*Error)
+	if !ok {
+		return http.StatusInternalServerError
+	}
+
 	switch innerError.Kind {
 	case RemoteError:
-		w.WriteHeader(http.StatusBadGateway)
+		return http.StatusBadGateway
 	case ValidationError:
-		w.WriteHeader(http.StatusUnprocessableEntity)
+		return http.StatusUnprocessableEntity
 	case InvalidJSONBody:
-		w.WriteHeader(http.StatusUnprocessableEntity)
+		return http.StatusUnprocessableEntity
 	case NotFound:
-		w.WriteHeader(http.StatusNotFound)
+		return http.StatusNotFound
+	case Unauthorized:
+		return http.StatusUnauthorized
+	case Forbidden:
+		return http.StatusForbidden
+	case TooManyRequests:
+		return http.StatusTooManyRequests
 	default:
-		w.WriteHeader(http.StatusInternalServerError)
-	}
-
-	err = json.NewEncoder(w).Encode(innerError)
-	if err != nil {
-		log.Print(err)
+		return http.StatusInternalServerError
 	}
 }
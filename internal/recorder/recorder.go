@@ -0,0 +1,43 @@
+// Package recorder observe the responses written by the HTTP handlers.
+package recorder
+
+import "net/http"
+
+// StatusRecorder keep the status and the size of a response. It implements
+// http.Flusher in order to not break the streamed responses.
+type StatusRecorder struct {
+	http.ResponseWriter
+	// Status is the response status, 200 until an other one is written.
+	Status int
+	// Bytes is the number of body bytes written.
+	Bytes int
+}
+
+// NewStatusRecorder instantiate a new StatusRecorder wrapping w.
+func NewStatusRecorder(w http.ResponseWriter) *StatusRecorder {
+	return &StatusRecorder{
+		ResponseWriter: w,
+		Status:         http.StatusOK,
+	}
+}
+
+// WriteHeader record the status and write it.
+func (t *StatusRecorder) WriteHeader(status int) {
+	t.Status = status
+	t.ResponseWriter.WriteHeader(status)
+}
+
+// Write count the written bytes.
+func (t *StatusRecorder) Write(b []byte) (int, error) {
+	n, err := t.ResponseWriter.Write(b)
+	t.Bytes += n
+
+	return n, err
+}
+
+// Flush send the buffered data if the wrapped writer can.
+func (t *StatusRecorder) Flush() {
+	if flusher, ok := t.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
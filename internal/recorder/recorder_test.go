@@ -0,0 +1,35 @@
+package recorder
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_StatusRecorder_success(t *testing.T) {
+	w := httptest.NewRecorder()
+	recorder := NewStatusRecorder(w)
+
+	recorder.WriteHeader(http.StatusTeapot)
+	_, err := recorder.Write([]byte("some-body"))
+	require.NoError(t, err)
+	recorder.Flush()
+
+	assert.Equal(t, http.StatusTeapot, recorder.Status)
+	assert.Equal(t, 9, recorder.Bytes)
+	assert.Equal(t, http.StatusTeapot, w.Code)
+	assert.Equal(t, "some-body", w.Body.String())
+	assert.True(t, w.Flushed)
+}
+
+func Test_StatusRecorder_without_status(t *testing.T) {
+	recorder := NewStatusRecorder(httptest.NewRecorder())
+
+	_, err := recorder.Write([]byte("some-body"))
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, recorder.Status)
+}
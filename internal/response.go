@@ -0,0 +1,20 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/Peltoche/avro-gateway/logging"
+)
+
+// WriteJSON write the value encoded in JSON into the response with the given
+// status.
+func WriteJSON(ctx context.Context, w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	err := json.NewEncoder(w).Encode(value)
+	if err != nil {
+		logging.Warn(ctx, "failed to write the response", logging.Fields{"error": err})
+	}
+}
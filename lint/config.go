@@ -0,0 +1,94 @@
+package lint
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+
+	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/Peltoche/avro-gateway/model"
+)
+
+// Case is a naming convention.
+type Case string
+
+const (
+	// CamelCase is like "userName".
+	CamelCase Case = "camelCase"
+	// PascalCase is like "UserName".
+	PascalCase Case = "PascalCase"
+	// SnakeCase is like "user_name".
+	SnakeCase Case = "snake_case"
+)
+
+// Rule is a set of checks applied on the schemas of the topics matching a
+// pattern. All the checks are disabled by default.
+type Rule struct {
+	// Topics is a glob pattern, like "payments.*".
+	Topics string `json:"topics"`
+	// Severity of the violations, "error" by default.
+	Severity model.LintSeverity `json:"severity"`
+	// RequireDoc require a doc on the records and their fields.
+	RequireDoc bool `json:"require_doc"`
+	// NamespacePrefix is the prefix of the named types namespace, like
+	// "com.example".
+	NamespacePrefix string `json:"namespace_prefix"`
+	// FieldCase is the naming convention of the record fields.
+	FieldCase Case `json:"field_case"`
+	// TypeCase is the naming convention of the named types: records, enums
+	// and fixed.
+	TypeCase Case `json:"type_case"`
+	// RequireOptionalDefaults require a default value on the optional
+	// fields, the unions containing "null".
+	RequireOptionalDefaults bool `json:"require_optional_defaults"`
+	// ForbiddenTypes contains the forbidden type names. A primitive or fixed
+	// type name match only the values without logical type, like "bytes" for
+	// the raw bytes but not the decimals. A logical type name match the
+	// values with this logical type, like "timestamp-micros".
+	ForbiddenTypes []string `json:"forbidden_types"`
+}
+
+// Config is the linting configuration.
+type Config struct {
+	Rules []Rule `json:"rules"`
+}
+
+// LoadConfig read the linting configuration from a JSON file.
+func LoadConfig(path string) (*Config, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, internal.Errorf(internal.InternalError, "failed to open the lint config: %s", err)
+	}
+	defer file.Close()
+
+	var config Config
+	err = json.NewDecoder(file).Decode(&config)
+	if err != nil {
+		return nil, internal.Errorf(internal.ValidationError, "failed to decode the lint config: %s", err)
+	}
+
+	return &config, nil
+}
+
+func validateConfig(config *Config) error {
+	for i, rule := range config.Rules {
+		_, err := path.Match(rule.Topics, "")
+		if rule.Topics == "" || err != nil {
+			return internal.Errorf(internal.ValidationError, "rule %d: invalid topic pattern %q", i, rule.Topics)
+		}
+
+		if rule.Severity != "" && rule.Severity != model.LintWarning && rule.Severity != model.LintError {
+			return internal.Errorf(internal.ValidationError, `rule %d: invalid input for field "severity"`, i)
+		}
+
+		if rule.FieldCase != "" && casePatterns[rule.FieldCase] == nil {
+			return internal.Errorf(internal.ValidationError, `rule %d: invalid input for field "field_case"`, i)
+		}
+
+		if rule.TypeCase != "" && casePatterns[rule.TypeCase] == nil {
+			return internal.Errorf(internal.ValidationError, `rule %d: invalid input for field "type_case"`, i)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package lint
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/Peltoche/avro-gateway/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_LoadConfig_success(t *testing.T) {
+	file, err := ioutil.TempFile("", "lint")
+	require.NoError(t, err)
+	defer os.Remove(file.Name())
+
+	_, err = file.WriteString(`{
+		"rules": [{
+			"topics": "payments.*",
+			"severity": "warning",
+			"require_doc": true,
+			"namespace_prefix": "com.example",
+			"field_case": "camelCase",
+			"type_case": "PascalCase",
+			"require_optional_defaults": true,
+			"forbidden_types": ["bytes"]
+		}]
+	}`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	config, err := LoadConfig(file.Name())
+
+	assert.NoError(t, err)
+	assert.Equal(t, &Config{
+		Rules: []Rule{{
+			Topics:                  "payments.*",
+			Severity:                model.LintWarning,
+			RequireDoc:              true,
+			NamespacePrefix:         "com.example",
+			FieldCase:               CamelCase,
+			TypeCase:                PascalCase,
+			RequireOptionalDefaults: true,
+			ForbiddenTypes:          []string{"bytes"},
+		}},
+	}, config)
+}
+
+func Test_LoadConfig_with_a_missing_file(t *testing.T) {
+	config, err := LoadConfig("/some/unknown/path")
+
+	assert.Nil(t, config)
+	assert.EqualError(t, err, "internal error: failed to open the lint config: open /some/unknown/path: no such file or directory")
+}
+
+func Test_LoadConfig_with_an_invalid_json(t *testing.T) {
+	file, err := ioutil.TempFile("", "lint")
+	require.NoError(t, err)
+	defer os.Remove(file.Name())
+
+	_, err = file.WriteString(`{"rules": 42}`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	config, err := LoadConfig(file.Name())
+
+	assert.Nil(t, config)
+	assert.Contains(t, err.Error(), "validation error: failed to decode the lint config")
+}
+
+func Test_validateConfig_errors(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+		err  string
+	}{
+		{"missing topics", Rule{}, `validation error: rule 0: invalid topic pattern ""`},
+		{"invalid topics", Rule{Topics: "["}, `validation error: rule 0: invalid topic pattern "["`},
+		{"invalid severity", Rule{Topics: "*", Severity: "fatal"}, `validation error: rule 0: invalid input for field "severity"`},
+		{"invalid field case", Rule{Topics: "*", FieldCase: "kebab-case"}, `validation error: rule 0: invalid input for field "field_case"`},
+		{"invalid type case", Rule{Topics: "*", TypeCase: "UPPER"}, `validation error: rule 0: invalid input for field "type_case"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateConfig(&Config{Rules: []Rule{test.rule}})
+
+			assert.EqualError(t, err, test.err)
+		})
+	}
+}
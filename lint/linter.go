@@ -0,0 +1,175 @@
+package lint
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/Peltoche/avro-gateway/avro"
+	"github.com/Peltoche/avro-gateway/model"
+)
+
+var casePatterns = map[Case]*regexp.Regexp{
+	CamelCase:  regexp.MustCompile(`^[a-z][a-zA-Z0-9]*$`),
+	PascalCase: regexp.MustCompile(`^[A-Z][a-zA-Z0-9]*$`),
+	SnakeCase:  regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`),
+}
+
+// Linter check the schemas against the governance rules.
+type Linter struct {
+	config *Config
+}
+
+// NewLinter instantiate a new Linter.
+func NewLinter(config *Config) (*Linter, error) {
+	err := validateConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Linter{config: config}, nil
+}
+
+// Lint return the violations of the rules matching the topic, in the order of
+// the rules then of the schema.
+func (t *Linter) Lint(ctx context.Context, topic string, schema *avro.Schema) []model.LintViolation {
+	var rules []Rule
+	for _, rule := range t.config.Rules {
+		// The pattern have been validated.
+		matched, _ := path.Match(rule.Topics, topic)
+		if matched {
+			rules = append(rules, rule)
+		}
+	}
+
+	if len(rules) == 0 {
+		return nil
+	}
+
+	var violations []model.LintViolation
+	for _, rule := range rules {
+		w := walker{rule: rule, seen: map[string]bool{}}
+		w.walk(schema, "$")
+
+		violations = append(violations, w.violations...)
+	}
+
+	return violations
+}
+
+type walker struct {
+	rule       Rule
+	violations []model.LintViolation
+	// seen contains the named types already checked. They are checked at
+	// their definition only.
+	seen map[string]bool
+}
+
+func (t *walker) add(rule string, path string, msg string, args ...interface{}) {
+	severity := t.rule.Severity
+	if severity == "" {
+		severity = model.LintError
+	}
+
+	t.violations = append(t.violations, model.LintViolation{
+		Rule:     rule,
+		Severity: severity,
+		Path:     path,
+		Message:  fmt.Sprintf(msg, args...),
+	})
+}
+
+func (t *walker) walk(schema *avro.Schema, path string) {
+	if schema.Name != "" {
+		if t.seen[schema.Name] {
+			return
+		}
+		t.seen[schema.Name] = true
+
+		t.checkNamedType(schema, path)
+	}
+
+	t.checkForbiddenType(schema, path)
+
+	switch schema.Type {
+	case avro.Record:
+		for _, field := range schema.Fields {
+			t.checkField(field, path+"."+field.Name)
+			t.walk(field.Type, path+"."+field.Name)
+		}
+	case avro.Array:
+		t.walk(schema.Items, path+"[*]")
+	case avro.Map:
+		t.walk(schema.Values, path+"[*]")
+	case avro.Union:
+		for _, branch := range schema.Branches {
+			t.walk(branch, path)
+		}
+	}
+}
+
+func (t *walker) checkNamedType(schema *avro.Schema, path string) {
+	if t.rule.RequireDoc && schema.Type == avro.Record && schema.Doc == "" {
+		t.add("require_doc", path, "the record %s has no doc", schema.Name)
+	}
+
+	prefix := t.rule.NamespacePrefix
+	if prefix != "" {
+		namespace := schema.Namespace()
+		if namespace != prefix && !strings.HasPrefix(namespace, prefix+".") {
+			t.add("namespace_prefix", path, "the namespace of %s must start with %q", schema.Name, prefix)
+		}
+	}
+
+	if t.rule.TypeCase != "" && !casePatterns[t.rule.TypeCase].MatchString(schema.ShortName()) {
+		t.add("type_case", path, "the name of %s must be in %s", schema.Name, t.rule.TypeCase)
+	}
+}
+
+func (t *walker) checkField(field *avro.Field, path string) {
+	if t.rule.RequireDoc && field.Doc == "" {
+		t.add("require_doc", path, "the field %q has no doc", field.Name)
+	}
+
+	if t.rule.FieldCase != "" && !casePatterns[t.rule.FieldCase].MatchString(field.Name) {
+		t.add("field_case", path, "the field %q must be in %s", field.Name, t.rule.FieldCase)
+	}
+
+	if t.rule.RequireOptionalDefaults && isOptional(field.Type) && !field.HasDefault {
+		t.add("require_optional_defaults", path, "the optional field %q has no default value", field.Name)
+	}
+}
+
+func (t *walker) checkForbiddenType(schema *avro.Schema, path string) {
+	if schema.Type == avro.Union {
+		return
+	}
+
+	name := string(schema.Type)
+	if schema.LogicalType != "" {
+		name = schema.LogicalType
+	}
+
+	for _, forbidden := range t.rule.ForbiddenTypes {
+		if forbidden == name || (schema.Name != "" && forbidden == schema.Name) {
+			t.add("forbidden_types", path, "the type %s is forbidden", forbidden)
+		}
+	}
+}
+
+// isOptional return true for the unions accepting null.
+func isOptional(schema *avro.Schema) bool {
+	if schema.Type != avro.Union {
+		return false
+	}
+
+	for _, branch := range schema.Branches {
+		if branch.Type == avro.Null {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,134 @@
+package lint
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Peltoche/avro-gateway/avro"
+	"github.com/Peltoche/avro-gateway/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const someSchema = `{
+	"type": "record",
+	"name": "com.example.Payment",
+	"doc": "A payment.",
+	"fields": [
+		{"name": "id", "type": "string", "doc": "The payment id."},
+		{"name": "amount", "type": {"type": "bytes", "logicalType": "decimal", "precision": 10, "scale": 2}, "doc": "The amount."},
+		{"name": "note", "type": ["null", "string"], "default": null, "doc": "A free note."}
+	]
+}`
+
+func Test_Linter_Lint_success(t *testing.T) {
+	linter, err := NewLinter(&Config{
+		Rules: []Rule{{
+			Topics:                  "payments.*",
+			RequireDoc:              true,
+			NamespacePrefix:         "com.example",
+			FieldCase:               CamelCase,
+			TypeCase:                PascalCase,
+			RequireOptionalDefaults: true,
+			ForbiddenTypes:          []string{"bytes"},
+		}},
+	})
+	require.NoError(t, err)
+
+	violations := linter.Lint(context.Background(), "payments.eu", mustParse(t, someSchema))
+
+	assert.Empty(t, violations)
+}
+
+func Test_Linter_Lint_with_violations(t *testing.T) {
+	linter, err := NewLinter(&Config{
+		Rules: []Rule{
+			{
+				Topics:                  "payments.*",
+				RequireDoc:              true,
+				NamespacePrefix:         "com.example",
+				FieldCase:               CamelCase,
+				TypeCase:                PascalCase,
+				RequireOptionalDefaults: true,
+				ForbiddenTypes:          []string{"bytes"},
+			},
+			{
+				Topics:     "*",
+				Severity:   model.LintWarning,
+				RequireDoc: true,
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	violations := linter.Lint(context.Background(), "payments.eu", mustParse(t, `{
+		"type": "record",
+		"name": "org.other.payment",
+		"fields": [
+			{"name": "Amount", "type": "bytes"},
+			{"name": "note", "type": ["null", "string"], "doc": "A free note."},
+			{"name": "items", "type": {"type": "array", "items": {
+				"type": "record",
+				"name": "Item",
+				"doc": "An item.",
+				"fields": [{"name": "price", "type": "bytes", "doc": "The price."}]
+			}}, "doc": "The items."}
+		]
+	}`))
+
+	assert.Equal(t, []model.LintViolation{
+		{Rule: "require_doc", Severity: model.LintError, Path: "$", Message: "the record org.other.payment has no doc"},
+		{Rule: "namespace_prefix", Severity: model.LintError, Path: "$", Message: `the namespace of org.other.payment must start with "com.example"`},
+		{Rule: "type_case", Severity: model.LintError, Path: "$", Message: "the name of org.other.payment must be in PascalCase"},
+		{Rule: "require_doc", Severity: model.LintError, Path: "$.Amount", Message: `the field "Amount" has no doc`},
+		{Rule: "field_case", Severity: model.LintError, Path: "$.Amount", Message: `the field "Amount" must be in camelCase`},
+		{Rule: "forbidden_types", Severity: model.LintError, Path: "$.Amount", Message: "the type bytes is forbidden"},
+		{Rule: "require_optional_defaults", Severity: model.LintError, Path: "$.note", Message: `the optional field "note" has no default value`},
+		{Rule: "namespace_prefix", Severity: model.LintError, Path: "$.items[*]", Message: `the namespace of org.other.Item must start with "com.example"`},
+		{Rule: "forbidden_types", Severity: model.LintError, Path: "$.items[*].price", Message: "the type bytes is forbidden"},
+		{Rule: "require_doc", Severity: model.LintWarning, Path: "$", Message: "the record org.other.payment has no doc"},
+		{Rule: "require_doc", Severity: model.LintWarning, Path: "$.Amount", Message: `the field "Amount" has no doc`},
+	}, violations)
+}
+
+func Test_Linter_Lint_with_a_recursive_type(t *testing.T) {
+	linter, err := NewLinter(&Config{
+		Rules: []Rule{{Topics: "*", RequireDoc: true}},
+	})
+	require.NoError(t, err)
+
+	violations := linter.Lint(context.Background(), "some-topic", mustParse(t, `{
+		"type": "record",
+		"name": "Node",
+		"fields": [{"name": "next", "type": ["null", "Node"], "doc": "The next node."}]
+	}`))
+
+	assert.Equal(t, []model.LintViolation{
+		{Rule: "require_doc", Severity: model.LintError, Path: "$", Message: "the record Node has no doc"},
+	}, violations)
+}
+
+func Test_Linter_Lint_with_an_unmatched_topic(t *testing.T) {
+	linter, err := NewLinter(&Config{
+		Rules: []Rule{{Topics: "payments.*", RequireDoc: true}},
+	})
+	require.NoError(t, err)
+
+	violations := linter.Lint(context.Background(), "orders", mustParse(t, `{"type": "record", "name": "R", "fields": []}`))
+
+	assert.Nil(t, violations)
+}
+
+func Test_NewLinter_with_an_invalid_config(t *testing.T) {
+	linter, err := NewLinter(&Config{Rules: []Rule{{}}})
+
+	assert.Nil(t, linter)
+	assert.EqualError(t, err, `validation error: rule 0: invalid topic pattern ""`)
+}
+
+func mustParse(t *testing.T, schema string) *avro.Schema {
+	parsed, err := avro.Parse(schema)
+	require.NoError(t, err)
+
+	return parsed
+}
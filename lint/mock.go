@@ -0,0 +1,24 @@
+package lint
+
+import (
+	"context"
+
+	"github.com/Peltoche/avro-gateway/avro"
+	"github.com/Peltoche/avro-gateway/model"
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock implementation of a Linter.
+type Mock struct {
+	mock.Mock
+}
+
+// Lint method mock.
+func (t *Mock) Lint(ctx context.Context, topic string, schema *avro.Schema) []model.LintViolation {
+	res := t.Called(topic, schema).Get(0)
+	if res == nil {
+		return nil
+	}
+
+	return res.([]model.LintViolation)
+}
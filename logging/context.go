@@ -0,0 +1,45 @@
+package logging
+
+import "context"
+
+type fieldsKey struct{}
+type requestIDKey struct{}
+
+// WithFields return a copy of the context carrying the fields, added to all
+// the entries logged with this context.
+func WithFields(ctx context.Context, fields Fields) context.Context {
+	merged := Fields{}
+	for key, value := range fieldsFromContext(ctx) {
+		merged[key] = value
+	}
+	for key, value := range fields {
+		merged[key] = value
+	}
+
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// WithRequestID return a copy of the context carrying the request id. The id
+// is added to the logged entries as "request_id".
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	ctx = context.WithValue(ctx, requestIDKey{}, requestID)
+
+	return WithFields(ctx, Fields{"request_id": requestID})
+}
+
+// RequestIDFromContext return the request id or an empty string.
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+
+	return requestID
+}
+
+func fieldsFromContext(ctx context.Context) Fields {
+	if ctx == nil {
+		return nil
+	}
+
+	fields, _ := ctx.Value(fieldsKey{}).(Fields)
+
+	return fields
+}
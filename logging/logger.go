@@ -0,0 +1,139 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level is the severity of a log entry.
+type Level int
+
+// The available levels, from the most verbose.
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = []string{"debug", "info", "warn", "error"}
+
+// String return the name of the level.
+func (t Level) String() string {
+	if t < LevelDebug || t > LevelError {
+		return "unknown"
+	}
+
+	return levelNames[t]
+}
+
+// ParseLevel return the level matching the name.
+func ParseLevel(name string) (Level, error) {
+	for i, levelName := range levelNames {
+		if strings.ToLower(name) == levelName {
+			return Level(i), nil
+		}
+	}
+
+	return LevelInfo, fmt.Errorf("unknown log level %q", name)
+}
+
+// Fields are the key/values added to a log entry.
+type Fields map[string]interface{}
+
+// Logger write the entries as JSON lines.
+//
+// Each entry contains the "time", "level" and "msg" keys, the fields carried
+// by the context (see WithFields) and the fields given at the call.
+type Logger struct {
+	mutex *sync.Mutex
+	w     io.Writer
+	level Level
+	// Set the time function as an attribute in order to be able to mock it.
+	now func() time.Time
+}
+
+// NewLogger instantiate a new Logger writing the entries of at least the
+// given level.
+func NewLogger(w io.Writer, level Level) *Logger {
+	return &Logger{
+		mutex: new(sync.Mutex),
+		w:     w,
+		level: level,
+		now:   time.Now,
+	}
+}
+
+// Log write an entry.
+func (t *Logger) Log(ctx context.Context, level Level, msg string, fields Fields) {
+	if level < t.level {
+		return
+	}
+
+	entry := Fields{}
+	for key, value := range fieldsFromContext(ctx) {
+		entry[key] = value
+	}
+	for key, value := range fields {
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		}
+		entry[key] = value
+	}
+	entry["time"] = t.now().UTC().Format(time.RFC3339Nano)
+	entry["level"] = level.String()
+	entry["msg"] = msg
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		line, _ = json.Marshal(Fields{
+			"time":  entry["time"],
+			"level": LevelError.String(),
+			"msg":   fmt.Sprintf("failed to encode the log entry %q: %s", msg, err),
+		})
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	_, _ = t.w.Write(append(line, '\n'))
+}
+
+var defaultLogger = NewLogger(os.Stderr, LevelInfo)
+
+// SetDefault replace the logger used by the package functions.
+func SetDefault(logger *Logger) {
+	defaultLogger = logger
+}
+
+// Debug write a debug entry with the default logger.
+func Debug(ctx context.Context, msg string, fields Fields) {
+	defaultLogger.Log(ctx, LevelDebug, msg, fields)
+}
+
+// Info write an info entry with the default logger.
+func Info(ctx context.Context, msg string, fields Fields) {
+	defaultLogger.Log(ctx, LevelInfo, msg, fields)
+}
+
+// Warn write a warning entry with the default logger.
+func Warn(ctx context.Context, msg string, fields Fields) {
+	defaultLogger.Log(ctx, LevelWarn, msg, fields)
+}
+
+// Error write an error entry with the default logger.
+func Error(ctx context.Context, msg string, fields Fields) {
+	defaultLogger.Log(ctx, LevelError, msg, fields)
+}
+
+// Fatal write an error entry with the default logger then exit.
+func Fatal(ctx context.Context, msg string, fields Fields) {
+	defaultLogger.Log(ctx, LevelError, msg, fields)
+	os.Exit(1)
+}
@@ -0,0 +1,78 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLogger(level Level) (*Logger, *bytes.Buffer) {
+	buf := new(bytes.Buffer)
+	logger := NewLogger(buf, level)
+	logger.now = func() time.Time {
+		return time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	return logger, buf
+}
+
+func Test_Logger_Log_success(t *testing.T) {
+	logger, buf := newTestLogger(LevelInfo)
+
+	ctx := WithRequestID(context.Background(), "some-request-id")
+	logger.Log(ctx, LevelWarn, "some message", Fields{"foo": 42, "error": errors.New("some-error")})
+
+	assert.JSONEq(t, `{
+		"time": "2019-01-01T00:00:00Z",
+		"level": "warn",
+		"msg": "some message",
+		"request_id": "some-request-id",
+		"foo": 42,
+		"error": "some-error"
+	}`, buf.String())
+}
+
+func Test_Logger_Log_below_the_level(t *testing.T) {
+	logger, buf := newTestLogger(LevelInfo)
+
+	logger.Log(context.Background(), LevelDebug, "some message", nil)
+
+	assert.Empty(t, buf.String())
+}
+
+func Test_Logger_Log_reserved_keys_not_overridden(t *testing.T) {
+	logger, buf := newTestLogger(LevelDebug)
+
+	logger.Log(context.Background(), LevelDebug, "some message", Fields{"msg": "other message", "level": "error"})
+
+	assert.JSONEq(t, `{"time": "2019-01-01T00:00:00Z", "level": "debug", "msg": "some message"}`, buf.String())
+}
+
+func Test_WithFields_merge_the_fields(t *testing.T) {
+	ctx := WithFields(context.Background(), Fields{"foo": "bar", "some": "value"})
+	ctx = WithFields(ctx, Fields{"foo": "baz"})
+
+	assert.Equal(t, Fields{"foo": "baz", "some": "value"}, fieldsFromContext(ctx))
+}
+
+func Test_RequestIDFromContext_without_id(t *testing.T) {
+	assert.Empty(t, RequestIDFromContext(context.Background()))
+}
+
+func Test_ParseLevel_success(t *testing.T) {
+	level, err := ParseLevel("WARN")
+
+	require.NoError(t, err)
+	assert.Equal(t, LevelWarn, level)
+}
+
+func Test_ParseLevel_with_unknown_level(t *testing.T) {
+	_, err := ParseLevel("verbose")
+
+	assert.EqualError(t, err, `unknown log level "verbose"`)
+}
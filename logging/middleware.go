@@ -0,0 +1,71 @@
+package logging
+
+import (
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/Peltoche/avro-gateway/internal/recorder"
+	"github.com/gorilla/mux"
+	uuid "github.com/satori/go.uuid"
+)
+
+// RequestIDHeader is the header carrying the request id, read on the incoming
+// requests and set on the responses and the outgoing requests.
+const RequestIDHeader = "X-Request-ID"
+
+// Only the ids of reasonable size and made of visible ASCII characters are
+// reused, the others are replaced in order to keep the logs readable.
+var requestIDRegexp = regexp.MustCompile(`^[\x21-\x7e]{1,128}$`)
+
+// Middleware put the request id into the request context and write an access
+// log entry for each request.
+//
+// The request id is taken from the X-Request-ID header if any, or generated.
+func Middleware() mux.MiddlewareFunc {
+	return middleware(time.Now, func() string {
+		return uuid.NewV4().String()
+	})
+}
+
+func middleware(now func() time.Time, generateID func() string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := now()
+
+			requestID := r.Header.Get(RequestIDHeader)
+			if !requestIDRegexp.MatchString(requestID) {
+				requestID = generateID()
+			}
+			w.Header().Set(RequestIDHeader, requestID)
+
+			ctx := WithRequestID(r.Context(), requestID)
+
+			route := r.URL.Path
+			if current := mux.CurrentRoute(r); current != nil {
+				if template, err := current.GetPathTemplate(); err == nil {
+					route = template
+				}
+			}
+
+			statusRecorder := recorder.NewStatusRecorder(w)
+			next.ServeHTTP(statusRecorder, r.WithContext(ctx))
+
+			level := LevelInfo
+			if statusRecorder.Status >= 500 {
+				level = LevelError
+			}
+
+			defaultLogger.Log(ctx, level, "request", Fields{
+				"method":      r.Method,
+				"route":       route,
+				"path":        r.URL.Path,
+				"status":      statusRecorder.Status,
+				"bytes":       statusRecorder.Bytes,
+				"duration":    now().Sub(start).Seconds(),
+				"remote_addr": r.RemoteAddr,
+				"user_agent":  r.UserAgent(),
+			})
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package logging
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Middleware_success(t *testing.T) {
+	logger, buf := newTestLogger(LevelInfo)
+	SetDefault(logger)
+	defer SetDefault(NewLogger(os.Stderr, LevelInfo))
+
+	now := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	router := mux.NewRouter()
+	router.Use(middleware(func() time.Time {
+		now = now.Add(250 * time.Millisecond)
+		return now
+	}, func() string {
+		return "generated-id"
+	}))
+
+	var requestID string
+	router.HandleFunc("/acls/{id}", func(w http.ResponseWriter, r *http.Request) {
+		requestID = RequestIDFromContext(r.Context())
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte("not found"))
+	}).Methods("DELETE")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("DELETE", "http://example.com/acls/some-id", nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, "generated-id", requestID)
+	assert.Equal(t, "generated-id", w.Header().Get("X-Request-ID"))
+	assert.JSONEq(t, `{
+		"time": "2019-01-01T00:00:00Z",
+		"level": "info",
+		"msg": "request",
+		"request_id": "generated-id",
+		"method": "DELETE",
+		"route": "/acls/{id}",
+		"path": "/acls/some-id",
+		"status": 404,
+		"bytes": 9,
+		"duration": 0.25,
+		"remote_addr": "10.0.0.1:1234",
+		"user_agent": ""
+	}`, buf.String())
+}
+
+func Test_Middleware_reuse_the_request_id(t *testing.T) {
+	logger, buf := newTestLogger(LevelInfo)
+	SetDefault(logger)
+	defer SetDefault(NewLogger(os.Stderr, LevelInfo))
+
+	router := mux.NewRouter()
+	router.Use(Middleware())
+	router.HandleFunc("/acls", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}).Methods("GET")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com/acls", nil)
+	r.Header.Set("X-Request-ID", "some-request-id")
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, "some-request-id", w.Header().Get("X-Request-ID"))
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "some-request-id", entry["request_id"])
+	assert.Equal(t, "error", entry["level"])
+}
+
+func Test_Middleware_replace_an_invalid_request_id(t *testing.T) {
+	tests := map[string]string{
+		"with spaces":     "some request id",
+		"too long":        strings.Repeat("a", 129),
+		"with a new line": "some-id\nfoo",
+	}
+
+	for name, value := range tests {
+		t.Run(name, func(t *testing.T) {
+			router := mux.NewRouter()
+			router.Use(middleware(time.Now, func() string {
+				return "generated-id"
+			}))
+			router.HandleFunc("/acls", func(w http.ResponseWriter, r *http.Request) {}).Methods("GET")
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "http://example.com/acls", nil)
+			r.Header["X-Request-Id"] = []string{value}
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, "generated-id", w.Header().Get("X-Request-ID"))
+		})
+	}
+}
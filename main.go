@@ -1,38 +1,484 @@
 package main
 
 import (
-	"log"
+	"context"
+	"flag"
+	"io"
 	"net/http"
 	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/Peltoche/avro-gateway/acl"
+	"github.com/Peltoche/avro-gateway/audit"
+	"github.com/Peltoche/avro-gateway/auth"
+	"github.com/Peltoche/avro-gateway/codegen"
+	"github.com/Peltoche/avro-gateway/diff"
+	"github.com/Peltoche/avro-gateway/event"
+	"github.com/Peltoche/avro-gateway/health"
+	"github.com/Peltoche/avro-gateway/lint"
+	"github.com/Peltoche/avro-gateway/logging"
+	"github.com/Peltoche/avro-gateway/metrics"
+	"github.com/Peltoche/avro-gateway/model"
+	"github.com/Peltoche/avro-gateway/namespace"
+	"github.com/Peltoche/avro-gateway/payload"
+	"github.com/Peltoche/avro-gateway/proxy"
+	"github.com/Peltoche/avro-gateway/ratelimit"
 	"github.com/Peltoche/avro-gateway/registry"
+	"github.com/Peltoche/avro-gateway/sample"
 	"github.com/Peltoche/avro-gateway/schema"
+	"github.com/Peltoche/avro-gateway/server"
 	"github.com/Peltoche/avro-gateway/storage"
+	"github.com/Peltoche/avro-gateway/tracing"
+	"github.com/Peltoche/avro-gateway/watcher"
+	"github.com/Peltoche/avro-gateway/webhook"
 	"github.com/gorilla/mux"
 )
 
-const addr = ":8080"
-
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "storage" {
+		os.Exit(runStorageCommand(os.Args[2:]))
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "codegen" {
+		os.Exit(runCodegenCommand(os.Args[2:]))
+	}
+
+	flags := flag.NewFlagSet("avro-gateway", flag.ExitOnError)
+	addr := flags.String("addr", ":8080", "address to listen on")
+	registryURL := flags.String("registry-url", "http://localhost:8081", "url of the Schema Registry")
+	storageFile := flags.String("storage-file", "", "file used to persist the clients (in memory if empty)")
+	watchInterval := flags.Duration("watch-interval", time.Minute, "interval between two registry polls (disabled if 0)")
+	webhooksConfig := flags.String("webhooks-config", "", "JSON file containing the webhooks list")
+	authConfig := flags.String("auth-config", "", "JSON file containing the authentication config (no authentication if empty)")
+	auditStdout := flags.Bool("audit-stdout", false, "write the audit entries to stdout as JSON lines")
+	auditFile := flags.String("audit-file", "", "file where the audit entries are appended as JSON lines")
+	auditStorage := flags.Bool("audit-storage", false, "keep the last audit entries in the storage to query them with GET /audit (each entry is appended to the storage file)")
+	aclAdmins := flags.String("acl-admins", "", "comma separated list of the applications allowed to manage the ACL rules and read the audit log, the webhook deliveries and the rate limit counters (no one if empty)")
+	namespacesConfig := flags.String("namespaces-config", "", "JSON file containing the namespaces served under /ns/{namespace}")
+	rateLimitConfig := flags.String("rate-limit-config", "", "JSON file containing the rate limits (no limit if empty)")
+	lintConfig := flags.String("lint-config", "", "JSON file containing the lint rules applied to the schemas fetched to write (no rule if empty)")
+	eventHistory := flags.Int("event-history", 1000, "number of events kept in memory")
+	tlsCert := flags.String("tls-cert", "", "PEM certificate file used to serve over TLS (plain HTTP if empty)")
+	tlsKey := flags.String("tls-key", "", "PEM private key file of the TLS certificate")
+	tlsClientCA := flags.String("tls-client-ca", "", "PEM file of the CAs used to verify the client certificates (mutual TLS)")
+	tlsClientCertRequired := flags.Bool("tls-client-cert-required", false, "refuse the connections without a valid client certificate")
+	tlsReloadInterval := flags.Duration("tls-reload-interval", time.Minute, "interval between two checks of the TLS files")
+	otlpEndpoint := flags.String("otlp-endpoint", "", "url of the OpenTelemetry collector receiving the traces (no tracing if empty)")
+	traceServiceName := flags.String("trace-service-name", "avro-gateway", "service name reported in the traces")
+	healthCheckInterval := flags.Duration("health-check-interval", 10*time.Second, "interval between two checks of the registries reachability")
+	healthCheckTimeout := flags.Duration("health-check-timeout", 2*time.Second, "maximum duration of a registry reachability check")
+	shutdownTimeout := flags.Duration("shutdown-timeout", 30*time.Second, "maximum duration given to the in-flight requests, then to the background workers, to end at shutdown")
+	proxyNamingStrategy := flags.String("proxy-naming-strategy", "topic", "subject naming strategy of the proxied serializers: topic")
+	proxyApplicationHeader := flags.String("proxy-application-header", "", "header giving the application of the anonymous proxied requests (ignored if empty)")
+	logLevel := flags.String("log-level", "info", "minimum level of the logs: debug, info, warn or error")
+	_ = flags.Parse(os.Args[1:])
+
+	level, err := logging.ParseLevel(*logLevel)
+	if err != nil {
+		fatal(err)
+	}
+	logging.SetDefault(logging.NewLogger(os.Stderr, level))
+
+	// The workers are stopped at shutdown by canceling their context, except
+	// the event consumers which stop once the event bus is closed in order to
+	// handle all the events published by the drained requests.
+	workers := server.NewWorkers()
+	eventWorkers := server.NewWorkers()
+	closers := []io.Closer{}
+
 	router := mux.NewRouter()
 
-	schemaRegistryURL, err := url.Parse("http://localhost:8081")
+	// Request ids and access logs.
+	router.Use(logging.Middleware())
+
+	// Metrics.
+	metricSet := metrics.NewSet()
+	router.Use(metrics.NewHTTPMetrics(metricSet).Middleware())
+
+	metricsHandler := metrics.NewHTTPHandler(metricSet)
+	metricsHandler.RegisterRoutes(router)
+
+	// Tracing.
+	if *otlpEndpoint != "" {
+		exporter := tracing.NewOTLPExporter(strings.TrimSuffix(*otlpEndpoint, "/"), *traceServiceName)
+		workers.Go("trace exporter", func(ctx context.Context) {
+			exporter.Run(ctx, 5*time.Second)
+		})
+
+		router.Use(tracing.Middleware(tracing.NewTracer(exporter)))
+	}
+
+	if *authConfig != "" {
+		config, err := auth.LoadConfig(*authConfig)
+		if err != nil {
+			fatal(err)
+		}
+
+		authenticators, err := config.Authenticators()
+		if err != nil {
+			fatal(err)
+		}
+
+		router.Use(auth.Middleware(authenticators, config.Required))
+	}
+	admins := splitList(*aclAdmins)
+
+	// Events.
+	eventBus := event.NewBus(*eventHistory)
+	loggedEvents, _ := eventBus.Subscribe(100)
+	eventWorkers.Go("event logger", func(ctx context.Context) {
+		logEvents(loggedEvents)
+	})
+
+	eventHandler := event.NewHTTPHandler(eventBus)
+	eventHandler.RegisterRoutes(router)
+
+	// Webhooks.
+	if *webhooksConfig != "" {
+		hooks, err := webhook.LoadConfig(*webhooksConfig)
+		if err != nil {
+			fatal(err)
+		}
+
+		dispatcher, err := webhook.NewDispatcher(hooks)
+		if err != nil {
+			fatal(err)
+		}
+
+		events, _ := eventBus.Subscribe(1000)
+		eventWorkers.Go("webhook dispatcher", func(ctx context.Context) {
+			dispatcher.Run(ctx, events)
+		})
+
+		webhookHandler := webhook.NewHTTPHandler(dispatcher, admins)
+		webhookHandler.RegisterRoutes(router)
+	}
+
+	// Audit.
+	auditSinks := []audit.Sink{metrics.NewAuditSink(metricSet)}
+	if *auditStdout {
+		auditSinks = append(auditSinks, audit.NewJSONSink(os.Stdout))
+	}
+	if *auditFile != "" {
+		fileSink, err := audit.OpenFileSink(*auditFile)
+		if err != nil {
+			fatal(err)
+		}
+		closers = append(closers, fileSink)
+
+		auditSinks = append(auditSinks, fileSink)
+	}
+
+	// Rate limiting.
+	limits := &ratelimit.Config{}
+	if *rateLimitConfig != "" {
+		limits, err = ratelimit.LoadConfig(*rateLimitConfig)
+		if err != nil {
+			fatal(err)
+		}
+	}
+
+	limiter, err := ratelimit.NewLimiter(limits)
+	if err != nil {
+		fatal(err)
+	}
+
+	rateLimitHandler := ratelimit.NewHTTPHandler(limiter, admins)
+	rateLimitHandler.RegisterRoutes(router)
+	metrics.RegisterRateLimiter(metricSet, limiter)
+
+	// Linting.
+	lintRules := &lint.Config{}
+	if *lintConfig != "" {
+		lintRules, err = lint.LoadConfig(*lintConfig)
+		if err != nil {
+			fatal(err)
+		}
+	}
+
+	linter, err := lint.NewLinter(lintRules)
+	if err != nil {
+		fatal(err)
+	}
+
+	// Health.
+	checker := health.NewChecker(*healthCheckTimeout)
+	healthHandler := health.NewHTTPHandler(checker)
+	healthHandler.RegisterRoutes(router)
+
+	// Schema Registry proxy.
+	namingStrategy, err := proxy.ParseNamingStrategy(*proxyNamingStrategy)
+	if err != nil {
+		fatal(err)
+	}
+
+	shared := &sharedServices{
+		identifier:      proxy.NewIdentifier(namingStrategy, *proxyApplicationHeader),
+		publisher:       eventBus,
+		auditSinks:      auditSinks,
+		auditStorage:    *auditStorage,
+		limiter:         limiter,
+		linter:          linter,
+		aclAdmins:       admins,
+		watchInterval:   *watchInterval,
+		checker:         checker,
+		workers:         workers,
+		registryMetrics: metrics.NewRegistryMetrics(metricSet),
+		storageGauges:   metrics.NewStorageGauges(metricSet),
+	}
+
+	// Namespaces, the default one being served at the root.
+	err = mountNamespace(router, &namespace.Namespace{RegistryURL: *registryURL, StorageFile: *storageFile}, shared)
+	if err != nil {
+		fatal(err)
+	}
+
+	if *namespacesConfig != "" {
+		namespaces, err := namespace.LoadConfig(*namespacesConfig, *storageFile)
+		if err != nil {
+			fatal(err)
+		}
+
+		for i := range namespaces {
+			err = mountNamespace(router.PathPrefix("/ns/"+namespaces[i].Name).Subrouter(), &namespaces[i], shared)
+			if err != nil {
+				fatal(err)
+			}
+		}
+	}
+
+	workers.Go("health checker", func(ctx context.Context) {
+		checker.Run(ctx, *healthCheckInterval)
+	})
+
+	// The probes are served without the middlewares: no authentication and
+	// no access log for each orchestrator call.
+	root := mux.NewRouter()
+	healthHandler.RegisterProbes(root)
+	root.PathPrefix("/").Handler(router)
+
+	httpServer := &http.Server{
+		Addr:    *addr,
+		Handler: root,
+	}
+	httpServer.RegisterOnShutdown(func() {
+		// The event streams never end by themselves.
+		eventHandler.Close()
+	})
+
+	listen := httpServer.ListenAndServe
+	if *tlsCert == "" {
+		logging.Info(context.Background(), "start listening", logging.Fields{"addr": *addr, "tls": false})
+	} else {
+		var reloader *server.CertReloader
+		reloader, err = server.NewCertReloader(server.TLSConfig{
+			CertFile:           *tlsCert,
+			KeyFile:            *tlsKey,
+			ClientCAFile:       *tlsClientCA,
+			ClientCertRequired: *tlsClientCertRequired,
+		})
+		if err != nil {
+			fatal(err)
+		}
+		workers.Go("tls reloader", func(ctx context.Context) {
+			reloader.Run(ctx, *tlsReloadInterval)
+		})
+
+		httpServer.TLSConfig = reloader.TLSConfig()
+		listen = func() error {
+			return httpServer.ListenAndServeTLS("", "")
+		}
+
+		logging.Info(context.Background(), "start listening", logging.Fields{"addr": *addr, "tls": true})
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+	err = server.Serve(httpServer, listen, signals, *shutdownTimeout, checker.SetDraining)
 	if err != nil {
-		panic(err)
+		fatal(err)
+	}
+
+	shutdown(eventBus, eventWorkers, workers, append(closers, shared.closers...), *shutdownTimeout)
+}
+
+// shutdown stop the background workers then close the storages and the
+// audit files. It's called once all the requests are drained.
+func shutdown(eventBus *event.Bus, eventWorkers *server.Workers, workers *server.Workers, closers []io.Closer, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	logging.Info(ctx, "stopping the background workers", nil)
+
+	// Let the webhooks deliver the queued events.
+	eventBus.Close()
+	err := eventWorkers.Wait(ctx)
+	if err != nil {
+		logging.Warn(ctx, "failed to deliver all the queued events", logging.Fields{"error": err})
+	}
+
+	for _, group := range []*server.Workers{eventWorkers, workers} {
+		err = group.Stop(ctx)
+		if err != nil {
+			logging.Warn(ctx, "failed to stop all the background workers", logging.Fields{"error": err})
+		}
+	}
+
+	for _, closer := range closers {
+		err = closer.Close()
+		if err != nil {
+			logging.Error(ctx, "failed to close a storage", logging.Fields{"error": err})
+		}
+	}
+
+	logging.Info(ctx, "shutdown completed", nil)
+}
+
+// sharedServices are the services used by all the namespaces.
+type sharedServices struct {
+	publisher     schema.Publisher
+	auditSinks    []audit.Sink
+	auditStorage  bool
+	limiter       *ratelimit.Limiter
+	linter        *lint.Linter
+	aclAdmins     []string
+	watchInterval time.Duration
+	identifier    *proxy.Identifier
+	checker       *health.Checker
+	workers       *server.Workers
+	// closers are the storages to close at shutdown.
+	closers []io.Closer
+
+	registryMetrics *metrics.RegistryMetrics
+	storageGauges   *metrics.StorageGauges
+}
+
+// mountNamespace instantiate the registry client, the storage and the
+// services of a namespace then register their routes into the router. The
+// default namespace has an empty name.
+func mountNamespace(router *mux.Router, ns *namespace.Namespace, shared *sharedServices) error {
+	registryURL, err := url.Parse(ns.RegistryURL)
+	if err != nil {
+		return err
+	}
+
+	trackedRegistry := shared.checker.AddRegistry(ns.Name, ns.RegistryURL, registry.NewClient(registryURL))
+	registry := shared.registryMetrics.Instrument(ns.Name, trackedRegistry)
+	clientStorage, err := openStorage(ns.StorageFile)
+	if err != nil {
+		return err
+	}
+
+	shared.checker.AddStorage(ns.Name, ns.StorageFile)
+
+	shared.storageGauges.Add(ns.Name, clientStorage)
+	if closer, ok := clientStorage.(io.Closer); ok {
+		shared.closers = append(shared.closers, closer)
+	}
+
+	publisher := namespace.NewPublisher(ns.Name, shared.publisher)
+
+	if shared.watchInterval > 0 {
+		registryWatcher := watcher.NewWatcher(registry, clientStorage, publisher, shared.watchInterval)
+		shared.workers.Go("registry watcher "+ns.Name, registryWatcher.Run)
 	}
 
-	// Clients.
-	registry := registry.NewClient(schemaRegistryURL)
-	inMemorystorage := storage.NewInMemory()
+	// Audit.
+	auditSinks := shared.auditSinks
+	if shared.auditStorage {
+		auditSinks = append([]audit.Sink{audit.NewStorageSink(clientStorage)}, auditSinks...)
+
+		auditHandler := audit.NewHTTPHandler(clientStorage, shared.aclAdmins)
+		auditHandler.RegisterRoutes(router)
+	}
+	auditor := namespace.NewAuditor(ns.Name, audit.NewLogger(auditSinks...))
+	limiter := namespace.NewLimiter(ns.Name, shared.limiter)
+
+	// ACL.
+	aclUsecase := acl.NewUsecase(clientStorage)
+	aclHandler := acl.NewHTTPHandler(aclUsecase, shared.aclAdmins)
+	aclHandler.RegisterRoutes(router)
 
 	// Schema.
-	schemaUsecase := schema.NewUsecase(registry, inMemorystorage)
-	schemaHandler := schema.NewHTTPHandler(schemaUsecase)
+	schemaUsecase := schema.NewUsecase(registry, clientStorage, publisher, aclUsecase, shared.linter)
+	schemaHandler := schema.NewHTTPHandler(schemaUsecase, auditor, limiter)
 	schemaHandler.RegisterRoutes(router)
 
-	log.Printf("start listening on %s", addr)
-	err = http.ListenAndServe(addr, router)
-	if err != nil {
-		log.Fatal(err)
+	// Payloads.
+	payloadHandler := payload.NewHTTPHandler(payload.NewUsecase(registry, clientStorage, schemaUsecase, aclUsecase), auditor, limiter)
+	payloadHandler.RegisterRoutes(router)
+
+	// Schema diffs.
+	diffHandler := diff.NewHTTPHandler(diff.NewUsecase(registry))
+	diffHandler.RegisterRoutes(router)
+
+	// Code generation.
+	codegenHandler := codegen.NewHTTPHandler(codegen.NewUsecase(registry))
+	codegenHandler.RegisterRoutes(router)
+
+	// Samples.
+	sampleHandler := sample.NewHTTPHandler(sample.NewUsecase(registry))
+	sampleHandler.RegisterRoutes(router)
+
+	// Schema Registry proxy.
+	pathPrefix := ""
+	if ns.Name != "" {
+		pathPrefix = "/ns/" + ns.Name
+	}
+
+	proxyHandler := proxy.NewHTTPHandler(registryURL, pathPrefix, shared.identifier, schemaUsecase, auditor, limiter)
+	proxyHandler.RegisterRoutes(router)
+
+	return nil
+}
+
+// backend is the set of methods implemented by every storage backend.
+type backend interface {
+	schema.Storage
+	storage.Restorer
+	audit.Appender
+	acl.Storage
+}
+
+// openStorage return a File storage if path is set or an InMemory one.
+func openStorage(path string) (backend, error) {
+	if path == "" {
+		return storage.NewInMemory(), nil
+	}
+
+	return storage.NewFile(path)
+}
+
+// splitList split a comma separated list, ignoring the empty values.
+func splitList(list string) []string {
+	res := []string{}
+	for _, value := range strings.Split(list, ",") {
+		value = strings.TrimSpace(value)
+		if value != "" {
+			res = append(res, value)
+		}
+	}
+
+	return res
+}
+
+// fatal log the error and exit.
+func fatal(err error) {
+	logging.Fatal(context.Background(), "fatal error", logging.Fields{"error": err})
+}
+
+// logEvents print all the events received on the channel.
+func logEvents(events <-chan model.Event) {
+	for evt := range events {
+		logging.Info(context.Background(), evt.Message, logging.Fields{
+			"event":     evt.Type,
+			"namespace": evt.Namespace,
+			"topic":     evt.Topic,
+		})
 	}
 }
@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Peltoche/avro-gateway/model"
+)
+
+// AuditSink count the schema requests from the audit entries.
+type AuditSink struct {
+	requests *Counter
+}
+
+// NewAuditSink instantiate a new AuditSink registered into the set.
+func NewAuditSink(set *Set) *AuditSink {
+	return &AuditSink{
+		requests: set.NewCounter("avro_gateway_schema_requests_total", "Number of schema requests by decision.", "namespace", "topic", "decision", "reason"),
+	}
+}
+
+// Record count the entry. The reason is the kind of the first error, like
+// "forbidden" or "validation error".
+func (t *AuditSink) Record(ctx context.Context, entry *model.AuditEntry) error {
+	reason := ""
+	if len(entry.Reasons) > 0 {
+		reason = strings.SplitN(entry.Reasons[0], ": ", 2)[0]
+	}
+
+	t.requests.Inc(entry.Namespace, entry.Topic, string(entry.Decision), reason)
+
+	return nil
+}
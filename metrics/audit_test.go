@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/Peltoche/avro-gateway/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_AuditSink_Record_success(t *testing.T) {
+	set := NewSet()
+	sink := NewAuditSink(set)
+
+	require.NoError(t, sink.Record(context.Background(), &model.AuditEntry{Topic: "some-topic", Decision: model.AuditAccepted}))
+	require.NoError(t, sink.Record(context.Background(), &model.AuditEntry{
+		Namespace: "staging",
+		Topic:     "some-topic",
+		Decision:  model.AuditRefused,
+		Reasons:   []string{`forbidden: the application "some-app" is not allowed to write on the topic "some-topic"`},
+	}))
+
+	buf := new(bytes.Buffer)
+	_, err := set.WriteTo(buf)
+	require.NoError(t, err)
+
+	assert.Equal(t, `# HELP avro_gateway_schema_requests_total Number of schema requests by decision.
+# TYPE avro_gateway_schema_requests_total counter
+avro_gateway_schema_requests_total{namespace="",topic="some-topic",decision="accepted",reason=""} 1
+avro_gateway_schema_requests_total{namespace="staging",topic="some-topic",decision="refused",reason="forbidden"} 1
+`, buf.String())
+}
@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Peltoche/avro-gateway/internal/recorder"
+	"github.com/Peltoche/avro-gateway/logging"
+	"github.com/gorilla/mux"
+)
+
+// HTTPMetrics count the served requests.
+type HTTPMetrics struct {
+	requests *Counter
+	duration *Histogram
+	// Set the time function as an attribute in order to be able to mock it.
+	now func() time.Time
+}
+
+// NewHTTPMetrics instantiate a new HTTPMetrics registered into the set.
+func NewHTTPMetrics(set *Set) *HTTPMetrics {
+	return &HTTPMetrics{
+		requests: set.NewCounter("avro_gateway_http_requests_total", "Number of HTTP requests served.", "route", "method", "status"),
+		duration: set.NewHistogram("avro_gateway_http_request_duration_seconds", "Duration of the HTTP requests.", DefaultBuckets, "route", "method"),
+		now:      time.Now,
+	}
+}
+
+// Middleware record the route, status and duration of each request.
+func (t *HTTPMetrics) Middleware() mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := t.now()
+			statusRecorder := recorder.NewStatusRecorder(w)
+
+			next.ServeHTTP(statusRecorder, r)
+
+			route := r.URL.Path
+			if current := mux.CurrentRoute(r); current != nil {
+				if template, err := current.GetPathTemplate(); err == nil {
+					route = template
+				}
+			}
+
+			t.requests.Inc(route, r.Method, strconv.Itoa(statusRecorder.Status))
+			t.duration.Observe(t.now().Sub(start).Seconds(), route, r.Method)
+		})
+	}
+}
+
+// HTTPHandler exposing the metrics in the Prometheus text format.
+type HTTPHandler struct {
+	set *Set
+}
+
+// NewHTTPHandler instantiate a new HTTPHandler.
+func NewHTTPHandler(set *Set) *HTTPHandler {
+	return &HTTPHandler{
+		set: set,
+	}
+}
+
+// RegisterRoutes into the givem mux.Router.
+func (t *HTTPHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/metrics", t.Get).Methods("GET")
+}
+
+// Get /metrics
+func (t *HTTPHandler) Get(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.WriteHeader(http.StatusOK)
+	_, err := t.set.WriteTo(w)
+	if err != nil {
+		logging.Warn(r.Context(), "failed to write the response", logging.Fields{"error": err})
+	}
+}
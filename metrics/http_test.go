@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_HTTPMetrics_Middleware_success(t *testing.T) {
+	set := NewSet()
+	httpMetrics := NewHTTPMetrics(set)
+
+	now := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	httpMetrics.now = func() time.Time {
+		now = now.Add(20 * time.Millisecond)
+		return now
+	}
+
+	router := mux.NewRouter()
+	router.Use(httpMetrics.Middleware())
+	router.HandleFunc("/acls/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}).Methods("DELETE")
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("DELETE", "http://example.com/acls/some-id", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("DELETE", "http://example.com/acls/some-other-id", nil))
+
+	buf := new(bytes.Buffer)
+	_, err := set.WriteTo(buf)
+	require.NoError(t, err)
+
+	assert.Contains(t, buf.String(), `avro_gateway_http_requests_total{route="/acls/{id}",method="DELETE",status="204"} 2`)
+	assert.Contains(t, buf.String(), `avro_gateway_http_request_duration_seconds_bucket{route="/acls/{id}",method="DELETE",le="0.025"} 2`)
+	assert.Contains(t, buf.String(), `avro_gateway_http_request_duration_seconds_count{route="/acls/{id}",method="DELETE"} 2`)
+}
+
+func Test_HTTPHandler_Get_success(t *testing.T) {
+	set := NewSet()
+	set.NewCounter("some_counter_total", "Some counter.").Inc()
+
+	handler := NewHTTPHandler(set)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com/metrics", nil)
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	res := w.Result()
+	body, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "text/plain; version=0.0.4", res.Header.Get("Content-Type"))
+	assert.Equal(t, `# HELP some_counter_total Some counter.
+# TYPE some_counter_total counter
+some_counter_total 1
+`, string(body))
+}
@@ -0,0 +1,309 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are the histogram buckets used for the durations, in
+// seconds.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Set is a list of metrics exposed together in the Prometheus text format.
+type Set struct {
+	mutex   *sync.Mutex
+	metrics []metric
+	names   map[string]bool
+}
+
+type metric interface {
+	name() string
+	write(buf *bytes.Buffer)
+}
+
+// Sample is a single value of a metric, used by the Func metrics.
+type Sample struct {
+	LabelValues []string
+	Value       float64
+}
+
+// NewSet instantiate a new empty Set.
+func NewSet() *Set {
+	return &Set{
+		mutex:   new(sync.Mutex),
+		metrics: []metric{},
+		names:   map[string]bool{},
+	}
+}
+
+// NewCounter register a new Counter into the set.
+func (t *Set) NewCounter(name string, help string, labelNames ...string) *Counter {
+	counter := &Counter{
+		header: header{metricName: name, help: help, kind: "counter", labelNames: labelNames},
+		mutex:  new(sync.Mutex),
+		values: map[string]*Sample{},
+	}
+
+	t.register(counter)
+
+	return counter
+}
+
+// NewHistogram register a new Histogram into the set.
+func (t *Set) NewHistogram(name string, help string, buckets []float64, labelNames ...string) *Histogram {
+	histogram := &Histogram{
+		header:  header{metricName: name, help: help, kind: "histogram", labelNames: labelNames},
+		buckets: buckets,
+		mutex:   new(sync.Mutex),
+		values:  map[string]*histogramValue{},
+	}
+
+	t.register(histogram)
+
+	return histogram
+}
+
+// NewFunc register a metric whose samples are collected by calling collect at
+// each scrape. The kind is "counter" or "gauge".
+func (t *Set) NewFunc(name string, help string, kind string, labelNames []string, collect func() []Sample) {
+	t.register(&Func{
+		header:  header{metricName: name, help: help, kind: kind, labelNames: labelNames},
+		collect: collect,
+	})
+}
+
+// WriteTo write all the metrics in the Prometheus text format.
+func (t *Set) WriteTo(w io.Writer) (int64, error) {
+	t.mutex.Lock()
+	metrics := make([]metric, len(t.metrics))
+	copy(metrics, t.metrics)
+	t.mutex.Unlock()
+
+	buf := new(bytes.Buffer)
+	for _, m := range metrics {
+		m.write(buf)
+	}
+
+	return buf.WriteTo(w)
+}
+
+func (t *Set) register(m metric) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.names[m.name()] {
+		panic(fmt.Sprintf("metric %q registered twice", m.name()))
+	}
+
+	t.names[m.name()] = true
+	t.metrics = append(t.metrics, m)
+}
+
+// Counter is a monotonic value per labels.
+type Counter struct {
+	header
+	mutex  *sync.Mutex
+	values map[string]*Sample
+}
+
+// Inc add 1 to the counter with the given label values.
+func (t *Counter) Inc(labelValues ...string) {
+	t.Add(1, labelValues...)
+}
+
+// Add a positive value to the counter with the given label values.
+func (t *Counter) Add(value float64, labelValues ...string) {
+	t.checkLabels(labelValues)
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	key := labelsKey(labelValues)
+	sample, exists := t.values[key]
+	if !exists {
+		sample = &Sample{LabelValues: labelValues}
+		t.values[key] = sample
+	}
+
+	sample.Value += value
+}
+
+func (t *Counter) write(buf *bytes.Buffer) {
+	t.mutex.Lock()
+	samples := make([]Sample, 0, len(t.values))
+	for _, sample := range t.values {
+		samples = append(samples, *sample)
+	}
+	t.mutex.Unlock()
+
+	t.writeHeader(buf)
+	writeSamples(buf, t.metricName, t.labelNames, samples)
+}
+
+// Histogram count the observed values into buckets per labels.
+type Histogram struct {
+	header
+	buckets []float64
+	mutex   *sync.Mutex
+	values  map[string]*histogramValue
+}
+
+type histogramValue struct {
+	labelValues []string
+	counts      []uint64
+	sum         float64
+	count       uint64
+}
+
+// Observe add a value into the histogram with the given label values.
+func (t *Histogram) Observe(value float64, labelValues ...string) {
+	t.checkLabels(labelValues)
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	key := labelsKey(labelValues)
+	hv, exists := t.values[key]
+	if !exists {
+		hv = &histogramValue{labelValues: labelValues, counts: make([]uint64, len(t.buckets))}
+		t.values[key] = hv
+	}
+
+	for i, upperBound := range t.buckets {
+		if value <= upperBound {
+			hv.counts[i]++
+		}
+	}
+
+	hv.sum += value
+	hv.count++
+}
+
+func (t *Histogram) write(buf *bytes.Buffer) {
+	t.writeHeader(buf)
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	values := make([]*histogramValue, 0, len(t.values))
+	for _, hv := range t.values {
+		values = append(values, hv)
+	}
+
+	sort.Slice(values, func(i, j int) bool {
+		return labelsLess(values[i].labelValues, values[j].labelValues)
+	})
+
+	bucketLabels := append(append([]string{}, t.labelNames...), "le")
+	for _, hv := range values {
+		for i, upperBound := range t.buckets {
+			writeSample(buf, t.metricName+"_bucket", bucketLabels, append(append([]string{}, hv.labelValues...), formatFloat(upperBound)), float64(hv.counts[i]))
+		}
+		writeSample(buf, t.metricName+"_bucket", bucketLabels, append(append([]string{}, hv.labelValues...), "+Inf"), float64(hv.count))
+		writeSample(buf, t.metricName+"_sum", t.labelNames, hv.labelValues, hv.sum)
+		writeSample(buf, t.metricName+"_count", t.labelNames, hv.labelValues, float64(hv.count))
+	}
+}
+
+// Func is a metric collected at each scrape.
+type Func struct {
+	header
+	collect func() []Sample
+}
+
+func (t *Func) write(buf *bytes.Buffer) {
+	t.writeHeader(buf)
+	writeSamples(buf, t.metricName, t.labelNames, t.collect())
+}
+
+type header struct {
+	metricName string
+	help       string
+	kind       string
+	labelNames []string
+}
+
+func (t *header) name() string {
+	return t.metricName
+}
+
+func (t *header) writeHeader(buf *bytes.Buffer) {
+	fmt.Fprintf(buf, "# HELP %s %s\n", t.metricName, strings.Replace(t.help, "\n", " ", -1))
+	fmt.Fprintf(buf, "# TYPE %s %s\n", t.metricName, t.kind)
+}
+
+func (t *header) checkLabels(labelValues []string) {
+	if len(labelValues) != len(t.labelNames) {
+		panic(fmt.Sprintf("metric %q: %d label values for %d labels", t.metricName, len(labelValues), len(t.labelNames)))
+	}
+}
+
+func writeSamples(buf *bytes.Buffer, name string, labelNames []string, samples []Sample) {
+	sort.Slice(samples, func(i, j int) bool {
+		return labelsLess(samples[i].LabelValues, samples[j].LabelValues)
+	})
+
+	for _, sample := range samples {
+		writeSample(buf, name, labelNames, sample.LabelValues, sample.Value)
+	}
+}
+
+func writeSample(buf *bytes.Buffer, name string, labelNames []string, labelValues []string, value float64) {
+	buf.WriteString(name)
+
+	if len(labelNames) > 0 {
+		buf.WriteByte('{')
+		for i, labelName := range labelNames {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			fmt.Fprintf(buf, "%s=\"%s\"", labelName, escapeLabelValue(labelValues[i]))
+		}
+		buf.WriteByte('}')
+	}
+
+	buf.WriteByte(' ')
+	buf.WriteString(formatFloat(value))
+	buf.WriteByte('\n')
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(value string) string {
+	return labelValueReplacer.Replace(value)
+}
+
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
+
+func labelsLess(a []string, b []string) bool {
+	for i := range a {
+		if i >= len(b) {
+			return false
+		}
+
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+
+	return len(a) < len(b)
+}
+
+func labelsKey(labelValues []string) string {
+	return strings.Join(labelValues, "\xff")
+}
@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Set_WriteTo_success(t *testing.T) {
+	set := NewSet()
+
+	counter := set.NewCounter("some_counter_total", "Some counter.", "label")
+	histogram := set.NewHistogram("some_duration_seconds", "Some histogram.", []float64{0.1, 1}, "label")
+	set.NewFunc("some_gauge", "Some gauge.", "gauge", nil, func() []Sample {
+		return []Sample{{Value: 42}}
+	})
+
+	counter.Inc("b")
+	counter.Add(2, "a")
+	counter.Inc(`with "quotes"`)
+	histogram.Observe(0.05, "a")
+	histogram.Observe(0.5, "a")
+	histogram.Observe(3, "a")
+
+	buf := new(bytes.Buffer)
+	_, err := set.WriteTo(buf)
+	require.NoError(t, err)
+
+	assert.Equal(t, `# HELP some_counter_total Some counter.
+# TYPE some_counter_total counter
+some_counter_total{label="a"} 2
+some_counter_total{label="b"} 1
+some_counter_total{label="with \"quotes\""} 1
+# HELP some_duration_seconds Some histogram.
+# TYPE some_duration_seconds histogram
+some_duration_seconds_bucket{label="a",le="0.1"} 1
+some_duration_seconds_bucket{label="a",le="1"} 2
+some_duration_seconds_bucket{label="a",le="+Inf"} 3
+some_duration_seconds_sum{label="a"} 3.55
+some_duration_seconds_count{label="a"} 3
+# HELP some_gauge Some gauge.
+# TYPE some_gauge gauge
+some_gauge 42
+`, buf.String())
+}
+
+func Test_Set_register_twice(t *testing.T) {
+	set := NewSet()
+	set.NewCounter("some_counter_total", "Some counter.")
+
+	assert.Panics(t, func() {
+		set.NewCounter("some_counter_total", "Some counter.")
+	})
+}
+
+func Test_Counter_Inc_with_invalid_labels(t *testing.T) {
+	set := NewSet()
+	counter := set.NewCounter("some_counter_total", "Some counter.", "label")
+
+	assert.Panics(t, func() {
+		counter.Inc()
+	})
+}
@@ -0,0 +1,22 @@
+package metrics
+
+import "github.com/Peltoche/avro-gateway/ratelimit"
+
+type limiter interface {
+	Stats() []ratelimit.Stat
+}
+
+// RegisterRateLimiter expose the counters of the rate limiter into the set.
+func RegisterRateLimiter(set *Set, limiter limiter) {
+	set.NewFunc("avro_gateway_rate_limited_requests_total", "Number of rate limited requests by result.", "counter", []string{"application", "result"}, func() []Sample {
+		samples := []Sample{}
+		for _, stat := range limiter.Stats() {
+			samples = append(samples,
+				Sample{LabelValues: []string{stat.Application, "allowed"}, Value: float64(stat.Allowed)},
+				Sample{LabelValues: []string{stat.Application, "throttled"}, Value: float64(stat.Throttled)},
+			)
+		}
+
+		return samples
+	})
+}
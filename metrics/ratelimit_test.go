@@ -0,0 +1,31 @@
+package metrics
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Peltoche/avro-gateway/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RegisterRateLimiter_success(t *testing.T) {
+	set := NewSet()
+
+	limiterMock := new(ratelimit.Mock)
+	RegisterRateLimiter(set, limiterMock)
+
+	limiterMock.On("Stats").Return([]ratelimit.Stat{{Application: "some-app", Allowed: 3, Throttled: 1}}).Once()
+
+	buf := new(bytes.Buffer)
+	_, err := set.WriteTo(buf)
+	require.NoError(t, err)
+
+	assert.Equal(t, `# HELP avro_gateway_rate_limited_requests_total Number of rate limited requests by result.
+# TYPE avro_gateway_rate_limited_requests_total counter
+avro_gateway_rate_limited_requests_total{application="some-app",result="allowed"} 3
+avro_gateway_rate_limited_requests_total{application="some-app",result="throttled"} 1
+`, buf.String())
+
+	limiterMock.AssertExpectations(t)
+}
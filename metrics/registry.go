@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/Peltoche/avro-gateway/internal"
+)
+
+// RegistryMetrics measure the calls made to the Schema Registries.
+type RegistryMetrics struct {
+	duration *Histogram
+	errors   *Counter
+	// Set the time function as an attribute in order to be able to mock it.
+	now func() time.Time
+}
+
+// NewRegistryMetrics instantiate a new RegistryMetrics registered into the
+// set.
+func NewRegistryMetrics(set *Set) *RegistryMetrics {
+	return &RegistryMetrics{
+		duration: set.NewHistogram("avro_gateway_registry_request_duration_seconds", "Duration of the Schema Registry calls.", DefaultBuckets, "namespace", "operation"),
+		errors:   set.NewCounter("avro_gateway_registry_errors_total", "Number of failed Schema Registry calls.", "namespace", "operation", "kind"),
+		now:      time.Now,
+	}
+}
+
+type registryClient interface {
+	FetchSchema(ctx context.Context, subject string, version string) (string, error)
+	FetchSchemaByID(ctx context.Context, id int) (string, error)
+	FetchSchemaID(ctx context.Context, subject string, version string) (int, error)
+	ListVersions(ctx context.Context, subject string, includeDeleted bool) ([]int, error)
+}
+
+// Instrument return a registry client measuring all the calls made to next.
+func (t *RegistryMetrics) Instrument(namespace string, next registryClient) *InstrumentedRegistry {
+	return &InstrumentedRegistry{
+		namespace: namespace,
+		next:      next,
+		metrics:   t,
+	}
+}
+
+// InstrumentedRegistry is a registry client recording the duration and the
+// errors of each call.
+type InstrumentedRegistry struct {
+	namespace string
+	next      registryClient
+	metrics   *RegistryMetrics
+}
+
+// FetchSchema corresponding to the subject/version.
+func (t *InstrumentedRegistry) FetchSchema(ctx context.Context, subject string, version string) (string, error) {
+	start := t.metrics.now()
+	schema, err := t.next.FetchSchema(ctx, subject, version)
+	t.observe("fetch_schema", start, err)
+
+	return schema, err
+}
+
+// FetchSchemaByID return the schema registered with the id.
+func (t *InstrumentedRegistry) FetchSchemaByID(ctx context.Context, id int) (string, error) {
+	start := t.metrics.now()
+	schema, err := t.next.FetchSchemaByID(ctx, id)
+	t.observe("fetch_schema_by_id", start, err)
+
+	return schema, err
+}
+
+// FetchSchemaID return the id of the schema registered under the
+// subject/version.
+func (t *InstrumentedRegistry) FetchSchemaID(ctx context.Context, subject string, version string) (int, error) {
+	start := t.metrics.now()
+	id, err := t.next.FetchSchemaID(ctx, subject, version)
+	t.observe("fetch_schema_id", start, err)
+
+	return id, err
+}
+
+// ListVersions return all the versions registered for the subject.
+func (t *InstrumentedRegistry) ListVersions(ctx context.Context, subject string, includeDeleted bool) ([]int, error) {
+	start := t.metrics.now()
+	versions, err := t.next.ListVersions(ctx, subject, includeDeleted)
+	t.observe("list_versions", start, err)
+
+	return versions, err
+}
+
+func (t *InstrumentedRegistry) observe(operation string, start time.Time, err error) {
+	t.metrics.duration.Observe(t.metrics.now().Sub(start).Seconds(), t.namespace, operation)
+
+	// A missing schema is an expected answer, not a registry failure.
+	if err != nil && !internal.IsKind(internal.NotFound, err) {
+		kind := internal.InternalError
+		if innerError, ok := err.(*internal.Error); ok {
+			kind = innerError.Kind
+		}
+
+		t.metrics.errors.Inc(t.namespace, operation, string(kind))
+	}
+}
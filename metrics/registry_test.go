@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/Peltoche/avro-gateway/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_InstrumentedRegistry_success(t *testing.T) {
+	set := NewSet()
+	registryMetrics := NewRegistryMetrics(set)
+	registryMetrics.now = func() time.Time { return time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC) }
+
+	registryMock := new(registry.Mock)
+	client := registryMetrics.Instrument("staging", registryMock)
+
+	registryMock.On("FetchSchema", "foobar", "1").Return("some-schema", nil).Once()
+	registryMock.On("FetchSchema", "foobar", "2").Return("", internal.NewError(internal.NotFound, "schema foobar/2 not found")).Once()
+	registryMock.On("ListVersions", "foobar", false).Return(nil, internal.NewError(internal.RemoteError, "some-error")).Once()
+
+	schema, err := client.FetchSchema(context.Background(), "foobar", "1")
+	assert.NoError(t, err)
+	assert.Equal(t, "some-schema", schema)
+
+	_, err = client.FetchSchema(context.Background(), "foobar", "2")
+	assert.EqualError(t, err, "not found: schema foobar/2 not found")
+
+	_, err = client.ListVersions(context.Background(), "foobar", false)
+	assert.EqualError(t, err, "remote error: some-error")
+
+	buf := new(bytes.Buffer)
+	_, err = set.WriteTo(buf)
+	require.NoError(t, err)
+
+	assert.Contains(t, buf.String(), `avro_gateway_registry_request_duration_seconds_count{namespace="staging",operation="fetch_schema"} 2`)
+	assert.Contains(t, buf.String(), `avro_gateway_registry_request_duration_seconds_count{namespace="staging",operation="list_versions"} 1`)
+	assert.Contains(t, buf.String(), `# TYPE avro_gateway_registry_errors_total counter
+avro_gateway_registry_errors_total{namespace="staging",operation="list_versions",kind="remote error"} 1
+`)
+
+	registryMock.AssertExpectations(t)
+}
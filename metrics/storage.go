@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/Peltoche/avro-gateway/logging"
+	"github.com/Peltoche/avro-gateway/model"
+)
+
+// StorageGauges expose the number of topics and clients of each namespace
+// storage.
+type StorageGauges struct {
+	mutex    *sync.Mutex
+	storages map[string]clientLister
+}
+
+type clientLister interface {
+	GetAllClients(ctx context.Context) ([]model.Client, error)
+}
+
+// NewStorageGauges instantiate a new StorageGauges registered into the set.
+func NewStorageGauges(set *Set) *StorageGauges {
+	gauges := &StorageGauges{
+		mutex:    new(sync.Mutex),
+		storages: map[string]clientLister{},
+	}
+
+	set.NewFunc("avro_gateway_topics", "Number of topics with at least one client.", "gauge", []string{"namespace"}, gauges.collectTopics)
+	set.NewFunc("avro_gateway_clients", "Number of registered clients by action.", "gauge", []string{"namespace", "action"}, gauges.collectClients)
+
+	return gauges
+}
+
+// Add the storage of a namespace.
+func (t *StorageGauges) Add(namespace string, storage clientLister) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.storages[namespace] = storage
+}
+
+func (t *StorageGauges) collectTopics() []Sample {
+	samples := []Sample{}
+	t.forEachNamespace(func(namespace string, clients []model.Client) {
+		topics := map[string]bool{}
+		for _, client := range clients {
+			topics[client.Topic] = true
+		}
+
+		samples = append(samples, Sample{LabelValues: []string{namespace}, Value: float64(len(topics))})
+	})
+
+	return samples
+}
+
+func (t *StorageGauges) collectClients() []Sample {
+	samples := []Sample{}
+	t.forEachNamespace(func(namespace string, clients []model.Client) {
+		byAction := map[string]int{"read": 0, "write": 0}
+		for _, client := range clients {
+			byAction[client.Action]++
+		}
+
+		for action, count := range byAction {
+			samples = append(samples, Sample{LabelValues: []string{namespace, action}, Value: float64(count)})
+		}
+	})
+
+	return samples
+}
+
+func (t *StorageGauges) forEachNamespace(fn func(namespace string, clients []model.Client)) {
+	t.mutex.Lock()
+	namespaces := make([]string, 0, len(t.storages))
+	for namespace := range t.storages {
+		namespaces = append(namespaces, namespace)
+	}
+	t.mutex.Unlock()
+
+	sort.Strings(namespaces)
+
+	for _, namespace := range namespaces {
+		t.mutex.Lock()
+		storage := t.storages[namespace]
+		t.mutex.Unlock()
+
+		clients, err := storage.GetAllClients(context.Background())
+		if err != nil {
+			logging.Error(context.Background(), "failed to retrieve the clients for the metrics", logging.Fields{"namespace": namespace, "error": err})
+			continue
+		}
+
+		fn(namespace, clients)
+	}
+}
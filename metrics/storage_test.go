@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Peltoche/avro-gateway/model"
+	"github.com/Peltoche/avro-gateway/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_StorageGauges_success(t *testing.T) {
+	set := NewSet()
+	gauges := NewStorageGauges(set)
+
+	storageMock := new(storage.Mock)
+	gauges.Add("", storageMock)
+
+	storageMock.On("GetAllClients").Return([]model.Client{
+		{ID: "1", Topic: "some-topic", Action: "read"},
+		{ID: "2", Topic: "some-topic", Action: "write"},
+		{ID: "3", Topic: "some-other-topic", Action: "read"},
+	}, nil).Twice()
+
+	buf := new(bytes.Buffer)
+	_, err := set.WriteTo(buf)
+	require.NoError(t, err)
+
+	assert.Equal(t, `# HELP avro_gateway_topics Number of topics with at least one client.
+# TYPE avro_gateway_topics gauge
+avro_gateway_topics{namespace=""} 2
+# HELP avro_gateway_clients Number of registered clients by action.
+# TYPE avro_gateway_clients gauge
+avro_gateway_clients{namespace="",action="read"} 2
+avro_gateway_clients{namespace="",action="write"} 1
+`, buf.String())
+
+	storageMock.AssertExpectations(t)
+}
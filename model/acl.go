@@ -0,0 +1,59 @@
+package model
+
+import "path"
+
+// ACLEffect is the effect of an ACLRule matching a request.
+type ACLEffect string
+
+var (
+	// ACLAllow grant the access.
+	ACLAllow ACLEffect = "allow"
+	// ACLDeny refuse the access, whatever the other rules.
+	ACLDeny ACLEffect = "deny"
+)
+
+// ACLAnyApplication is the Application value matching all the applications.
+const ACLAnyApplication = "*"
+
+// ACLRule allow or deny some applications to do some actions on the topics
+// matching a pattern.
+type ACLRule struct {
+	ID     string    `json:"id"`
+	Effect ACLEffect `json:"effect"`
+	// Application is the application name or "*" for all the applications.
+	Application string `json:"application"`
+	// Topic is a glob pattern, like "orders.*".
+	Topic string `json:"topic"`
+	// Actions covered by the rule, all the actions if empty.
+	Actions []string `json:"actions,omitempty"`
+}
+
+// Governs return true if the rule apply on the topic and action, whatever
+// the application.
+func (t *ACLRule) Governs(topic string, action string) bool {
+	matched, err := path.Match(t.Topic, topic)
+	if err != nil || !matched {
+		return false
+	}
+
+	if len(t.Actions) == 0 {
+		return true
+	}
+
+	for _, ruleAction := range t.Actions {
+		if ruleAction == action {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Matches return true if the rule apply on the application, topic and action.
+func (t *ACLRule) Matches(application string, topic string, action string) bool {
+	if t.Application != ACLAnyApplication && t.Application != application {
+		return false
+	}
+
+	return t.Governs(topic, action)
+}
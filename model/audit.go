@@ -0,0 +1,63 @@
+package model
+
+import "time"
+
+// AuditDecision is the outcome of an audited request.
+type AuditDecision string
+
+var (
+	// AuditAccepted is used for the requests served successfully.
+	AuditAccepted AuditDecision = "accepted"
+	// AuditRefused is used for the requests refused because of the request
+	// content or the topic state.
+	AuditRefused AuditDecision = "refused"
+	// AuditError is used for the requests which failed because of an
+	// unexpected error.
+	AuditError AuditDecision = "error"
+)
+
+// AuditEntry records a single request made to the gateway and its outcome.
+// The Namespace is empty for the default namespace.
+type AuditEntry struct {
+	ID          string        `json:"id"`
+	Time        time.Time     `json:"time"`
+	Caller      string        `json:"caller,omitempty"`
+	RemoteAddr  string        `json:"remote_addr,omitempty"`
+	Namespace   string        `json:"namespace,omitempty"`
+	Topic       string        `json:"topic"`
+	Application string        `json:"application"`
+	Action      string        `json:"action"`
+	Subject     string        `json:"subject"`
+	Version     string        `json:"version"`
+	Decision    AuditDecision `json:"decision"`
+	Reasons     []string      `json:"reasons,omitempty"`
+}
+
+// AuditFilter select the audit entries to retrieve. The zero values match
+// everything.
+type AuditFilter struct {
+	Topic string
+	// From is the inclusive lower time bound.
+	From time.Time
+	// To is the exclusive upper time bound.
+	To time.Time
+	// Limit is the maximum number of entries returned, the most recent ones.
+	Limit int
+}
+
+// Match return true if the entry match the filter, ignoring the limit.
+func (t *AuditFilter) Match(entry *AuditEntry) bool {
+	if t.Topic != "" && entry.Topic != t.Topic {
+		return false
+	}
+
+	if !t.From.IsZero() && entry.Time.Before(t.From) {
+		return false
+	}
+
+	if !t.To.IsZero() && !entry.Time.Before(t.To) {
+		return false
+	}
+
+	return true
+}
@@ -2,10 +2,13 @@ package model
 
 // Client data representing an unique consumer or producer.
 type Client struct {
-	ID          string
-	Topic       string
-	Application string
-	Action      string
-	Subject     string
-	Version     string
+	ID          string `json:"id"`
+	Topic       string `json:"topic"`
+	Application string `json:"application"`
+	Action      string `json:"action"`
+	Subject     string `json:"subject"`
+	Version     string `json:"version"`
+	// Fingerprint is the SHA-256 fingerprint of the schema canonical form. It's
+	// empty if the schema has not been fetched by the gateway.
+	Fingerprint string `json:"fingerprint,omitempty"`
 }
@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+// EventType is the kind of change described by an Event.
+type EventType string
+
+var (
+	// EventClientRegistered is emitted when an application start to use a
+	// topic.
+	EventClientRegistered EventType = "client_registered"
+	// EventClientUpgraded is emitted when an application already using a topic
+	// switch to an other schema version.
+	EventClientUpgraded EventType = "client_upgraded"
+	// EventRequestRefused is emitted when a schema request is refused because
+	// it's incompatible with the other clients of the topic.
+	EventRequestRefused EventType = "request_refused"
+	// EventVersionCreated is emitted when a new version is registered for a
+	// subject used on a topic.
+	EventVersionCreated EventType = "version_created"
+	// EventVersionSoftDeleted is emitted when a version pinned by a client has
+	// been soft-deleted from the Schema Registry.
+	EventVersionSoftDeleted EventType = "version_soft_deleted"
+	// EventVersionHardDeleted is emitted when a version pinned by a client has
+	// been permanently deleted from the Schema Registry.
+	EventVersionHardDeleted EventType = "version_hard_deleted"
+	// EventLintWarning is emitted when a schema fetched to write on a topic
+	// break some lint rules with the warning severity.
+	EventLintWarning EventType = "lint_warning"
+)
+
+// Event describe a change which happened on a topic. The Namespace is empty
+// for the default namespace.
+type Event struct {
+	ID          uint64    `json:"id"`
+	Type        EventType `json:"type"`
+	Time        time.Time `json:"time"`
+	Namespace   string    `json:"namespace,omitempty"`
+	Topic       string    `json:"topic"`
+	Application string    `json:"application,omitempty"`
+	Action      string    `json:"action,omitempty"`
+	Subject     string    `json:"subject,omitempty"`
+	Version     string    `json:"version,omitempty"`
+	Message     string    `json:"message,omitempty"`
+}
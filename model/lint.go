@@ -0,0 +1,28 @@
+package model
+
+import "fmt"
+
+// LintSeverity is the effect of a LintViolation.
+type LintSeverity string
+
+var (
+	// LintWarning is reported but the schema is served.
+	LintWarning LintSeverity = "warning"
+	// LintError refuse to serve the schema.
+	LintError LintSeverity = "error"
+)
+
+// LintViolation is a schema element breaking a lint rule.
+type LintViolation struct {
+	// Rule is the name of the broken check, like "require_doc".
+	Rule     string       `json:"rule"`
+	Severity LintSeverity `json:"severity"`
+	// Path locate the element, like "$.address.street".
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// String return a human readable description of the violation.
+func (t LintViolation) String() string {
+	return fmt.Sprintf("%s: %s (%s)", t.Path, t.Message, t.Rule)
+}
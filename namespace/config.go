@@ -0,0 +1,83 @@
+package namespace
+
+import (
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/Peltoche/avro-gateway/internal"
+)
+
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Namespace is an isolated set of topics backed by its own Schema Registry
+// and storage.
+type Namespace struct {
+	Name        string `json:"name"`
+	RegistryURL string `json:"registry_url"`
+	// StorageFile is the file used to persist the namespace clients. They
+	// are kept in memory if empty.
+	StorageFile string `json:"storage_file"`
+}
+
+// LoadConfig read the namespaces list from a JSON file. The
+// defaultStorageFile is the storage file of the default namespace, it can't be
+// used by any other namespace.
+func LoadConfig(path string, defaultStorageFile string) ([]Namespace, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, internal.Errorf(internal.InternalError, "failed to open the namespaces config: %s", err)
+	}
+	defer file.Close()
+
+	namespaces := []Namespace{}
+	err = json.NewDecoder(file).Decode(&namespaces)
+	if err != nil {
+		return nil, internal.Errorf(internal.ValidationError, "failed to decode the namespaces config: %s", err)
+	}
+
+	err = validateNamespaces(namespaces, defaultStorageFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return namespaces, nil
+}
+
+func validateNamespaces(namespaces []Namespace, defaultStorageFile string) error {
+	names := map[string]bool{}
+	files := map[string]bool{}
+	if defaultStorageFile != "" {
+		files[filepath.Clean(defaultStorageFile)] = true
+	}
+
+	for i, namespace := range namespaces {
+		if namespace.Name == "" {
+			return internal.Errorf(internal.ValidationError, `namespace %d: missing field "name"`, i)
+		}
+		if !namePattern.MatchString(namespace.Name) {
+			return internal.Errorf(internal.ValidationError, `namespace %d: invalid input for field "name"`, i)
+		}
+		if names[namespace.Name] {
+			return internal.Errorf(internal.ValidationError, "namespace %q: duplicated name", namespace.Name)
+		}
+		names[namespace.Name] = true
+
+		registryURL, err := url.Parse(namespace.RegistryURL)
+		if err != nil || (registryURL.Scheme != "http" && registryURL.Scheme != "https") {
+			return internal.Errorf(internal.ValidationError, `namespace %q: invalid input for field "registry_url"`, namespace.Name)
+		}
+
+		if namespace.StorageFile != "" {
+			storageFile := filepath.Clean(namespace.StorageFile)
+			if files[storageFile] {
+				return internal.Errorf(internal.ValidationError, "namespace %q: storage file shared with an other namespace", namespace.Name)
+			}
+			files[storageFile] = true
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package namespace
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_LoadConfig_success(t *testing.T) {
+	file, err := ioutil.TempFile("", "namespaces")
+	require.NoError(t, err)
+	defer os.Remove(file.Name())
+
+	_, err = file.WriteString(`[
+		{"name": "staging", "registry_url": "http://registry.staging:8081", "storage_file": "/var/lib/avro-gateway/staging.dump"},
+		{"name": "prod", "registry_url": "https://registry.prod:8081"}
+	]`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	namespaces, err := LoadConfig(file.Name(), "/var/lib/avro-gateway/default.dump")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Namespace{
+		{Name: "staging", RegistryURL: "http://registry.staging:8081", StorageFile: "/var/lib/avro-gateway/staging.dump"},
+		{Name: "prod", RegistryURL: "https://registry.prod:8081"},
+	}, namespaces)
+}
+
+func Test_LoadConfig_with_a_missing_file(t *testing.T) {
+	namespaces, err := LoadConfig("/some/unknown/path", "")
+
+	assert.Nil(t, namespaces)
+	assert.EqualError(t, err, "internal error: failed to open the namespaces config: open /some/unknown/path: no such file or directory")
+}
+
+func Test_validateNamespaces_with_invalid_inputs(t *testing.T) {
+	tests := []struct {
+		Name       string
+		Namespaces []Namespace
+		Error      string
+	}{
+		{
+			Name:       "missing name",
+			Namespaces: []Namespace{{RegistryURL: "http://localhost:8081"}},
+			Error:      `validation error: namespace 0: missing field "name"`,
+		},
+		{
+			Name:       "invalid name",
+			Namespaces: []Namespace{{Name: "Some/Name", RegistryURL: "http://localhost:8081"}},
+			Error:      `validation error: namespace 0: invalid input for field "name"`,
+		},
+		{
+			Name: "duplicated name",
+			Namespaces: []Namespace{
+				{Name: "dev", RegistryURL: "http://localhost:8081"},
+				{Name: "dev", RegistryURL: "http://localhost:8082"},
+			},
+			Error: `validation error: namespace "dev": duplicated name`,
+		},
+		{
+			Name:       "invalid registry url",
+			Namespaces: []Namespace{{Name: "dev", RegistryURL: "localhost:8081"}},
+			Error:      `validation error: namespace "dev": invalid input for field "registry_url"`,
+		},
+		{
+			Name: "shared storage file",
+			Namespaces: []Namespace{
+				{Name: "dev", RegistryURL: "http://localhost:8081", StorageFile: "some-file"},
+				{Name: "staging", RegistryURL: "http://localhost:8082", StorageFile: "some-file"},
+			},
+			Error: `validation error: namespace "staging": storage file shared with an other namespace`,
+		},
+		{
+			Name: "storage file shared with the default namespace",
+			Namespaces: []Namespace{
+				{Name: "dev", RegistryURL: "http://localhost:8081", StorageFile: "./default-file"},
+			},
+			Error: `validation error: namespace "dev": storage file shared with an other namespace`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			err := validateNamespaces(test.Namespaces, "default-file")
+
+			assert.EqualError(t, err, test.Error)
+		})
+	}
+}
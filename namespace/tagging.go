@@ -0,0 +1,83 @@
+package namespace
+
+import (
+	"context"
+	"time"
+
+	"github.com/Peltoche/avro-gateway/model"
+)
+
+// Publisher set the namespace of the events before publishing them.
+type Publisher struct {
+	namespace string
+	next      publisher
+}
+
+type publisher interface {
+	Publish(ctx context.Context, evt model.Event)
+}
+
+// NewPublisher instantiate a new Publisher.
+func NewPublisher(namespace string, next publisher) *Publisher {
+	return &Publisher{
+		namespace: namespace,
+		next:      next,
+	}
+}
+
+// Publish the event into the namespace.
+func (t *Publisher) Publish(ctx context.Context, evt model.Event) {
+	evt.Namespace = t.namespace
+
+	t.next.Publish(ctx, evt)
+}
+
+// Auditor set the namespace of the audit entries before recording them.
+type Auditor struct {
+	namespace string
+	next      auditor
+}
+
+type auditor interface {
+	Record(ctx context.Context, entry model.AuditEntry)
+}
+
+// NewAuditor instantiate a new Auditor.
+func NewAuditor(namespace string, next auditor) *Auditor {
+	return &Auditor{
+		namespace: namespace,
+		next:      next,
+	}
+}
+
+// Record the entry into the namespace.
+func (t *Auditor) Record(ctx context.Context, entry model.AuditEntry) {
+	entry.Namespace = t.namespace
+
+	t.next.Record(ctx, entry)
+}
+
+// Limiter rate limit the applications of the namespace with their own
+// buckets, separated from the applications of the same name in the other
+// namespaces.
+type Limiter struct {
+	namespace string
+	next      limiter
+}
+
+type limiter interface {
+	AllowNamespace(namespace string, application string, remoteAddr string) (time.Duration, bool)
+}
+
+// NewLimiter instantiate a new Limiter.
+func NewLimiter(namespace string, next limiter) *Limiter {
+	return &Limiter{
+		namespace: namespace,
+		next:      next,
+	}
+}
+
+// Allow consume a token for the application of the namespace.
+func (t *Limiter) Allow(application string, remoteAddr string) (time.Duration, bool) {
+	return t.next.AllowNamespace(t.namespace, application, remoteAddr)
+}
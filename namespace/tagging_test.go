@@ -0,0 +1,51 @@
+package namespace
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Peltoche/avro-gateway/audit"
+	"github.com/Peltoche/avro-gateway/event"
+	"github.com/Peltoche/avro-gateway/model"
+	"github.com/Peltoche/avro-gateway/ratelimit"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Publisher_Publish_success(t *testing.T) {
+	publisherMock := new(event.Mock)
+
+	publisher := NewPublisher("staging", publisherMock)
+
+	publisherMock.On("Publish", model.Event{Type: model.EventVersionCreated, Namespace: "staging", Topic: "some-topic"}).Once()
+
+	publisher.Publish(context.Background(), model.Event{Type: model.EventVersionCreated, Topic: "some-topic"})
+
+	publisherMock.AssertExpectations(t)
+}
+
+func Test_Auditor_Record_success(t *testing.T) {
+	auditMock := new(audit.Mock)
+
+	auditor := NewAuditor("staging", auditMock)
+
+	auditMock.On("Record", model.AuditEntry{Namespace: "staging", Topic: "some-topic", Decision: model.AuditAccepted}).Once()
+
+	auditor.Record(context.Background(), model.AuditEntry{Topic: "some-topic", Decision: model.AuditAccepted})
+
+	auditMock.AssertExpectations(t)
+}
+
+func Test_Limiter_Allow_success(t *testing.T) {
+	limiterMock := new(ratelimit.Mock)
+
+	limiter := NewLimiter("staging", limiterMock)
+
+	limiterMock.On("AllowNamespace", "staging", "some-app", "192.0.2.1:1234").Return(time.Second, false).Once()
+
+	retryAfter, allowed := limiter.Allow("some-app", "192.0.2.1:1234")
+
+	assert.False(t, allowed)
+	assert.Equal(t, time.Second, retryAfter)
+	limiterMock.AssertExpectations(t)
+}
@@ -0,0 +1,282 @@
+package payload
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Peltoche/avro-gateway/audit"
+	"github.com/Peltoche/avro-gateway/auth"
+	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/Peltoche/avro-gateway/model"
+	"github.com/gorilla/mux"
+)
+
+// maxBodySize is the maximum size of a request body.
+const maxBodySize = 1 << 20
+
+// HTTPHandler handling all the payload related HTTP requests.
+type HTTPHandler struct {
+	usecase usecase
+	auditor auditor
+	limiter limiter
+}
+
+type usecase interface {
+	Validate(ctx context.Context, cmd *ValidateCmd) (*Validation, error)
+	Encode(ctx context.Context, cmd *EncodeCmd) (*Encoded, error)
+	Decode(ctx context.Context, cmd *DecodeCmd) (*Decoded, error)
+}
+
+type auditor interface {
+	Record(ctx context.Context, entry model.AuditEntry)
+}
+
+type limiter interface {
+	Allow(application string, remoteAddr string) (time.Duration, bool)
+}
+
+// NewHTTPHandler instantiate a new HTTPHandler.
+func NewHTTPHandler(usecase usecase, auditor auditor, limiter limiter) *HTTPHandler {
+	return &HTTPHandler{
+		usecase: usecase,
+		auditor: auditor,
+		limiter: limiter,
+	}
+}
+
+// RegisterRoutes into the givem mux.Router.
+func (t *HTTPHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/validate", t.Validate).Methods("POST")
+	router.HandleFunc("/encode", t.Encode).Methods("POST")
+	router.HandleFunc("/decode", t.Decode).Methods("POST")
+}
+
+// Validate /validate
+//
+// The payload is the JSON value for the JSON encoding and a base64 string for
+// the binary encodings. An invalid payload is not an error: the response
+// contains the location of the invalid value.
+func (t *HTTPHandler) Validate(w http.ResponseWriter, r *http.Request) {
+	type request struct {
+		Topic    string          `json:"topic"`
+		Subject  string          `json:"subject"`
+		Version  string          `json:"version"`
+		SchemaID int             `json:"schema_id"`
+		Encoding string          `json:"encoding"`
+		Payload  json.RawMessage `json:"payload"`
+	}
+
+	var req request
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req)
+	if err != nil {
+		internal.WriteErrorIntoResponse(w, internal.NewError(internal.InvalidJSONBody, err.Error()))
+		return
+	}
+
+	payload, err := decodePayload(Encoding(req.Encoding), req.Payload)
+	if err != nil {
+		internal.WriteErrorIntoResponse(w, err)
+		return
+	}
+
+	validation, err := t.usecase.Validate(r.Context(), &ValidateCmd{
+		Topic:    req.Topic,
+		Subject:  req.Subject,
+		Version:  req.Version,
+		SchemaID: req.SchemaID,
+		Encoding: Encoding(req.Encoding),
+		Payload:  payload,
+	})
+	if err != nil {
+		internal.WriteErrorIntoResponse(w, err)
+		return
+	}
+
+	internal.WriteJSON(r.Context(), w, http.StatusOK, validation)
+}
+
+// Encode /encode
+//
+// The payload is the JSON value and the response payload the base64 message
+// with the Confluent wire format.
+func (t *HTTPHandler) Encode(w http.ResponseWriter, r *http.Request) {
+	type request struct {
+		Topic       string          `json:"topic"`
+		Application string          `json:"application"`
+		Subject     string          `json:"subject"`
+		Version     string          `json:"version"`
+		Payload     json.RawMessage `json:"payload"`
+	}
+
+	var req request
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req)
+	if err != nil {
+		internal.WriteErrorIntoResponse(w, internal.NewError(internal.InvalidJSONBody, err.Error()))
+		return
+	}
+
+	application, err := auth.CallerApplication(r.Context(), req.Application)
+	if err != nil {
+		internal.WriteErrorIntoResponse(w, err)
+		return
+	}
+
+	payload, err := decodePayload(JSONEncoding, req.Payload)
+	if err != nil {
+		internal.WriteErrorIntoResponse(w, err)
+		return
+	}
+
+	if req.Version != "" {
+		record, allowed := t.register(w, r, model.AuditEntry{
+			Topic:       req.Topic,
+			Application: application,
+			Action:      "write",
+			Subject:     req.Subject,
+			Version:     req.Version,
+		})
+		if !allowed {
+			return
+		}
+		defer func() { record(err) }()
+	}
+
+	encoded, err := t.usecase.Encode(r.Context(), &EncodeCmd{
+		Topic:       req.Topic,
+		Application: application,
+		Subject:     req.Subject,
+		Version:     req.Version,
+		Payload:     payload,
+	})
+	if err != nil {
+		internal.WriteErrorIntoResponse(w, err)
+		return
+	}
+
+	internal.WriteJSON(r.Context(), w, http.StatusOK, encoded)
+}
+
+// Decode /decode
+//
+// The payload is the base64 message with the Confluent wire format and the
+// response payload the JSON value.
+func (t *HTTPHandler) Decode(w http.ResponseWriter, r *http.Request) {
+	type request struct {
+		Topic       string          `json:"topic"`
+		Application string          `json:"application"`
+		Subject     string          `json:"subject"`
+		Version     string          `json:"version"`
+		Payload     json.RawMessage `json:"payload"`
+	}
+
+	var req request
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req)
+	if err != nil {
+		internal.WriteErrorIntoResponse(w, internal.NewError(internal.InvalidJSONBody, err.Error()))
+		return
+	}
+
+	application, err := auth.CallerApplication(r.Context(), req.Application)
+	if err != nil {
+		internal.WriteErrorIntoResponse(w, err)
+		return
+	}
+
+	payload, err := decodePayload(ConfluentEncoding, req.Payload)
+	if err != nil {
+		internal.WriteErrorIntoResponse(w, err)
+		return
+	}
+
+	if req.Version != "" {
+		record, allowed := t.register(w, r, model.AuditEntry{
+			Topic:       req.Topic,
+			Application: application,
+			Action:      "read",
+			Subject:     req.Subject,
+			Version:     req.Version,
+		})
+		if !allowed {
+			return
+		}
+		defer func() { record(err) }()
+	}
+
+	decoded, err := t.usecase.Decode(r.Context(), &DecodeCmd{
+		Topic:       req.Topic,
+		Application: application,
+		Subject:     req.Subject,
+		Version:     req.Version,
+		Payload:     payload,
+	})
+	if err != nil {
+		internal.WriteErrorIntoResponse(w, err)
+		return
+	}
+
+	internal.WriteJSON(r.Context(), w, http.StatusOK, decoded)
+}
+
+// register handle a request registering the application, like a POST /schema
+// one: it consume a rate limit token and return the function recording the
+// outcome into the audit log. A throttled request is refused and recorded.
+//
+// The requests without any topic or application are refused by the usecase
+// without any registration, so they don't consume any token.
+func (t *HTTPHandler) register(w http.ResponseWriter, r *http.Request, entry model.AuditEntry) (func(err error), bool) {
+	entry.RemoteAddr = r.RemoteAddr
+	if principal := auth.PrincipalFromContext(r.Context()); principal != nil {
+		entry.Caller = principal.String()
+	}
+
+	record := func(err error) {
+		entry.Decision, entry.Reasons = audit.DecisionOf(err)
+		t.auditor.Record(r.Context(), entry)
+	}
+
+	if entry.Topic == "" || entry.Application == "" {
+		return record, true
+	}
+
+	retryAfter, allowed := t.limiter.Allow(entry.Application, r.RemoteAddr)
+	if !allowed {
+		err := internal.Errorf(internal.TooManyRequests, "rate limit exceeded for the application %q", entry.Application)
+		record(err)
+
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		internal.WriteErrorIntoResponse(w, err)
+		return nil, false
+	}
+
+	return record, true
+}
+
+// decodePayload return the payload bytes: the JSON text for the JSON encoding
+// or the decoded base64 string for the binary encodings.
+func decodePayload(encoding Encoding, raw json.RawMessage) ([]byte, error) {
+	if len(raw) == 0 {
+		return nil, internal.NewError(internal.ValidationError, `missing field "payload"`)
+	}
+
+	if encoding == JSONEncoding {
+		return raw, nil
+	}
+
+	var encoded string
+	err := json.Unmarshal(raw, &encoded)
+	if err != nil {
+		return nil, internal.NewError(internal.ValidationError, `invalid input for field "payload": expected a base64 string`)
+	}
+
+	payload, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, internal.NewError(internal.ValidationError, `invalid input for field "payload": expected a base64 string`)
+	}
+
+	return payload, nil
+}
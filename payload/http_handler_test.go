@@ -0,0 +1,315 @@
+package payload
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Peltoche/avro-gateway/audit"
+	"github.com/Peltoche/avro-gateway/auth"
+	"github.com/Peltoche/avro-gateway/avro"
+	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/Peltoche/avro-gateway/model"
+	"github.com/Peltoche/avro-gateway/ratelimit"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_HTTPHandler_Validate_binary(t *testing.T) {
+	usecaseMock := new(UsecaseMock)
+	handler := NewHTTPHandler(usecaseMock, new(audit.Mock), new(ratelimit.Mock))
+
+	usecaseMock.On("Validate", &ValidateCmd{
+		Subject:  "users-value",
+		Version:  "3",
+		Encoding: BinaryEncoding,
+		Payload:  []byte{0x01, 0x02},
+	}).Return(&Validation{Valid: false, Path: "$.name", Error: "invalid negative length -1"}, nil).Once()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "http://example.com/validate", strings.NewReader(`{
+		"subject": "users-value",
+		"version": "3",
+		"encoding": "binary",
+		"payload": "AQI="
+	}`))
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"valid": false, "path": "$.name", "error": "invalid negative length -1"}`, w.Body.String())
+	usecaseMock.AssertExpectations(t)
+}
+
+func Test_HTTPHandler_Validate_json(t *testing.T) {
+	usecaseMock := new(UsecaseMock)
+	handler := NewHTTPHandler(usecaseMock, new(audit.Mock), new(ratelimit.Mock))
+
+	usecaseMock.On("Validate", &ValidateCmd{
+		SchemaID: 7,
+		Encoding: JSONEncoding,
+		Payload:  []byte(`{"name": "Alice", "age": 42}`),
+	}).Return(&Validation{Valid: true}, nil).Once()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "http://example.com/validate", strings.NewReader(`{
+		"schema_id": 7,
+		"encoding": "json",
+		"payload": {"name": "Alice", "age": 42}
+	}`))
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"valid": true}`, w.Body.String())
+	usecaseMock.AssertExpectations(t)
+}
+
+func Test_HTTPHandler_Validate_with_an_invalid_base64(t *testing.T) {
+	handler := NewHTTPHandler(new(UsecaseMock), new(audit.Mock), new(ratelimit.Mock))
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "http://example.com/validate", strings.NewReader(`{
+		"schema_id": 7,
+		"encoding": "confluent",
+		"payload": "not base64!"
+	}`))
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.JSONEq(t, `{"kind": "validation error", "message": "invalid input for field \"payload\": expected a base64 string"}`, w.Body.String())
+}
+
+func Test_HTTPHandler_Validate_with_a_missing_payload(t *testing.T) {
+	handler := NewHTTPHandler(new(UsecaseMock), new(audit.Mock), new(ratelimit.Mock))
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "http://example.com/validate", strings.NewReader(`{"schema_id": 7, "encoding": "json"}`))
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.JSONEq(t, `{"kind": "validation error", "message": "missing field \"payload\""}`, w.Body.String())
+}
+
+func Test_HTTPHandler_Validate_with_an_invalid_body(t *testing.T) {
+	handler := NewHTTPHandler(new(UsecaseMock), new(audit.Mock), new(ratelimit.Mock))
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "http://example.com/validate", strings.NewReader(`{`))
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), `"kind":"invalid json body"`)
+}
+
+func Test_HTTPHandler_Validate_with_an_usecase_error(t *testing.T) {
+	usecaseMock := new(UsecaseMock)
+	handler := NewHTTPHandler(usecaseMock, new(audit.Mock), new(ratelimit.Mock))
+
+	usecaseMock.On("Validate", &ValidateCmd{
+		SchemaID: 7,
+		Encoding: JSONEncoding,
+		Payload:  []byte(`1`),
+	}).Return(nil, internal.NewError(internal.NotFound, "failed to fetch the schema: schema 7 not found")).Once()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "http://example.com/validate", strings.NewReader(`{"schema_id": 7, "encoding": "json", "payload": 1}`))
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	usecaseMock.AssertExpectations(t)
+}
+
+func Test_HTTPHandler_Encode_success(t *testing.T) {
+	usecaseMock := new(UsecaseMock)
+	handler := NewHTTPHandler(usecaseMock, new(audit.Mock), new(ratelimit.Mock))
+
+	usecaseMock.On("Encode", &EncodeCmd{
+		Topic:       "users",
+		Application: "billing",
+		Payload:     []byte(`{"name": "Alice", "age": 42}`),
+	}).Return(&Encoded{
+		SchemaID:     7,
+		Subject:      "users-value",
+		Version:      "2",
+		Fingerprints: &avro.Fingerprints{CRC64Avro: "some-crc", MD5: "some-md5", SHA256: "some-sha"},
+		Payload:      []byte{0x00, 0x01, 0x02},
+	}, nil).Once()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "http://example.com/encode", strings.NewReader(`{
+		"topic": "users",
+		"application": "billing",
+		"payload": {"name": "Alice", "age": 42}
+	}`))
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"schema_id": 7,
+		"subject": "users-value",
+		"version": "2",
+		"fingerprints": {"crc64_avro": "some-crc", "md5": "some-md5", "sha256": "some-sha"},
+		"payload": "AAEC"
+	}`, w.Body.String())
+	usecaseMock.AssertExpectations(t)
+}
+
+func Test_HTTPHandler_Encode_with_the_authenticated_application(t *testing.T) {
+	usecaseMock := new(UsecaseMock)
+	auditMock := new(audit.Mock)
+	limiterMock := new(ratelimit.Mock)
+	handler := NewHTTPHandler(usecaseMock, auditMock, limiterMock)
+
+	limiterMock.On("Allow", "billing", "192.0.2.1:1234").Return(time.Duration(0), true).Once()
+	usecaseMock.On("Encode", &EncodeCmd{
+		Topic:       "users",
+		Application: "billing",
+		Version:     "3",
+		Payload:     []byte(`{}`),
+	}).Return(&Encoded{SchemaID: 7, Subject: "users-value", Version: "3", Payload: []byte{0x00}}, nil).Once()
+	auditMock.On("Record", model.AuditEntry{
+		Caller:      "api_key:billing",
+		RemoteAddr:  "192.0.2.1:1234",
+		Topic:       "users",
+		Application: "billing",
+		Action:      "write",
+		Version:     "3",
+		Decision:    model.AuditAccepted,
+	}).Once()
+
+	r := httptest.NewRequest("POST", "http://example.com/encode", strings.NewReader(`{"topic": "users", "version": "3", "payload": {}}`))
+	r = r.WithContext(auth.WithPrincipal(r.Context(), &auth.Principal{Method: auth.APIKeyMethod, Application: "billing"}))
+
+	w := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	usecaseMock.AssertExpectations(t)
+	auditMock.AssertExpectations(t)
+	limiterMock.AssertExpectations(t)
+}
+
+func Test_HTTPHandler_Decode_with_a_version_throttled(t *testing.T) {
+	usecaseMock := new(UsecaseMock)
+	auditMock := new(audit.Mock)
+	limiterMock := new(ratelimit.Mock)
+	handler := NewHTTPHandler(usecaseMock, auditMock, limiterMock)
+
+	limiterMock.On("Allow", "crm", "192.0.2.1:1234").Return(1500*time.Millisecond, false).Once()
+	auditMock.On("Record", model.AuditEntry{
+		RemoteAddr:  "192.0.2.1:1234",
+		Topic:       "users",
+		Application: "crm",
+		Action:      "read",
+		Version:     "2",
+		Decision:    model.AuditRefused,
+		Reasons:     []string{`too many requests: rate limit exceeded for the application "crm"`},
+	}).Once()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "http://example.com/decode", strings.NewReader(`{"topic": "users", "application": "crm", "version": "2", "payload": "AAEC"}`))
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+	usecaseMock.AssertExpectations(t)
+	auditMock.AssertExpectations(t)
+	limiterMock.AssertExpectations(t)
+}
+
+func Test_HTTPHandler_Encode_with_another_application_than_the_authenticated_one(t *testing.T) {
+	handler := NewHTTPHandler(new(UsecaseMock), new(audit.Mock), new(ratelimit.Mock))
+
+	r := httptest.NewRequest("POST", "http://example.com/encode", strings.NewReader(`{"topic": "users", "application": "crm", "payload": {}}`))
+	r = r.WithContext(auth.WithPrincipal(r.Context(), &auth.Principal{Application: "billing"}))
+
+	w := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), `authenticated as \"billing\"`)
+}
+
+func Test_HTTPHandler_Decode_success(t *testing.T) {
+	usecaseMock := new(UsecaseMock)
+	handler := NewHTTPHandler(usecaseMock, new(audit.Mock), new(ratelimit.Mock))
+
+	usecaseMock.On("Decode", &DecodeCmd{
+		Topic:       "users",
+		Application: "crm",
+		Payload:     []byte{0x00, 0x01, 0x02},
+	}).Return(&Decoded{
+		SchemaID:     7,
+		Subject:      "users-value",
+		Version:      "2",
+		Fingerprints: &avro.Fingerprints{CRC64Avro: "some-crc", MD5: "some-md5", SHA256: "some-sha"},
+		Payload:      []byte(`{"name":"Alice"}`),
+	}, nil).Once()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "http://example.com/decode", strings.NewReader(`{
+		"topic": "users",
+		"application": "crm",
+		"payload": "AAEC"
+	}`))
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"schema_id": 7,
+		"subject": "users-value",
+		"version": "2",
+		"fingerprints": {"crc64_avro": "some-crc", "md5": "some-md5", "sha256": "some-sha"},
+		"payload": {"name": "Alice"}
+	}`, w.Body.String())
+	usecaseMock.AssertExpectations(t)
+}
+
+func Test_HTTPHandler_Decode_with_an_invalid_base64(t *testing.T) {
+	handler := NewHTTPHandler(new(UsecaseMock), new(audit.Mock), new(ratelimit.Mock))
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "http://example.com/decode", strings.NewReader(`{"topic": "users", "application": "crm", "payload": "!!"}`))
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), "expected a base64 string")
+}
@@ -0,0 +1,469 @@
+package payload
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/Peltoche/avro-gateway/avro"
+	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/Peltoche/avro-gateway/model"
+	"github.com/Peltoche/avro-gateway/schema"
+	"github.com/Peltoche/avro-gateway/tracing"
+)
+
+// Encoding of a payload.
+type Encoding string
+
+const (
+	// BinaryEncoding is the Avro binary encoding.
+	BinaryEncoding Encoding = "binary"
+	// ConfluentEncoding is the Avro binary encoding prefixed with the magic
+	// byte and the schema id.
+	ConfluentEncoding Encoding = "confluent"
+	// JSONEncoding is the Avro JSON encoding.
+	JSONEncoding Encoding = "json"
+)
+
+// Usecase handling all the logic about the payloads.
+type Usecase struct {
+	registry   Registry
+	storage    Storage
+	registrar  Registrar
+	authorizer Authorizer
+}
+
+// Registry is used to fetch schema from any Schema Registry.
+type Registry interface {
+	FetchSchema(ctx context.Context, subject string, version string) (string, error)
+	FetchSchemaByID(ctx context.Context, id int) (string, error)
+	FetchSchemaID(ctx context.Context, subject string, version string) (int, error)
+}
+
+// Registrar register the clients, like a POST /schema request.
+type Registrar interface {
+	GetSchema(ctx context.Context, cmd *schema.GetSchemaCmd) (string, *avro.Fingerprints, error)
+}
+
+// Authorizer check the ACL rules.
+type Authorizer interface {
+	Authorize(ctx context.Context, application string, topic string, action string) error
+}
+
+// Storage used to retrieve the subject of a topic.
+type Storage interface {
+	GetAllClientsOnTopic(ctx context.Context, topicName string) ([]model.Client, error)
+}
+
+// NewUsecase instantiate a new Usecase.
+func NewUsecase(registry Registry, storage Storage, registrar Registrar, authorizer Authorizer) *Usecase {
+	return &Usecase{
+		registry:   registry,
+		storage:    storage,
+		registrar:  registrar,
+		authorizer: authorizer,
+	}
+}
+
+// ValidateCmd is the requests parameters for the Validate method.
+//
+// The schema is selected by SchemaID, by Subject and Version or by Topic and
+// Version, the subject being the one used by the clients of the topic. The
+// Version is "latest" if empty. For the Confluent wire format the embedded
+// schema id is used if no schema is selected.
+type ValidateCmd struct {
+	Topic    string
+	Subject  string
+	Version  string
+	SchemaID int
+	Encoding Encoding
+	Payload  []byte
+}
+
+// Validation is the result of a payload validation. Path and Error locate and
+// describe the first invalid value of an invalid payload.
+type Validation struct {
+	Valid bool   `json:"valid"`
+	Path  string `json:"path,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// Validate check if the payload match the schema.
+func (t *Usecase) Validate(ctx context.Context, cmd *ValidateCmd) (*Validation, error) {
+	ctx, span := tracing.Start(ctx, "payload.Usecase.Validate")
+	defer span.End()
+
+	validation, err := t.validate(ctx, cmd)
+	span.SetError(err)
+
+	return validation, err
+}
+
+func (t *Usecase) validate(ctx context.Context, cmd *ValidateCmd) (*Validation, error) {
+	err := validateEncoding(cmd.Encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	data := cmd.Payload
+	schemaID := cmd.SchemaID
+	if cmd.Encoding == ConfluentEncoding {
+		var writerID int
+		writerID, data, err = avro.ParseWireFormat(cmd.Payload)
+		if err != nil {
+			return invalid(err)
+		}
+
+		if schemaID != 0 && schemaID != writerID {
+			return invalid(&avro.Error{Path: "$", Message: fmt.Sprintf("the message is written with the schema %d, not %d", writerID, schemaID)})
+		}
+
+		if cmd.Topic == "" && cmd.Subject == "" {
+			schemaID = writerID
+		}
+	}
+
+	parsed, err := t.fetchSchema(ctx, cmd.Topic, cmd.Subject, cmd.Version, schemaID)
+	if err != nil {
+		return nil, err
+	}
+
+	if cmd.Encoding == JSONEncoding {
+		_, err = avro.DecodeJSON(parsed, data)
+	} else {
+		_, err = avro.DecodeBinary(parsed, data)
+	}
+	if err != nil {
+		return invalid(err)
+	}
+
+	return &Validation{Valid: true}, nil
+}
+
+// EncodeCmd is the requests parameters for the Encode method.
+//
+// The schema is the one of the application registered as writer of the
+// topic. If the Version is set the application is registered with this
+// version first, the Subject being the one used by the topic if empty.
+type EncodeCmd struct {
+	Topic       string
+	Application string
+	Subject     string
+	Version     string
+	// Payload is the value with the Avro JSON encoding.
+	Payload []byte
+}
+
+// Encoded is a payload encoded with the Confluent wire format.
+type Encoded struct {
+	SchemaID     int                `json:"schema_id"`
+	Subject      string             `json:"subject"`
+	Version      string             `json:"version"`
+	Fingerprints *avro.Fingerprints `json:"fingerprints"`
+	Payload      []byte             `json:"payload"`
+}
+
+// Encode convert a JSON value into a message with the Confluent wire format
+// using the schema of the writer.
+func (t *Usecase) Encode(ctx context.Context, cmd *EncodeCmd) (*Encoded, error) {
+	ctx, span := tracing.Start(ctx, "payload.Usecase.Encode")
+	defer span.End()
+
+	encoded, err := t.encode(ctx, cmd)
+	span.SetError(err)
+
+	return encoded, err
+}
+
+func (t *Usecase) encode(ctx context.Context, cmd *EncodeCmd) (*Encoded, error) {
+	client, err := t.client(ctx, cmd.Topic, cmd.Application, "write", cmd.Subject, cmd.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	writer, schemaID, err := t.clientSchema(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := avro.DecodeJSON(writer, cmd.Payload)
+	if err != nil {
+		return nil, internal.Errorf(internal.ValidationError, "invalid payload: %s", err)
+	}
+
+	data, err := avro.EncodeBinary(writer, value)
+	if err != nil {
+		return nil, internal.Wrap(err, "failed to encode the payload")
+	}
+
+	return &Encoded{
+		SchemaID:     schemaID,
+		Subject:      client.Subject,
+		Version:      client.Version,
+		Fingerprints: avro.Fingerprint(writer),
+		Payload:      avro.EncodeWireFormat(schemaID, data),
+	}, nil
+}
+
+// DecodeCmd is the requests parameters for the Decode method.
+//
+// The payload is read with the schema of the application registered as
+// reader of the topic. If the Version is set the application is registered
+// with this version first, the Subject being the one used by the topic if
+// empty.
+type DecodeCmd struct {
+	Topic       string
+	Application string
+	Subject     string
+	Version     string
+	// Payload is the message with the Confluent wire format.
+	Payload []byte
+}
+
+// Decoded is a message converted into the reader schema.
+//
+// SchemaID is the id of the writer schema and Subject/Version/Fingerprints the
+// reader schema.
+type Decoded struct {
+	SchemaID     int                `json:"schema_id"`
+	Subject      string             `json:"subject"`
+	Version      string             `json:"version"`
+	Fingerprints *avro.Fingerprints `json:"fingerprints"`
+	Payload      json.RawMessage    `json:"payload"`
+}
+
+// Decode convert a message with the Confluent wire format into a JSON value
+// of the reader schema, resolving the differences between the writer and the
+// reader schemas.
+func (t *Usecase) Decode(ctx context.Context, cmd *DecodeCmd) (*Decoded, error) {
+	ctx, span := tracing.Start(ctx, "payload.Usecase.Decode")
+	defer span.End()
+
+	decoded, err := t.decode(ctx, cmd)
+	span.SetError(err)
+
+	return decoded, err
+}
+
+func (t *Usecase) decode(ctx context.Context, cmd *DecodeCmd) (*Decoded, error) {
+	client, err := t.client(ctx, cmd.Topic, cmd.Application, "read", cmd.Subject, cmd.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	writerID, data, err := avro.ParseWireFormat(cmd.Payload)
+	if err != nil {
+		return nil, internal.Errorf(internal.ValidationError, "invalid payload: %s", err)
+	}
+
+	reader, _, err := t.clientSchema(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	writer, err := t.fetchSchema(ctx, "", "", "", writerID)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := avro.DecodeResolved(writer, reader, data)
+	if err != nil {
+		return nil, internal.Errorf(internal.ValidationError, "invalid payload: %s", err)
+	}
+
+	payload, err := avro.EncodeJSON(reader, value)
+	if err != nil {
+		return nil, internal.Wrap(err, "failed to encode the payload")
+	}
+
+	return &Decoded{
+		SchemaID:     writerID,
+		Subject:      client.Subject,
+		Version:      client.Version,
+		Fingerprints: avro.Fingerprint(reader),
+		Payload:      payload,
+	}, nil
+}
+
+// client return the client of the application for the action on the topic.
+//
+// With a version the client is registered with the GetSchema flow, so the
+// compatibility and the ACL rules are checked. Otherwise the ACL rules are
+// checked again, as they could have changed since the registration, and the
+// client with the highest version is used.
+func (t *Usecase) client(ctx context.Context, topic string, application string, action string, subject string, version string) (*model.Client, error) {
+	if topic == "" {
+		return nil, internal.NewError(internal.ValidationError, `missing field "topic"`)
+	}
+
+	if application == "" {
+		return nil, internal.NewError(internal.ValidationError, `missing field "application"`)
+	}
+
+	clients, err := t.storage.GetAllClientsOnTopic(ctx, topic)
+	if err != nil {
+		return nil, internal.Wrapf(err, "failed to retrieve the list of clients connected to the topic %q", topic)
+	}
+
+	if version != "" {
+		if subject == "" && len(clients) > 0 {
+			subject = clients[0].Subject
+		}
+
+		cmd := schema.GetSchemaCmd{
+			Topic:       topic,
+			Application: application,
+			Action:      action,
+			Subject:     subject,
+			Version:     version,
+		}
+
+		_, _, err = t.registrar.GetSchema(ctx, &cmd)
+		if err != nil {
+			return nil, err
+		}
+
+		return &model.Client{Topic: topic, Application: application, Action: action, Subject: subject, Version: version}, nil
+	}
+
+	err = t.authorizer.Authorize(ctx, application, topic, action)
+	if err != nil {
+		return nil, err
+	}
+
+	var res *model.Client
+	for i := range clients {
+		if clients[i].Application != application || clients[i].Action != action {
+			continue
+		}
+
+		if res == nil || versionNumber(clients[i].Version) > versionNumber(res.Version) {
+			res = &clients[i]
+		}
+	}
+
+	if res == nil {
+		return nil, internal.Errorf(internal.ValidationError, `missing field "version": the application %q is not registered to %s the topic %q`, application, action, topic)
+	}
+
+	return res, nil
+}
+
+// clientSchema return the parsed schema of the client and its id.
+func (t *Usecase) clientSchema(ctx context.Context, client *model.Client) (*avro.Schema, int, error) {
+	schemaID, err := t.registry.FetchSchemaID(ctx, client.Subject, client.Version)
+	if err != nil {
+		return nil, 0, internal.Wrap(err, "failed to fetch the schema id")
+	}
+
+	// The id is used rather than the subject/version as the version "latest"
+	// could have changed between the two calls.
+	parsed, err := t.fetchSchema(ctx, "", "", "", schemaID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return parsed, schemaID, nil
+}
+
+// versionNumber return the number of a client version, "latest" being above
+// all the others.
+func versionNumber(version string) int {
+	if version == "latest" {
+		return math.MaxInt32
+	}
+
+	val, _ := strconv.Atoi(version)
+
+	return val
+}
+
+// fetchSchema retrieve and parse the selected schema.
+func (t *Usecase) fetchSchema(ctx context.Context, topic string, subject string, version string, schemaID int) (*avro.Schema, error) {
+	var rawSchema string
+	var err error
+
+	switch {
+	case schemaID != 0:
+		rawSchema, err = t.registry.FetchSchemaByID(ctx, schemaID)
+	case subject != "" || topic != "":
+		if subject == "" {
+			subject, err = t.topicSubject(ctx, topic)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if version == "" {
+			version = "latest"
+		}
+		err = validateVersion(version)
+		if err != nil {
+			return nil, err
+		}
+
+		rawSchema, err = t.registry.FetchSchema(ctx, subject, version)
+	default:
+		return nil, internal.NewError(internal.ValidationError, `missing field "schema_id", "subject" or "topic"`)
+	}
+	if err != nil {
+		return nil, internal.Wrap(err, "failed to fetch the schema")
+	}
+
+	parsed, err := avro.Parse(rawSchema)
+	if err != nil {
+		return nil, internal.Wrap(err, "failed to parse the registry schema")
+	}
+
+	return parsed, nil
+}
+
+// topicSubject return the subject used by the clients of the topic.
+func (t *Usecase) topicSubject(ctx context.Context, topic string) (string, error) {
+	clients, err := t.storage.GetAllClientsOnTopic(ctx, topic)
+	if err != nil {
+		return "", internal.Wrapf(err, "failed to retrieve the list of clients connected to the topic %q", topic)
+	}
+
+	if len(clients) == 0 {
+		return "", internal.Errorf(internal.NotFound, "no client registered on the topic %q", topic)
+	}
+
+	return clients[0].Subject, nil
+}
+
+// invalid return the Validation of an invalid payload.
+func invalid(err error) (*Validation, error) {
+	avroErr, ok := err.(*avro.Error)
+	if !ok {
+		return nil, err
+	}
+
+	return &Validation{Valid: false, Path: avroErr.Path, Error: avroErr.Message}, nil
+}
+
+func validateEncoding(encoding Encoding) error {
+	switch encoding {
+	case BinaryEncoding, ConfluentEncoding, JSONEncoding:
+		return nil
+	case "":
+		return internal.NewError(internal.ValidationError, `missing field "encoding"`)
+	default:
+		return internal.NewError(internal.ValidationError, `invalid input for field "encoding"`)
+	}
+}
+
+func validateVersion(version string) error {
+	if version == "latest" {
+		return nil
+	}
+
+	val, err := strconv.Atoi(version)
+	if err != nil || val < 1 {
+		return internal.NewError(internal.ValidationError, `invalid input for field "version"`)
+	}
+
+	return nil
+}
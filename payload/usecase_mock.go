@@ -0,0 +1,45 @@
+package payload
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// UsecaseMock is a mock implementation of payload.Usecase.
+type UsecaseMock struct {
+	mock.Mock
+}
+
+// Validate method mock.
+func (t *UsecaseMock) Validate(ctx context.Context, cmd *ValidateCmd) (*Validation, error) {
+	args := t.Called(cmd)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*Validation), args.Error(1)
+}
+
+// Encode method mock.
+func (t *UsecaseMock) Encode(ctx context.Context, cmd *EncodeCmd) (*Encoded, error) {
+	args := t.Called(cmd)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*Encoded), args.Error(1)
+}
+
+// Decode method mock.
+func (t *UsecaseMock) Decode(ctx context.Context, cmd *DecodeCmd) (*Decoded, error) {
+	args := t.Called(cmd)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*Decoded), args.Error(1)
+}
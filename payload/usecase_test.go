@@ -0,0 +1,380 @@
+package payload
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Peltoche/avro-gateway/acl"
+	"github.com/Peltoche/avro-gateway/avro"
+	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/Peltoche/avro-gateway/model"
+	"github.com/Peltoche/avro-gateway/registry"
+	"github.com/Peltoche/avro-gateway/schema"
+	"github.com/Peltoche/avro-gateway/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+const someSchema = `{
+	"type": "record",
+	"name": "User",
+	"fields": [
+		{"name": "name", "type": "string"},
+		{"name": "age", "type": "int"}
+	]
+}`
+
+// someUser is {"name": "Alice", "age": 42} in Avro binary.
+var someUser = []byte{0x0a, 'A', 'l', 'i', 'c', 'e', 0x54}
+
+func Test_Usecase_Validate_binary_success(t *testing.T) {
+	registryMock := new(registry.Mock)
+	usecase := NewUsecase(registryMock, new(storage.Mock), new(schema.UsecaseMock), new(acl.UsecaseMock))
+
+	registryMock.On("FetchSchema", "users-value", "3").Return(someSchema, nil).Once()
+
+	validation, err := usecase.Validate(context.Background(), &ValidateCmd{
+		Subject:  "users-value",
+		Version:  "3",
+		Encoding: BinaryEncoding,
+		Payload:  someUser,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, &Validation{Valid: true}, validation)
+	registryMock.AssertExpectations(t)
+}
+
+func Test_Usecase_Validate_confluent_success(t *testing.T) {
+	registryMock := new(registry.Mock)
+	usecase := NewUsecase(registryMock, new(storage.Mock), new(schema.UsecaseMock), new(acl.UsecaseMock))
+
+	registryMock.On("FetchSchemaByID", 7).Return(someSchema, nil).Once()
+
+	validation, err := usecase.Validate(context.Background(), &ValidateCmd{
+		Encoding: ConfluentEncoding,
+		Payload:  avro.EncodeWireFormat(7, someUser),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, &Validation{Valid: true}, validation)
+	registryMock.AssertExpectations(t)
+}
+
+func Test_Usecase_Validate_confluent_with_another_schema_id(t *testing.T) {
+	usecase := NewUsecase(new(registry.Mock), new(storage.Mock), new(schema.UsecaseMock), new(acl.UsecaseMock))
+
+	validation, err := usecase.Validate(context.Background(), &ValidateCmd{
+		SchemaID: 8,
+		Encoding: ConfluentEncoding,
+		Payload:  avro.EncodeWireFormat(7, someUser),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, &Validation{Valid: false, Path: "$", Error: "the message is written with the schema 7, not 8"}, validation)
+}
+
+func Test_Usecase_Validate_json_with_the_topic_subject(t *testing.T) {
+	registryMock := new(registry.Mock)
+	storageMock := new(storage.Mock)
+	usecase := NewUsecase(registryMock, storageMock, new(schema.UsecaseMock), new(acl.UsecaseMock))
+
+	storageMock.On("GetAllClientsOnTopic", "users").Return([]model.Client{{Topic: "users", Subject: "users-value", Version: "1"}}, nil).Once()
+	registryMock.On("FetchSchema", "users-value", "latest").Return(someSchema, nil).Once()
+
+	validation, err := usecase.Validate(context.Background(), &ValidateCmd{
+		Topic:    "users",
+		Encoding: JSONEncoding,
+		Payload:  []byte(`{"name": "Alice", "age": "42"}`),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, &Validation{Valid: false, Path: "$.age", Error: "expected an int, got string"}, validation)
+	registryMock.AssertExpectations(t)
+	storageMock.AssertExpectations(t)
+}
+
+func Test_Usecase_Validate_with_a_topic_without_client(t *testing.T) {
+	storageMock := new(storage.Mock)
+	usecase := NewUsecase(new(registry.Mock), storageMock, new(schema.UsecaseMock), new(acl.UsecaseMock))
+
+	storageMock.On("GetAllClientsOnTopic", "users").Return([]model.Client{}, nil).Once()
+
+	validation, err := usecase.Validate(context.Background(), &ValidateCmd{
+		Topic:    "users",
+		Encoding: JSONEncoding,
+		Payload:  []byte(`{}`),
+	})
+
+	assert.Nil(t, validation)
+	assert.EqualError(t, err, `not found: no client registered on the topic "users"`)
+}
+
+func Test_Usecase_Validate_binary_invalid(t *testing.T) {
+	registryMock := new(registry.Mock)
+	usecase := NewUsecase(registryMock, new(storage.Mock), new(schema.UsecaseMock), new(acl.UsecaseMock))
+
+	registryMock.On("FetchSchemaByID", 7).Return(someSchema, nil).Once()
+
+	validation, err := usecase.Validate(context.Background(), &ValidateCmd{
+		SchemaID: 7,
+		Encoding: BinaryEncoding,
+		Payload:  someUser[:6],
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, &Validation{Valid: false, Path: "$.age", Error: "unexpected end of data"}, validation)
+}
+
+func Test_Usecase_Validate_with_a_schema_not_found(t *testing.T) {
+	registryMock := new(registry.Mock)
+	usecase := NewUsecase(registryMock, new(storage.Mock), new(schema.UsecaseMock), new(acl.UsecaseMock))
+
+	registryMock.On("FetchSchemaByID", 7).Return("", internal.NewError(internal.NotFound, "schema 7 not found")).Once()
+
+	validation, err := usecase.Validate(context.Background(), &ValidateCmd{
+		SchemaID: 7,
+		Encoding: BinaryEncoding,
+		Payload:  someUser,
+	})
+
+	assert.Nil(t, validation)
+	assert.EqualError(t, err, "not found: failed to fetch the schema: schema 7 not found")
+}
+
+func Test_Usecase_Validate_with_invalid_inputs(t *testing.T) {
+	usecase := NewUsecase(new(registry.Mock), new(storage.Mock), new(schema.UsecaseMock), new(acl.UsecaseMock))
+
+	tests := []struct {
+		name string
+		cmd  ValidateCmd
+		err  string
+	}{
+		{"missing encoding", ValidateCmd{SchemaID: 1}, `validation error: missing field "encoding"`},
+		{"invalid encoding", ValidateCmd{SchemaID: 1, Encoding: "xml"}, `validation error: invalid input for field "encoding"`},
+		{"missing schema", ValidateCmd{Encoding: BinaryEncoding}, `validation error: missing field "schema_id", "subject" or "topic"`},
+		{"invalid version", ValidateCmd{Subject: "users-value", Version: "0", Encoding: BinaryEncoding}, `validation error: invalid input for field "version"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			validation, err := usecase.Validate(context.Background(), &test.cmd)
+
+			assert.Nil(t, validation)
+			assert.EqualError(t, err, test.err)
+		})
+	}
+}
+
+func Test_Usecase_Encode_with_a_registered_writer(t *testing.T) {
+	registryMock := new(registry.Mock)
+	storageMock := new(storage.Mock)
+	authorizerMock := new(acl.UsecaseMock)
+	usecase := NewUsecase(registryMock, storageMock, new(schema.UsecaseMock), authorizerMock)
+
+	authorizerMock.On("Authorize", "billing", "users", "write").Return(nil).Once()
+	storageMock.On("GetAllClientsOnTopic", "users").Return([]model.Client{
+		{Topic: "users", Application: "billing", Action: "write", Subject: "users-value", Version: "1"},
+		{Topic: "users", Application: "billing", Action: "write", Subject: "users-value", Version: "2"},
+		{Topic: "users", Application: "billing", Action: "read", Subject: "users-value", Version: "3"},
+	}, nil).Once()
+	registryMock.On("FetchSchemaID", "users-value", "2").Return(7, nil).Once()
+	registryMock.On("FetchSchemaByID", 7).Return(someSchema, nil).Once()
+
+	encoded, err := usecase.Encode(context.Background(), &EncodeCmd{
+		Topic:       "users",
+		Application: "billing",
+		Payload:     []byte(`{"name": "Alice", "age": 42}`),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, &Encoded{
+		SchemaID: 7,
+		Subject:  "users-value",
+		Version:  "2",
+		Fingerprints: &avro.Fingerprints{
+			CRC64Avro: "428c3373d2214232",
+			MD5:       "1aa37cc6de979785f296ce4c82e588d5",
+			SHA256:    "84b95d9fbb591847c35fab7b097c276d9a0fc1b56d2669eee543696f4ce354f1",
+		},
+		Payload: avro.EncodeWireFormat(7, someUser),
+	}, encoded)
+	registryMock.AssertExpectations(t)
+	storageMock.AssertExpectations(t)
+	authorizerMock.AssertExpectations(t)
+}
+
+func Test_Usecase_Encode_with_a_version_register_the_writer(t *testing.T) {
+	registryMock := new(registry.Mock)
+	storageMock := new(storage.Mock)
+	registrarMock := new(schema.UsecaseMock)
+	usecase := NewUsecase(registryMock, storageMock, registrarMock, new(acl.UsecaseMock))
+
+	storageMock.On("GetAllClientsOnTopic", "users").Return([]model.Client{
+		{Topic: "users", Application: "crm", Action: "read", Subject: "users-value", Version: "1"},
+	}, nil).Once()
+	registrarMock.On("GetSchema", &schema.GetSchemaCmd{
+		Topic:       "users",
+		Application: "billing",
+		Action:      "write",
+		Subject:     "users-value",
+		Version:     "latest",
+	}).Return(someSchema, nil, nil).Once()
+	registryMock.On("FetchSchemaID", "users-value", "latest").Return(7, nil).Once()
+	registryMock.On("FetchSchemaByID", 7).Return(someSchema, nil).Once()
+
+	encoded, err := usecase.Encode(context.Background(), &EncodeCmd{
+		Topic:       "users",
+		Application: "billing",
+		Version:     "latest",
+		Payload:     []byte(`{"name": "Alice", "age": 42}`),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, avro.EncodeWireFormat(7, someUser), encoded.Payload)
+	registryMock.AssertExpectations(t)
+	storageMock.AssertExpectations(t)
+	registrarMock.AssertExpectations(t)
+}
+
+func Test_Usecase_Encode_with_an_unregistered_application(t *testing.T) {
+	storageMock := new(storage.Mock)
+	authorizerMock := new(acl.UsecaseMock)
+	usecase := NewUsecase(new(registry.Mock), storageMock, new(schema.UsecaseMock), authorizerMock)
+
+	authorizerMock.On("Authorize", "billing", "users", "write").Return(nil).Once()
+	storageMock.On("GetAllClientsOnTopic", "users").Return([]model.Client{
+		{Topic: "users", Application: "billing", Action: "read", Subject: "users-value", Version: "1"},
+	}, nil).Once()
+
+	encoded, err := usecase.Encode(context.Background(), &EncodeCmd{
+		Topic:       "users",
+		Application: "billing",
+		Payload:     []byte(`{"name": "Alice", "age": 42}`),
+	})
+
+	assert.Nil(t, encoded)
+	assert.EqualError(t, err, `validation error: missing field "version": the application "billing" is not registered to write the topic "users"`)
+	authorizerMock.AssertExpectations(t)
+}
+
+func Test_Usecase_Encode_with_a_denied_application(t *testing.T) {
+	storageMock := new(storage.Mock)
+	authorizerMock := new(acl.UsecaseMock)
+	usecase := NewUsecase(new(registry.Mock), storageMock, new(schema.UsecaseMock), authorizerMock)
+
+	denial := internal.NewError(internal.Forbidden, `the application "billing" is denied to write on the topic "users" by the rule "some-rule"`)
+	authorizerMock.On("Authorize", "billing", "users", "write").Return(denial).Once()
+	storageMock.On("GetAllClientsOnTopic", "users").Return([]model.Client{
+		{Topic: "users", Application: "billing", Action: "write", Subject: "users-value", Version: "1"},
+	}, nil).Once()
+
+	// The application was registered before the deny rule.
+	encoded, err := usecase.Encode(context.Background(), &EncodeCmd{
+		Topic:       "users",
+		Application: "billing",
+		Payload:     []byte(`{"name": "Alice", "age": 42}`),
+	})
+
+	assert.Nil(t, encoded)
+	assert.Equal(t, denial, err)
+	storageMock.AssertExpectations(t)
+	authorizerMock.AssertExpectations(t)
+}
+
+func Test_Usecase_Encode_with_an_invalid_payload(t *testing.T) {
+	registryMock := new(registry.Mock)
+	storageMock := new(storage.Mock)
+	authorizerMock := new(acl.UsecaseMock)
+	usecase := NewUsecase(registryMock, storageMock, new(schema.UsecaseMock), authorizerMock)
+
+	authorizerMock.On("Authorize", "billing", "users", "write").Return(nil).Once()
+	storageMock.On("GetAllClientsOnTopic", "users").Return([]model.Client{
+		{Topic: "users", Application: "billing", Action: "write", Subject: "users-value", Version: "1"},
+	}, nil).Once()
+	registryMock.On("FetchSchemaID", "users-value", "1").Return(7, nil).Once()
+	registryMock.On("FetchSchemaByID", 7).Return(someSchema, nil).Once()
+
+	encoded, err := usecase.Encode(context.Background(), &EncodeCmd{
+		Topic:       "users",
+		Application: "billing",
+		Payload:     []byte(`{"name": "Alice"}`),
+	})
+
+	assert.Nil(t, encoded)
+	assert.EqualError(t, err, "validation error: invalid payload: $.age: missing field")
+	authorizerMock.AssertExpectations(t)
+}
+
+func Test_Usecase_Encode_with_missing_inputs(t *testing.T) {
+	usecase := NewUsecase(new(registry.Mock), new(storage.Mock), new(schema.UsecaseMock), new(acl.UsecaseMock))
+
+	encoded, err := usecase.Encode(context.Background(), &EncodeCmd{Application: "billing"})
+	assert.Nil(t, encoded)
+	assert.EqualError(t, err, `validation error: missing field "topic"`)
+
+	encoded, err = usecase.Encode(context.Background(), &EncodeCmd{Topic: "users"})
+	assert.Nil(t, encoded)
+	assert.EqualError(t, err, `validation error: missing field "application"`)
+}
+
+func Test_Usecase_Decode_with_the_reader_schema(t *testing.T) {
+	registryMock := new(registry.Mock)
+	storageMock := new(storage.Mock)
+	authorizerMock := new(acl.UsecaseMock)
+	usecase := NewUsecase(registryMock, storageMock, new(schema.UsecaseMock), authorizerMock)
+
+	readerSchema := `{
+		"type": "record",
+		"name": "User",
+		"fields": [
+			{"name": "name", "type": "string"},
+			{"name": "age", "type": "long"},
+			{"name": "email", "type": ["null", "string"], "default": null}
+		]
+	}`
+
+	authorizerMock.On("Authorize", "crm", "users", "read").Return(nil).Once()
+	storageMock.On("GetAllClientsOnTopic", "users").Return([]model.Client{
+		{Topic: "users", Application: "crm", Action: "read", Subject: "users-value", Version: "2"},
+	}, nil).Once()
+	registryMock.On("FetchSchemaID", "users-value", "2").Return(8, nil).Once()
+	registryMock.On("FetchSchemaByID", 8).Return(readerSchema, nil).Once()
+	registryMock.On("FetchSchemaByID", 7).Return(someSchema, nil).Once()
+
+	decoded, err := usecase.Decode(context.Background(), &DecodeCmd{
+		Topic:       "users",
+		Application: "crm",
+		Payload:     avro.EncodeWireFormat(7, someUser),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 7, decoded.SchemaID)
+	assert.Equal(t, "users-value", decoded.Subject)
+	assert.Equal(t, "2", decoded.Version)
+	assert.JSONEq(t, `{"name": "Alice", "age": 42, "email": null}`, string(decoded.Payload))
+	registryMock.AssertExpectations(t)
+	storageMock.AssertExpectations(t)
+	authorizerMock.AssertExpectations(t)
+}
+
+func Test_Usecase_Decode_with_an_invalid_payload(t *testing.T) {
+	storageMock := new(storage.Mock)
+	authorizerMock := new(acl.UsecaseMock)
+	usecase := NewUsecase(new(registry.Mock), storageMock, new(schema.UsecaseMock), authorizerMock)
+
+	authorizerMock.On("Authorize", "crm", "users", "read").Return(nil).Once()
+	storageMock.On("GetAllClientsOnTopic", "users").Return([]model.Client{
+		{Topic: "users", Application: "crm", Action: "read", Subject: "users-value", Version: "2"},
+	}, nil).Once()
+
+	decoded, err := usecase.Decode(context.Background(), &DecodeCmd{
+		Topic:       "users",
+		Application: "crm",
+		Payload:     someUser,
+	})
+
+	assert.Nil(t, decoded)
+	assert.Error(t, err)
+	assert.Equal(t, internal.ValidationError, err.(*internal.Error).Kind)
+	authorizerMock.AssertExpectations(t)
+}
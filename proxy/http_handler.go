@@ -0,0 +1,536 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Peltoche/avro-gateway/audit"
+	"github.com/Peltoche/avro-gateway/auth"
+	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/Peltoche/avro-gateway/logging"
+	"github.com/Peltoche/avro-gateway/model"
+	"github.com/Peltoche/avro-gateway/schema"
+	"github.com/Peltoche/avro-gateway/tracing"
+	"github.com/gorilla/mux"
+)
+
+// ContentType is the content type of the Schema Registry API.
+const ContentType = "application/vnd.schemaregistry.v1+json"
+
+// maxBodySize is the maximum size of an intercepted request body.
+const maxBodySize = 8 << 20
+
+// HTTPHandler expose the Schema Registry API and forward the requests to the
+// Schema Registry, so the stock serializers can use the gateway as registry.
+//
+// The schema registrations and lookups made by an identified producer and the
+// schemas fetched by an identified consumer are checked, rate limited and
+// recorded like the POST /schema requests. The registrations and lookups of an
+// anonymous producer on a topic are refused. All the other requests are
+// forwarded untouched.
+type HTTPHandler struct {
+	registryURL *url.URL
+	pathPrefix  string
+	identifier  *Identifier
+	usecase     usecase
+	auditor     auditor
+	limiter     limiter
+	client      *http.Client
+	proxy       *httputil.ReverseProxy
+}
+
+type usecase interface {
+	CheckClient(ctx context.Context, cmd *schema.GetSchemaCmd) error
+	LintSchema(ctx context.Context, cmd *schema.GetSchemaCmd, schema string) error
+	RegisterClient(ctx context.Context, cmd *schema.GetSchemaCmd, schema string) error
+}
+
+type auditor interface {
+	Record(ctx context.Context, entry model.AuditEntry)
+}
+
+type limiter interface {
+	Allow(application string, remoteAddr string) (time.Duration, bool)
+}
+
+// NewHTTPHandler instantiate a new HTTPHandler forwarding the requests to the
+// registry. The pathPrefix is removed from the forwarded paths, it's the path
+// of the namespace the routes are registered into.
+func NewHTTPHandler(registryURL *url.URL, pathPrefix string, identifier *Identifier, usecase usecase, auditor auditor, limiter limiter) *HTTPHandler {
+	handler := &HTTPHandler{
+		registryURL: registryURL,
+		pathPrefix:  pathPrefix,
+		identifier:  identifier,
+		usecase:     usecase,
+		auditor:     auditor,
+		limiter:     limiter,
+		client:      http.DefaultClient,
+	}
+
+	handler.proxy = &httputil.ReverseProxy{
+		Director: handler.direct,
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			writeError(w, r, internal.NewError(internal.RemoteError, err.Error()))
+		},
+	}
+
+	return handler
+}
+
+// RegisterRoutes register all the Schema Registry routes into the router.
+func (t *HTTPHandler) RegisterRoutes(router *mux.Router) {
+	// The routes match the escaped path: a subject containing an escaped "/"
+	// stays a single path segment.
+	router = router.NewRoute().Subrouter()
+	router.UseEncodedPath()
+
+	router.HandleFunc("/subjects/{subject}/versions", t.PostVersion).Methods("POST")
+	router.HandleFunc("/subjects/{subject}", t.PostLookup).Methods("POST")
+	router.HandleFunc("/schemas/ids/{id}", t.GetSchemaByID).Methods("GET")
+
+	for _, path := range []string{
+		"/schemas/types",
+		"/schemas/ids/{id}/schema",
+		"/schemas/ids/{id}/subjects",
+		"/schemas/ids/{id}/versions",
+		"/subjects",
+		"/subjects/{subject}/versions",
+		"/subjects/{subject}/versions/{version}",
+		"/subjects/{subject}/versions/{version}/schema",
+		"/subjects/{subject}/versions/{version}/referencedby",
+		"/config",
+		"/config/{subject}",
+	} {
+		router.Handle(path, t.proxy).Methods("GET")
+	}
+
+	router.Handle("/compatibility/subjects/{subject}/versions", t.proxy).Methods("POST")
+	router.Handle("/compatibility/subjects/{subject}/versions/{version}", t.proxy).Methods("POST")
+}
+
+// PostVersion /subjects/{subject}/versions
+//
+// Register a schema. For an identified producer all the checks are done before
+// the registration, then the client is recorded with the registered version
+// if possible. An anonymous producer is refused.
+func (t *HTTPHandler) PostVersion(w http.ResponseWriter, r *http.Request) {
+	subject, err := url.PathUnescape(mux.Vars(r)["subject"])
+	if err != nil {
+		writeError(w, r, internal.Errorf(internal.ValidationError, "invalid subject: %s", err))
+		return
+	}
+
+	body, err := readBody(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	cmd, err := t.identifyProducer(r, subject)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if cmd == nil {
+		t.forward(w, r, body)
+		return
+	}
+
+	if !t.allow(w, r, cmd) {
+		return
+	}
+
+	err = t.usecase.CheckClient(r.Context(), cmd)
+	if err == nil {
+		err = t.usecase.LintSchema(r.Context(), cmd, registeredSchema(body))
+	}
+	if err != nil {
+		t.record(r, cmd, err)
+		writeError(w, r, err)
+		return
+	}
+
+	res, err := t.send(r.Context(), r.Method, r.URL.RequestURI(), r.Header, body)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if res.status == http.StatusOK {
+		// The registration only return the schema id, the lookup return the
+		// version. The schema is already registered: a tracking failure can't
+		// refuse the request anymore.
+		lookup, err := t.send(r.Context(), "POST", lookupURI(subject, r.URL.RawQuery), r.Header, body)
+		if err == nil {
+			err = t.track(r, cmd, registeredSchema(body), lookup)
+		}
+		if err != nil {
+			logging.Warn(r.Context(), "failed to track the registered schema", logging.Fields{"subject": subject, "error": err})
+		}
+	}
+
+	res.write(w, r)
+}
+
+// PostLookup /subjects/{subject}
+//
+// Check if a schema is registered under the subject. An identified producer
+// finding its schema is recorded with the found version, an anonymous one is
+// refused.
+func (t *HTTPHandler) PostLookup(w http.ResponseWriter, r *http.Request) {
+	subject, err := url.PathUnescape(mux.Vars(r)["subject"])
+	if err != nil {
+		writeError(w, r, internal.Errorf(internal.ValidationError, "invalid subject: %s", err))
+		return
+	}
+
+	body, err := readBody(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	cmd, err := t.identifyProducer(r, subject)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if cmd == nil {
+		t.forward(w, r, body)
+		return
+	}
+
+	if !t.allow(w, r, cmd) {
+		return
+	}
+
+	res, err := t.send(r.Context(), r.Method, r.URL.RequestURI(), r.Header, body)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if res.status == http.StatusOK {
+		err = t.track(r, cmd, registeredSchema(body), res)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+	}
+
+	res.write(w, r)
+}
+
+// GetSchemaByID /schemas/ids/{id}
+//
+// Fetch a schema by id, it's how the deserializers retrieve the writer schema
+// of a message. For an identified consumer the subjects using this schema are
+// retrieved and the client is registered with the one matching a topic.
+func (t *HTTPHandler) GetSchemaByID(w http.ResponseWriter, r *http.Request) {
+	application := t.identifier.application(r)
+	if application == "" {
+		t.proxy.ServeHTTP(w, r)
+		return
+	}
+
+	if !t.allow(w, r, &schema.GetSchemaCmd{Application: application, Action: "read"}) {
+		return
+	}
+
+	res, err := t.send(r.Context(), r.Method, r.URL.RequestURI(), r.Header, nil)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if res.status != http.StatusOK {
+		res.write(w, r)
+		return
+	}
+
+	cmd, err := t.identifyConsumer(r, mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	if cmd != nil {
+		err = t.usecase.RegisterClient(r.Context(), cmd, registeredSchema(res.body))
+		t.record(r, cmd, err)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+	}
+
+	res.write(w, r)
+}
+
+// identifyProducer return the producer using the subject, or nil if the
+// subject isn't the one of a topic. An anonymous producer is refused and
+// recorded: its schemas would skip all the checks of the topic.
+func (t *HTTPHandler) identifyProducer(r *http.Request, subject string) (*schema.GetSchemaCmd, error) {
+	topic := t.identifier.strategy.Topic(subject)
+	if topic == "" {
+		return nil, nil
+	}
+
+	cmd := t.identifier.Identify(r, subject, "write")
+	if cmd != nil {
+		return cmd, nil
+	}
+
+	err := internal.Errorf(internal.Unauthorized, "the producers of the topic %q must be identified", topic)
+	t.record(r, &schema.GetSchemaCmd{Topic: topic, Action: "write", Subject: subject}, err)
+
+	return nil, err
+}
+
+// allow consume a rate limit token of the application. A throttled request is
+// refused and recorded.
+func (t *HTTPHandler) allow(w http.ResponseWriter, r *http.Request, cmd *schema.GetSchemaCmd) bool {
+	retryAfter, allowed := t.limiter.Allow(cmd.Application, r.RemoteAddr)
+	if allowed {
+		return true
+	}
+
+	err := internal.Errorf(internal.TooManyRequests, "rate limit exceeded for the application %q", cmd.Application)
+	t.record(r, cmd, err)
+
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	writeError(w, r, err)
+
+	return false
+}
+
+// identifyConsumer return the consumer of the schema id, or nil if the
+// schema isn't used by exactly one subject matching a topic.
+func (t *HTTPHandler) identifyConsumer(r *http.Request, id string) (*schema.GetSchemaCmd, error) {
+	res, err := t.send(r.Context(), "GET", "/schemas/ids/"+url.PathEscape(id)+"/versions", r.Header, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []struct {
+		Subject string `json:"subject"`
+		Version int    `json:"version"`
+	}
+
+	err = json.Unmarshal(res.body, &versions)
+	if err != nil || res.status != http.StatusOK {
+		return nil, internal.Errorf(internal.RemoteError, "failed to retrieve the subjects using the schema %s", id)
+	}
+
+	var cmd *schema.GetSchemaCmd
+	for _, version := range versions {
+		found := t.identifier.Identify(r, version.Subject, "read")
+		if found == nil {
+			continue
+		}
+
+		if cmd != nil {
+			// The schema is shared by several topics: the consumed one is
+			// unknown.
+			logging.Debug(r.Context(), "the consumed topic of the schema is ambiguous", logging.Fields{"schema_id": id})
+			return nil, nil
+		}
+
+		found.Version = strconv.Itoa(version.Version)
+		cmd = found
+	}
+
+	return cmd, nil
+}
+
+// registeredSchema return the Avro schema of a registration body or of a
+// schema response. The other schema types and the invalid bodies give an empty
+// string, they are left to the Schema Registry.
+func registeredSchema(body []byte) string {
+	var req struct {
+		Schema     string `json:"schema"`
+		SchemaType string `json:"schemaType"`
+	}
+
+	err := json.Unmarshal(body, &req)
+	if err != nil || (req.SchemaType != "" && req.SchemaType != "AVRO") {
+		return ""
+	}
+
+	return req.Schema
+}
+
+// track register the client with the schema and the version of the lookup
+// response.
+func (t *HTTPHandler) track(r *http.Request, cmd *schema.GetSchemaCmd, schema string, lookup *response) error {
+	var found struct {
+		Version int `json:"version"`
+	}
+
+	err := json.Unmarshal(lookup.body, &found)
+	if err != nil || lookup.status != http.StatusOK || found.Version < 1 {
+		return internal.Errorf(internal.RemoteError, "failed to retrieve the version of the schema registered under %q", cmd.Subject)
+	}
+
+	cmd.Version = strconv.Itoa(found.Version)
+
+	err = t.usecase.RegisterClient(r.Context(), cmd, schema)
+	t.record(r, cmd, err)
+
+	return err
+}
+
+// record write the audit entry of a checked request.
+func (t *HTTPHandler) record(r *http.Request, cmd *schema.GetSchemaCmd, err error) {
+	entry := model.AuditEntry{
+		RemoteAddr:  r.RemoteAddr,
+		Topic:       cmd.Topic,
+		Application: cmd.Application,
+		Action:      cmd.Action,
+		Subject:     cmd.Subject,
+		Version:     cmd.Version,
+	}
+	entry.Decision, entry.Reasons = audit.DecisionOf(err)
+	if principal := auth.PrincipalFromContext(r.Context()); principal != nil {
+		entry.Caller = principal.String()
+	}
+
+	t.auditor.Record(r.Context(), entry)
+}
+
+// forward the request with an already read body.
+func (t *HTTPHandler) forward(w http.ResponseWriter, r *http.Request, body []byte) {
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+
+	t.proxy.ServeHTTP(w, r)
+}
+
+// direct rewrite a request for the Schema Registry.
+func (t *HTTPHandler) direct(req *http.Request) {
+	req.URL.Scheme = t.registryURL.Scheme
+	req.URL.Host = t.registryURL.Host
+	// The escaped path is kept for the subjects containing an escaped "/".
+	req.URL.RawPath = strings.TrimSuffix(t.registryURL.EscapedPath(), "/") + strings.TrimPrefix(req.URL.EscapedPath(), t.pathPrefix)
+	req.URL.Path = strings.TrimSuffix(t.registryURL.Path, "/") + strings.TrimPrefix(req.URL.Path, t.pathPrefix)
+	req.Host = t.registryURL.Host
+
+	// The credentials are the gateway ones, not the registry ones.
+	req.Header.Del("Authorization")
+	req.Header.Del(auth.APIKeyHeader)
+	if t.identifier.applicationHeader != "" {
+		req.Header.Del(t.identifier.applicationHeader)
+	}
+
+	tracing.Inject(req.Context(), req.Header)
+	if requestID := logging.RequestIDFromContext(req.Context()); requestID != "" {
+		req.Header.Set(logging.RequestIDHeader, requestID)
+	}
+}
+
+type response struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+func (t *response) write(w http.ResponseWriter, r *http.Request) {
+	for _, key := range []string{"Content-Type", "Cache-Control"} {
+		if value := t.header.Get(key); value != "" {
+			w.Header().Set(key, value)
+		}
+	}
+
+	w.WriteHeader(t.status)
+	_, err := w.Write(t.body)
+	if err != nil {
+		logging.Warn(r.Context(), "failed to write the response", logging.Fields{"error": err})
+	}
+}
+
+// lookupURI return the request URI of the lookup of a schema under the
+// subject, with the query of the registration (normalize, ...).
+func lookupURI(subject string, rawQuery string) string {
+	uri := "/subjects/" + url.PathEscape(subject)
+	if rawQuery != "" {
+		uri += "?" + rawQuery
+	}
+
+	return uri
+}
+
+// send a request to the Schema Registry and read the response. The uri is an
+// escaped path with its query.
+func (t *HTTPHandler) send(ctx context.Context, method string, uri string, header http.Header, body []byte) (*response, error) {
+	//nolint
+	// Error not possible
+	req, _ := http.NewRequest(method, uri, bytes.NewReader(body))
+	for _, key := range []string{"Content-Type", "Accept"} {
+		if value := header.Get(key); value != "" {
+			req.Header.Set(key, value)
+		}
+	}
+	req = req.WithContext(ctx)
+	t.direct(req)
+
+	res, err := t.client.Do(req)
+	if err != nil {
+		return nil, internal.NewError(internal.RemoteError, err.Error())
+	}
+	defer res.Body.Close()
+
+	resBody, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, internal.Errorf(internal.RemoteError, "failed to read the response body: %s", err)
+	}
+
+	return &response{status: res.StatusCode, header: res.Header, body: resBody}, nil
+}
+
+func readBody(r *http.Request) ([]byte, error) {
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxBodySize+1))
+	if err != nil {
+		return nil, internal.Errorf(internal.InvalidJSONBody, "failed to read the body: %s", err)
+	}
+
+	if len(body) > maxBodySize {
+		return nil, internal.Errorf(internal.ValidationError, "the body exceed %d bytes", maxBodySize)
+	}
+
+	return body, nil
+}
+
+// writeError write the error with the Schema Registry format, understood by
+// the serializers.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	status := internal.StatusCode(err)
+	// The subject conflicts are reported like the incompatible schemas.
+	if internal.IsKind(internal.BadRequest, err) {
+		status = http.StatusConflict
+	}
+
+	if status >= 500 {
+		logging.Error(r.Context(), "failed to proxy the request", logging.Fields{"error": err})
+	}
+
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(status)
+	err = json.NewEncoder(w).Encode(map[string]interface{}{
+		"error_code": status*100 + 1,
+		"message":    err.Error(),
+	})
+	if err != nil {
+		logging.Warn(r.Context(), "failed to write the response", logging.Fields{"error": err})
+	}
+}
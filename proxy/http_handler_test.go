@@ -0,0 +1,723 @@
+package proxy
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Peltoche/avro-gateway/audit"
+	"github.com/Peltoche/avro-gateway/auth"
+	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/Peltoche/avro-gateway/model"
+	"github.com/Peltoche/avro-gateway/ratelimit"
+	"github.com/Peltoche/avro-gateway/schema"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const someSchema = `{"schema": "{\"type\": \"string\"}"}`
+
+type registryCall struct {
+	method        string
+	path          string
+	body          string
+	authorization string
+	apiKey        string
+	application   string
+}
+
+// startRegistry start a fake Schema Registry answering with the responses
+// matching "<method> <path>" and recording the calls with their escaped path
+// and query.
+func startRegistry(t *testing.T, responses map[string]string) (*url.URL, *[]registryCall, func()) {
+	calls := []registryCall{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		calls = append(calls, registryCall{
+			method:        r.Method,
+			path:          r.RequestURI,
+			body:          string(body),
+			authorization: r.Header.Get("Authorization"),
+			apiKey:        r.Header.Get(auth.APIKeyHeader),
+			application:   r.Header.Get("X-Application"),
+		})
+
+		response, ok := responses[r.Method+" "+r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error_code": 40401, "message": "Subject not found."}`))
+			return
+		}
+
+		w.Header().Set("Content-Type", ContentType)
+		_, _ = w.Write([]byte(response))
+	}))
+
+	registryURL, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+
+	return registryURL, &calls, ts.Close
+}
+
+func withPrincipal(r *http.Request, application string) *http.Request {
+	return r.WithContext(auth.WithPrincipal(r.Context(), &auth.Principal{Application: application, Method: auth.APIKeyMethod}))
+}
+
+func Test_HTTPHandler_forward_success(t *testing.T) {
+	registryURL, calls, closeRegistry := startRegistry(t, map[string]string{
+		"GET /schemas/ids/1": someSchema,
+	})
+	defer closeRegistry()
+
+	handler := NewHTTPHandler(registryURL, "/ns/prod", NewIdentifier(TopicNameStrategy, ""), new(schema.UsecaseMock), new(audit.Mock), new(ratelimit.Mock))
+
+	r := httptest.NewRequest("GET", "http://example.com/ns/prod/schemas/ids/1", nil)
+	r.Header.Set("Authorization", "Bearer some-gateway-token")
+	r.Header.Set(auth.APIKeyHeader, "some-api-key")
+	w := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router.PathPrefix("/ns/prod").Subrouter())
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, ContentType, w.Header().Get("Content-Type"))
+	assert.JSONEq(t, someSchema, w.Body.String())
+	assert.Equal(t, []registryCall{{method: "GET", path: "/schemas/ids/1", body: ""}}, *calls)
+}
+
+func Test_HTTPHandler_forward_with_an_escaped_subject(t *testing.T) {
+	registryURL, calls, closeRegistry := startRegistry(t, map[string]string{
+		"GET /subjects/a/b/versions/1": someSchema,
+	})
+	defer closeRegistry()
+
+	handler := NewHTTPHandler(registryURL, "/ns/prod", NewIdentifier(TopicNameStrategy, ""), new(schema.UsecaseMock), new(audit.Mock), new(ratelimit.Mock))
+
+	r := httptest.NewRequest("GET", "http://example.com/ns/prod/subjects/a%2Fb/versions/1?deleted=true", nil)
+	w := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router.PathPrefix("/ns/prod").Subrouter())
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []registryCall{{method: "GET", path: "/subjects/a%2Fb/versions/1?deleted=true"}}, *calls)
+}
+
+func Test_HTTPHandler_forward_without_the_application_header(t *testing.T) {
+	registryURL, calls, closeRegistry := startRegistry(t, map[string]string{
+		"GET /config": `{"compatibilityLevel": "BACKWARD"}`,
+	})
+	defer closeRegistry()
+
+	handler := NewHTTPHandler(registryURL, "", NewIdentifier(TopicNameStrategy, "X-Application"), new(schema.UsecaseMock), new(audit.Mock), new(ratelimit.Mock))
+
+	r := httptest.NewRequest("GET", "http://example.com/config", nil)
+	r.Header.Set("X-Application", "some-app")
+	w := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []registryCall{{method: "GET", path: "/config"}}, *calls)
+}
+
+func Test_HTTPHandler_forward_with_an_unreachable_registry(t *testing.T) {
+	registryURL, err := url.Parse("http://127.0.0.1:1")
+	require.NoError(t, err)
+
+	handler := NewHTTPHandler(registryURL, "", NewIdentifier(TopicNameStrategy, ""), new(schema.UsecaseMock), new(audit.Mock), new(ratelimit.Mock))
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com/subjects", nil)
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusBadGateway, w.Code)
+	assert.Equal(t, ContentType, w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), `"error_code":50201`)
+}
+
+func Test_HTTPHandler_PostVersion_anonymous(t *testing.T) {
+	registryURL, calls, closeRegistry := startRegistry(t, map[string]string{
+		"POST /subjects/some-topic-value/versions": `{"id": 1}`,
+	})
+	defer closeRegistry()
+
+	usecaseMock := new(schema.UsecaseMock)
+	auditMock := new(audit.Mock)
+	handler := NewHTTPHandler(registryURL, "", NewIdentifier(TopicNameStrategy, ""), usecaseMock, auditMock, new(ratelimit.Mock))
+
+	auditMock.On("Record", model.AuditEntry{
+		RemoteAddr: "192.0.2.1:1234",
+		Topic:      "some-topic",
+		Action:     "write",
+		Subject:    "some-topic-value",
+		Decision:   model.AuditRefused,
+		Reasons:    []string{`unauthorized: the producers of the topic "some-topic" must be identified`},
+	}).Once()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "http://example.com/subjects/some-topic-value/versions", strings.NewReader(someSchema))
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), `"error_code":40101`)
+	assert.Empty(t, *calls)
+	usecaseMock.AssertExpectations(t)
+	auditMock.AssertExpectations(t)
+}
+
+func Test_HTTPHandler_PostVersion_anonymous_on_a_key_subject(t *testing.T) {
+	registryURL, calls, closeRegistry := startRegistry(t, map[string]string{
+		"POST /subjects/some-topic-key/versions": `{"id": 1}`,
+	})
+	defer closeRegistry()
+
+	usecaseMock := new(schema.UsecaseMock)
+	handler := NewHTTPHandler(registryURL, "", NewIdentifier(TopicNameStrategy, ""), usecaseMock, new(audit.Mock), new(ratelimit.Mock))
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "http://example.com/subjects/some-topic-key/versions", strings.NewReader(someSchema))
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	// The key subjects are not the one of a topic: they are forwarded.
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"id": 1}`, w.Body.String())
+	assert.Equal(t, []registryCall{{method: "POST", path: "/subjects/some-topic-key/versions", body: someSchema}}, *calls)
+	usecaseMock.AssertExpectations(t)
+}
+
+func Test_HTTPHandler_PostVersion_with_two_record_types_on_a_topic(t *testing.T) {
+	registryURL, calls, closeRegistry := startRegistry(t, map[string]string{
+		"POST /subjects/some-topic-com.example.Created/versions": `{"id": 1}`,
+		"POST /subjects/some-topic-com.example.Deleted/versions": `{"id": 2}`,
+	})
+	defer closeRegistry()
+
+	usecaseMock := new(schema.UsecaseMock)
+	handler := NewHTTPHandler(registryURL, "", NewIdentifier(TopicNameStrategy, ""), usecaseMock, new(audit.Mock), new(ratelimit.Mock))
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+
+	for _, subject := range []string{"some-topic-com.example.Created", "some-topic-com.example.Deleted"} {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "http://example.com/subjects/"+subject+"/versions", strings.NewReader(someSchema))
+		router.ServeHTTP(w, withPrincipal(r, "my-application"))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+
+	// The record subjects are not the one of a topic: none of them is refused
+	// because of the other.
+	assert.Equal(t, []registryCall{
+		{method: "POST", path: "/subjects/some-topic-com.example.Created/versions", body: someSchema},
+		{method: "POST", path: "/subjects/some-topic-com.example.Deleted/versions", body: someSchema},
+	}, *calls)
+	usecaseMock.AssertExpectations(t)
+}
+
+func Test_HTTPHandler_PostVersion_throttled(t *testing.T) {
+	registryURL, calls, closeRegistry := startRegistry(t, map[string]string{})
+	defer closeRegistry()
+
+	usecaseMock := new(schema.UsecaseMock)
+	auditMock := new(audit.Mock)
+	limiterMock := new(ratelimit.Mock)
+	handler := NewHTTPHandler(registryURL, "", NewIdentifier(TopicNameStrategy, ""), usecaseMock, auditMock, limiterMock)
+
+	limiterMock.On("Allow", "my-application", "192.0.2.1:1234").Return(1500*time.Millisecond, false).Once()
+	auditMock.On("Record", model.AuditEntry{
+		RemoteAddr:  "192.0.2.1:1234",
+		Caller:      "api_key:my-application",
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "write",
+		Subject:     "some-topic-value",
+		Decision:    model.AuditRefused,
+		Reasons:     []string{`too many requests: rate limit exceeded for the application "my-application"`},
+	}).Once()
+
+	r := httptest.NewRequest("POST", "http://example.com/subjects/some-topic-value/versions", strings.NewReader(someSchema))
+	w := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, withPrincipal(r, "my-application"))
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), `"error_code":42901`)
+	assert.Empty(t, *calls)
+
+	usecaseMock.AssertExpectations(t)
+	limiterMock.AssertExpectations(t)
+	auditMock.AssertExpectations(t)
+}
+
+func Test_HTTPHandler_PostVersion_success(t *testing.T) {
+	registryURL, calls, closeRegistry := startRegistry(t, map[string]string{
+		"POST /subjects/some-topic-value/versions": `{"id": 1}`,
+		"POST /subjects/some-topic-value":          `{"subject": "some-topic-value", "id": 1, "version": 3, "schema": "\"string\""}`,
+	})
+	defer closeRegistry()
+
+	usecaseMock := new(schema.UsecaseMock)
+	auditMock := new(audit.Mock)
+	limiterMock := new(ratelimit.Mock)
+	handler := NewHTTPHandler(registryURL, "", NewIdentifier(TopicNameStrategy, ""), usecaseMock, auditMock, limiterMock)
+
+	limiterMock.On("Allow", "my-application", "192.0.2.1:1234").Return(time.Duration(0), true).Once()
+	usecaseMock.On("CheckClient", &schema.GetSchemaCmd{
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "write",
+		Subject:     "some-topic-value",
+	}).Return(nil).Once()
+	usecaseMock.On("LintSchema", &schema.GetSchemaCmd{
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "write",
+		Subject:     "some-topic-value",
+	}, `{"type": "string"}`).Return(nil).Once()
+	usecaseMock.On("RegisterClient", &schema.GetSchemaCmd{
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "write",
+		Subject:     "some-topic-value",
+		Version:     "3",
+	}, `{"type": "string"}`).Return(nil).Once()
+	auditMock.On("Record", model.AuditEntry{
+		RemoteAddr:  "192.0.2.1:1234",
+		Caller:      "api_key:my-application",
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "write",
+		Subject:     "some-topic-value",
+		Version:     "3",
+		Decision:    model.AuditAccepted,
+	}).Once()
+
+	r := httptest.NewRequest("POST", "http://example.com/subjects/some-topic-value/versions?normalize=true", strings.NewReader(someSchema))
+	w := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, withPrincipal(r, "my-application"))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"id": 1}`, w.Body.String())
+	assert.Equal(t, []registryCall{
+		{method: "POST", path: "/subjects/some-topic-value/versions?normalize=true", body: someSchema},
+		{method: "POST", path: "/subjects/some-topic-value?normalize=true", body: someSchema},
+	}, *calls)
+
+	usecaseMock.AssertExpectations(t)
+	limiterMock.AssertExpectations(t)
+	auditMock.AssertExpectations(t)
+}
+
+func Test_HTTPHandler_PostVersion_with_a_tracking_error(t *testing.T) {
+	registryURL, calls, closeRegistry := startRegistry(t, map[string]string{
+		"POST /subjects/some-topic-value/versions": `{"id": 1}`,
+		"POST /subjects/some-topic-value":          `{"subject": "some-topic-value", "id": 1, "version": 3, "schema": "\"string\""}`,
+	})
+	defer closeRegistry()
+
+	usecaseMock := new(schema.UsecaseMock)
+	auditMock := new(audit.Mock)
+	limiterMock := new(ratelimit.Mock)
+	handler := NewHTTPHandler(registryURL, "", NewIdentifier(TopicNameStrategy, ""), usecaseMock, auditMock, limiterMock)
+
+	cmd := &schema.GetSchemaCmd{
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "write",
+		Subject:     "some-topic-value",
+	}
+	someErr := internal.NewError(internal.InternalError, "some-error")
+	limiterMock.On("Allow", "my-application", "192.0.2.1:1234").Return(time.Duration(0), true).Once()
+	usecaseMock.On("CheckClient", cmd).Return(nil).Once()
+	usecaseMock.On("LintSchema", cmd, `{"type": "string"}`).Return(nil).Once()
+	usecaseMock.On("RegisterClient", &schema.GetSchemaCmd{
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "write",
+		Subject:     "some-topic-value",
+		Version:     "3",
+	}, `{"type": "string"}`).Return(someErr).Once()
+	auditMock.On("Record", model.AuditEntry{
+		RemoteAddr:  "192.0.2.1:1234",
+		Caller:      "api_key:my-application",
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "write",
+		Subject:     "some-topic-value",
+		Version:     "3",
+		Decision:    model.AuditError,
+		Reasons:     []string{someErr.Error()},
+	}).Once()
+
+	r := httptest.NewRequest("POST", "http://example.com/subjects/some-topic-value/versions", strings.NewReader(someSchema))
+	w := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, withPrincipal(r, "my-application"))
+
+	// The schema is registered: the registry response is returned anyway.
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"id": 1}`, w.Body.String())
+	assert.Len(t, *calls, 2)
+
+	usecaseMock.AssertExpectations(t)
+	limiterMock.AssertExpectations(t)
+	auditMock.AssertExpectations(t)
+}
+
+func Test_HTTPHandler_PostVersion_refused(t *testing.T) {
+	registryURL, calls, closeRegistry := startRegistry(t, map[string]string{})
+	defer closeRegistry()
+
+	usecaseMock := new(schema.UsecaseMock)
+	auditMock := new(audit.Mock)
+	limiterMock := new(ratelimit.Mock)
+	handler := NewHTTPHandler(registryURL, "", NewIdentifier(TopicNameStrategy, ""), usecaseMock, auditMock, limiterMock)
+
+	limiterMock.On("Allow", "my-application", "192.0.2.1:1234").Return(time.Duration(0), true).Once()
+	refusal := internal.NewError(internal.BadRequest, `invalid subject: you can't use the subject "some-topic-value" because the application "other" use the schema "other-subject/1"`)
+	usecaseMock.On("CheckClient", &schema.GetSchemaCmd{
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "write",
+		Subject:     "some-topic-value",
+	}).Return(refusal).Once()
+	auditMock.On("Record", model.AuditEntry{
+		RemoteAddr:  "192.0.2.1:1234",
+		Caller:      "api_key:my-application",
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "write",
+		Subject:     "some-topic-value",
+		Decision:    model.AuditRefused,
+		Reasons:     []string{refusal.Error()},
+	}).Once()
+
+	r := httptest.NewRequest("POST", "http://example.com/subjects/some-topic-value/versions", strings.NewReader(someSchema))
+	w := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, withPrincipal(r, "my-application"))
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, ContentType, w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"error_code": 40901,
+		"message": "bad request: invalid subject: you can't use the subject \"some-topic-value\" because the application \"other\" use the schema \"other-subject/1\""
+	}`, w.Body.String())
+	assert.Empty(t, *calls)
+
+	usecaseMock.AssertExpectations(t)
+	limiterMock.AssertExpectations(t)
+	auditMock.AssertExpectations(t)
+}
+
+func Test_HTTPHandler_PostVersion_with_a_lint_error(t *testing.T) {
+	registryURL, calls, closeRegistry := startRegistry(t, map[string]string{})
+	defer closeRegistry()
+
+	usecaseMock := new(schema.UsecaseMock)
+	auditMock := new(audit.Mock)
+	limiterMock := new(ratelimit.Mock)
+	handler := NewHTTPHandler(registryURL, "", NewIdentifier(TopicNameStrategy, ""), usecaseMock, auditMock, limiterMock)
+
+	limiterMock.On("Allow", "my-application", "192.0.2.1:1234").Return(time.Duration(0), true).Once()
+	cmd := &schema.GetSchemaCmd{
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "write",
+		Subject:     "some-topic-value",
+	}
+	refusal := internal.NewError(internal.ValidationError, "the schema violates the lint rules: $: the record must have a doc (require_doc)")
+	usecaseMock.On("CheckClient", cmd).Return(nil).Once()
+	usecaseMock.On("LintSchema", cmd, `{"type": "string"}`).Return(refusal).Once()
+	auditMock.On("Record", model.AuditEntry{
+		RemoteAddr:  "192.0.2.1:1234",
+		Caller:      "api_key:my-application",
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "write",
+		Subject:     "some-topic-value",
+		Decision:    model.AuditRefused,
+		Reasons:     []string{refusal.Error()},
+	}).Once()
+
+	r := httptest.NewRequest("POST", "http://example.com/subjects/some-topic-value/versions", strings.NewReader(someSchema))
+	w := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, withPrincipal(r, "my-application"))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), `"error_code":42201`)
+	assert.Empty(t, *calls)
+
+	usecaseMock.AssertExpectations(t)
+	limiterMock.AssertExpectations(t)
+	auditMock.AssertExpectations(t)
+}
+
+func Test_registeredSchema(t *testing.T) {
+	assert.Equal(t, `"string"`, registeredSchema([]byte(`{"schema": "\"string\""}`)))
+	assert.Equal(t, `"string"`, registeredSchema([]byte(`{"schema": "\"string\"", "schemaType": "AVRO"}`)))
+	assert.Equal(t, "", registeredSchema([]byte(`{"schema": "syntax = \"proto3\";", "schemaType": "PROTOBUF"}`)))
+	assert.Equal(t, "", registeredSchema([]byte(`invalid`)))
+}
+
+func Test_HTTPHandler_PostVersion_with_a_registry_error(t *testing.T) {
+	registryURL, calls, closeRegistry := startRegistry(t, map[string]string{})
+	defer closeRegistry()
+
+	usecaseMock := new(schema.UsecaseMock)
+	limiterMock := new(ratelimit.Mock)
+	handler := NewHTTPHandler(registryURL, "", NewIdentifier(TopicNameStrategy, ""), usecaseMock, new(audit.Mock), limiterMock)
+
+	limiterMock.On("Allow", "my-application", "192.0.2.1:1234").Return(time.Duration(0), true).Once()
+	usecaseMock.On("CheckClient", &schema.GetSchemaCmd{
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "write",
+		Subject:     "some-topic-value",
+	}).Return(nil).Once()
+	usecaseMock.On("LintSchema", &schema.GetSchemaCmd{
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "write",
+		Subject:     "some-topic-value",
+	}, `{"type": "string"}`).Return(nil).Once()
+
+	r := httptest.NewRequest("POST", "http://example.com/subjects/some-topic-value/versions", strings.NewReader(someSchema))
+	w := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, withPrincipal(r, "my-application"))
+
+	// The registry response is forwarded and nothing is recorded.
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"error_code": 40401, "message": "Subject not found."}`, w.Body.String())
+	assert.Len(t, *calls, 1)
+
+	usecaseMock.AssertExpectations(t)
+	limiterMock.AssertExpectations(t)
+}
+
+func Test_HTTPHandler_PostLookup_success(t *testing.T) {
+	registryURL, calls, closeRegistry := startRegistry(t, map[string]string{
+		"POST /subjects/some/topic-value": `{"subject": "some/topic-value", "id": 4, "version": 2, "schema": "\"string\""}`,
+	})
+	defer closeRegistry()
+
+	usecaseMock := new(schema.UsecaseMock)
+	auditMock := new(audit.Mock)
+	limiterMock := new(ratelimit.Mock)
+	handler := NewHTTPHandler(registryURL, "/ns/prod", NewIdentifier(TopicNameStrategy, ""), usecaseMock, auditMock, limiterMock)
+
+	limiterMock.On("Allow", "my-application", "192.0.2.1:1234").Return(time.Duration(0), true).Once()
+	usecaseMock.On("RegisterClient", &schema.GetSchemaCmd{
+		Topic:       "some/topic",
+		Application: "my-application",
+		Action:      "write",
+		Subject:     "some/topic-value",
+		Version:     "2",
+	}, `{"type": "string"}`).Return(nil).Once()
+	auditMock.On("Record", model.AuditEntry{
+		RemoteAddr:  "192.0.2.1:1234",
+		Caller:      "api_key:my-application",
+		Topic:       "some/topic",
+		Application: "my-application",
+		Action:      "write",
+		Subject:     "some/topic-value",
+		Version:     "2",
+		Decision:    model.AuditAccepted,
+	}).Once()
+
+	r := httptest.NewRequest("POST", "http://example.com/ns/prod/subjects/some%2Ftopic-value?deleted=true", strings.NewReader(someSchema))
+	w := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router.PathPrefix("/ns/prod").Subrouter())
+	router.ServeHTTP(w, withPrincipal(r, "my-application"))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"subject": "some/topic-value", "id": 4, "version": 2, "schema": "\"string\""}`, w.Body.String())
+	assert.Equal(t, []registryCall{{method: "POST", path: "/subjects/some%2Ftopic-value?deleted=true", body: someSchema}}, *calls)
+
+	usecaseMock.AssertExpectations(t)
+	limiterMock.AssertExpectations(t)
+	auditMock.AssertExpectations(t)
+}
+
+func Test_HTTPHandler_GetSchemaByID_success(t *testing.T) {
+	registryURL, calls, closeRegistry := startRegistry(t, map[string]string{
+		"GET /schemas/ids/4":          someSchema,
+		"GET /schemas/ids/4/versions": `[{"subject": "some-topic-key", "version": 1}, {"subject": "some-topic-value", "version": 2}]`,
+	})
+	defer closeRegistry()
+
+	usecaseMock := new(schema.UsecaseMock)
+	auditMock := new(audit.Mock)
+	limiterMock := new(ratelimit.Mock)
+	handler := NewHTTPHandler(registryURL, "", NewIdentifier(TopicNameStrategy, "X-Application"), usecaseMock, auditMock, limiterMock)
+
+	limiterMock.On("Allow", "my-consumer", "192.0.2.1:1234").Return(time.Duration(0), true).Once()
+	usecaseMock.On("RegisterClient", &schema.GetSchemaCmd{
+		Topic:       "some-topic",
+		Application: "my-consumer",
+		Action:      "read",
+		Subject:     "some-topic-value",
+		Version:     "2",
+	}, `{"type": "string"}`).Return(nil).Once()
+	auditMock.On("Record", model.AuditEntry{
+		RemoteAddr:  "192.0.2.1:1234",
+		Topic:       "some-topic",
+		Application: "my-consumer",
+		Action:      "read",
+		Subject:     "some-topic-value",
+		Version:     "2",
+		Decision:    model.AuditAccepted,
+	}).Once()
+
+	r := httptest.NewRequest("GET", "http://example.com/schemas/ids/4", nil)
+	r.Header.Set("X-Application", "my-consumer")
+	w := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, someSchema, w.Body.String())
+	assert.Len(t, *calls, 2)
+
+	usecaseMock.AssertExpectations(t)
+	limiterMock.AssertExpectations(t)
+	auditMock.AssertExpectations(t)
+}
+
+func Test_HTTPHandler_GetSchemaByID_anonymous(t *testing.T) {
+	registryURL, calls, closeRegistry := startRegistry(t, map[string]string{
+		"GET /schemas/ids/4": someSchema,
+	})
+	defer closeRegistry()
+
+	// The application header isn't configured.
+	handler := NewHTTPHandler(registryURL, "", NewIdentifier(TopicNameStrategy, ""), new(schema.UsecaseMock), new(audit.Mock), new(ratelimit.Mock))
+
+	r := httptest.NewRequest("GET", "http://example.com/schemas/ids/4", nil)
+	r.Header.Set("X-Application", "my-consumer")
+	w := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, someSchema, w.Body.String())
+	assert.Equal(t, []registryCall{{method: "GET", path: "/schemas/ids/4", application: "my-consumer"}}, *calls)
+}
+
+func Test_HTTPHandler_GetSchemaByID_with_a_shared_schema(t *testing.T) {
+	registryURL, _, closeRegistry := startRegistry(t, map[string]string{
+		"GET /schemas/ids/4":          someSchema,
+		"GET /schemas/ids/4/versions": `[{"subject": "some-topic-value", "version": 2}, {"subject": "other-topic-value", "version": 1}]`,
+	})
+	defer closeRegistry()
+
+	usecaseMock := new(schema.UsecaseMock)
+	limiterMock := new(ratelimit.Mock)
+	handler := NewHTTPHandler(registryURL, "", NewIdentifier(TopicNameStrategy, ""), usecaseMock, new(audit.Mock), limiterMock)
+
+	limiterMock.On("Allow", "my-consumer", "192.0.2.1:1234").Return(time.Duration(0), true).Once()
+	r := httptest.NewRequest("GET", "http://example.com/schemas/ids/4", nil)
+	w := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, withPrincipal(r, "my-consumer"))
+
+	// The consumed topic is unknown, nothing is recorded.
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, someSchema, w.Body.String())
+	usecaseMock.AssertExpectations(t)
+	limiterMock.AssertExpectations(t)
+}
+
+func Test_HTTPHandler_GetSchemaByID_refused(t *testing.T) {
+	registryURL, _, closeRegistry := startRegistry(t, map[string]string{
+		"GET /schemas/ids/4":          someSchema,
+		"GET /schemas/ids/4/versions": `[{"subject": "some-topic-value", "version": 2}]`,
+	})
+	defer closeRegistry()
+
+	usecaseMock := new(schema.UsecaseMock)
+	auditMock := new(audit.Mock)
+	limiterMock := new(ratelimit.Mock)
+	handler := NewHTTPHandler(registryURL, "", NewIdentifier(TopicNameStrategy, ""), usecaseMock, auditMock, limiterMock)
+
+	limiterMock.On("Allow", "my-consumer", "192.0.2.1:1234").Return(time.Duration(0), true).Once()
+	refusal := internal.NewError(internal.Forbidden, `the application "my-consumer" is not allowed to read the topic "some-topic"`)
+	usecaseMock.On("RegisterClient", &schema.GetSchemaCmd{
+		Topic:       "some-topic",
+		Application: "my-consumer",
+		Action:      "read",
+		Subject:     "some-topic-value",
+		Version:     "2",
+	}, `{"type": "string"}`).Return(refusal).Once()
+	auditMock.On("Record", model.AuditEntry{
+		RemoteAddr:  "192.0.2.1:1234",
+		Caller:      "api_key:my-consumer",
+		Topic:       "some-topic",
+		Application: "my-consumer",
+		Action:      "read",
+		Subject:     "some-topic-value",
+		Version:     "2",
+		Decision:    model.AuditRefused,
+		Reasons:     []string{refusal.Error()},
+	}).Once()
+
+	r := httptest.NewRequest("GET", "http://example.com/schemas/ids/4", nil)
+	w := httptest.NewRecorder()
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, withPrincipal(r, "my-consumer"))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), `"error_code":40301`)
+
+	usecaseMock.AssertExpectations(t)
+	limiterMock.AssertExpectations(t)
+	auditMock.AssertExpectations(t)
+}
@@ -0,0 +1,98 @@
+package proxy
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Peltoche/avro-gateway/auth"
+	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/Peltoche/avro-gateway/schema"
+)
+
+// NamingStrategy is the strategy used by the serializers to name the subject
+// of a topic.
+type NamingStrategy string
+
+// TopicNameStrategy use the subject "<topic>-value". It's the serializers
+// default.
+//
+// The strategies mapping several subjects to a topic, like the
+// TopicRecordNameStrategy ("<topic>-<record full name>"), are not supported:
+// the clients of a topic must all use the same subject.
+const TopicNameStrategy NamingStrategy = "topic"
+
+// ParseNamingStrategy return the NamingStrategy matching the name.
+func ParseNamingStrategy(name string) (NamingStrategy, error) {
+	switch NamingStrategy(name) {
+	case TopicNameStrategy:
+		return NamingStrategy(name), nil
+	default:
+		return "", internal.Errorf(internal.ValidationError, "unknown naming strategy %q: expected %q", name, TopicNameStrategy)
+	}
+}
+
+// Topic return the topic of a subject, or an empty string if the subject
+// doesn't follow the strategy.
+//
+// Only the value subjects are mapped to their topic: the key subjects are
+// forwarded without being tracked as a topic is tracked with a single subject.
+func (t NamingStrategy) Topic(subject string) string {
+	if t == TopicNameStrategy && strings.HasSuffix(subject, "-value") {
+		return strings.TrimSuffix(subject, "-value")
+	}
+
+	return ""
+}
+
+// Identifier deduce the client behind a proxied request as the serializers
+// never send any topic, application or action.
+type Identifier struct {
+	strategy          NamingStrategy
+	applicationHeader string
+}
+
+// NewIdentifier instantiate a new Identifier. The application is taken from
+// the credentials or, for the anonymous requests, from the applicationHeader
+// if set.
+func NewIdentifier(strategy NamingStrategy, applicationHeader string) *Identifier {
+	return &Identifier{
+		strategy:          strategy,
+		applicationHeader: applicationHeader,
+	}
+}
+
+// Identify return the client using the subject, or nil if it can't be
+// identified. The HTTPHandler forwards the unidentified reads without any check
+// and refuses the unidentified writes on a topic.
+func (t *Identifier) Identify(r *http.Request, subject string, action string) *schema.GetSchemaCmd {
+	application := t.application(r)
+	if application == "" {
+		return nil
+	}
+
+	topic := t.strategy.Topic(subject)
+	if topic == "" {
+		return nil
+	}
+
+	return &schema.GetSchemaCmd{
+		Topic:       topic,
+		Application: application,
+		Action:      action,
+		Subject:     subject,
+	}
+}
+
+// application return the application of the caller or an empty string.
+func (t *Identifier) application(r *http.Request) string {
+	principal := auth.PrincipalFromContext(r.Context())
+	if principal != nil {
+		return principal.Application
+	}
+
+	if t.applicationHeader == "" {
+		return ""
+	}
+
+	return strings.TrimSpace(r.Header.Get(t.applicationHeader))
+}
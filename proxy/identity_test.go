@@ -0,0 +1,78 @@
+package proxy
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Peltoche/avro-gateway/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseNamingStrategy_success(t *testing.T) {
+	strategy, err := ParseNamingStrategy("topic")
+
+	assert.NoError(t, err)
+	assert.Equal(t, TopicNameStrategy, strategy)
+}
+
+func Test_ParseNamingStrategy_with_an_unknown_strategy(t *testing.T) {
+	strategy, err := ParseNamingStrategy("topic_record")
+
+	assert.EqualError(t, err, `validation error: unknown naming strategy "topic_record": expected "topic"`)
+	assert.Empty(t, strategy)
+}
+
+func Test_NamingStrategy_Topic(t *testing.T) {
+	assert.Equal(t, "some-topic", TopicNameStrategy.Topic("some-topic-value"))
+	assert.Equal(t, "", TopicNameStrategy.Topic("some-topic-key"))
+	assert.Equal(t, "", TopicNameStrategy.Topic("com.example.Person"))
+	assert.Equal(t, "", TopicNameStrategy.Topic("some-topic-com.example.Person"))
+}
+
+func Test_Identifier_Identify_with_a_principal(t *testing.T) {
+	identifier := NewIdentifier(TopicNameStrategy, "X-Application")
+
+	r := httptest.NewRequest("POST", "http://example.com/subjects/some-topic-value/versions", nil)
+	// The credentials take precedence over the header.
+	r.Header.Set("X-Application", "other-application")
+
+	cmd := identifier.Identify(withPrincipal(r, "my-application"), "some-topic-value", "write")
+
+	require.NotNil(t, cmd)
+	assert.Equal(t, &schema.GetSchemaCmd{
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "write",
+		Subject:     "some-topic-value",
+	}, cmd)
+}
+
+func Test_Identifier_Identify_with_the_application_header(t *testing.T) {
+	identifier := NewIdentifier(TopicNameStrategy, "X-Application")
+
+	r := httptest.NewRequest("POST", "http://example.com/subjects/some-topic-value/versions", nil)
+	r.Header.Set("X-Application", " my-application ")
+
+	cmd := identifier.Identify(r, "some-topic-value", "write")
+
+	require.NotNil(t, cmd)
+	assert.Equal(t, "my-application", cmd.Application)
+}
+
+func Test_Identifier_Identify_anonymous(t *testing.T) {
+	identifier := NewIdentifier(TopicNameStrategy, "")
+
+	r := httptest.NewRequest("POST", "http://example.com/subjects/some-topic-value/versions", nil)
+	r.Header.Set("X-Application", "my-application")
+
+	assert.Nil(t, identifier.Identify(r, "some-topic-value", "write"))
+}
+
+func Test_Identifier_Identify_with_an_unknown_subject(t *testing.T) {
+	identifier := NewIdentifier(TopicNameStrategy, "")
+
+	r := httptest.NewRequest("POST", "http://example.com/subjects/com.example.Person/versions", nil)
+
+	assert.Nil(t, identifier.Identify(withPrincipal(r, "my-application"), "com.example.Person", "write"))
+}
@@ -0,0 +1,76 @@
+package ratelimit
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/Peltoche/avro-gateway/internal"
+)
+
+// Limit is a token bucket configuration.
+type Limit struct {
+	// Rate is the number of requests per second added to the bucket.
+	Rate float64 `json:"rate"`
+	// Burst is the bucket size. Default to the rate rounded up, with a
+	// minimum of 1.
+	Burst int `json:"burst"`
+}
+
+// Config is the rate limiting configuration.
+type Config struct {
+	// Default is the limit used for the applications without a specific
+	// limit. They are unlimited if nil.
+	Default *Limit `json:"default"`
+	// Applications contains the limits for some specific applications.
+	Applications map[string]Limit `json:"applications"`
+	// PerIP use a bucket per application and source IP instead of a bucket
+	// per application.
+	PerIP bool `json:"per_ip"`
+}
+
+// LoadConfig read the rate limiting configuration from a JSON file.
+func LoadConfig(path string) (*Config, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, internal.Errorf(internal.InternalError, "failed to open the rate limit config: %s", err)
+	}
+	defer file.Close()
+
+	var config Config
+	err = json.NewDecoder(file).Decode(&config)
+	if err != nil {
+		return nil, internal.Errorf(internal.ValidationError, "failed to decode the rate limit config: %s", err)
+	}
+
+	return &config, nil
+}
+
+func validateConfig(config *Config) error {
+	if config.Default != nil {
+		err := validateLimit(config.Default)
+		if err != nil {
+			return internal.Wrap(err, "default limit")
+		}
+	}
+
+	for application, limit := range config.Applications {
+		err := validateLimit(&limit)
+		if err != nil {
+			return internal.Wrapf(err, "limit for %q", application)
+		}
+	}
+
+	return nil
+}
+
+func validateLimit(limit *Limit) error {
+	if limit.Rate <= 0 {
+		return internal.NewError(internal.ValidationError, `invalid input for field "rate"`)
+	}
+
+	if limit.Burst < 0 {
+		return internal.NewError(internal.ValidationError, `invalid input for field "burst"`)
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package ratelimit
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_LoadConfig_success(t *testing.T) {
+	file, err := ioutil.TempFile("", "ratelimit")
+	require.NoError(t, err)
+	defer os.Remove(file.Name())
+
+	_, err = file.WriteString(`{
+		"default": {"rate": 10, "burst": 20},
+		"applications": {"some-app": {"rate": 1}},
+		"per_ip": true
+	}`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	config, err := LoadConfig(file.Name())
+
+	assert.NoError(t, err)
+	assert.Equal(t, &Config{
+		Default:      &Limit{Rate: 10, Burst: 20},
+		Applications: map[string]Limit{"some-app": {Rate: 1}},
+		PerIP:        true,
+	}, config)
+}
+
+func Test_LoadConfig_with_a_missing_file(t *testing.T) {
+	config, err := LoadConfig("/some/unknown/path")
+
+	assert.Nil(t, config)
+	assert.EqualError(t, err, "internal error: failed to open the rate limit config: open /some/unknown/path: no such file or directory")
+}
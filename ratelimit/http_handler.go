@@ -0,0 +1,52 @@
+package ratelimit
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Peltoche/avro-gateway/auth"
+	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/Peltoche/avro-gateway/logging"
+	"github.com/gorilla/mux"
+)
+
+// HTTPHandler exposing the rate limiting counters to the admins.
+type HTTPHandler struct {
+	limiter limiter
+	// admins is the list of applications allowed to read the counters. No
+	// one can read them if empty.
+	admins []string
+}
+
+type limiter interface {
+	Stats() []Stat
+}
+
+// NewHTTPHandler instantiate a new HTTPHandler.
+func NewHTTPHandler(limiter limiter, admins []string) *HTTPHandler {
+	return &HTTPHandler{
+		limiter: limiter,
+		admins:  admins,
+	}
+}
+
+// RegisterRoutes into the givem mux.Router.
+func (t *HTTPHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/ratelimit/stats", t.GetStats).Methods("GET")
+}
+
+// GetStats /ratelimit/stats
+func (t *HTTPHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	err := auth.CheckAdmin(r.Context(), t.admins, "rate limit stats API")
+	if err != nil {
+		internal.WriteErrorIntoResponse(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(t.limiter.Stats())
+	if err != nil {
+		logging.Warn(r.Context(), "failed to write the response", logging.Fields{"error": err})
+	}
+}
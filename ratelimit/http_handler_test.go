@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Peltoche/avro-gateway/auth"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_HTTPHandler_GetStats_success(t *testing.T) {
+	limiterMock := new(Mock)
+
+	handler := NewHTTPHandler(limiterMock, []string{"admin-app"})
+
+	limiterMock.On("Stats").Return([]Stat{{Application: "some-app", Allowed: 3, Throttled: 1}}).Once()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com/ratelimit/stats", nil)
+	r = r.WithContext(auth.WithPrincipal(context.Background(), &auth.Principal{Application: "admin-app", Method: auth.APIKeyMethod}))
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	res := w.Result()
+	body, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.JSONEq(t, `[{"application": "some-app", "allowed": 3, "throttled": 1}]`, string(body))
+
+	limiterMock.AssertExpectations(t)
+}
+
+func Test_HTTPHandler_GetStats_with_a_non_admin_caller(t *testing.T) {
+	limiterMock := new(Mock)
+
+	handler := NewHTTPHandler(limiterMock, []string{"admin-app"})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com/ratelimit/stats", nil)
+	r = r.WithContext(auth.WithPrincipal(context.Background(), &auth.Principal{Application: "some-app", Method: auth.APIKeyMethod}))
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	res := w.Result()
+
+	assert.Equal(t, http.StatusForbidden, res.StatusCode)
+
+	limiterMock.AssertExpectations(t)
+}
+
+func Test_HTTPHandler_GetStats_with_an_anonymous_caller(t *testing.T) {
+	limiterMock := new(Mock)
+
+	handler := NewHTTPHandler(limiterMock, []string{"admin-app"})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com/ratelimit/stats", nil)
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	res := w.Result()
+
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+	limiterMock.AssertExpectations(t)
+}
@@ -0,0 +1,213 @@
+package ratelimit
+
+import (
+	"math"
+	"net"
+	"sort"
+	"sync"
+	"time"
+)
+
+// sweepInterval is the minimum interval between two removals of the unused
+// buckets and counters.
+const sweepInterval = time.Minute
+
+// statTTL is the duration after which the counters of an idle application
+// are removed.
+const statTTL = time.Hour
+
+// OtherApplications is the Stat application of the requests made by the
+// applications without a specific limit. They are counted together as their
+// names are given by the callers: the number of counters stays bounded.
+const OtherApplications = "*"
+
+// Limiter throttle the requests with a token bucket per namespace and
+// application (and source IP if configured).
+type Limiter struct {
+	config *Config
+
+	mutex     *sync.Mutex
+	buckets   map[bucketKey]*bucket
+	stats     map[string]*stat
+	lastSweep time.Time
+
+	// Set the time function as an attribute in order to be able to mock it.
+	now func() time.Time
+}
+
+// Stat is the requests counters of an application.
+type Stat struct {
+	Application string `json:"application"`
+	Allowed     uint64 `json:"allowed"`
+	Throttled   uint64 `json:"throttled"`
+}
+
+type stat struct {
+	Stat
+	last time.Time
+}
+
+// bucketKey identify the bucket of an application: the same application name
+// in two namespaces is two applications.
+type bucketKey struct {
+	namespace   string
+	application string
+	// host is empty if the buckets are not per source IP.
+	host string
+}
+
+type bucket struct {
+	limit  Limit
+	tokens float64
+	last   time.Time
+}
+
+// NewLimiter instantiate a new Limiter.
+func NewLimiter(config *Config) (*Limiter, error) {
+	err := validateConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Limiter{
+		config:  config,
+		mutex:   new(sync.Mutex),
+		buckets: map[bucketKey]*bucket{},
+		stats:   map[string]*stat{},
+		now:     time.Now,
+	}, nil
+}
+
+// Allow consume a token for the application of the default namespace. If the
+// bucket is empty it return false and the duration to wait before the next
+// token.
+func (t *Limiter) Allow(application string, remoteAddr string) (time.Duration, bool) {
+	return t.AllowNamespace("", application, remoteAddr)
+}
+
+// AllowNamespace consume a token for the application of the namespace, like
+// Allow.
+func (t *Limiter) AllowNamespace(namespace string, application string, remoteAddr string) (time.Duration, bool) {
+	limit, limited := t.limitFor(application)
+	if !limited {
+		return 0, true
+	}
+
+	key := bucketKey{namespace: namespace, application: application}
+	if t.config.PerIP {
+		key.host = hostOf(remoteAddr)
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := t.now()
+	t.sweep(now)
+
+	b, exists := t.buckets[key]
+	if !exists {
+		b = &bucket{limit: limit, tokens: float64(limit.Burst), last: now}
+		t.buckets[key] = b
+	}
+
+	b.refill(now)
+
+	statKey := OtherApplications
+	if _, configured := t.config.Applications[application]; configured {
+		statKey = application
+	}
+
+	st, exists := t.stats[statKey]
+	if !exists {
+		st = &stat{Stat: Stat{Application: statKey}}
+		t.stats[statKey] = st
+	}
+	st.last = now
+
+	if b.tokens < 1 {
+		st.Throttled++
+		return time.Duration((1 - b.tokens) / limit.Rate * float64(time.Second)), false
+	}
+
+	b.tokens--
+	st.Allowed++
+
+	return 0, true
+}
+
+// Stats return the counters of the rate limited applications sorted by
+// application. The applications without a specific limit are counted together
+// as OtherApplications.
+func (t *Limiter) Stats() []Stat {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	res := make([]Stat, 0, len(t.stats))
+	for _, st := range t.stats {
+		res = append(res, st.Stat)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Application < res[j].Application
+	})
+
+	return res
+}
+
+func (t *Limiter) limitFor(application string) (Limit, bool) {
+	limit, found := t.config.Applications[application]
+	if !found {
+		if t.config.Default == nil {
+			return Limit{}, false
+		}
+
+		limit = *t.config.Default
+	}
+
+	if limit.Burst == 0 {
+		limit.Burst = int(math.Max(1, math.Ceil(limit.Rate)))
+	}
+
+	return limit, true
+}
+
+// sweep remove the full buckets, they are the same as new ones, and the
+// counters unused for statTTL.
+func (t *Limiter) sweep(now time.Time) {
+	if now.Sub(t.lastSweep) < sweepInterval {
+		return
+	}
+	t.lastSweep = now
+
+	for key, b := range t.buckets {
+		b.refill(now)
+		if b.tokens >= float64(b.limit.Burst) {
+			delete(t.buckets, key)
+		}
+	}
+
+	for key, st := range t.stats {
+		if now.Sub(st.last) >= statTTL {
+			delete(t.stats, key)
+		}
+	}
+}
+
+func (t *bucket) refill(now time.Time) {
+	elapsed := now.Sub(t.last).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+
+	t.tokens = math.Min(float64(t.limit.Burst), t.tokens+elapsed*t.limit.Rate)
+	t.last = now
+}
+
+func hostOf(remoteAddr string) string {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+
+	return host
+}
@@ -0,0 +1,162 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Limiter_Allow_success(t *testing.T) {
+	limiter, err := NewLimiter(&Config{
+		Default: &Limit{Rate: 1, Burst: 2},
+	})
+	require.NoError(t, err)
+
+	now := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		retryAfter, allowed := limiter.Allow("some-app", "192.0.2.1:1234")
+		assert.True(t, allowed)
+		assert.Zero(t, retryAfter)
+	}
+
+	retryAfter, allowed := limiter.Allow("some-app", "192.0.2.1:1234")
+	assert.False(t, allowed)
+	assert.Equal(t, time.Second, retryAfter)
+
+	// The other applications have their own bucket.
+	_, allowed = limiter.Allow("some-other-app", "192.0.2.1:1234")
+	assert.True(t, allowed)
+
+	// A token is added after a second.
+	now = now.Add(time.Second)
+	_, allowed = limiter.Allow("some-app", "192.0.2.1:1234")
+	assert.True(t, allowed)
+
+	// The applications without a specific limit are counted together.
+	assert.Equal(t, []Stat{
+		{Application: OtherApplications, Allowed: 4, Throttled: 1},
+	}, limiter.Stats())
+}
+
+func Test_Limiter_Allow_with_a_specific_limit(t *testing.T) {
+	limiter, err := NewLimiter(&Config{
+		Applications: map[string]Limit{"some-app": {Rate: 0.5}},
+	})
+	require.NoError(t, err)
+
+	now := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+
+	_, allowed := limiter.Allow("some-app", "192.0.2.1:1234")
+	assert.True(t, allowed)
+
+	retryAfter, allowed := limiter.Allow("some-app", "192.0.2.1:1234")
+	assert.False(t, allowed)
+	assert.Equal(t, 2*time.Second, retryAfter)
+
+	// Unlimited without a default limit.
+	for i := 0; i < 10; i++ {
+		_, allowed = limiter.Allow("some-other-app", "192.0.2.1:1234")
+		assert.True(t, allowed)
+	}
+
+	assert.Equal(t, []Stat{{Application: "some-app", Allowed: 1, Throttled: 1}}, limiter.Stats())
+}
+
+func Test_Limiter_Allow_per_ip(t *testing.T) {
+	limiter, err := NewLimiter(&Config{
+		Default: &Limit{Rate: 1},
+		PerIP:   true,
+	})
+	require.NoError(t, err)
+
+	now := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+
+	_, allowed := limiter.Allow("some-app", "192.0.2.1:1234")
+	assert.True(t, allowed)
+
+	// Same ip with an other port.
+	_, allowed = limiter.Allow("some-app", "192.0.2.1:5678")
+	assert.False(t, allowed)
+
+	_, allowed = limiter.Allow("some-app", "192.0.2.2:1234")
+	assert.True(t, allowed)
+}
+
+func Test_Limiter_AllowNamespace_success(t *testing.T) {
+	limiter, err := NewLimiter(&Config{
+		Default: &Limit{Rate: 1},
+	})
+	require.NoError(t, err)
+
+	now := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+
+	_, allowed := limiter.AllowNamespace("staging", "some-app", "192.0.2.1:1234")
+	assert.True(t, allowed)
+
+	_, allowed = limiter.AllowNamespace("staging", "some-app", "192.0.2.1:1234")
+	assert.False(t, allowed)
+
+	// The application of an other namespace has its own bucket.
+	_, allowed = limiter.AllowNamespace("prod", "some-app", "192.0.2.1:1234")
+	assert.True(t, allowed)
+
+	_, allowed = limiter.Allow("some-app", "192.0.2.1:1234")
+	assert.True(t, allowed)
+}
+
+func Test_Limiter_sweep_removes_the_full_buckets(t *testing.T) {
+	limiter, err := NewLimiter(&Config{
+		Default: &Limit{Rate: 1},
+	})
+	require.NoError(t, err)
+
+	now := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+
+	limiter.Allow("some-app", "192.0.2.1:1234")
+	assert.Len(t, limiter.buckets, 1)
+
+	now = now.Add(2 * sweepInterval)
+	limiter.Allow("some-other-app", "192.0.2.1:1234")
+	assert.Len(t, limiter.buckets, 1)
+	assert.Contains(t, limiter.buckets, bucketKey{application: "some-other-app"})
+}
+
+func Test_Limiter_sweep_removes_the_idle_stats(t *testing.T) {
+	limiter, err := NewLimiter(&Config{
+		Default:      &Limit{Rate: 1},
+		Applications: map[string]Limit{"some-app": {Rate: 1}},
+	})
+	require.NoError(t, err)
+
+	now := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+
+	limiter.Allow("some-app", "192.0.2.1:1234")
+	limiter.Allow("some-other-app", "192.0.2.1:1234")
+	assert.Len(t, limiter.Stats(), 2)
+
+	now = now.Add(statTTL / 2)
+	limiter.Allow("some-app", "192.0.2.1:1234")
+	assert.Len(t, limiter.Stats(), 2)
+
+	now = now.Add(statTTL / 2)
+	limiter.Allow("some-app", "192.0.2.1:1234")
+	assert.Equal(t, []Stat{{Application: "some-app", Allowed: 3}}, limiter.Stats())
+}
+
+func Test_NewLimiter_with_an_invalid_config(t *testing.T) {
+	limiter, err := NewLimiter(&Config{
+		Applications: map[string]Limit{"some-app": {Rate: 0}},
+	})
+
+	assert.Nil(t, limiter)
+	assert.EqualError(t, err, `validation error: limit for "some-app": invalid input for field "rate"`)
+}
@@ -0,0 +1,31 @@
+package ratelimit
+
+import (
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock implementation of a Limiter.
+type Mock struct {
+	mock.Mock
+}
+
+// Allow method mock.
+func (t *Mock) Allow(application string, remoteAddr string) (time.Duration, bool) {
+	args := t.Called(application, remoteAddr)
+
+	return args.Get(0).(time.Duration), args.Bool(1)
+}
+
+// AllowNamespace method mock.
+func (t *Mock) AllowNamespace(namespace string, application string, remoteAddr string) (time.Duration, bool) {
+	args := t.Called(namespace, application, remoteAddr)
+
+	return args.Get(0).(time.Duration), args.Bool(1)
+}
+
+// Stats method mock.
+func (t *Mock) Stats() []Stat {
+	return t.Called().Get(0).([]Stat)
+}
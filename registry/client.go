@@ -2,12 +2,17 @@ package registry
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strconv"
 
 	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/Peltoche/avro-gateway/logging"
+	"github.com/Peltoche/avro-gateway/tracing"
 )
 
 // Client handle all the interaction between the service and the Schema .
@@ -25,22 +30,30 @@ func NewClient(schemaRegistryURL *url.URL) *Client {
 }
 
 // FetchSchema corresponding to the subject/version.
-func (t *Client) FetchSchema(ctx context.Context, subject string, version string) (string, error) {
+func (t *Client) FetchSchema(ctx context.Context, subject string, version string) (schema string, err error) {
+	ctx, span := tracing.StartWithKind(ctx, "registry.Client.FetchSchema", tracing.SpanKindClient)
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+	span.SetAttribute("registry.subject", subject)
+	span.SetAttribute("registry.version", version)
+
 	fetchSchemaPath, err := url.Parse(fmt.Sprintf("/subjects/%s/versions/%s/schema", subject, version))
 	if err != nil {
 		return "", internal.Errorf(internal.InternalError, "failed to generate the path: %s", err)
 	}
 
-	//nolint
-	// Error not possible
-	req, _ := http.NewRequest("GET", t.baseURL.ResolveReference(fetchSchemaPath).String(), nil)
+	req := t.newRequest(ctx, "GET", fetchSchemaPath, nil)
 
-	res, err := t.client.Do(req.WithContext(ctx))
+	res, err := t.client.Do(req)
 	if err != nil {
 		return "", internal.NewError(internal.RemoteError, err.Error())
 	}
 	defer res.Body.Close()
 
+	logging.Debug(ctx, "schema registry response", logging.Fields{"url": req.URL.String(), "status": res.StatusCode})
+
 	switch res.StatusCode {
 	case 200:
 		break
@@ -57,3 +70,179 @@ func (t *Client) FetchSchema(ctx context.Context, subject string, version string
 
 	return string(rawSchema), nil
 }
+
+// FetchSchemaByID return the schema registered with the id.
+func (t *Client) FetchSchemaByID(ctx context.Context, id int) (schema string, err error) {
+	ctx, span := tracing.StartWithKind(ctx, "registry.Client.FetchSchemaByID", tracing.SpanKindClient)
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+	span.SetAttribute("registry.schema_id", strconv.Itoa(id))
+
+	req := t.newRequest(ctx, "GET", &url.URL{Path: fmt.Sprintf("/schemas/ids/%d", id)}, nil)
+
+	res, err := t.client.Do(req)
+	if err != nil {
+		return "", internal.NewError(internal.RemoteError, err.Error())
+	}
+	defer res.Body.Close()
+
+	logging.Debug(ctx, "schema registry response", logging.Fields{"url": req.URL.String(), "status": res.StatusCode})
+
+	switch res.StatusCode {
+	case 200:
+		break
+	case 404:
+		return "", internal.Errorf(internal.NotFound, `schema %d not found`, id)
+	default:
+		return "", internal.Errorf(internal.RemoteError, "unexpected response status: %s", res.Status)
+	}
+
+	var body struct {
+		Schema string `json:"schema"`
+	}
+
+	err = json.NewDecoder(res.Body).Decode(&body)
+	if err != nil {
+		return "", internal.Errorf(internal.RemoteError, "failed to decode the response body: %s", err)
+	}
+
+	return body.Schema, nil
+}
+
+// FetchSchemaID return the id of the schema registered under the
+// subject/version.
+func (t *Client) FetchSchemaID(ctx context.Context, subject string, version string) (id int, err error) {
+	ctx, span := tracing.StartWithKind(ctx, "registry.Client.FetchSchemaID", tracing.SpanKindClient)
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+	span.SetAttribute("registry.subject", subject)
+	span.SetAttribute("registry.version", version)
+
+	fetchVersionPath, err := url.Parse(fmt.Sprintf("/subjects/%s/versions/%s", subject, version))
+	if err != nil {
+		return 0, internal.Errorf(internal.InternalError, "failed to generate the path: %s", err)
+	}
+
+	req := t.newRequest(ctx, "GET", fetchVersionPath, nil)
+
+	res, err := t.client.Do(req)
+	if err != nil {
+		return 0, internal.NewError(internal.RemoteError, err.Error())
+	}
+	defer res.Body.Close()
+
+	logging.Debug(ctx, "schema registry response", logging.Fields{"url": req.URL.String(), "status": res.StatusCode})
+
+	switch res.StatusCode {
+	case 200:
+		break
+	case 404:
+		return 0, internal.Errorf(internal.NotFound, `schema %s/%s not found`, subject, version)
+	default:
+		return 0, internal.Errorf(internal.RemoteError, "unexpected response status: %s", res.Status)
+	}
+
+	var body struct {
+		ID int `json:"id"`
+	}
+
+	err = json.NewDecoder(res.Body).Decode(&body)
+	if err != nil {
+		return 0, internal.Errorf(internal.RemoteError, "failed to decode the response body: %s", err)
+	}
+
+	return body.ID, nil
+}
+
+// ListVersions return all the versions registered for the subject.
+//
+// The soft-deleted versions are returned only if includeDeleted is set.
+func (t *Client) ListVersions(ctx context.Context, subject string, includeDeleted bool) (versions []int, err error) {
+	ctx, span := tracing.StartWithKind(ctx, "registry.Client.ListVersions", tracing.SpanKindClient)
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+	span.SetAttribute("registry.subject", subject)
+
+	listVersionsPath, err := url.Parse(fmt.Sprintf("/subjects/%s/versions", subject))
+	if err != nil {
+		return nil, internal.Errorf(internal.InternalError, "failed to generate the path: %s", err)
+	}
+
+	if includeDeleted {
+		listVersionsPath.RawQuery = "deleted=true"
+	}
+
+	req := t.newRequest(ctx, "GET", listVersionsPath, nil)
+
+	res, err := t.client.Do(req)
+	if err != nil {
+		return nil, internal.NewError(internal.RemoteError, err.Error())
+	}
+	defer res.Body.Close()
+
+	logging.Debug(ctx, "schema registry response", logging.Fields{"url": req.URL.String(), "status": res.StatusCode})
+
+	switch res.StatusCode {
+	case 200:
+		break
+	case 404:
+		return nil, internal.Errorf(internal.NotFound, `subject %s not found`, subject)
+	default:
+		return nil, internal.Errorf(internal.RemoteError, "unexpected response status: %s", res.Status)
+	}
+
+	versions = []int{}
+	err = json.NewDecoder(res.Body).Decode(&versions)
+	if err != nil {
+		return nil, internal.Errorf(internal.RemoteError, "failed to decode the response body: %s", err)
+	}
+
+	return versions, nil
+}
+
+// Ping check that the Schema Registry is reachable and answers to the
+// requests.
+func (t *Client) Ping(ctx context.Context) (err error) {
+	ctx, span := tracing.StartWithKind(ctx, "registry.Client.Ping", tracing.SpanKindClient)
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+
+	req := t.newRequest(ctx, "GET", &url.URL{Path: "/config"}, nil)
+
+	res, err := t.client.Do(req)
+	if err != nil {
+		return internal.NewError(internal.RemoteError, err.Error())
+	}
+	defer res.Body.Close()
+
+	// Drain the body in order to reuse the connection.
+	_, _ = io.Copy(ioutil.Discard, res.Body)
+
+	if res.StatusCode != 200 {
+		return internal.Errorf(internal.RemoteError, "unexpected response status: %s", res.Status)
+	}
+
+	return nil
+}
+
+// newRequest create a request to the Schema Registry for the path, relative to
+// the base URL. The tracing context and the request id are propagated.
+func (t *Client) newRequest(ctx context.Context, method string, path *url.URL, body io.Reader) *http.Request {
+	//nolint
+	// Error not possible
+	req, _ := http.NewRequest(method, t.baseURL.ResolveReference(path).String(), body)
+	tracing.Inject(ctx, req.Header)
+	if requestID := logging.RequestIDFromContext(ctx); requestID != "" {
+		req.Header.Set(logging.RequestIDHeader, requestID)
+	}
+
+	return req.WithContext(ctx)
+}
@@ -7,6 +7,8 @@ import (
 	"net/url"
 	"testing"
 
+	"github.com/Peltoche/avro-gateway/logging"
+	"github.com/Peltoche/avro-gateway/tracing"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -106,3 +108,235 @@ func Test_Client_FetchSchema_with_an_unexpected_error(t *testing.T) {
 	assert.Empty(t, schema)
 	assert.EqualError(t, err, "remote error: unexpected response status: 418 I'm a teapot")
 }
+
+func Test_Client_FetchSchemaByID_success(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/schemas/ids/42", r.URL.Path)
+
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write([]byte(`{"schema": "{\"type\": \"string\"}"}`))
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	registryURL, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+
+	client := NewClient(registryURL)
+
+	schema, err := client.FetchSchemaByID(context.Background(), 42)
+
+	require.NoError(t, err)
+	assert.Equal(t, `{"type": "string"}`, schema)
+}
+
+func Test_Client_FetchSchemaByID_with_a_schema_not_found(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	registryURL, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+
+	client := NewClient(registryURL)
+
+	schema, err := client.FetchSchemaByID(context.Background(), 42)
+
+	assert.Empty(t, schema)
+	assert.EqualError(t, err, "not found: schema 42 not found")
+}
+
+func Test_Client_FetchSchemaID_success(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/subjects/foobar/versions/3", r.URL.Path)
+
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write([]byte(`{"subject": "foobar", "version": 3, "id": 42, "schema": "\"string\""}`))
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	registryURL, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+
+	client := NewClient(registryURL)
+
+	id, err := client.FetchSchemaID(context.Background(), "foobar", "3")
+
+	require.NoError(t, err)
+	assert.Equal(t, 42, id)
+}
+
+func Test_Client_FetchSchemaID_with_a_schema_not_found(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	registryURL, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+
+	client := NewClient(registryURL)
+
+	id, err := client.FetchSchemaID(context.Background(), "foobar", "3")
+
+	assert.Equal(t, 0, id)
+	assert.EqualError(t, err, "not found: schema foobar/3 not found")
+}
+
+func Test_Client_ListVersions_success(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/subjects/foobar/versions", r.URL.Path)
+		assert.Equal(t, "", r.URL.RawQuery)
+
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write([]byte(`[1,2,3]`))
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	registryURL, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+
+	client := NewClient(registryURL)
+
+	versions, err := client.ListVersions(context.Background(), "foobar", false)
+
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, versions)
+}
+
+func Test_Client_ListVersions_with_deleted(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "deleted=true", r.URL.RawQuery)
+
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write([]byte(`[1,2,3,4]`))
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	registryURL, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+
+	client := NewClient(registryURL)
+
+	versions, err := client.ListVersions(context.Background(), "foobar", true)
+
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3, 4}, versions)
+}
+
+func Test_Client_ListVersions_with_a_subject_not_found(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	registryURL, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+
+	client := NewClient(registryURL)
+
+	versions, err := client.ListVersions(context.Background(), "foobar", false)
+
+	assert.Nil(t, versions)
+	assert.EqualError(t, err, "not found: subject foobar not found")
+}
+
+func Test_Client_ListVersions_with_an_invalid_body(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write([]byte(`not json`))
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	registryURL, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+
+	client := NewClient(registryURL)
+
+	versions, err := client.ListVersions(context.Background(), "foobar", false)
+
+	assert.Nil(t, versions)
+	assert.EqualError(t, err, "remote error: failed to decode the response body: invalid character 'o' in literal null (expecting 'u')")
+}
+
+func Test_Client_FetchSchema_propagate_the_trace(t *testing.T) {
+	var traceParent string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceParent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	registryURL, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+
+	client := NewClient(registryURL)
+
+	exporter := tracing.NewInMemoryExporter()
+	ctx := tracing.WithTracer(context.Background(), tracing.NewTracer(exporter))
+	ctx, parent := tracing.Start(ctx, "some-parent")
+
+	_, err = client.FetchSchema(ctx, "foobar", "1")
+	require.Error(t, err)
+
+	spans := exporter.Spans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "registry.Client.FetchSchema", spans[0].Name)
+	assert.Equal(t, tracing.SpanKindClient, spans[0].Kind)
+	assert.Equal(t, parent.Context().SpanID, spans[0].ParentSpanID)
+	assert.Equal(t, "not found: schema foobar/1 not found", spans[0].Error)
+	assert.Equal(t, "00-"+spans[0].Context.TraceID.String()+"-"+spans[0].Context.SpanID.String()+"-01", traceParent)
+}
+
+func Test_Client_FetchSchema_propagate_the_request_id(t *testing.T) {
+	var requestID string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID = r.Header.Get("X-Request-ID")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	registryURL, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+
+	client := NewClient(registryURL)
+
+	_, err = client.FetchSchema(logging.WithRequestID(context.Background(), "some-request-id"), "foobar", "1")
+
+	require.NoError(t, err)
+	assert.Equal(t, "some-request-id", requestID)
+}
+
+func Test_Client_Ping_success(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/config", r.URL.Path)
+		_, _ = w.Write([]byte(`{"compatibilityLevel":"BACKWARD"}`))
+	}))
+	defer ts.Close()
+
+	registryURL, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+
+	err = NewClient(registryURL).Ping(context.Background())
+
+	assert.NoError(t, err)
+}
+
+func Test_Client_Ping_with_an_error_status(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	registryURL, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+
+	err = NewClient(registryURL).Ping(context.Background())
+
+	assert.EqualError(t, err, "remote error: unexpected response status: 503 Service Unavailable")
+}
@@ -17,3 +17,35 @@ func (t *Mock) FetchSchema(ctx context.Context, subject string, version string)
 
 	return args.String(0), args.Error(1)
 }
+
+// FetchSchemaByID method mock.
+func (t *Mock) FetchSchemaByID(ctx context.Context, id int) (string, error) {
+	args := t.Called(id)
+
+	return args.String(0), args.Error(1)
+}
+
+// FetchSchemaID method mock.
+func (t *Mock) FetchSchemaID(ctx context.Context, subject string, version string) (int, error) {
+	args := t.Called(subject, version)
+
+	return args.Int(0), args.Error(1)
+}
+
+// ListVersions method mock.
+func (t *Mock) ListVersions(ctx context.Context, subject string, includeDeleted bool) ([]int, error) {
+	args := t.Called(subject, includeDeleted)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]int), args.Error(1)
+}
+
+// Ping method mock.
+func (t *Mock) Ping(ctx context.Context) error {
+	args := t.Called()
+
+	return args.Error(0)
+}
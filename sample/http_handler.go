@@ -0,0 +1,94 @@
+package sample
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/Peltoche/avro-gateway/logging"
+	"github.com/gorilla/mux"
+)
+
+// DefaultSamples is the number of samples generated if not specified.
+const DefaultSamples = 10
+
+// HTTPHandler handling all the samples related HTTP requests.
+type HTTPHandler struct {
+	usecase usecase
+}
+
+type usecase interface {
+	Generate(ctx context.Context, cmd *GenerateCmd) (*Samples, error)
+}
+
+// NewHTTPHandler instantiate a new HTTPHandler.
+func NewHTTPHandler(usecase usecase) *HTTPHandler {
+	return &HTTPHandler{
+		usecase: usecase,
+	}
+}
+
+// RegisterRoutes into the givem mux.Router.
+func (t *HTTPHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/subjects/{subject}/versions/{version}/samples", t.Get).Methods("GET")
+}
+
+// Get /subjects/{subject}/versions/{version}/samples?n={n}&seed={seed}&encoding={json|binary}
+func (t *HTTPHandler) Get(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	cmd, err := parseQuery(r)
+	if err != nil {
+		internal.WriteErrorIntoResponse(w, err)
+		return
+	}
+	cmd.Subject = vars["subject"]
+	cmd.Version = vars["version"]
+
+	samples, err := t.usecase.Generate(r.Context(), cmd)
+	if err != nil {
+		internal.WriteErrorIntoResponse(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	err = json.NewEncoder(w).Encode(samples)
+	if err != nil {
+		logging.Warn(r.Context(), "failed to write the response", logging.Fields{"error": err})
+	}
+}
+
+// parseQuery return the command matching the query parameters, with the
+// defaults values: 10 samples, the seed 0 and the JSON encoding.
+func parseQuery(r *http.Request) (*GenerateCmd, error) {
+	query := r.URL.Query()
+	cmd := GenerateCmd{
+		N:        DefaultSamples,
+		Encoding: JSONEncoding,
+	}
+
+	var err error
+	if rawN := query.Get("n"); rawN != "" {
+		cmd.N, err = strconv.Atoi(rawN)
+		if err != nil {
+			return nil, internal.NewError(internal.ValidationError, `invalid input for parameter "n"`)
+		}
+	}
+
+	if rawSeed := query.Get("seed"); rawSeed != "" {
+		cmd.Seed, err = strconv.ParseInt(rawSeed, 10, 64)
+		if err != nil {
+			return nil, internal.NewError(internal.ValidationError, `invalid input for parameter "seed"`)
+		}
+	}
+
+	if encoding := query.Get("encoding"); encoding != "" {
+		cmd.Encoding = Encoding(encoding)
+	}
+
+	return &cmd, nil
+}
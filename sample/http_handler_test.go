@@ -0,0 +1,104 @@
+package sample
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_HTTPHandler_Get_success(t *testing.T) {
+	usecaseMock := new(UsecaseMock)
+	handler := NewHTTPHandler(usecaseMock)
+
+	usecaseMock.On("Generate", &GenerateCmd{Subject: "users-value", Version: "3", N: 2, Seed: 42, Encoding: BinaryEncoding}).Return(&Samples{
+		Subject:  "users-value",
+		Version:  "3",
+		Seed:     42,
+		Encoding: BinaryEncoding,
+		Samples:  []json.RawMessage{json.RawMessage(`"AgQ="`), json.RawMessage(`"AA=="`)},
+	}, nil).Once()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com/subjects/users-value/versions/3/samples?n=2&seed=42&encoding=binary", nil)
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"subject": "users-value",
+		"version": "3",
+		"seed": 42,
+		"encoding": "binary",
+		"samples": ["AgQ=", "AA=="]
+	}`, w.Body.String())
+	usecaseMock.AssertExpectations(t)
+}
+
+func Test_HTTPHandler_Get_with_the_default_parameters(t *testing.T) {
+	usecaseMock := new(UsecaseMock)
+	handler := NewHTTPHandler(usecaseMock)
+
+	usecaseMock.On("Generate", &GenerateCmd{Subject: "users-value", Version: "latest", N: DefaultSamples, Encoding: JSONEncoding}).Return(&Samples{}, nil).Once()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com/subjects/users-value/versions/latest/samples", nil)
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	usecaseMock.AssertExpectations(t)
+}
+
+func Test_HTTPHandler_Get_with_invalid_parameters(t *testing.T) {
+	handler := NewHTTPHandler(new(UsecaseMock))
+
+	tests := []struct {
+		name  string
+		query string
+		param string
+	}{
+		{"invalid n", "n=ten", "n"},
+		{"invalid seed", "seed=1.5", "seed"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "http://example.com/subjects/users-value/versions/1/samples?"+test.query, nil)
+
+			router := mux.NewRouter()
+			handler.RegisterRoutes(router)
+			router.ServeHTTP(w, r)
+
+			assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+			assert.JSONEq(t, `{"kind": "validation error", "message": "invalid input for parameter \"`+test.param+`\""}`, w.Body.String())
+		})
+	}
+}
+
+func Test_HTTPHandler_Get_with_an_usecase_error(t *testing.T) {
+	usecaseMock := new(UsecaseMock)
+	handler := NewHTTPHandler(usecaseMock)
+
+	usecaseMock.On("Generate", &GenerateCmd{Subject: "users-value", Version: "9", N: DefaultSamples, Encoding: JSONEncoding}).Return(nil, internal.NewError(internal.NotFound, "failed to fetch the schema: version not found")).Once()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com/subjects/users-value/versions/9/samples", nil)
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	usecaseMock.AssertExpectations(t)
+}
@@ -0,0 +1,151 @@
+package sample
+
+import (
+	"context"
+	"encoding/json"
+	"math/rand"
+	"strconv"
+
+	"github.com/Peltoche/avro-gateway/avro"
+	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/Peltoche/avro-gateway/tracing"
+)
+
+// MaxSamples is the maximum number of samples generated by request.
+const MaxSamples = 1000
+
+// Encoding of the samples.
+type Encoding string
+
+const (
+	// JSONEncoding is the Avro JSON encoding.
+	JSONEncoding Encoding = "json"
+	// BinaryEncoding is the Avro binary encoding, as base64 strings.
+	BinaryEncoding Encoding = "binary"
+)
+
+// Usecase handling all the logic about the samples generation.
+type Usecase struct {
+	registry Registry
+}
+
+// Registry is used to fetch schema from any Schema Registry.
+type Registry interface {
+	FetchSchema(ctx context.Context, subject string, version string) (string, error)
+}
+
+// NewUsecase instantiate a new Usecase.
+func NewUsecase(registry Registry) *Usecase {
+	return &Usecase{
+		registry: registry,
+	}
+}
+
+// GenerateCmd is the requests parameters for the Generate method.
+type GenerateCmd struct {
+	Subject  string
+	Version  string
+	N        int
+	Seed     int64
+	Encoding Encoding
+}
+
+// Samples of a schema version. The same seed always generate the same samples.
+type Samples struct {
+	Subject  string            `json:"subject"`
+	Version  string            `json:"version"`
+	Seed     int64             `json:"seed"`
+	Encoding Encoding          `json:"encoding"`
+	Samples  []json.RawMessage `json:"samples"`
+}
+
+// Generate return random values of a schema version.
+func (t *Usecase) Generate(ctx context.Context, cmd *GenerateCmd) (*Samples, error) {
+	ctx, span := tracing.Start(ctx, "sample.Usecase.Generate")
+	defer span.End()
+
+	span.SetAttribute("sample.subject", cmd.Subject)
+	span.SetAttribute("sample.version", cmd.Version)
+
+	samples, err := t.generate(ctx, cmd)
+	span.SetError(err)
+
+	return samples, err
+}
+
+func (t *Usecase) generate(ctx context.Context, cmd *GenerateCmd) (*Samples, error) {
+	err := validateGenerateCmd(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := t.registry.FetchSchema(ctx, cmd.Subject, cmd.Version)
+	if err != nil {
+		return nil, internal.Wrap(err, "failed to fetch the schema")
+	}
+
+	schema, err := avro.Parse(raw)
+	if err != nil {
+		return nil, internal.Wrap(err, "failed to parse the registry schema")
+	}
+
+	rnd := rand.New(rand.NewSource(cmd.Seed))
+
+	samples := make([]json.RawMessage, cmd.N)
+	for i := range samples {
+		samples[i], err = encode(schema, avro.Sample(schema, rnd), cmd.Encoding)
+		if err != nil {
+			// Only the schemas without any valid value, like an empty enum.
+			return nil, internal.Errorf(internal.ValidationError, "failed to generate a sample: %s", err)
+		}
+	}
+
+	return &Samples{
+		Subject:  cmd.Subject,
+		Version:  cmd.Version,
+		Seed:     cmd.Seed,
+		Encoding: cmd.Encoding,
+		Samples:  samples,
+	}, nil
+}
+
+// encode return a sample as a JSON value: the Avro JSON encoding or the
+// binary encoding as a base64 string.
+func encode(schema *avro.Schema, value interface{}, encoding Encoding) (json.RawMessage, error) {
+	if encoding == JSONEncoding {
+		return avro.EncodeJSON(schema, value)
+	}
+
+	data, err := avro.EncodeBinary(schema, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(data)
+}
+
+func validateGenerateCmd(cmd *GenerateCmd) error {
+	if cmd.Subject == "" {
+		return internal.NewError(internal.ValidationError, `missing field "subject"`)
+	}
+
+	if cmd.Version == "" {
+		return internal.NewError(internal.ValidationError, `missing field "version"`)
+	}
+	if cmd.Version != "latest" {
+		val, err := strconv.Atoi(cmd.Version)
+		if err != nil || val < 1 {
+			return internal.NewError(internal.ValidationError, `invalid input for field "version"`)
+		}
+	}
+
+	if cmd.N < 1 || cmd.N > MaxSamples {
+		return internal.NewError(internal.ValidationError, `invalid input for parameter "n"`)
+	}
+
+	if cmd.Encoding != JSONEncoding && cmd.Encoding != BinaryEncoding {
+		return internal.NewError(internal.ValidationError, `invalid input for parameter "encoding"`)
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package sample
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/Peltoche/avro-gateway/avro"
+	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/Peltoche/avro-gateway/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const someSchema = `{
+	"type": "record",
+	"name": "User",
+	"fields": [
+		{"name": "name", "type": "string"},
+		{"name": "status", "type": {"type": "enum", "name": "Status", "symbols": ["ACTIVE", "DELETED"]}},
+		{"name": "email", "type": ["null", "string"], "default": null}
+	]
+}`
+
+func Test_Usecase_Generate_json(t *testing.T) {
+	registryMock := new(registry.Mock)
+	usecase := NewUsecase(registryMock)
+
+	registryMock.On("FetchSchema", "users-value", "latest").Return(someSchema, nil).Twice()
+
+	cmd := GenerateCmd{Subject: "users-value", Version: "latest", N: 5, Seed: 42, Encoding: JSONEncoding}
+	samples, err := usecase.Generate(context.Background(), &cmd)
+	require.NoError(t, err)
+
+	assert.Equal(t, "users-value", samples.Subject)
+	assert.Equal(t, "latest", samples.Version)
+	assert.Equal(t, int64(42), samples.Seed)
+	assert.Equal(t, JSONEncoding, samples.Encoding)
+	require.Len(t, samples.Samples, 5)
+
+	schema, err := avro.Parse(someSchema)
+	require.NoError(t, err)
+	for _, sample := range samples.Samples {
+		_, err = avro.DecodeJSON(schema, sample)
+		assert.NoError(t, err)
+	}
+
+	// The same seed generate the same samples.
+	again, err := usecase.Generate(context.Background(), &cmd)
+	assert.NoError(t, err)
+	assert.Equal(t, samples, again)
+	registryMock.AssertExpectations(t)
+}
+
+func Test_Usecase_Generate_binary(t *testing.T) {
+	registryMock := new(registry.Mock)
+	usecase := NewUsecase(registryMock)
+
+	registryMock.On("FetchSchema", "users-value", "2").Return(someSchema, nil).Once()
+
+	samples, err := usecase.Generate(context.Background(), &GenerateCmd{
+		Subject:  "users-value",
+		Version:  "2",
+		N:        3,
+		Encoding: BinaryEncoding,
+	})
+	require.NoError(t, err)
+	require.Len(t, samples.Samples, 3)
+
+	schema, err := avro.Parse(someSchema)
+	require.NoError(t, err)
+	for _, sample := range samples.Samples {
+		var data []byte
+		require.NoError(t, json.Unmarshal(sample, &data))
+
+		_, err = avro.DecodeBinary(schema, data)
+		assert.NoError(t, err)
+	}
+	registryMock.AssertExpectations(t)
+}
+
+func Test_Usecase_Generate_with_a_fetch_error(t *testing.T) {
+	registryMock := new(registry.Mock)
+	usecase := NewUsecase(registryMock)
+
+	registryMock.On("FetchSchema", "users-value", "9").Return("", internal.NewError(internal.NotFound, "version not found")).Once()
+
+	samples, err := usecase.Generate(context.Background(), &GenerateCmd{
+		Subject:  "users-value",
+		Version:  "9",
+		N:        1,
+		Encoding: JSONEncoding,
+	})
+
+	assert.Nil(t, samples)
+	assert.EqualError(t, err, "not found: failed to fetch the schema: version not found")
+	registryMock.AssertExpectations(t)
+}
+
+func Test_Usecase_Generate_with_a_schema_without_value(t *testing.T) {
+	registryMock := new(registry.Mock)
+	usecase := NewUsecase(registryMock)
+
+	registryMock.On("FetchSchema", "users-value", "1").Return(`{"type": "enum", "name": "Empty", "symbols": []}`, nil).Once()
+
+	samples, err := usecase.Generate(context.Background(), &GenerateCmd{
+		Subject:  "users-value",
+		Version:  "1",
+		N:        1,
+		Encoding: JSONEncoding,
+	})
+
+	assert.Nil(t, samples)
+	assert.True(t, internal.IsKind(internal.ValidationError, err))
+	registryMock.AssertExpectations(t)
+}
+
+func Test_Usecase_Generate_with_invalid_inputs(t *testing.T) {
+	usecase := NewUsecase(new(registry.Mock))
+
+	tests := []struct {
+		name string
+		cmd  GenerateCmd
+		err  string
+	}{
+		{"missing subject", GenerateCmd{Version: "1", N: 1, Encoding: JSONEncoding}, `validation error: missing field "subject"`},
+		{"missing version", GenerateCmd{Subject: "users-value", N: 1, Encoding: JSONEncoding}, `validation error: missing field "version"`},
+		{"invalid version", GenerateCmd{Subject: "users-value", Version: "0", N: 1, Encoding: JSONEncoding}, `validation error: invalid input for field "version"`},
+		{"no samples", GenerateCmd{Subject: "users-value", Version: "1", Encoding: JSONEncoding}, `validation error: invalid input for parameter "n"`},
+		{"too many samples", GenerateCmd{Subject: "users-value", Version: "1", N: MaxSamples + 1, Encoding: JSONEncoding}, `validation error: invalid input for parameter "n"`},
+		{"invalid encoding", GenerateCmd{Subject: "users-value", Version: "1", N: 1, Encoding: "xml"}, `validation error: invalid input for parameter "encoding"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			samples, err := usecase.Generate(context.Background(), &test.cmd)
+
+			assert.Nil(t, samples)
+			assert.EqualError(t, err, test.err)
+		})
+	}
+}
@@ -3,27 +3,47 @@ package schema
 import (
 	"context"
 	"encoding/json"
-	"log"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/Peltoche/avro-gateway/audit"
+	"github.com/Peltoche/avro-gateway/auth"
+	"github.com/Peltoche/avro-gateway/avro"
 	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/Peltoche/avro-gateway/logging"
+	"github.com/Peltoche/avro-gateway/model"
+	"github.com/Peltoche/avro-gateway/tracing"
 	"github.com/gorilla/mux"
 )
 
 // HTTPHandler handling all the http logic about the schema resource.
 type HTTPHandler struct {
 	usecase usecase
+	auditor auditor
+	limiter limiter
 }
 
 type usecase interface {
-	GetSchema(ctx context.Context, cmd *GetSchemaCmd) (string, error)
+	GetSchema(ctx context.Context, cmd *GetSchemaCmd) (string, *avro.Fingerprints, error)
+}
+
+type auditor interface {
+	Record(ctx context.Context, entry model.AuditEntry)
+}
+
+type limiter interface {
+	Allow(application string, remoteAddr string) (time.Duration, bool)
 }
 
 // NewHTTPHandler instantiate a new HTTPHandler.
-func NewHTTPHandler(usecase usecase) *HTTPHandler {
+func NewHTTPHandler(usecase usecase, auditor auditor, limiter limiter) *HTTPHandler {
 
 	handler := &HTTPHandler{
 		usecase: usecase,
+		auditor: auditor,
+		limiter: limiter,
 	}
 
 	return handler
@@ -35,6 +55,9 @@ func (t *HTTPHandler) RegisterRoutes(router *mux.Router) {
 }
 
 // Post /schemas/{subject}
+//
+// For an authenticated caller the application is the authenticated one: the
+// "application" field can be omitted and any other value is refused.
 func (t *HTTPHandler) Post(w http.ResponseWriter, r *http.Request) {
 	type request struct {
 		Topic       string `json:"topic"`
@@ -44,29 +67,91 @@ func (t *HTTPHandler) Post(w http.ResponseWriter, r *http.Request) {
 		Action      string `json:"action"`
 	}
 
+	ctx, span := tracing.Start(r.Context(), "schema.HTTPHandler.Post")
+	defer span.End()
+
+	principal := auth.PrincipalFromContext(ctx)
+
 	var req request
-	err := json.NewDecoder(r.Body).Decode(&req)
+	var err error
+	defer func() {
+		entry := model.AuditEntry{
+			RemoteAddr:  r.RemoteAddr,
+			Topic:       req.Topic,
+			Application: req.Application,
+			Action:      req.Action,
+			Subject:     req.Subject,
+			Version:     req.Version,
+		}
+		entry.Decision, entry.Reasons = audit.DecisionOf(err)
+		if principal != nil {
+			entry.Caller = principal.String()
+		}
+
+		span.SetError(err)
+		t.auditor.Record(ctx, entry)
+	}()
+
+	err = json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		internal.WriteErrorIntoResponse(w, internal.NewError(internal.InvalidJSONBody, err.Error()))
+		err = internal.NewError(internal.InvalidJSONBody, err.Error())
+		internal.WriteErrorIntoResponse(w, err)
+		return
+	}
+
+	application, err := auth.CallerApplication(ctx, req.Application)
+	if err != nil {
+		internal.WriteErrorIntoResponse(w, err)
 		return
 	}
+	req.Application = application
 
-	schema, err := t.usecase.GetSchema(r.Context(), &GetSchemaCmd{
+	cmd := &GetSchemaCmd{
 		Topic:       req.Topic,
 		Application: req.Application,
 		Action:      req.Action,
 		Subject:     req.Subject,
 		Version:     req.Version,
-	})
+	}
+
+	// The invalid requests don't consume any token.
+	err = validateGetSchemaCmd(cmd)
 	if err != nil {
 		internal.WriteErrorIntoResponse(w, err)
 		return
 	}
 
+	retryAfter, allowed := t.limiter.Allow(req.Application, r.RemoteAddr)
+	if !allowed {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		err = internal.Errorf(internal.TooManyRequests, "rate limit exceeded for the application %q", req.Application)
+		internal.WriteErrorIntoResponse(w, err)
+		return
+	}
+
+	schema, fingerprints, err := t.usecase.GetSchema(ctx, cmd)
+	if err != nil {
+		internal.WriteErrorIntoResponse(w, err)
+		return
+	}
+
+	setFingerprintHeaders(w, fingerprints)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	_, err = w.Write([]byte(schema))
-	if err != nil {
-		log.Print(err)
+	_, writeErr := w.Write([]byte(schema))
+	if writeErr != nil {
+		logging.Warn(ctx, "failed to write the response", logging.Fields{"error": writeErr})
 	}
 }
+
+// setFingerprintHeaders set the fingerprints of an Avro schema into the
+// response headers. Nothing is set for the other schemas.
+func setFingerprintHeaders(w http.ResponseWriter, fingerprints *avro.Fingerprints) {
+	if fingerprints == nil {
+		return
+	}
+
+	w.Header().Set("X-Schema-Fingerprint-Crc64-Avro", fingerprints.CRC64Avro)
+	w.Header().Set("X-Schema-Fingerprint-Md5", fingerprints.MD5)
+	w.Header().Set("X-Schema-Fingerprint-Sha256", fingerprints.SHA256)
+}
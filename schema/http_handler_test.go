@@ -7,8 +7,14 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/Peltoche/avro-gateway/audit"
+	"github.com/Peltoche/avro-gateway/auth"
+	"github.com/Peltoche/avro-gateway/avro"
 	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/Peltoche/avro-gateway/model"
+	"github.com/Peltoche/avro-gateway/ratelimit"
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -16,16 +22,29 @@ import (
 
 func Test_HTTPHandler_Post_success(t *testing.T) {
 	usecaseMock := new(UsecaseMock)
+	auditMock := new(audit.Mock)
+	limiterMock := new(ratelimit.Mock)
 
-	handler := NewHTTPHandler(usecaseMock)
+	handler := NewHTTPHandler(usecaseMock, auditMock, limiterMock)
 
+	limiterMock.On("Allow", "my-application", "192.0.2.1:1234").Return(time.Duration(0), true).Once()
 	usecaseMock.On("GetSchema", &GetSchemaCmd{
 		Topic:       "my-topic",
 		Application: "my-application",
 		Action:      "read",
 		Subject:     "my-avro-subject",
 		Version:     "1",
-	}).Return("some-schema", nil).Once()
+	}).Return("some-schema", nil, nil).Once()
+
+	auditMock.On("Record", model.AuditEntry{
+		RemoteAddr:  "192.0.2.1:1234",
+		Topic:       "my-topic",
+		Application: "my-application",
+		Action:      "read",
+		Subject:     "my-avro-subject",
+		Version:     "1",
+		Decision:    model.AuditAccepted,
+	}).Once()
 
 	w := httptest.NewRecorder()
 	r := httptest.NewRequest("POST", "http://example.com/schema", strings.NewReader(`{
@@ -46,14 +65,79 @@ func Test_HTTPHandler_Post_success(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, res.StatusCode)
 	assert.Equal(t, "some-schema", string(body))
+	assert.Empty(t, res.Header.Get("X-Schema-Fingerprint-Sha256"))
+
+	usecaseMock.AssertExpectations(t)
+	auditMock.AssertExpectations(t)
+	limiterMock.AssertExpectations(t)
+}
+
+func Test_HTTPHandler_Post_with_an_avro_schema(t *testing.T) {
+	usecaseMock := new(UsecaseMock)
+	auditMock := new(audit.Mock)
+	limiterMock := new(ratelimit.Mock)
+
+	handler := NewHTTPHandler(usecaseMock, auditMock, limiterMock)
+
+	limiterMock.On("Allow", "my-application", "192.0.2.1:1234").Return(time.Duration(0), true).Once()
+	usecaseMock.On("GetSchema", &GetSchemaCmd{
+		Topic:       "my-topic",
+		Application: "my-application",
+		Action:      "read",
+		Subject:     "my-avro-subject",
+		Version:     "1",
+	}).Return(`{"type": "int"}`, &avro.Fingerprints{
+		CRC64Avro: "7275d51a3f395c8f",
+		MD5:       "ef524ea1b91e73173d938ade36c1db32",
+		SHA256:    "3f2b87a9fe7cc9b13835598c3981cd45e3e355309e5090aa0933d7becb6fba45",
+	}, nil).Once()
+	auditMock.On("Record", model.AuditEntry{
+		RemoteAddr:  "192.0.2.1:1234",
+		Topic:       "my-topic",
+		Application: "my-application",
+		Action:      "read",
+		Subject:     "my-avro-subject",
+		Version:     "1",
+		Decision:    model.AuditAccepted,
+	}).Once()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "http://example.com/schema", strings.NewReader(`{
+		"topic": "my-topic",
+		"application": "my-application",
+		"action": "read",
+		"subject": "my-avro-subject",
+		"version": "1"
+	}`))
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	res := w.Result()
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "7275d51a3f395c8f", res.Header.Get("X-Schema-Fingerprint-Crc64-Avro"))
+	assert.Equal(t, "ef524ea1b91e73173d938ade36c1db32", res.Header.Get("X-Schema-Fingerprint-Md5"))
+	assert.Equal(t, "3f2b87a9fe7cc9b13835598c3981cd45e3e355309e5090aa0933d7becb6fba45", res.Header.Get("X-Schema-Fingerprint-Sha256"))
 
 	usecaseMock.AssertExpectations(t)
+	auditMock.AssertExpectations(t)
+	limiterMock.AssertExpectations(t)
 }
 
 func Test_HTTPHandler_Post_with_an_invalid_body_format(t *testing.T) {
 	usecaseMock := new(UsecaseMock)
+	auditMock := new(audit.Mock)
+	limiterMock := new(ratelimit.Mock)
 
-	handler := NewHTTPHandler(usecaseMock)
+	handler := NewHTTPHandler(usecaseMock, auditMock, limiterMock)
+
+	auditMock.On("Record", model.AuditEntry{
+		RemoteAddr: "192.0.2.1:1234",
+		Decision:   model.AuditRefused,
+		Reasons:    []string{"invalid json body: invalid character 'i' looking for beginning of value"},
+	}).Once()
 
 	w := httptest.NewRecorder()
 	r := httptest.NewRequest("POST", "http://example.com/schema", strings.NewReader("invalid json"))
@@ -73,20 +157,36 @@ func Test_HTTPHandler_Post_with_an_invalid_body_format(t *testing.T) {
 	}`, string(body))
 
 	usecaseMock.AssertExpectations(t)
+	auditMock.AssertExpectations(t)
+	limiterMock.AssertExpectations(t)
 }
 
 func Test_HTTPHandler_Post_with_an_error_from_the_usecase(t *testing.T) {
 	usecaseMock := new(UsecaseMock)
+	auditMock := new(audit.Mock)
+	limiterMock := new(ratelimit.Mock)
 
-	handler := NewHTTPHandler(usecaseMock)
+	handler := NewHTTPHandler(usecaseMock, auditMock, limiterMock)
 
+	limiterMock.On("Allow", "my-application", "192.0.2.1:1234").Return(time.Duration(0), true).Once()
 	usecaseMock.On("GetSchema", &GetSchemaCmd{
 		Topic:       "my-topic",
 		Application: "my-application",
 		Action:      "read",
 		Subject:     "my-avro-subject",
-		Version:     "-1",
-	}).Return("", internal.NewError(internal.ValidationError, "some-message")).Once()
+		Version:     "1",
+	}).Return("", nil, internal.NewError(internal.ValidationError, "some-message")).Once()
+
+	auditMock.On("Record", model.AuditEntry{
+		RemoteAddr:  "192.0.2.1:1234",
+		Topic:       "my-topic",
+		Application: "my-application",
+		Action:      "read",
+		Subject:     "my-avro-subject",
+		Version:     "1",
+		Decision:    model.AuditRefused,
+		Reasons:     []string{"validation error: some-message"},
+	}).Once()
 
 	w := httptest.NewRecorder()
 	r := httptest.NewRequest("POST", "http://example.com/schema", strings.NewReader(`{
@@ -94,7 +194,7 @@ func Test_HTTPHandler_Post_with_an_error_from_the_usecase(t *testing.T) {
 		"application": "my-application",
 		"action": "read",
 		"subject": "my-avro-subject",
-		"version": "-1"
+		"version": "1"
 	}`))
 
 	router := mux.NewRouter()
@@ -112,20 +212,36 @@ func Test_HTTPHandler_Post_with_an_error_from_the_usecase(t *testing.T) {
 	}`, string(body))
 
 	usecaseMock.AssertExpectations(t)
+	auditMock.AssertExpectations(t)
+	limiterMock.AssertExpectations(t)
 }
 
 func Test_HTTPHandler_Post_with_an_unexpected_error_from_the_usecase(t *testing.T) {
 	usecaseMock := new(UsecaseMock)
+	auditMock := new(audit.Mock)
+	limiterMock := new(ratelimit.Mock)
 
-	handler := NewHTTPHandler(usecaseMock)
+	handler := NewHTTPHandler(usecaseMock, auditMock, limiterMock)
 
+	limiterMock.On("Allow", "my-application", "192.0.2.1:1234").Return(time.Duration(0), true).Once()
 	usecaseMock.On("GetSchema", &GetSchemaCmd{
 		Topic:       "my-topic",
 		Application: "my-application",
 		Action:      "read",
 		Subject:     "my-avro-subject",
 		Version:     "1",
-	}).Return("", errors.New("some-unexpected-message")).Once()
+	}).Return("", nil, errors.New("some-unexpected-message")).Once()
+
+	auditMock.On("Record", model.AuditEntry{
+		RemoteAddr:  "192.0.2.1:1234",
+		Topic:       "my-topic",
+		Application: "my-application",
+		Action:      "read",
+		Subject:     "my-avro-subject",
+		Version:     "1",
+		Decision:    model.AuditError,
+		Reasons:     []string{"some-unexpected-message"},
+	}).Once()
 
 	w := httptest.NewRecorder()
 	r := httptest.NewRequest("POST", "http://example.com/schema", strings.NewReader(`{
@@ -151,4 +267,199 @@ func Test_HTTPHandler_Post_with_an_unexpected_error_from_the_usecase(t *testing.
 	}`, string(body))
 
 	usecaseMock.AssertExpectations(t)
+	auditMock.AssertExpectations(t)
+	limiterMock.AssertExpectations(t)
+}
+
+func Test_HTTPHandler_Post_with_an_authenticated_caller(t *testing.T) {
+	usecaseMock := new(UsecaseMock)
+	auditMock := new(audit.Mock)
+	limiterMock := new(ratelimit.Mock)
+
+	handler := NewHTTPHandler(usecaseMock, auditMock, limiterMock)
+
+	limiterMock.On("Allow", "my-authenticated-application", "192.0.2.1:1234").Return(time.Duration(0), true).Once()
+	usecaseMock.On("GetSchema", &GetSchemaCmd{
+		Topic:       "my-topic",
+		Application: "my-authenticated-application",
+		Action:      "read",
+		Subject:     "my-avro-subject",
+		Version:     "1",
+	}).Return("some-schema", nil, nil).Once()
+	auditMock.On("Record", model.AuditEntry{
+		Caller:      "jwt:my-authenticated-application",
+		RemoteAddr:  "192.0.2.1:1234",
+		Topic:       "my-topic",
+		Application: "my-authenticated-application",
+		Action:      "read",
+		Subject:     "my-avro-subject",
+		Version:     "1",
+		Decision:    model.AuditAccepted,
+	}).Once()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "http://example.com/schema", strings.NewReader(`{
+		"topic": "my-topic",
+		"action": "read",
+		"subject": "my-avro-subject",
+		"version": "1"
+	}`))
+	r = r.WithContext(auth.WithPrincipal(r.Context(), &auth.Principal{Application: "my-authenticated-application", Method: auth.JWTMethod}))
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	res := w.Result()
+	body, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "some-schema", string(body))
+
+	usecaseMock.AssertExpectations(t)
+	auditMock.AssertExpectations(t)
+	limiterMock.AssertExpectations(t)
+}
+
+func Test_HTTPHandler_Post_with_an_application_different_from_the_authenticated_one(t *testing.T) {
+	usecaseMock := new(UsecaseMock)
+	auditMock := new(audit.Mock)
+	limiterMock := new(ratelimit.Mock)
+
+	handler := NewHTTPHandler(usecaseMock, auditMock, limiterMock)
+
+	auditMock.On("Record", model.AuditEntry{
+		Caller:      "api_key:my-authenticated-application",
+		RemoteAddr:  "192.0.2.1:1234",
+		Topic:       "my-topic",
+		Application: "some-other-application",
+		Action:      "read",
+		Subject:     "my-avro-subject",
+		Version:     "1",
+		Decision:    model.AuditRefused,
+		Reasons:     []string{`validation error: invalid input for field "application": authenticated as "my-authenticated-application"`},
+	}).Once()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "http://example.com/schema", strings.NewReader(`{
+		"topic": "my-topic",
+		"application": "some-other-application",
+		"action": "read",
+		"subject": "my-avro-subject",
+		"version": "1"
+	}`))
+	r = r.WithContext(auth.WithPrincipal(r.Context(), &auth.Principal{Application: "my-authenticated-application", Method: auth.APIKeyMethod}))
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	res := w.Result()
+	body, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+	assert.JSONEq(t, `{
+		"kind": "validation error",
+		"message": "invalid input for field \"application\": authenticated as \"my-authenticated-application\""
+	}`, string(body))
+
+	usecaseMock.AssertExpectations(t)
+	auditMock.AssertExpectations(t)
+	limiterMock.AssertExpectations(t)
+}
+
+func Test_HTTPHandler_Post_with_an_invalid_request(t *testing.T) {
+	usecaseMock := new(UsecaseMock)
+	auditMock := new(audit.Mock)
+	limiterMock := new(ratelimit.Mock)
+
+	handler := NewHTTPHandler(usecaseMock, auditMock, limiterMock)
+
+	auditMock.On("Record", model.AuditEntry{
+		RemoteAddr: "192.0.2.1:1234",
+		Topic:      "my-topic",
+		Action:     "read",
+		Subject:    "my-avro-subject",
+		Version:    "1",
+		Decision:   model.AuditRefused,
+		Reasons:    []string{`validation error: missing field "application"`},
+	}).Once()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "http://example.com/schema", strings.NewReader(`{
+		"topic": "my-topic",
+		"action": "read",
+		"subject": "my-avro-subject",
+		"version": "1"
+	}`))
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	res := w.Result()
+	body, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	// The request is refused without consuming any token.
+	assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+	assert.JSONEq(t, `{
+		"kind": "validation error",
+		"message": "missing field \"application\""
+	}`, string(body))
+
+	usecaseMock.AssertExpectations(t)
+	auditMock.AssertExpectations(t)
+	limiterMock.AssertExpectations(t)
+}
+
+func Test_HTTPHandler_Post_with_a_rate_limited_application(t *testing.T) {
+	usecaseMock := new(UsecaseMock)
+	auditMock := new(audit.Mock)
+	limiterMock := new(ratelimit.Mock)
+
+	handler := NewHTTPHandler(usecaseMock, auditMock, limiterMock)
+
+	limiterMock.On("Allow", "my-application", "192.0.2.1:1234").Return(1500*time.Millisecond, false).Once()
+
+	auditMock.On("Record", model.AuditEntry{
+		RemoteAddr:  "192.0.2.1:1234",
+		Topic:       "my-topic",
+		Application: "my-application",
+		Action:      "read",
+		Subject:     "my-avro-subject",
+		Version:     "1",
+		Decision:    model.AuditRefused,
+		Reasons:     []string{`too many requests: rate limit exceeded for the application "my-application"`},
+	}).Once()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("POST", "http://example.com/schema", strings.NewReader(`{
+		"topic": "my-topic",
+		"application": "my-application",
+		"action": "read",
+		"subject": "my-avro-subject",
+		"version": "1"
+	}`))
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	res := w.Result()
+	body, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+	assert.Equal(t, "2", res.Header.Get("Retry-After"))
+	assert.JSONEq(t, `{
+		"kind": "too many requests",
+		"message": "rate limit exceeded for the application \"my-application\""
+	}`, string(body))
+
+	usecaseMock.AssertExpectations(t)
+	auditMock.AssertExpectations(t)
+	limiterMock.AssertExpectations(t)
 }
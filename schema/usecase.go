@@ -2,17 +2,25 @@ package schema
 
 import (
 	"context"
+	"fmt"
 	"strconv"
+	"strings"
 
+	"github.com/Peltoche/avro-gateway/avro"
 	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/Peltoche/avro-gateway/logging"
 	"github.com/Peltoche/avro-gateway/model"
+	"github.com/Peltoche/avro-gateway/tracing"
 	uuid "github.com/satori/go.uuid"
 )
 
 // Usecase handling all the logic about the schema resource.
 type Usecase struct {
-	registry Registry
-	storage  Storage
+	registry   Registry
+	storage    Storage
+	publisher  Publisher
+	authorizer Authorizer
+	linter     Linter
 	// Set the uuid generation function as an attribute in order to be able to
 	// mock id.
 	generateUUID func() string
@@ -29,11 +37,29 @@ type Storage interface {
 	GetAllClientsOnTopic(ctx context.Context, topicName string) ([]model.Client, error)
 }
 
+// Publisher receive the events about the topics changes.
+type Publisher interface {
+	Publish(ctx context.Context, evt model.Event)
+}
+
+// Authorizer check the ACL rules.
+type Authorizer interface {
+	Authorize(ctx context.Context, application string, topic string, action string) error
+}
+
+// Linter check the Avro schemas against the governance rules of a topic.
+type Linter interface {
+	Lint(ctx context.Context, topic string, schema *avro.Schema) []model.LintViolation
+}
+
 // NewUsecase instantiate a new Usecase.
-func NewUsecase(registry Registry, storage Storage) *Usecase {
+func NewUsecase(registry Registry, storage Storage, publisher Publisher, authorizer Authorizer, linter Linter) *Usecase {
 	return &Usecase{
-		registry: registry,
-		storage:  storage,
+		registry:   registry,
+		storage:    storage,
+		publisher:  publisher,
+		authorizer: authorizer,
+		linter:     linter,
 		generateUUID: func() string {
 			return uuid.NewV4().String()
 		},
@@ -49,26 +75,165 @@ type GetSchemaCmd struct {
 	Version     string
 }
 
-// GetSchema check if the client is authorized to use the schema and return it.
-func (t *Usecase) GetSchema(ctx context.Context, cmd *GetSchemaCmd) (string, error) {
-	err := t.validateGetSchemaCmd(cmd)
+// GetSchema check if the client is authorized to use the schema and return it
+// with its fingerprints. The fingerprints are nil if it's not an Avro schema.
+func (t *Usecase) GetSchema(ctx context.Context, cmd *GetSchemaCmd) (string, *avro.Fingerprints, error) {
+	ctx, span := tracing.Start(ctx, "schema.Usecase.GetSchema")
+	defer span.End()
+
+	span.SetAttribute("schema.topic", cmd.Topic)
+	span.SetAttribute("schema.application", cmd.Application)
+	span.SetAttribute("schema.action", cmd.Action)
+	span.SetAttribute("schema.subject", cmd.Subject)
+	span.SetAttribute("schema.version", cmd.Version)
+
+	schema, fingerprints, err := t.getSchema(ctx, cmd)
+	span.SetError(err)
+
+	return schema, fingerprints, err
+}
+
+func (t *Usecase) getSchema(ctx context.Context, cmd *GetSchemaCmd) (string, *avro.Fingerprints, error) {
+	err := validateGetSchemaCmd(cmd)
+	if err != nil {
+		return "", nil, err
+	}
+
+	err = t.authorize(ctx, cmd)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
 	schema, err := t.registry.FetchSchema(ctx, cmd.Subject, cmd.Version)
 	if err != nil {
-		return "", internal.Wrap(err, "failed to fetch the schema")
+		return "", nil, internal.Wrap(err, "failed to fetch the schema")
+	}
+
+	// The schema is parsed once for the lint and the fingerprints.
+	parsed := parse(ctx, schema)
+
+	if cmd.Action == "write" {
+		err = t.lint(ctx, cmd, parsed)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	var fingerprints *avro.Fingerprints
+	fingerprint := ""
+	if parsed != nil {
+		fingerprints = avro.Fingerprint(parsed)
+		fingerprint = fingerprints.SHA256
+	}
+
+	err = t.registerClient(ctx, cmd, fingerprint)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return schema, fingerprints, nil
+}
+
+// CheckClient check if the client is authorized to use the subject on the
+// topic, whatever the version. The version field is ignored.
+//
+// It's used before forwarding a request to the Schema Registry, when the
+// version is only known from the response.
+func (t *Usecase) CheckClient(ctx context.Context, cmd *GetSchemaCmd) error {
+	ctx, span := tracing.Start(ctx, "schema.Usecase.CheckClient")
+	defer span.End()
+
+	err := t.checkClient(ctx, cmd)
+	span.SetError(err)
+
+	return err
+}
+
+func (t *Usecase) checkClient(ctx context.Context, cmd *GetSchemaCmd) error {
+	err := validateClientFields(cmd)
+	if err != nil {
+		return err
+	}
+
+	err = t.authorize(ctx, cmd)
+	if err != nil {
+		return err
 	}
 
 	clientsOnTopic, err := t.storage.GetAllClientsOnTopic(ctx, cmd.Topic)
 	if err != nil {
-		return "", internal.Wrapf(err, "failed to retrieve the list of clients connected to the topic %q", cmd.Topic)
+		return internal.Wrapf(err, "failed to retrieve the list of clients connected to the topic %q", cmd.Topic)
+	}
+
+	err = t.checkSubjectCompatibility(cmd, "", clientsOnTopic)
+	if err != nil {
+		t.publishRefusal(ctx, cmd, err)
+		return err
+	}
+
+	return nil
+}
+
+// LintSchema check the schema against the lint rules of the topic, like the
+// schemas fetched by GetSchema to write. It's used before forwarding a schema
+// registration to the Schema Registry.
+func (t *Usecase) LintSchema(ctx context.Context, cmd *GetSchemaCmd, schema string) error {
+	ctx, span := tracing.Start(ctx, "schema.Usecase.LintSchema")
+	defer span.End()
+
+	err := t.lint(ctx, cmd, parse(ctx, schema))
+	span.SetError(err)
+
+	return err
+}
+
+// RegisterClient check if the client is authorized to use the schema and
+// register it, like GetSchema but without fetching the schema. It's used
+// when the schema is served by the Schema Registry itself.
+func (t *Usecase) RegisterClient(ctx context.Context, cmd *GetSchemaCmd, schema string) error {
+	ctx, span := tracing.Start(ctx, "schema.Usecase.RegisterClient")
+	defer span.End()
+
+	err := validateGetSchemaCmd(cmd)
+	if err == nil {
+		err = t.authorize(ctx, cmd)
+	}
+	if err == nil {
+		fingerprint := ""
+		if parsed := parse(ctx, schema); parsed != nil {
+			fingerprint = avro.Fingerprint(parsed).SHA256
+		}
+
+		err = t.registerClient(ctx, cmd, fingerprint)
 	}
+	span.SetError(err)
+
+	return err
+}
 
-	err = t.checkSubjectCompatibility(cmd, clientsOnTopic)
+// authorize check the ACL rules and publish the refusal if any.
+func (t *Usecase) authorize(ctx context.Context, cmd *GetSchemaCmd) error {
+	err := t.authorizer.Authorize(ctx, cmd.Application, cmd.Topic, cmd.Action)
 	if err != nil {
-		return "", err
+		t.publishRefusal(ctx, cmd, err)
+	}
+
+	return err
+}
+
+// registerClient check the compatibility of the subject with the other
+// clients of the topic then save the client with the schema fingerprint, if
+// known.
+func (t *Usecase) registerClient(ctx context.Context, cmd *GetSchemaCmd, fingerprint string) error {
+	clientsOnTopic, err := t.storage.GetAllClientsOnTopic(ctx, cmd.Topic)
+	if err != nil {
+		return internal.Wrapf(err, "failed to retrieve the list of clients connected to the topic %q", cmd.Topic)
+	}
+
+	err = t.checkSubjectCompatibility(cmd, fingerprint, clientsOnTopic)
+	if err != nil {
+		t.publishRefusal(ctx, cmd, err)
+		return err
 	}
 
 	client := model.Client{
@@ -78,18 +243,120 @@ func (t *Usecase) GetSchema(ctx context.Context, cmd *GetSchemaCmd) (string, err
 		Action:      cmd.Action,
 		Subject:     cmd.Subject,
 		Version:     cmd.Version,
+		Fingerprint: fingerprint,
 	}
 
 	err = t.storage.RegisterNewClient(ctx, &client)
 	if err != nil {
-		return "", internal.Wrap(err, "failed to register the client")
+		return internal.Wrap(err, "failed to register the client")
+	}
+
+	t.publishRegistration(ctx, &client, clientsOnTopic)
+
+	return nil
+}
+
+// lint check the schema against the lint rules of the topic. The violations
+// with the error severity refuse the request, the warnings are only published.
+// Only the Avro schemas are checked, a nil schema is ignored.
+func (t *Usecase) lint(ctx context.Context, cmd *GetSchemaCmd, schema *avro.Schema) error {
+	if schema == nil {
+		return nil
+	}
+
+	var errs, warnings []string
+	for _, violation := range t.linter.Lint(ctx, cmd.Topic, schema) {
+		if violation.Severity == model.LintWarning {
+			warnings = append(warnings, violation.String())
+		} else {
+			errs = append(errs, violation.String())
+		}
+	}
+
+	if len(errs) > 0 {
+		err := internal.Errorf(internal.ValidationError, "the schema violates the lint rules: %s", strings.Join(errs, ", "))
+		t.publishRefusal(ctx, cmd, err)
+		return err
+	}
+
+	if len(warnings) > 0 {
+		// The version of a schema being registered is not known yet.
+		name := cmd.Subject
+		if cmd.Version != "" {
+			name += "/" + cmd.Version
+		}
+
+		msg := fmt.Sprintf("the schema %q violates the lint rules: %s", name, strings.Join(warnings, ", "))
+		logging.Warn(ctx, msg, logging.Fields{"topic": cmd.Topic, "application": cmd.Application})
+
+		t.publisher.Publish(ctx, model.Event{
+			Type:        model.EventLintWarning,
+			Topic:       cmd.Topic,
+			Application: cmd.Application,
+			Action:      cmd.Action,
+			Subject:     cmd.Subject,
+			Version:     cmd.Version,
+			Message:     msg,
+		})
+	}
+
+	return nil
+}
+
+// publishRefusal publish the reason why the request has been refused.
+func (t *Usecase) publishRefusal(ctx context.Context, cmd *GetSchemaCmd, err error) {
+	t.publisher.Publish(ctx, model.Event{
+		Type:        model.EventRequestRefused,
+		Topic:       cmd.Topic,
+		Application: cmd.Application,
+		Action:      cmd.Action,
+		Subject:     cmd.Subject,
+		Version:     cmd.Version,
+		Message:     err.Error(),
+	})
+}
+
+// publishRegistration publish the event corresponding to the client
+// registration: a new application on the topic or an application switching to
+// an other schema version. Nothing is published if the application already
+// use the same schema version.
+func (t *Usecase) publishRegistration(ctx context.Context, client *model.Client, clientsOnTopic []model.Client) {
+	evt := model.Event{
+		Type:        model.EventClientRegistered,
+		Topic:       client.Topic,
+		Application: client.Application,
+		Action:      client.Action,
+		Subject:     client.Subject,
+		Version:     client.Version,
+		Message:     fmt.Sprintf("the application %q use the schema \"%s/%s\" to %s", client.Application, client.Subject, client.Version, client.Action),
+	}
+
+	for _, previous := range clientsOnTopic {
+		if previous.Application != client.Application || previous.Action != client.Action {
+			continue
+		}
+
+		if previous.Subject == client.Subject && previous.Version == client.Version {
+			return
+		}
+
+		evt.Type = model.EventClientUpgraded
+		evt.Message = fmt.Sprintf("the application %q switched from the schema \"%s/%s\" to \"%s/%s\" to %s",
+			client.Application, previous.Subject, previous.Version, client.Subject, client.Version, client.Action)
 	}
 
-	return schema, nil
+	t.publisher.Publish(ctx, evt)
 }
 
-func (t *Usecase) checkSubjectCompatibility(cmd *GetSchemaCmd, clientsOnTopic []model.Client) error {
+// checkSubjectCompatibility refuse a subject different from the one used by
+// the other clients of the topic. A client with the same schema fingerprint is
+// trivially compatible, whatever its subject.
+func (t *Usecase) checkSubjectCompatibility(cmd *GetSchemaCmd, fingerprint string, clientsOnTopic []model.Client) error {
 	for _, client := range clientsOnTopic {
+		if fingerprint != "" && fingerprint == client.Fingerprint {
+			continue
+		}
+
 		if cmd.Subject != client.Subject {
 			return internal.Errorf(
 				internal.BadRequest,
@@ -104,7 +371,7 @@ func (t *Usecase) checkSubjectCompatibility(cmd *GetSchemaCmd, clientsOnTopic []
 	return nil
 }
 
-func (t *Usecase) validateGetSchemaCmd(cmd *GetSchemaCmd) error {
+func validateGetSchemaCmd(cmd *GetSchemaCmd) error {
 	// Parse the "Version" field.
 	if cmd.Version == "" {
 		return internal.NewError(internal.ValidationError, `missing field "version"`)
@@ -116,6 +383,11 @@ func (t *Usecase) validateGetSchemaCmd(cmd *GetSchemaCmd) error {
 		}
 	}
 
+	return validateClientFields(cmd)
+}
+
+// validateClientFields validate all the fields but the version.
+func validateClientFields(cmd *GetSchemaCmd) error {
 	// Parse the "Subject" field.
 	if cmd.Subject == "" {
 		return internal.NewError(internal.ValidationError, `missing field "subject"`)
@@ -141,3 +413,14 @@ func (t *Usecase) validateGetSchemaCmd(cmd *GetSchemaCmd) error {
 
 	return nil
 }
+
+// parse return the parsed schema or nil if it's not an Avro schema.
+func parse(ctx context.Context, schema string) *avro.Schema {
+	parsed, err := avro.Parse(schema)
+	if err != nil {
+		logging.Debug(ctx, "the schema is not an Avro schema", logging.Fields{"error": err})
+		return nil
+	}
+
+	return parsed
+}
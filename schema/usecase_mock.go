@@ -3,6 +3,7 @@ package schema
 import (
 	"context"
 
+	"github.com/Peltoche/avro-gateway/avro"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -12,8 +13,31 @@ type UsecaseMock struct {
 }
 
 // GetSchema method mock.
-func (t *UsecaseMock) GetSchema(ctx context.Context, cmd *GetSchemaCmd) (string, error) {
+func (t *UsecaseMock) GetSchema(ctx context.Context, cmd *GetSchemaCmd) (string, *avro.Fingerprints, error) {
 	args := t.Called(cmd)
 
-	return args.String(0), args.Error(1)
+	fingerprints, _ := args.Get(1).(*avro.Fingerprints)
+
+	return args.String(0), fingerprints, args.Error(2)
+}
+
+// CheckClient method mock.
+func (t *UsecaseMock) CheckClient(ctx context.Context, cmd *GetSchemaCmd) error {
+	args := t.Called(cmd)
+
+	return args.Error(0)
+}
+
+// LintSchema method mock.
+func (t *UsecaseMock) LintSchema(ctx context.Context, cmd *GetSchemaCmd, schema string) error {
+	args := t.Called(cmd, schema)
+
+	return args.Error(0)
+}
+
+// RegisterClient method mock.
+func (t *UsecaseMock) RegisterClient(ctx context.Context, cmd *GetSchemaCmd, schema string) error {
+	args := t.Called(cmd, schema)
+
+	return args.Error(0)
 }
@@ -5,21 +5,30 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/Peltoche/avro-gateway/acl"
+	"github.com/Peltoche/avro-gateway/avro"
+	"github.com/Peltoche/avro-gateway/event"
 	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/Peltoche/avro-gateway/lint"
 	"github.com/Peltoche/avro-gateway/model"
 	"github.com/Peltoche/avro-gateway/registry"
 	"github.com/Peltoche/avro-gateway/storage"
 	uuid "github.com/satori/go.uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_Usecase_GetSchema_success(t *testing.T) {
 	registryMock := new(registry.Mock)
 	storageMock := new(storage.Mock)
+	publisherMock := new(event.Mock)
+	authorizerMock := new(acl.UsecaseMock)
+	linterMock := new(lint.Mock)
 
-	usecase := NewUsecase(registryMock, storageMock)
+	usecase := NewUsecase(registryMock, storageMock, publisherMock, authorizerMock, linterMock)
 	usecase.generateUUID = func() string { return "some-id" }
 
+	authorizerMock.On("Authorize", "my-application", "some-topic", "read").Return(nil).Once()
 	registryMock.On("FetchSchema", "foobar", "1").Return("some-schema", nil).Once()
 	storageMock.On("GetAllClientsOnTopic", "some-topic").Return([]model.Client{}, nil).Once()
 	storageMock.On("RegisterNewClient", &model.Client{
@@ -30,8 +39,17 @@ func Test_Usecase_GetSchema_success(t *testing.T) {
 		Subject:     "foobar",
 		Version:     "1",
 	}).Return(nil).Once()
+	publisherMock.On("Publish", model.Event{
+		Type:        model.EventClientRegistered,
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "read",
+		Subject:     "foobar",
+		Version:     "1",
+		Message:     `the application "my-application" use the schema "foobar/1" to read`,
+	}).Once()
 
-	schema, err := usecase.GetSchema(context.Background(), &GetSchemaCmd{
+	schema, _, err := usecase.GetSchema(context.Background(), &GetSchemaCmd{
 		Topic:       "some-topic",
 		Application: "my-application",
 		Action:      "read",
@@ -44,15 +62,111 @@ func Test_Usecase_GetSchema_success(t *testing.T) {
 
 	registryMock.AssertExpectations(t)
 	storageMock.AssertExpectations(t)
+	publisherMock.AssertExpectations(t)
+	authorizerMock.AssertExpectations(t)
+}
+
+func Test_Usecase_GetSchema_with_an_upgrade(t *testing.T) {
+	registryMock := new(registry.Mock)
+	storageMock := new(storage.Mock)
+	publisherMock := new(event.Mock)
+	authorizerMock := new(acl.UsecaseMock)
+	linterMock := new(lint.Mock)
+
+	usecase := NewUsecase(registryMock, storageMock, publisherMock, authorizerMock, linterMock)
+	usecase.generateUUID = func() string { return "some-id" }
+
+	authorizerMock.On("Authorize", "my-application", "some-topic", "read").Return(nil).Once()
+	registryMock.On("FetchSchema", "foobar", "2").Return("some-schema", nil).Once()
+	storageMock.On("GetAllClientsOnTopic", "some-topic").Return([]model.Client{
+		{ID: "some-other-id", Topic: "some-topic", Application: "my-application", Action: "read", Subject: "foobar", Version: "1"},
+	}, nil).Once()
+	storageMock.On("RegisterNewClient", &model.Client{
+		ID:          "some-id",
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "read",
+		Subject:     "foobar",
+		Version:     "2",
+	}).Return(nil).Once()
+	publisherMock.On("Publish", model.Event{
+		Type:        model.EventClientUpgraded,
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "read",
+		Subject:     "foobar",
+		Version:     "2",
+		Message:     `the application "my-application" switched from the schema "foobar/1" to "foobar/2" to read`,
+	}).Once()
+
+	schema, _, err := usecase.GetSchema(context.Background(), &GetSchemaCmd{
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "read",
+		Subject:     "foobar",
+		Version:     "2",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "some-schema", schema)
+
+	registryMock.AssertExpectations(t)
+	storageMock.AssertExpectations(t)
+	publisherMock.AssertExpectations(t)
+	authorizerMock.AssertExpectations(t)
+}
+
+func Test_Usecase_GetSchema_with_the_same_version_twice(t *testing.T) {
+	registryMock := new(registry.Mock)
+	storageMock := new(storage.Mock)
+	publisherMock := new(event.Mock)
+	authorizerMock := new(acl.UsecaseMock)
+	linterMock := new(lint.Mock)
+
+	usecase := NewUsecase(registryMock, storageMock, publisherMock, authorizerMock, linterMock)
+	usecase.generateUUID = func() string { return "some-id" }
+
+	authorizerMock.On("Authorize", "my-application", "some-topic", "read").Return(nil).Once()
+	registryMock.On("FetchSchema", "foobar", "1").Return("some-schema", nil).Once()
+	storageMock.On("GetAllClientsOnTopic", "some-topic").Return([]model.Client{
+		{ID: "some-other-id", Topic: "some-topic", Application: "my-application", Action: "read", Subject: "foobar", Version: "1"},
+	}, nil).Once()
+	storageMock.On("RegisterNewClient", &model.Client{
+		ID:          "some-id",
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "read",
+		Subject:     "foobar",
+		Version:     "1",
+	}).Return(nil).Once()
+
+	schema, _, err := usecase.GetSchema(context.Background(), &GetSchemaCmd{
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "read",
+		Subject:     "foobar",
+		Version:     "1",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "some-schema", schema)
+
+	registryMock.AssertExpectations(t)
+	storageMock.AssertExpectations(t)
+	publisherMock.AssertExpectations(t)
+	authorizerMock.AssertExpectations(t)
 }
 
 func Test_Usecase_GetSchema_with_a_schema_validation_error(t *testing.T) {
 	registryMock := new(registry.Mock)
 	storageMock := new(storage.Mock)
+	publisherMock := new(event.Mock)
+	authorizerMock := new(acl.UsecaseMock)
+	linterMock := new(lint.Mock)
 
-	usecase := NewUsecase(registryMock, storageMock)
+	usecase := NewUsecase(registryMock, storageMock, publisherMock, authorizerMock, linterMock)
 
-	schema, err := usecase.GetSchema(context.Background(), &GetSchemaCmd{
+	schema, _, err := usecase.GetSchema(context.Background(), &GetSchemaCmd{
 		Topic:       "some-topic",
 		Application: "my-application",
 		Action:      "read",
@@ -65,17 +179,23 @@ func Test_Usecase_GetSchema_with_a_schema_validation_error(t *testing.T) {
 
 	registryMock.AssertExpectations(t)
 	storageMock.AssertExpectations(t)
+	publisherMock.AssertExpectations(t)
+	authorizerMock.AssertExpectations(t)
 }
 
 func Test_Usecase_GetSchema_with_a_fetch_schema_error(t *testing.T) {
 	registryMock := new(registry.Mock)
 	storageMock := new(storage.Mock)
+	publisherMock := new(event.Mock)
+	authorizerMock := new(acl.UsecaseMock)
+	linterMock := new(lint.Mock)
 
-	usecase := NewUsecase(registryMock, storageMock)
+	usecase := NewUsecase(registryMock, storageMock, publisherMock, authorizerMock, linterMock)
 
+	authorizerMock.On("Authorize", "my-application", "some-topic", "read").Return(nil).Once()
 	registryMock.On("FetchSchema", "foobar", "1").Return("", errors.New("some-error")).Once()
 
-	schema, err := usecase.GetSchema(context.Background(), &GetSchemaCmd{
+	schema, _, err := usecase.GetSchema(context.Background(), &GetSchemaCmd{
 		Topic:       "some-topic",
 		Application: "my-application",
 		Action:      "read",
@@ -88,19 +208,25 @@ func Test_Usecase_GetSchema_with_a_fetch_schema_error(t *testing.T) {
 
 	registryMock.AssertExpectations(t)
 	storageMock.AssertExpectations(t)
+	publisherMock.AssertExpectations(t)
+	authorizerMock.AssertExpectations(t)
 }
 
 func Test_Usecase_GetSchema_with_GetAllClientOnTopic_error(t *testing.T) {
 	registryMock := new(registry.Mock)
 	storageMock := new(storage.Mock)
+	publisherMock := new(event.Mock)
+	authorizerMock := new(acl.UsecaseMock)
+	linterMock := new(lint.Mock)
 
-	usecase := NewUsecase(registryMock, storageMock)
+	usecase := NewUsecase(registryMock, storageMock, publisherMock, authorizerMock, linterMock)
 	usecase.generateUUID = func() string { return "some-id" }
 
+	authorizerMock.On("Authorize", "my-application", "some-topic", "read").Return(nil).Once()
 	registryMock.On("FetchSchema", "foobar", "1").Return("some-schema", nil).Once()
 	storageMock.On("GetAllClientsOnTopic", "some-topic").Return([]model.Client{}, errors.New("some-error")).Once()
 
-	schema, err := usecase.GetSchema(context.Background(), &GetSchemaCmd{
+	schema, _, err := usecase.GetSchema(context.Background(), &GetSchemaCmd{
 		Topic:       "some-topic",
 		Application: "my-application",
 		Action:      "read",
@@ -113,15 +239,21 @@ func Test_Usecase_GetSchema_with_GetAllClientOnTopic_error(t *testing.T) {
 
 	registryMock.AssertExpectations(t)
 	storageMock.AssertExpectations(t)
+	publisherMock.AssertExpectations(t)
+	authorizerMock.AssertExpectations(t)
 }
 
 func Test_Usecase_GetSchema_with_in_incompatible_subject(t *testing.T) {
 	registryMock := new(registry.Mock)
 	storageMock := new(storage.Mock)
+	publisherMock := new(event.Mock)
+	authorizerMock := new(acl.UsecaseMock)
+	linterMock := new(lint.Mock)
 
-	usecase := NewUsecase(registryMock, storageMock)
+	usecase := NewUsecase(registryMock, storageMock, publisherMock, authorizerMock, linterMock)
 	usecase.generateUUID = func() string { return "some-id" }
 
+	authorizerMock.On("Authorize", "my-application", "some-topic", "read").Return(nil).Once()
 	registryMock.On("FetchSchema", "foobar", "1").Return("some-schema", nil).Once()
 	storageMock.On("GetAllClientsOnTopic", "some-topic").Return([]model.Client{
 		{
@@ -135,8 +267,17 @@ func Test_Usecase_GetSchema_with_in_incompatible_subject(t *testing.T) {
 			Version: "1",
 		},
 	}, nil).Once()
+	publisherMock.On("Publish", model.Event{
+		Type:        model.EventRequestRefused,
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "read",
+		Subject:     "foobar",
+		Version:     "1",
+		Message:     `bad request: invalid subject: you can't use the subject "foobar" because the application "an-other-application" use the schema "an-other-subject/1"`,
+	}).Once()
 
-	schema, err := usecase.GetSchema(context.Background(), &GetSchemaCmd{
+	schema, _, err := usecase.GetSchema(context.Background(), &GetSchemaCmd{
 		Topic:       "some-topic",
 		Application: "my-application",
 		Action:      "read",
@@ -149,15 +290,84 @@ func Test_Usecase_GetSchema_with_in_incompatible_subject(t *testing.T) {
 
 	registryMock.AssertExpectations(t)
 	storageMock.AssertExpectations(t)
+	publisherMock.AssertExpectations(t)
+	authorizerMock.AssertExpectations(t)
+}
+
+func Test_Usecase_GetSchema_with_the_same_fingerprint_on_an_other_subject(t *testing.T) {
+	registryMock := new(registry.Mock)
+	storageMock := new(storage.Mock)
+	publisherMock := new(event.Mock)
+	authorizerMock := new(acl.UsecaseMock)
+	linterMock := new(lint.Mock)
+
+	usecase := NewUsecase(registryMock, storageMock, publisherMock, authorizerMock, linterMock)
+	usecase.generateUUID = func() string { return "some-id" }
+
+	// The SHA-256 fingerprint of "string".
+	fingerprint := "e9e5c1c9e4f6277339d1bcde0733a59bd42f8731f449da6dc13010a916930d48"
+
+	authorizerMock.On("Authorize", "my-application", "some-topic", "read").Return(nil).Once()
+	registryMock.On("FetchSchema", "foobar", "1").Return(`{"type": "string", "doc": "Some doc"}`, nil).Once()
+	storageMock.On("GetAllClientsOnTopic", "some-topic").Return([]model.Client{
+		{
+			ID:          "some-other-id",
+			Topic:       "some-topic",
+			Application: "an-other-application",
+			Action:      "write",
+			Subject:     "an-other-subject",
+			Version:     "1",
+			Fingerprint: fingerprint,
+		},
+	}, nil).Once()
+	storageMock.On("RegisterNewClient", &model.Client{
+		ID:          "some-id",
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "read",
+		Subject:     "foobar",
+		Version:     "1",
+		Fingerprint: fingerprint,
+	}).Return(nil).Once()
+	publisherMock.On("Publish", model.Event{
+		Type:        model.EventClientRegistered,
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "read",
+		Subject:     "foobar",
+		Version:     "1",
+		Message:     `the application "my-application" use the schema "foobar/1" to read`,
+	}).Once()
+
+	schema, fingerprints, err := usecase.GetSchema(context.Background(), &GetSchemaCmd{
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "read",
+		Subject:     "foobar",
+		Version:     "1",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, `{"type": "string", "doc": "Some doc"}`, schema)
+	assert.Equal(t, fingerprint, fingerprints.SHA256)
+
+	registryMock.AssertExpectations(t)
+	storageMock.AssertExpectations(t)
+	publisherMock.AssertExpectations(t)
+	authorizerMock.AssertExpectations(t)
 }
 
 func Test_Usecase_GetSchema_with_a_register_client_error(t *testing.T) {
 	registryMock := new(registry.Mock)
 	storageMock := new(storage.Mock)
+	publisherMock := new(event.Mock)
+	authorizerMock := new(acl.UsecaseMock)
+	linterMock := new(lint.Mock)
 
-	usecase := NewUsecase(registryMock, storageMock)
+	usecase := NewUsecase(registryMock, storageMock, publisherMock, authorizerMock, linterMock)
 	usecase.generateUUID = func() string { return "some-id" }
 
+	authorizerMock.On("Authorize", "my-application", "some-topic", "read").Return(nil).Once()
 	registryMock.On("FetchSchema", "foobar", "1").Return("some-schema", nil).Once()
 	storageMock.On("GetAllClientsOnTopic", "some-topic").Return([]model.Client{}, nil).Once()
 	storageMock.On("RegisterNewClient", &model.Client{
@@ -169,7 +379,7 @@ func Test_Usecase_GetSchema_with_a_register_client_error(t *testing.T) {
 		Version:     "1",
 	}).Return(internal.NewError(internal.InternalError, "some-error")).Once()
 
-	schema, err := usecase.GetSchema(context.Background(), &GetSchemaCmd{
+	schema, _, err := usecase.GetSchema(context.Background(), &GetSchemaCmd{
 		Topic:       "some-topic",
 		Application: "my-application",
 		Action:      "read",
@@ -182,6 +392,229 @@ func Test_Usecase_GetSchema_with_a_register_client_error(t *testing.T) {
 
 	registryMock.AssertExpectations(t)
 	storageMock.AssertExpectations(t)
+	publisherMock.AssertExpectations(t)
+	authorizerMock.AssertExpectations(t)
+}
+
+func Test_Usecase_GetSchema_with_a_write_action(t *testing.T) {
+	registryMock := new(registry.Mock)
+	storageMock := new(storage.Mock)
+	publisherMock := new(event.Mock)
+	authorizerMock := new(acl.UsecaseMock)
+	linterMock := new(lint.Mock)
+
+	usecase := NewUsecase(registryMock, storageMock, publisherMock, authorizerMock, linterMock)
+	usecase.generateUUID = func() string { return "some-id" }
+
+	// The SHA-256 fingerprint of "string".
+	fingerprint := "e9e5c1c9e4f6277339d1bcde0733a59bd42f8731f449da6dc13010a916930d48"
+	parsed, err := avro.Parse(`{"type": "string"}`)
+	require.NoError(t, err)
+
+	authorizerMock.On("Authorize", "my-application", "some-topic", "write").Return(nil).Once()
+	registryMock.On("FetchSchema", "foobar", "1").Return(`{"type": "string"}`, nil).Once()
+	linterMock.On("Lint", "some-topic", parsed).Return(nil).Once()
+	storageMock.On("GetAllClientsOnTopic", "some-topic").Return([]model.Client{}, nil).Once()
+	storageMock.On("RegisterNewClient", &model.Client{
+		ID:          "some-id",
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "write",
+		Subject:     "foobar",
+		Version:     "1",
+		Fingerprint: fingerprint,
+	}).Return(nil).Once()
+	publisherMock.On("Publish", model.Event{
+		Type:        model.EventClientRegistered,
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "write",
+		Subject:     "foobar",
+		Version:     "1",
+		Message:     `the application "my-application" use the schema "foobar/1" to write`,
+	}).Once()
+
+	schema, _, err := usecase.GetSchema(context.Background(), &GetSchemaCmd{
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "write",
+		Subject:     "foobar",
+		Version:     "1",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, `{"type": "string"}`, schema)
+
+	registryMock.AssertExpectations(t)
+	storageMock.AssertExpectations(t)
+	publisherMock.AssertExpectations(t)
+	authorizerMock.AssertExpectations(t)
+	linterMock.AssertExpectations(t)
+}
+
+func Test_Usecase_GetSchema_with_a_lint_error(t *testing.T) {
+	registryMock := new(registry.Mock)
+	storageMock := new(storage.Mock)
+	publisherMock := new(event.Mock)
+	authorizerMock := new(acl.UsecaseMock)
+	linterMock := new(lint.Mock)
+
+	usecase := NewUsecase(registryMock, storageMock, publisherMock, authorizerMock, linterMock)
+
+	parsed, err := avro.Parse(`{"type": "string"}`)
+	require.NoError(t, err)
+
+	authorizerMock.On("Authorize", "my-application", "some-topic", "write").Return(nil).Once()
+	registryMock.On("FetchSchema", "foobar", "1").Return(`{"type": "string"}`, nil).Once()
+	linterMock.On("Lint", "some-topic", parsed).Return([]model.LintViolation{
+		{Rule: "require_doc", Severity: model.LintWarning, Path: "$", Message: "the record R has no doc"},
+		{Rule: "field_case", Severity: model.LintError, Path: "$.Foo", Message: `the field "Foo" must be in camelCase`},
+	}).Once()
+	publisherMock.On("Publish", model.Event{
+		Type:        model.EventRequestRefused,
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "write",
+		Subject:     "foobar",
+		Version:     "1",
+		Message:     `validation error: the schema violates the lint rules: $.Foo: the field "Foo" must be in camelCase (field_case)`,
+	}).Once()
+
+	schema, _, err := usecase.GetSchema(context.Background(), &GetSchemaCmd{
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "write",
+		Subject:     "foobar",
+		Version:     "1",
+	})
+
+	assert.Empty(t, schema)
+	assert.True(t, internal.IsKind(internal.ValidationError, err))
+	assert.EqualError(t, err, `validation error: the schema violates the lint rules: $.Foo: the field "Foo" must be in camelCase (field_case)`)
+
+	registryMock.AssertExpectations(t)
+	storageMock.AssertExpectations(t)
+	publisherMock.AssertExpectations(t)
+	authorizerMock.AssertExpectations(t)
+	linterMock.AssertExpectations(t)
+}
+
+func Test_Usecase_GetSchema_with_a_lint_warning(t *testing.T) {
+	registryMock := new(registry.Mock)
+	storageMock := new(storage.Mock)
+	publisherMock := new(event.Mock)
+	authorizerMock := new(acl.UsecaseMock)
+	linterMock := new(lint.Mock)
+
+	usecase := NewUsecase(registryMock, storageMock, publisherMock, authorizerMock, linterMock)
+	usecase.generateUUID = func() string { return "some-id" }
+
+	// The SHA-256 fingerprint of "string".
+	fingerprint := "e9e5c1c9e4f6277339d1bcde0733a59bd42f8731f449da6dc13010a916930d48"
+	parsed, err := avro.Parse(`{"type": "string"}`)
+	require.NoError(t, err)
+
+	authorizerMock.On("Authorize", "my-application", "some-topic", "write").Return(nil).Once()
+	registryMock.On("FetchSchema", "foobar", "1").Return(`{"type": "string"}`, nil).Once()
+	linterMock.On("Lint", "some-topic", parsed).Return([]model.LintViolation{
+		{Rule: "require_doc", Severity: model.LintWarning, Path: "$", Message: "the record R has no doc"},
+	}).Once()
+	storageMock.On("GetAllClientsOnTopic", "some-topic").Return([]model.Client{}, nil).Once()
+	storageMock.On("RegisterNewClient", &model.Client{
+		ID:          "some-id",
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "write",
+		Subject:     "foobar",
+		Version:     "1",
+		Fingerprint: fingerprint,
+	}).Return(nil).Once()
+	publisherMock.On("Publish", model.Event{
+		Type:        model.EventLintWarning,
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "write",
+		Subject:     "foobar",
+		Version:     "1",
+		Message:     `the schema "foobar/1" violates the lint rules: $: the record R has no doc (require_doc)`,
+	}).Once()
+	publisherMock.On("Publish", model.Event{
+		Type:        model.EventClientRegistered,
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "write",
+		Subject:     "foobar",
+		Version:     "1",
+		Message:     `the application "my-application" use the schema "foobar/1" to write`,
+	}).Once()
+
+	schema, _, err := usecase.GetSchema(context.Background(), &GetSchemaCmd{
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "write",
+		Subject:     "foobar",
+		Version:     "1",
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, `{"type": "string"}`, schema)
+
+	registryMock.AssertExpectations(t)
+	storageMock.AssertExpectations(t)
+	publisherMock.AssertExpectations(t)
+	authorizerMock.AssertExpectations(t)
+	linterMock.AssertExpectations(t)
+}
+
+func Test_Usecase_LintSchema_with_a_lint_warning(t *testing.T) {
+	publisherMock := new(event.Mock)
+	linterMock := new(lint.Mock)
+
+	usecase := NewUsecase(new(registry.Mock), new(storage.Mock), publisherMock, new(acl.UsecaseMock), linterMock)
+
+	parsed, err := avro.Parse(`{"type": "string"}`)
+	require.NoError(t, err)
+
+	linterMock.On("Lint", "some-topic", parsed).Return([]model.LintViolation{
+		{Rule: "require_doc", Severity: model.LintWarning, Path: "$", Message: "the record R has no doc"},
+	}).Once()
+	publisherMock.On("Publish", model.Event{
+		Type:        model.EventLintWarning,
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "write",
+		Subject:     "foobar",
+		Message:     `the schema "foobar" violates the lint rules: $: the record R has no doc (require_doc)`,
+	}).Once()
+
+	err = usecase.LintSchema(context.Background(), &GetSchemaCmd{
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "write",
+		Subject:     "foobar",
+	}, `{"type": "string"}`)
+
+	assert.NoError(t, err)
+
+	publisherMock.AssertExpectations(t)
+	linterMock.AssertExpectations(t)
+}
+
+func Test_Usecase_LintSchema_with_a_non_avro_schema(t *testing.T) {
+	linterMock := new(lint.Mock)
+
+	usecase := NewUsecase(new(registry.Mock), new(storage.Mock), new(event.Mock), new(acl.UsecaseMock), linterMock)
+
+	err := usecase.LintSchema(context.Background(), &GetSchemaCmd{
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "write",
+		Subject:     "foobar",
+	}, `{"type": "object"}`)
+
+	assert.NoError(t, err)
+
+	linterMock.AssertExpectations(t)
 }
 
 func Test_Usecase_validateGetSchemaCmd(t *testing.T) {
@@ -254,9 +687,7 @@ func Test_Usecase_validateGetSchemaCmd(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.Title, func(tt *testing.T) {
-			usecase := NewUsecase(nil, nil)
-
-			err := usecase.validateGetSchemaCmd(&test.Cmd)
+			err := validateGetSchemaCmd(&test.Cmd)
 			if test.Err == "" {
 				assert.NoError(tt, err)
 			} else {
@@ -267,9 +698,173 @@ func Test_Usecase_validateGetSchemaCmd(t *testing.T) {
 }
 
 func Test_Usecase_generateUUID_is_a_valid_uuid(t *testing.T) {
-	usecase := NewUsecase(nil, nil)
+	usecase := NewUsecase(nil, nil, nil, nil, nil)
 
 	res := usecase.generateUUID()
 
 	assert.NotNil(t, uuid.FromStringOrNil(res))
 }
+
+func Test_Usecase_GetSchema_with_a_forbidden_access(t *testing.T) {
+	registryMock := new(registry.Mock)
+	storageMock := new(storage.Mock)
+	publisherMock := new(event.Mock)
+	authorizerMock := new(acl.UsecaseMock)
+	linterMock := new(lint.Mock)
+
+	usecase := NewUsecase(registryMock, storageMock, publisherMock, authorizerMock, linterMock)
+
+	authorizerMock.On("Authorize", "my-application", "some-topic", "write").
+		Return(internal.NewError(internal.Forbidden, `the application "my-application" is not allowed to write on the topic "some-topic"`)).Once()
+	publisherMock.On("Publish", model.Event{
+		Type:        model.EventRequestRefused,
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "write",
+		Subject:     "foobar",
+		Version:     "1",
+		Message:     `forbidden: the application "my-application" is not allowed to write on the topic "some-topic"`,
+	}).Once()
+
+	schema, _, err := usecase.GetSchema(context.Background(), &GetSchemaCmd{
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "write",
+		Subject:     "foobar",
+		Version:     "1",
+	})
+
+	assert.EqualError(t, err, `forbidden: the application "my-application" is not allowed to write on the topic "some-topic"`)
+	assert.Empty(t, schema)
+
+	registryMock.AssertExpectations(t)
+	storageMock.AssertExpectations(t)
+	publisherMock.AssertExpectations(t)
+	authorizerMock.AssertExpectations(t)
+}
+
+func Test_Usecase_CheckClient_success(t *testing.T) {
+	registryMock := new(registry.Mock)
+	storageMock := new(storage.Mock)
+	publisherMock := new(event.Mock)
+	authorizerMock := new(acl.UsecaseMock)
+	linterMock := new(lint.Mock)
+
+	usecase := NewUsecase(registryMock, storageMock, publisherMock, authorizerMock, linterMock)
+
+	authorizerMock.On("Authorize", "my-application", "some-topic", "write").Return(nil).Once()
+	storageMock.On("GetAllClientsOnTopic", "some-topic").Return([]model.Client{
+		{ID: "some-other-id", Topic: "some-topic", Application: "some-other-application", Action: "read", Subject: "foobar", Version: "1"},
+	}, nil).Once()
+
+	err := usecase.CheckClient(context.Background(), &GetSchemaCmd{
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "write",
+		Subject:     "foobar",
+	})
+
+	assert.NoError(t, err)
+
+	registryMock.AssertExpectations(t)
+	storageMock.AssertExpectations(t)
+	publisherMock.AssertExpectations(t)
+	authorizerMock.AssertExpectations(t)
+}
+
+func Test_Usecase_CheckClient_with_an_incompatible_subject(t *testing.T) {
+	registryMock := new(registry.Mock)
+	storageMock := new(storage.Mock)
+	publisherMock := new(event.Mock)
+	authorizerMock := new(acl.UsecaseMock)
+	linterMock := new(lint.Mock)
+
+	usecase := NewUsecase(registryMock, storageMock, publisherMock, authorizerMock, linterMock)
+
+	authorizerMock.On("Authorize", "my-application", "some-topic", "write").Return(nil).Once()
+	storageMock.On("GetAllClientsOnTopic", "some-topic").Return([]model.Client{
+		{ID: "some-other-id", Topic: "some-topic", Application: "some-other-application", Action: "read", Subject: "foobar", Version: "1"},
+	}, nil).Once()
+	publisherMock.On("Publish", model.Event{
+		Type:        model.EventRequestRefused,
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "write",
+		Subject:     "other-subject",
+		Message:     `bad request: invalid subject: you can't use the subject "other-subject" because the application "some-other-application" use the schema "foobar/1"`,
+	}).Once()
+
+	err := usecase.CheckClient(context.Background(), &GetSchemaCmd{
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "write",
+		Subject:     "other-subject",
+	})
+
+	assert.True(t, internal.IsKind(internal.BadRequest, err))
+
+	registryMock.AssertExpectations(t)
+	storageMock.AssertExpectations(t)
+	publisherMock.AssertExpectations(t)
+	authorizerMock.AssertExpectations(t)
+}
+
+func Test_Usecase_CheckClient_with_a_validation_error(t *testing.T) {
+	usecase := NewUsecase(new(registry.Mock), new(storage.Mock), new(event.Mock), new(acl.UsecaseMock), new(lint.Mock))
+
+	err := usecase.CheckClient(context.Background(), &GetSchemaCmd{
+		Application: "my-application",
+		Action:      "write",
+		Subject:     "foobar",
+	})
+
+	assert.EqualError(t, err, `validation error: missing field "topic"`)
+}
+
+func Test_Usecase_RegisterClient_success(t *testing.T) {
+	registryMock := new(registry.Mock)
+	storageMock := new(storage.Mock)
+	publisherMock := new(event.Mock)
+	authorizerMock := new(acl.UsecaseMock)
+	linterMock := new(lint.Mock)
+
+	usecase := NewUsecase(registryMock, storageMock, publisherMock, authorizerMock, linterMock)
+	usecase.generateUUID = func() string { return "some-id" }
+
+	authorizerMock.On("Authorize", "my-application", "some-topic", "write").Return(nil).Once()
+	storageMock.On("GetAllClientsOnTopic", "some-topic").Return([]model.Client{}, nil).Once()
+	storageMock.On("RegisterNewClient", &model.Client{
+		ID:          "some-id",
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "write",
+		Subject:     "foobar",
+		Version:     "3",
+		Fingerprint: "e9e5c1c9e4f6277339d1bcde0733a59bd42f8731f449da6dc13010a916930d48",
+	}).Return(nil).Once()
+	publisherMock.On("Publish", model.Event{
+		Type:        model.EventClientRegistered,
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "write",
+		Subject:     "foobar",
+		Version:     "3",
+		Message:     `the application "my-application" use the schema "foobar/3" to write`,
+	}).Once()
+
+	err := usecase.RegisterClient(context.Background(), &GetSchemaCmd{
+		Topic:       "some-topic",
+		Application: "my-application",
+		Action:      "write",
+		Subject:     "foobar",
+		Version:     "3",
+	}, `{"type": "string"}`)
+
+	assert.NoError(t, err)
+
+	// The schema is never fetched.
+	registryMock.AssertExpectations(t)
+	storageMock.AssertExpectations(t)
+	publisherMock.AssertExpectations(t)
+	authorizerMock.AssertExpectations(t)
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/Peltoche/avro-gateway/logging"
+)
+
+// Workers run the background goroutines and stop them at shutdown.
+type Workers struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     *sync.WaitGroup
+}
+
+// NewWorkers instantiate a new Workers.
+func NewWorkers() *Workers {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Workers{
+		ctx:    ctx,
+		cancel: cancel,
+		wg:     new(sync.WaitGroup),
+	}
+}
+
+// Go run fn in a new goroutine. The context given to fn is canceled by Stop.
+func (t *Workers) Go(name string, fn func(ctx context.Context)) {
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+
+		fn(t.ctx)
+		logging.Info(t.ctx, "worker stopped", logging.Fields{"worker": name})
+	}()
+}
+
+// Wait for all the workers to return by themselves, until the context is
+// done.
+func (t *Workers) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return internal.Errorf(internal.InternalError, "workers still running: %s", ctx.Err())
+	}
+}
+
+// Stop cancel the context of the workers then wait for them to return, until
+// the context is done.
+func (t *Workers) Stop(ctx context.Context) error {
+	t.cancel()
+
+	return t.Wait(ctx)
+}
+
+// Serve run listen until a signal is received, then shutdown the server
+// gracefully: draining is called, the listeners are closed and the in-flight
+// requests have drainTimeout to finish before their connections are closed.
+//
+// listen is usually srv.ListenAndServe or srv.ListenAndServeTLS. draining is
+// called while the listeners are still open, to report the server as not
+// ready.
+func Serve(srv *http.Server, listen func() error, signals <-chan os.Signal, drainTimeout time.Duration, draining func()) error {
+	errs := make(chan error, 1)
+	go func() {
+		errs <- listen()
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case sig := <-signals:
+		logging.Info(context.Background(), "shutdown started, draining the requests", logging.Fields{
+			"signal":        sig.String(),
+			"drain_timeout": drainTimeout.String(),
+		})
+	}
+
+	draining()
+
+	ctx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancel()
+
+	err := srv.Shutdown(ctx)
+	if err != nil {
+		logging.Warn(context.Background(), "drain timeout reached, closing the remaining connections", logging.Fields{"error": err})
+		_ = srv.Close()
+	} else {
+		logging.Info(context.Background(), "all the requests are drained", nil)
+	}
+
+	// ListenAndServe return as soon as Shutdown is called.
+	err = <-errs
+	if err != nil && err != http.ErrServerClosed {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package server
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Workers_Stop_success(t *testing.T) {
+	workers := NewWorkers()
+
+	stopped := make(chan struct{})
+	workers.Go("some-worker", func(ctx context.Context) {
+		<-ctx.Done()
+		close(stopped)
+	})
+
+	err := workers.Stop(context.Background())
+
+	require.NoError(t, err)
+	select {
+	case <-stopped:
+	default:
+		t.Fatal("the worker has not been stopped")
+	}
+}
+
+func Test_Workers_Wait_with_a_timeout(t *testing.T) {
+	workers := NewWorkers()
+	defer workers.cancel()
+
+	workers.Go("some-worker", func(ctx context.Context) {
+		<-ctx.Done()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := workers.Wait(ctx)
+
+	assert.EqualError(t, err, "internal error: workers still running: context deadline exceeded")
+}
+
+func startServer(t *testing.T, handler http.Handler, drainTimeout time.Duration, draining func()) (string, chan<- os.Signal, <-chan error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv := &http.Server{Handler: handler}
+	signals := make(chan os.Signal, 1)
+	errs := make(chan error, 1)
+
+	go func() {
+		errs <- Serve(srv, func() error { return srv.Serve(listener) }, signals, drainTimeout, draining)
+	}()
+
+	return "http://" + listener.Addr().String(), signals, errs
+}
+
+func Test_Serve_drain_the_requests(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	url, signals, errs := startServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}), time.Second, func() {})
+
+	statuses := make(chan int, 1)
+	go func() {
+		res, err := http.Post(url+"/schema", "application/json", nil)
+		if err != nil {
+			statuses <- 0
+			return
+		}
+		res.Body.Close()
+		statuses <- res.StatusCode
+	}()
+
+	<-started
+	signals <- syscall.SIGTERM
+
+	// The server must wait for the in-flight request.
+	select {
+	case err := <-errs:
+		t.Fatalf("the server stopped before the end of the request: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+
+	assert.Equal(t, http.StatusOK, <-statuses)
+	assert.NoError(t, <-errs)
+
+	// The listener is closed.
+	_, err := http.Get(url)
+	assert.Error(t, err)
+}
+
+func Test_Serve_with_the_drain_timeout_reached(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+
+	url, signals, errs := startServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	}), 20*time.Millisecond, func() {})
+
+	requestErrs := make(chan error, 1)
+	go func() {
+		_, err := http.Get(url)
+		requestErrs <- err
+	}()
+
+	<-started
+	signals <- syscall.SIGINT
+
+	assert.NoError(t, <-errs)
+	assert.Error(t, <-requestErrs)
+}
+
+func Test_Serve_call_draining_before_closing_the_listener(t *testing.T) {
+	var url string
+	statuses := make(chan int, 1)
+
+	url, signals, errs := startServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), time.Second, func() {
+		// The listener still accept the probes.
+		res, err := http.Get(url)
+		if err != nil {
+			statuses <- 0
+			return
+		}
+		res.Body.Close()
+		statuses <- res.StatusCode
+	})
+
+	signals <- syscall.SIGTERM
+
+	assert.NoError(t, <-errs)
+	assert.Equal(t, http.StatusOK, <-statuses)
+}
@@ -0,0 +1,182 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/Peltoche/avro-gateway/logging"
+)
+
+// TLSConfig is the TLS configuration of the server.
+type TLSConfig struct {
+	CertFile string
+	KeyFile  string
+	// ClientCAFile enable the mutual TLS: the client certificates signed by
+	// one of these CAs are verified and accepted.
+	ClientCAFile string
+	// ClientCertRequired refuse the connections without a valid client
+	// certificate. Ignored without ClientCAFile.
+	ClientCertRequired bool
+}
+
+// CertReloader keep the server certificate and the client CAs in sync with
+// their files, so a renewed certificate is used without restarting the
+// server.
+type CertReloader struct {
+	config TLSConfig
+
+	mutex     *sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTimes  map[string]time.Time
+}
+
+// NewCertReloader instantiate a new CertReloader and load the files.
+func NewCertReloader(config TLSConfig) (*CertReloader, error) {
+	if config.CertFile == "" || config.KeyFile == "" {
+		return nil, internal.NewError(internal.ValidationError, "both the certificate and the key files are required")
+	}
+
+	reloader := &CertReloader{
+		config:   config,
+		mutex:    new(sync.RWMutex),
+		modTimes: map[string]time.Time{},
+	}
+
+	_, err := reloader.Reload()
+	if err != nil {
+		return nil, err
+	}
+
+	return reloader, nil
+}
+
+// Reload the files if one of them has been modified since the last load. It
+// return true if the files have been reloaded.
+//
+// In case of error the previous certificate and CAs are kept.
+func (t *CertReloader) Reload() (bool, error) {
+	modTimes, err := t.readModTimes()
+	if err != nil {
+		return false, err
+	}
+
+	if !t.changed(modTimes) {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(t.config.CertFile, t.config.KeyFile)
+	if err != nil {
+		return false, internal.Errorf(internal.ValidationError, "failed to load the certificate: %s", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if t.config.ClientCAFile != "" {
+		rawCAs, readErr := ioutil.ReadFile(t.config.ClientCAFile)
+		if readErr != nil {
+			return false, internal.Errorf(internal.InternalError, "failed to read the client CA file: %s", readErr)
+		}
+
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(rawCAs) {
+			return false, internal.NewError(internal.ValidationError, "no certificate found in the client CA file")
+		}
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.cert = &cert
+	t.clientCAs = clientCAs
+	t.modTimes = modTimes
+
+	return true, nil
+}
+
+// Run check the files at each interval until the context is canceled.
+func (t *CertReloader) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := t.Reload()
+			if err != nil {
+				logging.Error(ctx, "failed to reload the tls files", logging.Fields{"error": err})
+			} else if reloaded {
+				logging.Info(ctx, "tls files reloaded", nil)
+			}
+		}
+	}
+}
+
+// TLSConfig return the configuration to use with the http.Server. Each
+// handshake use the last loaded files.
+func (t *CertReloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			t.mutex.RLock()
+			defer t.mutex.RUnlock()
+
+			config := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*t.cert},
+			}
+
+			if t.clientCAs != nil {
+				config.ClientCAs = t.clientCAs
+				config.ClientAuth = tls.VerifyClientCertIfGiven
+				if t.config.ClientCertRequired {
+					config.ClientAuth = tls.RequireAndVerifyClientCert
+				}
+			}
+
+			return config, nil
+		},
+	}
+}
+
+func (t *CertReloader) readModTimes() (map[string]time.Time, error) {
+	modTimes := map[string]time.Time{}
+	for _, path := range []string{t.config.CertFile, t.config.KeyFile, t.config.ClientCAFile} {
+		if path == "" {
+			continue
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, internal.Errorf(internal.InternalError, "failed to stat the tls file: %s", err)
+		}
+
+		modTimes[path] = info.ModTime()
+	}
+
+	return modTimes, nil
+}
+
+func (t *CertReloader) changed(modTimes map[string]time.Time) bool {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	if t.cert == nil {
+		return true
+	}
+
+	for path, modTime := range modTimes {
+		if !modTime.Equal(t.modTimes[path]) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,240 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+
+	raw, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(raw)
+	require.NoError(t, err)
+
+	return &testCA{
+		cert: cert,
+		key:  key,
+		pem:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: raw}),
+	}
+}
+
+// issue return a PEM encoded certificate and key signed by the CA.
+func (t *testCA) issue(tt *testing.T, commonName string, usage x509.ExtKeyUsage) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(tt, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+
+	raw, err := x509.CreateCertificate(rand.Reader, template, t.cert, &key.PublicKey, t.key)
+	require.NoError(tt, err)
+
+	rawKey, err := x509.MarshalECPrivateKey(key)
+	require.NoError(tt, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: raw}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: rawKey})
+}
+
+func writeTestFile(t *testing.T, path string, content []byte, modTime time.Time) {
+	require.NoError(t, ioutil.WriteFile(path, content, 0600))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
+
+func startTestServer(t *testing.T, reloader *CertReloader) *httptest.Server {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) > 0 {
+			_, _ = w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+		}
+	}))
+	server.TLS = reloader.TLSConfig()
+	server.StartTLS()
+
+	return server
+}
+
+func newTestClient(ca *testCA, cert *tls.Certificate) *http.Client {
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	config := &tls.Config{RootCAs: roots, ServerName: "localhost"}
+	if cert != nil {
+		config.Certificates = []tls.Certificate{*cert}
+	}
+
+	return &http.Client{Transport: &http.Transport{TLSClientConfig: config}}
+}
+
+func Test_CertReloader_with_a_client_certificate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ca := newTestCA(t)
+	serverCert, serverKey := ca.issue(t, "server", x509.ExtKeyUsageServerAuth)
+	clientCert, clientKey := ca.issue(t, "some-app", x509.ExtKeyUsageClientAuth)
+
+	modTime := time.Now().Add(-time.Minute)
+	writeTestFile(t, filepath.Join(dir, "cert.pem"), serverCert, modTime)
+	writeTestFile(t, filepath.Join(dir, "key.pem"), serverKey, modTime)
+	writeTestFile(t, filepath.Join(dir, "ca.pem"), ca.pem, modTime)
+
+	reloader, err := NewCertReloader(TLSConfig{
+		CertFile:           filepath.Join(dir, "cert.pem"),
+		KeyFile:            filepath.Join(dir, "key.pem"),
+		ClientCAFile:       filepath.Join(dir, "ca.pem"),
+		ClientCertRequired: true,
+	})
+	require.NoError(t, err)
+
+	server := startTestServer(t, reloader)
+	defer server.Close()
+
+	cert, err := tls.X509KeyPair(clientCert, clientKey)
+	require.NoError(t, err)
+
+	res, err := newTestClient(ca, &cert).Get(server.URL)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "some-app", string(body))
+
+	// The connection without certificate is refused.
+	_, err = newTestClient(ca, nil).Get(server.URL)
+	assert.Error(t, err)
+}
+
+func Test_CertReloader_Reload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ca := newTestCA(t)
+	serverCert, serverKey := ca.issue(t, "first", x509.ExtKeyUsageServerAuth)
+
+	modTime := time.Now().Add(-time.Minute)
+	writeTestFile(t, filepath.Join(dir, "cert.pem"), serverCert, modTime)
+	writeTestFile(t, filepath.Join(dir, "key.pem"), serverKey, modTime)
+
+	reloader, err := NewCertReloader(TLSConfig{
+		CertFile: filepath.Join(dir, "cert.pem"),
+		KeyFile:  filepath.Join(dir, "key.pem"),
+	})
+	require.NoError(t, err)
+
+	// Nothing changed.
+	reloaded, err := reloader.Reload()
+	require.NoError(t, err)
+	assert.False(t, reloaded)
+
+	server := startTestServer(t, reloader)
+	defer server.Close()
+
+	client := newTestClient(ca, nil)
+
+	res, err := client.Get(server.URL)
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, "first", res.TLS.PeerCertificates[0].Subject.CommonName)
+
+	// Renew the certificate.
+	serverCert, serverKey = ca.issue(t, "second", x509.ExtKeyUsageServerAuth)
+	writeTestFile(t, filepath.Join(dir, "cert.pem"), serverCert, modTime.Add(time.Second))
+	writeTestFile(t, filepath.Join(dir, "key.pem"), serverKey, modTime.Add(time.Second))
+
+	reloaded, err = reloader.Reload()
+	require.NoError(t, err)
+	assert.True(t, reloaded)
+
+	client = newTestClient(ca, nil)
+	res, err = client.Get(server.URL)
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, "second", res.TLS.PeerCertificates[0].Subject.CommonName)
+}
+
+func Test_CertReloader_Reload_keeps_the_previous_certificate_on_error(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ca := newTestCA(t)
+	serverCert, serverKey := ca.issue(t, "first", x509.ExtKeyUsageServerAuth)
+
+	modTime := time.Now().Add(-time.Minute)
+	writeTestFile(t, filepath.Join(dir, "cert.pem"), serverCert, modTime)
+	writeTestFile(t, filepath.Join(dir, "key.pem"), serverKey, modTime)
+
+	reloader, err := NewCertReloader(TLSConfig{
+		CertFile: filepath.Join(dir, "cert.pem"),
+		KeyFile:  filepath.Join(dir, "key.pem"),
+	})
+	require.NoError(t, err)
+
+	// A half written certificate.
+	writeTestFile(t, filepath.Join(dir, "cert.pem"), []byte("invalid"), modTime.Add(time.Second))
+
+	reloaded, err := reloader.Reload()
+	assert.False(t, reloaded)
+	assert.Error(t, err)
+
+	server := startTestServer(t, reloader)
+	defer server.Close()
+
+	res, err := newTestClient(ca, nil).Get(server.URL)
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, "first", res.TLS.PeerCertificates[0].Subject.CommonName)
+}
+
+func Test_NewCertReloader_with_missing_files(t *testing.T) {
+	reloader, err := NewCertReloader(TLSConfig{CertFile: "/some/unknown/cert.pem"})
+
+	assert.Nil(t, reloader)
+	assert.EqualError(t, err, "validation error: both the certificate and the key files are required")
+}
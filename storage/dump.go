@@ -0,0 +1,430 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"path"
+	"reflect"
+
+	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/Peltoche/avro-gateway/model"
+)
+
+// DumpFormat is the identifier written in the header of every dump.
+const DumpFormat = "avro-gateway-dump"
+
+// DumpVersion is the version of the dump format generated by Export.
+//
+// Import refuse any dump with a greater version. The version 2 added the audit
+// records and the version 3 the ACL records.
+const DumpVersion = 3
+
+const (
+	// dumpRecordClient is the record type used for the model.Client entries.
+	dumpRecordClient = "client"
+	// dumpRecordAudit is the record type used for the model.AuditEntry entries.
+	dumpRecordAudit = "audit"
+	// dumpRecordACL is the record type used for the model.ACLRule entries.
+	dumpRecordACL = "acl"
+)
+
+// ImportMode define how the dump is applied on the destination storage.
+//
+// The audit log is append-only: whatever the mode, the audit entries from the
+// dump are added to the existing ones, skipping those already present.
+type ImportMode string
+
+var (
+	// MergeMode keep the existing clients and add the ones from the dump. A
+	// client present on both sides with a different content is a conflict.
+	MergeMode ImportMode = "merge"
+	// ReplaceMode remove all the existing clients before adding the ones from
+	// the dump.
+	ReplaceMode ImportMode = "replace"
+)
+
+// Dumper is a storage able to list all its content.
+type Dumper interface {
+	GetAllClients(ctx context.Context) ([]model.Client, error)
+	FindAuditEntries(ctx context.Context, filter *model.AuditFilter) ([]model.AuditEntry, error)
+	GetAllACLRules(ctx context.Context) ([]model.ACLRule, error)
+}
+
+// Restorer is a storage able to receive the content of a dump.
+type Restorer interface {
+	Dumper
+	// Restore replace all the storage content with the dump, at once.
+	Restore(ctx context.Context, dump *Dump) error
+}
+
+// ImportOptions are the parameters for the Import function.
+type ImportOptions struct {
+	Mode ImportMode
+	// DryRun validate the dump and compute the report without modifying the
+	// destination storage.
+	DryRun bool
+}
+
+// ImportReport describe the changes made (or that would be made in case of
+// dry-run) by an Import.
+type ImportReport struct {
+	Added      int `json:"added"`
+	Unchanged  int `json:"unchanged"`
+	Deleted    int `json:"deleted"`
+	AuditAdded int `json:"audit_added"`
+
+	ACLAdded     int `json:"acl_added"`
+	ACLUnchanged int `json:"acl_unchanged"`
+	ACLDeleted   int `json:"acl_deleted"`
+}
+
+// Dump is the content of a dump.
+type Dump struct {
+	Clients      []model.Client
+	AuditEntries []model.AuditEntry
+	ACLRules     []model.ACLRule
+}
+
+type dumpHeader struct {
+	Format  string `json:"format"`
+	Version int    `json:"version"`
+}
+
+type dumpRecord struct {
+	Type   string            `json:"type"`
+	Client *model.Client     `json:"client,omitempty"`
+	Audit  *model.AuditEntry `json:"audit,omitempty"`
+	ACL    *model.ACLRule    `json:"acl,omitempty"`
+}
+
+// Export write all the content of the given storage into w using the
+// versioned JSON-lines dump format.
+//
+// The first line is a header with the format version, then each following
+// line is a single record. It return the number of records written.
+func Export(ctx context.Context, src Dumper, w io.Writer) (int, error) {
+	clients, err := src.GetAllClients(ctx)
+	if err != nil {
+		return 0, internal.Wrap(err, "failed to retrieve the clients")
+	}
+
+	auditEntries, err := src.FindAuditEntries(ctx, &model.AuditFilter{})
+	if err != nil {
+		return 0, internal.Wrap(err, "failed to retrieve the audit entries")
+	}
+
+	aclRules, err := src.GetAllACLRules(ctx)
+	if err != nil {
+		return 0, internal.Wrap(err, "failed to retrieve the acl rules")
+	}
+
+	encoder := json.NewEncoder(w)
+
+	err = encoder.Encode(dumpHeader{Format: DumpFormat, Version: DumpVersion})
+	if err != nil {
+		return 0, internal.Errorf(internal.InternalError, "failed to write the header: %s", err)
+	}
+
+	for i := range clients {
+		err = encoder.Encode(dumpRecord{Type: dumpRecordClient, Client: &clients[i]})
+		if err != nil {
+			return i, internal.Errorf(internal.InternalError, "failed to write the client %q: %s", clients[i].ID, err)
+		}
+	}
+
+	for i := range auditEntries {
+		err = encoder.Encode(dumpRecord{Type: dumpRecordAudit, Audit: &auditEntries[i]})
+		if err != nil {
+			return len(clients) + i, internal.Errorf(internal.InternalError, "failed to write the audit entry %q: %s", auditEntries[i].ID, err)
+		}
+	}
+
+	for i := range aclRules {
+		err = encoder.Encode(dumpRecord{Type: dumpRecordACL, ACL: &aclRules[i]})
+		if err != nil {
+			return len(clients) + len(auditEntries) + i, internal.Errorf(internal.InternalError, "failed to write the acl rule %q: %s", aclRules[i].ID, err)
+		}
+	}
+
+	return len(clients) + len(auditEntries) + len(aclRules), nil
+}
+
+// ReadDump parse and validate a dump generated by Export.
+//
+// The whole dump is validated before returning so an invalid dump never
+// result in a partial import.
+func ReadDump(r io.Reader) (*Dump, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 10*1024*1024)
+
+	if !scanner.Scan() {
+		if scanner.Err() != nil {
+			return nil, internal.Errorf(internal.InternalError, "failed to read the dump: %s", scanner.Err())
+		}
+
+		return nil, internal.NewError(internal.ValidationError, "empty dump: missing header")
+	}
+
+	var header dumpHeader
+	err := json.Unmarshal(scanner.Bytes(), &header)
+	if err != nil {
+		return nil, internal.Errorf(internal.ValidationError, "line 1: invalid header: %s", err)
+	}
+	if header.Format != DumpFormat {
+		return nil, internal.Errorf(internal.ValidationError, "line 1: unknown dump format %q", header.Format)
+	}
+	if header.Version < 1 || header.Version > DumpVersion {
+		return nil, internal.Errorf(internal.ValidationError, "line 1: unsupported dump version %d", header.Version)
+	}
+
+	dump := Dump{
+		Clients:      []model.Client{},
+		AuditEntries: []model.AuditEntry{},
+		ACLRules:     []model.ACLRule{},
+	}
+	seen := map[string]bool{}
+	seenAudit := map[string]bool{}
+	seenACL := map[string]bool{}
+	line := 1
+	for scanner.Scan() {
+		line++
+
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var record dumpRecord
+		err = json.Unmarshal(scanner.Bytes(), &record)
+		if err != nil {
+			return nil, internal.Errorf(internal.ValidationError, "line %d: invalid record: %s", line, err)
+		}
+
+		switch record.Type {
+		case dumpRecordClient:
+			err = validateDumpClient(record.Client)
+			if err != nil {
+				return nil, internal.Wrapf(err, "line %d", line)
+			}
+
+			if seen[record.Client.ID] {
+				return nil, internal.Errorf(internal.ValidationError, "line %d: client %q present twice", line, record.Client.ID)
+			}
+			seen[record.Client.ID] = true
+
+			dump.Clients = append(dump.Clients, *record.Client)
+		case dumpRecordAudit:
+			if header.Version < 2 {
+				return nil, internal.Errorf(internal.ValidationError, "line %d: audit records require the dump version 2", line)
+			}
+
+			if record.Audit == nil || record.Audit.ID == "" {
+				return nil, internal.Errorf(internal.ValidationError, `line %d: missing field "audit.id"`, line)
+			}
+
+			if seenAudit[record.Audit.ID] {
+				return nil, internal.Errorf(internal.ValidationError, "line %d: audit entry %q present twice", line, record.Audit.ID)
+			}
+			seenAudit[record.Audit.ID] = true
+
+			dump.AuditEntries = append(dump.AuditEntries, *record.Audit)
+		case dumpRecordACL:
+			if header.Version < 3 {
+				return nil, internal.Errorf(internal.ValidationError, "line %d: acl records require the dump version 3", line)
+			}
+
+			err = validateDumpACLRule(record.ACL)
+			if err != nil {
+				return nil, internal.Wrapf(err, "line %d", line)
+			}
+
+			if seenACL[record.ACL.ID] {
+				return nil, internal.Errorf(internal.ValidationError, "line %d: acl rule %q present twice", line, record.ACL.ID)
+			}
+			seenACL[record.ACL.ID] = true
+
+			dump.ACLRules = append(dump.ACLRules, *record.ACL)
+		default:
+			return nil, internal.Errorf(internal.ValidationError, "line %d: unknown record type %q", line, record.Type)
+		}
+	}
+
+	if scanner.Err() != nil {
+		return nil, internal.Errorf(internal.InternalError, "failed to read the dump: %s", scanner.Err())
+	}
+
+	return &dump, nil
+}
+
+// Import restore a dump generated by Export into the given storage.
+func Import(ctx context.Context, dst Restorer, r io.Reader, opts ImportOptions) (*ImportReport, error) {
+	if opts.Mode != MergeMode && opts.Mode != ReplaceMode {
+		return nil, internal.Errorf(internal.ValidationError, "invalid import mode %q", opts.Mode)
+	}
+
+	dump, err := ReadDump(r)
+	if err != nil {
+		return nil, internal.Wrap(err, "failed to read the dump")
+	}
+	clients := dump.Clients
+
+	existingClients, err := dst.GetAllClients(ctx)
+	if err != nil {
+		return nil, internal.Wrap(err, "failed to retrieve the existing clients")
+	}
+
+	existing := make(map[string]model.Client, len(existingClients))
+	for _, client := range existingClients {
+		existing[client.ID] = client
+	}
+
+	report := ImportReport{}
+	toKeep := existingClients
+	toAdd := []model.Client{}
+
+	if opts.Mode == ReplaceMode {
+		toKeep = []model.Client{}
+		toAdd = clients
+		report.Added = len(clients)
+		report.Deleted = len(existingClients)
+	} else {
+		for _, client := range clients {
+			current, present := existing[client.ID]
+			switch {
+			case !present:
+				toAdd = append(toAdd, client)
+				report.Added++
+			case current == client:
+				report.Unchanged++
+			default:
+				return nil, internal.Errorf(internal.ValidationError, "conflict on client %q: a different client with the same id already exists", client.ID)
+			}
+		}
+	}
+
+	existingAuditEntries, err := dst.FindAuditEntries(ctx, &model.AuditFilter{})
+	if err != nil {
+		return nil, internal.Wrap(err, "failed to retrieve the existing audit entries")
+	}
+
+	existingAudit := make(map[string]bool, len(existingAuditEntries))
+	for _, entry := range existingAuditEntries {
+		existingAudit[entry.ID] = true
+	}
+
+	auditToAdd := []model.AuditEntry{}
+	for _, entry := range dump.AuditEntries {
+		if !existingAudit[entry.ID] {
+			auditToAdd = append(auditToAdd, entry)
+		}
+	}
+	report.AuditAdded = len(auditToAdd)
+
+	existingACLRules, err := dst.GetAllACLRules(ctx)
+	if err != nil {
+		return nil, internal.Wrap(err, "failed to retrieve the existing acl rules")
+	}
+
+	existingACL := make(map[string]model.ACLRule, len(existingACLRules))
+	for _, rule := range existingACLRules {
+		existingACL[rule.ID] = rule
+	}
+
+	aclToKeep := existingACLRules
+	aclToAdd := []model.ACLRule{}
+
+	if opts.Mode == ReplaceMode {
+		aclToKeep = []model.ACLRule{}
+		aclToAdd = dump.ACLRules
+		report.ACLAdded = len(dump.ACLRules)
+		report.ACLDeleted = len(existingACLRules)
+	} else {
+		for _, rule := range dump.ACLRules {
+			current, present := existingACL[rule.ID]
+			switch {
+			case !present:
+				aclToAdd = append(aclToAdd, rule)
+				report.ACLAdded++
+			case reflect.DeepEqual(current, rule):
+				report.ACLUnchanged++
+			default:
+				return nil, internal.Errorf(internal.ValidationError, "conflict on acl rule %q: a different rule with the same id already exists", rule.ID)
+			}
+		}
+	}
+
+	if opts.DryRun {
+		return &report, nil
+	}
+
+	// The new content is built then written at once, so a failure never leave
+	// the storage half replaced.
+	content := Dump{
+		Clients:      append(toKeep, toAdd...),
+		AuditEntries: append(existingAuditEntries, auditToAdd...),
+		ACLRules:     append(aclToKeep, aclToAdd...),
+	}
+
+	err = dst.Restore(ctx, &content)
+	if err != nil {
+		return nil, internal.Wrap(err, "failed to restore the dump")
+	}
+
+	return &report, nil
+}
+
+func validateDumpClient(client *model.Client) error {
+	if client == nil {
+		return internal.NewError(internal.ValidationError, `missing field "client"`)
+	}
+
+	fields := []struct {
+		name  string
+		value string
+	}{
+		{"id", client.ID},
+		{"topic", client.Topic},
+		{"application", client.Application},
+		{"action", client.Action},
+		{"subject", client.Subject},
+		{"version", client.Version},
+	}
+
+	for _, field := range fields {
+		if field.value == "" {
+			return internal.Errorf(internal.ValidationError, "missing field %q for client %q", field.name, client.ID)
+		}
+	}
+
+	if client.Action != "read" && client.Action != "write" {
+		return internal.Errorf(internal.ValidationError, "invalid action %q for client %q", client.Action, client.ID)
+	}
+
+	return nil
+}
+
+func validateDumpACLRule(rule *model.ACLRule) error {
+	if rule == nil {
+		return internal.NewError(internal.ValidationError, `missing field "acl"`)
+	}
+
+	if rule.ID == "" {
+		return internal.NewError(internal.ValidationError, `missing field "id" for acl rule`)
+	}
+
+	if rule.Effect != model.ACLAllow && rule.Effect != model.ACLDeny {
+		return internal.Errorf(internal.ValidationError, "invalid effect %q for acl rule %q", rule.Effect, rule.ID)
+	}
+
+	if rule.Application == "" {
+		return internal.Errorf(internal.ValidationError, "missing field %q for acl rule %q", "application", rule.ID)
+	}
+
+	_, err := path.Match(rule.Topic, "")
+	if rule.Topic == "" || err != nil {
+		return internal.Errorf(internal.ValidationError, "invalid topic pattern %q for acl rule %q", rule.Topic, rule.ID)
+	}
+
+	return nil
+}
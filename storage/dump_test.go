@@ -0,0 +1,358 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Peltoche/avro-gateway/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var dumpClient = model.Client{
+	ID:          "some-id",
+	Topic:       "some-topic",
+	Application: "some-app",
+	Action:      "read",
+	Subject:     "my-avro-subject",
+	Version:     "2",
+}
+
+var dumpClient2 = model.Client{
+	ID:          "some-other-id",
+	Topic:       "some-topic",
+	Application: "some-other-app",
+	Action:      "write",
+	Subject:     "my-avro-subject",
+	Version:     "3",
+}
+
+func Test_Export_success(t *testing.T) {
+	storage := NewInMemory()
+	require.NoError(t, storage.RegisterNewClient(context.Background(), &dumpClient2))
+	require.NoError(t, storage.RegisterNewClient(context.Background(), &dumpClient))
+
+	buf := new(bytes.Buffer)
+	count, err := Export(context.Background(), storage, buf)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, `{"format":"avro-gateway-dump","version":3}
+{"type":"client","client":{"id":"some-id","topic":"some-topic","application":"some-app","action":"read","subject":"my-avro-subject","version":"2"}}
+{"type":"client","client":{"id":"some-other-id","topic":"some-topic","application":"some-other-app","action":"write","subject":"my-avro-subject","version":"3"}}
+`, buf.String())
+}
+
+func Test_Export_Import_roundtrip(t *testing.T) {
+	src := NewInMemory()
+	require.NoError(t, src.RegisterNewClient(context.Background(), &dumpClient))
+	require.NoError(t, src.RegisterNewClient(context.Background(), &dumpClient2))
+
+	buf := new(bytes.Buffer)
+	_, err := Export(context.Background(), src, buf)
+	require.NoError(t, err)
+
+	dst := NewInMemory()
+	report, err := Import(context.Background(), dst, buf, ImportOptions{Mode: MergeMode})
+	require.NoError(t, err)
+	assert.Equal(t, &ImportReport{Added: 2}, report)
+
+	res, err := dst.GetAllClients(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []model.Client{dumpClient, dumpClient2}, res)
+}
+
+func Test_Import_audit_entries_are_always_merged(t *testing.T) {
+	date := time.Date(2019, time.February, 3, 10, 0, 0, 0, time.UTC)
+	entry := model.AuditEntry{ID: "some-audit-id", Time: date, Topic: "some-topic", Decision: model.AuditAccepted}
+	entry2 := model.AuditEntry{ID: "some-other-audit-id", Time: date, Topic: "some-topic", Decision: model.AuditRefused, Reasons: []string{"some-reason"}}
+
+	dst := NewInMemory()
+	require.NoError(t, dst.AppendAuditEntry(context.Background(), &entry))
+
+	src := NewInMemory()
+	require.NoError(t, src.AppendAuditEntry(context.Background(), &entry))
+	require.NoError(t, src.AppendAuditEntry(context.Background(), &entry2))
+
+	buf := new(bytes.Buffer)
+	count, err := Export(context.Background(), src, buf)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	report, err := Import(context.Background(), dst, buf, ImportOptions{Mode: ReplaceMode})
+	require.NoError(t, err)
+	assert.Equal(t, &ImportReport{AuditAdded: 1}, report)
+
+	res, err := dst.FindAuditEntries(context.Background(), &model.AuditFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []model.AuditEntry{entry, entry2}, res)
+}
+
+func Test_Import_acl_rules(t *testing.T) {
+	rule := model.ACLRule{ID: "some-rule-id", Effect: model.ACLAllow, Application: "some-app", Topic: "orders.*", Actions: []string{"write"}}
+	rule2 := model.ACLRule{ID: "some-other-rule-id", Effect: model.ACLDeny, Application: "*", Topic: "orders.*"}
+	conflicting := rule
+	conflicting.Actions = []string{"read"}
+
+	src := NewInMemory()
+	require.NoError(t, src.RegisterNewACLRule(context.Background(), &rule))
+	require.NoError(t, src.RegisterNewACLRule(context.Background(), &rule2))
+
+	buf := new(bytes.Buffer)
+	_, err := Export(context.Background(), src, buf)
+	require.NoError(t, err)
+	dump := buf.String()
+
+	// Merge with the same rule already present.
+	dst := NewInMemory()
+	require.NoError(t, dst.RegisterNewACLRule(context.Background(), &rule))
+
+	report, err := Import(context.Background(), dst, strings.NewReader(dump), ImportOptions{Mode: MergeMode})
+	require.NoError(t, err)
+	assert.Equal(t, &ImportReport{ACLAdded: 1, ACLUnchanged: 1}, report)
+
+	res, err := dst.GetAllACLRules(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []model.ACLRule{rule2, rule}, res)
+
+	// Merge with a conflicting rule.
+	dst = NewInMemory()
+	require.NoError(t, dst.RegisterNewACLRule(context.Background(), &conflicting))
+
+	report, err = Import(context.Background(), dst, strings.NewReader(dump), ImportOptions{Mode: MergeMode})
+	assert.Nil(t, report)
+	assert.EqualError(t, err, `validation error: conflict on acl rule "some-rule-id": a different rule with the same id already exists`)
+
+	// Replace the conflicting rule.
+	report, err = Import(context.Background(), dst, strings.NewReader(dump), ImportOptions{Mode: ReplaceMode})
+	require.NoError(t, err)
+	assert.Equal(t, &ImportReport{ACLAdded: 2, ACLDeleted: 1}, report)
+
+	res, err = dst.GetAllACLRules(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []model.ACLRule{rule2, rule}, res)
+}
+
+func Test_Import_version_1_dump(t *testing.T) {
+	dump := `{"format":"avro-gateway-dump","version":1}
+{"type":"client","client":{"id":"some-id","topic":"some-topic","application":"some-app","action":"read","subject":"my-avro-subject","version":"2"}}
+`
+
+	dst := NewInMemory()
+	report, err := Import(context.Background(), dst, strings.NewReader(dump), ImportOptions{Mode: MergeMode})
+	require.NoError(t, err)
+	assert.Equal(t, &ImportReport{Added: 1}, report)
+
+	res, err := dst.GetAllClients(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []model.Client{dumpClient}, res)
+}
+
+func Test_Import_merge_with_existing_clients(t *testing.T) {
+	dst := NewInMemory()
+	require.NoError(t, dst.RegisterNewClient(context.Background(), &dumpClient))
+
+	src := NewInMemory()
+	require.NoError(t, src.RegisterNewClient(context.Background(), &dumpClient))
+	require.NoError(t, src.RegisterNewClient(context.Background(), &dumpClient2))
+
+	buf := new(bytes.Buffer)
+	_, err := Export(context.Background(), src, buf)
+	require.NoError(t, err)
+
+	report, err := Import(context.Background(), dst, buf, ImportOptions{Mode: MergeMode})
+	require.NoError(t, err)
+	assert.Equal(t, &ImportReport{Added: 1, Unchanged: 1}, report)
+
+	res, err := dst.GetAllClients(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []model.Client{dumpClient, dumpClient2}, res)
+}
+
+func Test_Import_merge_with_a_conflict(t *testing.T) {
+	conflicting := dumpClient
+	conflicting.Version = "42"
+
+	dst := NewInMemory()
+	require.NoError(t, dst.RegisterNewClient(context.Background(), &conflicting))
+
+	src := NewInMemory()
+	require.NoError(t, src.RegisterNewClient(context.Background(), &dumpClient))
+	require.NoError(t, src.RegisterNewClient(context.Background(), &dumpClient2))
+
+	buf := new(bytes.Buffer)
+	_, err := Export(context.Background(), src, buf)
+	require.NoError(t, err)
+
+	report, err := Import(context.Background(), dst, buf, ImportOptions{Mode: MergeMode})
+	assert.Nil(t, report)
+	assert.EqualError(t, err, `validation error: conflict on client "some-id": a different client with the same id already exists`)
+
+	// Nothing must have been written.
+	res, err := dst.GetAllClients(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []model.Client{conflicting}, res)
+}
+
+func Test_Import_replace(t *testing.T) {
+	dst := NewInMemory()
+	require.NoError(t, dst.RegisterNewClient(context.Background(), &dumpClient))
+
+	src := NewInMemory()
+	require.NoError(t, src.RegisterNewClient(context.Background(), &dumpClient2))
+
+	buf := new(bytes.Buffer)
+	_, err := Export(context.Background(), src, buf)
+	require.NoError(t, err)
+
+	report, err := Import(context.Background(), dst, buf, ImportOptions{Mode: ReplaceMode})
+	require.NoError(t, err)
+	assert.Equal(t, &ImportReport{Added: 1, Deleted: 1}, report)
+
+	res, err := dst.GetAllClients(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []model.Client{dumpClient2}, res)
+}
+
+func Test_Import_dry_run(t *testing.T) {
+	dst := NewInMemory()
+	require.NoError(t, dst.RegisterNewClient(context.Background(), &dumpClient))
+
+	src := NewInMemory()
+	require.NoError(t, src.RegisterNewClient(context.Background(), &dumpClient2))
+
+	buf := new(bytes.Buffer)
+	_, err := Export(context.Background(), src, buf)
+	require.NoError(t, err)
+
+	report, err := Import(context.Background(), dst, buf, ImportOptions{Mode: ReplaceMode, DryRun: true})
+	require.NoError(t, err)
+	assert.Equal(t, &ImportReport{Added: 1, Deleted: 1}, report)
+
+	res, err := dst.GetAllClients(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []model.Client{dumpClient}, res)
+}
+
+func Test_Import_with_an_invalid_mode(t *testing.T) {
+	report, err := Import(context.Background(), NewInMemory(), strings.NewReader(""), ImportOptions{Mode: "foobar"})
+
+	assert.Nil(t, report)
+	assert.EqualError(t, err, `validation error: invalid import mode "foobar"`)
+}
+
+func Test_ReadDump_with_invalid_inputs(t *testing.T) {
+	tests := []struct {
+		Name  string
+		Input string
+		Error string
+	}{
+		{
+			Name:  "empty dump",
+			Input: "",
+			Error: "validation error: empty dump: missing header",
+		},
+		{
+			Name:  "invalid header",
+			Input: "foobar\n",
+			Error: "validation error: line 1: invalid header: invalid character 'o' in literal false (expecting 'a')",
+		},
+		{
+			Name:  "unknown format",
+			Input: `{"format":"foobar","version":1}`,
+			Error: `validation error: line 1: unknown dump format "foobar"`,
+		},
+		{
+			Name:  "unsupported version",
+			Input: `{"format":"avro-gateway-dump","version":4}`,
+			Error: "validation error: line 1: unsupported dump version 4",
+		},
+		{
+			Name: "unknown record type",
+			Input: `{"format":"avro-gateway-dump","version":1}
+{"type":"foobar"}`,
+			Error: `validation error: line 2: unknown record type "foobar"`,
+		},
+		{
+			Name: "missing client",
+			Input: `{"format":"avro-gateway-dump","version":1}
+{"type":"client"}`,
+			Error: `validation error: line 2: missing field "client"`,
+		},
+		{
+			Name: "missing client field",
+			Input: `{"format":"avro-gateway-dump","version":1}
+{"type":"client","client":{"id":"some-id","topic":"some-topic","application":"some-app","action":"read","subject":"my-avro-subject"}}`,
+			Error: `validation error: line 2: missing field "version" for client "some-id"`,
+		},
+		{
+			Name: "invalid client action",
+			Input: `{"format":"avro-gateway-dump","version":1}
+{"type":"client","client":{"id":"some-id","topic":"some-topic","application":"some-app","action":"foo","subject":"my-avro-subject","version":"1"}}`,
+			Error: `validation error: line 2: invalid action "foo" for client "some-id"`,
+		},
+		{
+			Name: "duplicated client",
+			Input: `{"format":"avro-gateway-dump","version":1}
+{"type":"client","client":{"id":"some-id","topic":"some-topic","application":"some-app","action":"read","subject":"my-avro-subject","version":"1"}}
+{"type":"client","client":{"id":"some-id","topic":"some-topic","application":"some-app","action":"read","subject":"my-avro-subject","version":"1"}}`,
+			Error: `validation error: line 3: client "some-id" present twice`,
+		},
+		{
+			Name: "audit record in a version 1 dump",
+			Input: `{"format":"avro-gateway-dump","version":1}
+{"type":"audit","audit":{"id":"some-audit-id"}}`,
+			Error: "validation error: line 2: audit records require the dump version 2",
+		},
+		{
+			Name: "missing audit id",
+			Input: `{"format":"avro-gateway-dump","version":2}
+{"type":"audit","audit":{"topic":"some-topic"}}`,
+			Error: `validation error: line 2: missing field "audit.id"`,
+		},
+		{
+			Name: "duplicated audit entry",
+			Input: `{"format":"avro-gateway-dump","version":2}
+{"type":"audit","audit":{"id":"some-audit-id"}}
+{"type":"audit","audit":{"id":"some-audit-id"}}`,
+			Error: `validation error: line 3: audit entry "some-audit-id" present twice`,
+		},
+		{
+			Name: "acl record in a version 2 dump",
+			Input: `{"format":"avro-gateway-dump","version":2}
+{"type":"acl","acl":{"id":"some-rule-id"}}`,
+			Error: "validation error: line 2: acl records require the dump version 3",
+		},
+		{
+			Name: "invalid acl effect",
+			Input: `{"format":"avro-gateway-dump","version":3}
+{"type":"acl","acl":{"id":"some-rule-id","effect":"foo","application":"*","topic":"*"}}`,
+			Error: `validation error: line 2: invalid effect "foo" for acl rule "some-rule-id"`,
+		},
+		{
+			Name: "invalid acl topic pattern",
+			Input: `{"format":"avro-gateway-dump","version":3}
+{"type":"acl","acl":{"id":"some-rule-id","effect":"allow","application":"*","topic":"[a-"}}`,
+			Error: `validation error: line 2: invalid topic pattern "[a-" for acl rule "some-rule-id"`,
+		},
+		{
+			Name: "duplicated acl rule",
+			Input: `{"format":"avro-gateway-dump","version":3}
+{"type":"acl","acl":{"id":"some-rule-id","effect":"allow","application":"*","topic":"*"}}
+{"type":"acl","acl":{"id":"some-rule-id","effect":"allow","application":"*","topic":"*"}}`,
+			Error: `validation error: line 3: acl rule "some-rule-id" present twice`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			res, err := ReadDump(strings.NewReader(test.Input))
+
+			assert.Nil(t, res)
+			assert.EqualError(t, err, test.Error)
+		})
+	}
+}
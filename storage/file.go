@@ -0,0 +1,327 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/Peltoche/avro-gateway/logging"
+	"github.com/Peltoche/avro-gateway/model"
+	"github.com/Peltoche/avro-gateway/tracing"
+)
+
+// File storage keeping all the clients in memory and persisting them into a
+// single file using the dump format.
+//
+// The file is rewritten after each modification, except for the audit entries
+// which are appended at the end of the file. The file is compacted when loaded
+// and by the next rewrite, at the latest after MaxAuditEntries appends.
+type File struct {
+	path   string
+	memory *InMemory
+	// writeMutex serialize the modifications and the file writes in order to
+	// keep the file in sync with the memory state.
+	writeMutex *sync.Mutex
+	closed     bool
+	// exists is false until the file is written or loaded: the audit entries
+	// can't be appended before the dump header.
+	exists bool
+	// appended is the number of audit entries appended since the last
+	// rewrite.
+	appended int
+}
+
+// NewFile instantiate a new File storage and load the content of the file at
+// path if it exists.
+func NewFile(path string) (*File, error) {
+	storage := &File{
+		path:       path,
+		memory:     NewInMemory(),
+		writeMutex: new(sync.Mutex),
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return storage, nil
+	}
+	if err != nil {
+		return nil, internal.Errorf(internal.InternalError, "failed to open the storage file: %s", err)
+	}
+	defer file.Close()
+
+	_, err = Import(context.Background(), storage.memory, file, ImportOptions{Mode: ReplaceMode})
+	if err != nil {
+		return nil, internal.Wrapf(err, "failed to load the storage file %q", path)
+	}
+
+	// The audit entries appended before the restart are not counted: the file
+	// is compacted at once to keep it bounded.
+	err = storage.persist(context.Background())
+	if err != nil {
+		return nil, internal.Wrapf(err, "failed to compact the storage file %q", path)
+	}
+
+	return storage, nil
+}
+
+// RegisterNewClient register a new Client into the list of clients.
+func (t *File) RegisterNewClient(ctx context.Context, client *model.Client) error {
+	t.writeMutex.Lock()
+	defer t.writeMutex.Unlock()
+
+	err := t.memory.RegisterNewClient(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	err = t.persist(ctx)
+	if err != nil {
+		_ = t.memory.DeleteClient(ctx, client.ID)
+		return err
+	}
+
+	return nil
+}
+
+// DeleteClient remove the client matching the id. It's a no-op if the client
+// doesn't exist.
+func (t *File) DeleteClient(ctx context.Context, clientID string) error {
+	t.writeMutex.Lock()
+	defer t.writeMutex.Unlock()
+
+	client, err := t.memory.GetClientByID(ctx, clientID)
+	if err != nil || client == nil {
+		return err
+	}
+
+	err = t.memory.DeleteClient(ctx, clientID)
+	if err != nil {
+		return err
+	}
+
+	err = t.persist(ctx)
+	if err != nil {
+		_ = t.memory.RegisterNewClient(ctx, client)
+		return err
+	}
+
+	return nil
+}
+
+// AppendAuditEntry add an entry at the end of the audit log. The entry is
+// appended to the file without rewriting it.
+func (t *File) AppendAuditEntry(ctx context.Context, entry *model.AuditEntry) error {
+	t.writeMutex.Lock()
+	defer t.writeMutex.Unlock()
+
+	previous := t.memory.auditLog()
+
+	err := t.memory.AppendAuditEntry(ctx, entry)
+	if err != nil {
+		return err
+	}
+
+	if t.exists && !t.closed && t.appended < MaxAuditEntries {
+		err = t.appendAuditRecord(entry)
+		if err == nil {
+			t.appended++
+			return nil
+		}
+
+		// The file may end with a partial record: rewrite it.
+		logging.Warn(ctx, "failed to append the audit entry to the storage file", logging.Fields{"path": t.path, "error": err})
+	}
+
+	err = t.persist(ctx)
+	if err != nil {
+		t.memory.setAuditLog(previous)
+		return err
+	}
+
+	return nil
+}
+
+// Restore replace all the storage content with the dump. The file is written
+// once.
+func (t *File) Restore(ctx context.Context, dump *Dump) error {
+	t.writeMutex.Lock()
+	defer t.writeMutex.Unlock()
+
+	previous := Dump{}
+	previous.Clients, _ = t.memory.GetAllClients(ctx)
+	previous.AuditEntries, _ = t.memory.FindAuditEntries(ctx, &model.AuditFilter{})
+	previous.ACLRules, _ = t.memory.GetAllACLRules(ctx)
+
+	err := t.memory.Restore(ctx, dump)
+	if err != nil {
+		return err
+	}
+
+	err = t.persist(ctx)
+	if err != nil {
+		_ = t.memory.Restore(ctx, &previous)
+		return err
+	}
+
+	return nil
+}
+
+// FindAuditEntries return the audit entries matching the filter, oldest
+// first.
+func (t *File) FindAuditEntries(ctx context.Context, filter *model.AuditFilter) ([]model.AuditEntry, error) {
+	return t.memory.FindAuditEntries(ctx, filter)
+}
+
+// RegisterNewACLRule save a new ACL rule.
+func (t *File) RegisterNewACLRule(ctx context.Context, rule *model.ACLRule) error {
+	t.writeMutex.Lock()
+	defer t.writeMutex.Unlock()
+
+	err := t.memory.RegisterNewACLRule(ctx, rule)
+	if err != nil {
+		return err
+	}
+
+	err = t.persist(ctx)
+	if err != nil {
+		_ = t.memory.DeleteACLRule(ctx, rule.ID)
+		return err
+	}
+
+	return nil
+}
+
+// DeleteACLRule remove the ACL rule matching the id. It's a no-op if the rule
+// doesn't exist.
+func (t *File) DeleteACLRule(ctx context.Context, ruleID string) error {
+	t.writeMutex.Lock()
+	defer t.writeMutex.Unlock()
+
+	rule, err := t.memory.GetACLRuleByID(ctx, ruleID)
+	if err != nil || rule == nil {
+		return err
+	}
+
+	err = t.memory.DeleteACLRule(ctx, ruleID)
+	if err != nil {
+		return err
+	}
+
+	err = t.persist(ctx)
+	if err != nil {
+		_ = t.memory.RegisterNewACLRule(ctx, rule)
+		return err
+	}
+
+	return nil
+}
+
+// GetACLRuleByID retrieve the ACL rule matching the id.
+func (t *File) GetACLRuleByID(ctx context.Context, ruleID string) (*model.ACLRule, error) {
+	return t.memory.GetACLRuleByID(ctx, ruleID)
+}
+
+// GetAllACLRules return all the ACL rules sorted by ID.
+func (t *File) GetAllACLRules(ctx context.Context) ([]model.ACLRule, error) {
+	return t.memory.GetAllACLRules(ctx)
+}
+
+// GetClientByID retrieve the client matching the id.
+func (t *File) GetClientByID(ctx context.Context, clientID string) (*model.Client, error) {
+	return t.memory.GetClientByID(ctx, clientID)
+}
+
+// GetAllClientsOnTopic return all the client connected to a given topic.
+func (t *File) GetAllClientsOnTopic(ctx context.Context, topicName string) ([]model.Client, error) {
+	return t.memory.GetAllClientsOnTopic(ctx, topicName)
+}
+
+// GetAllClients return all the registered clients sorted by ID.
+func (t *File) GetAllClients(ctx context.Context) ([]model.Client, error) {
+	return t.memory.GetAllClients(ctx)
+}
+
+// Close wait for the running write to end and refuse the next ones. The file
+// is always up to date so there is nothing to flush.
+func (t *File) Close() error {
+	t.writeMutex.Lock()
+	defer t.writeMutex.Unlock()
+
+	t.closed = true
+
+	return nil
+}
+
+// persist write the memory state into a temporary file then replace the
+// storage file with it, so a crash never leave a truncated file behind.
+func (t *File) persist(ctx context.Context) (err error) {
+	ctx, span := tracing.Start(ctx, "storage.File.persist")
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+
+	if t.closed {
+		return internal.NewError(internal.InternalError, "the storage is closed")
+	}
+
+	tmpFile, err := ioutil.TempFile(filepath.Dir(t.path), filepath.Base(t.path)+".tmp")
+	if err != nil {
+		return internal.Errorf(internal.InternalError, "failed to create the temporary storage file: %s", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	_, err = Export(ctx, t.memory, tmpFile)
+	if err != nil {
+		tmpFile.Close()
+		return internal.Wrap(err, "failed to write the storage file")
+	}
+
+	err = tmpFile.Close()
+	if err != nil {
+		return internal.Errorf(internal.InternalError, "failed to write the storage file: %s", err)
+	}
+
+	err = os.Rename(tmpFile.Name(), t.path)
+	if err != nil {
+		return internal.Errorf(internal.InternalError, "failed to replace the storage file: %s", err)
+	}
+
+	t.exists = true
+	t.appended = 0
+
+	logging.Debug(ctx, "storage file written", logging.Fields{"path": t.path})
+
+	return nil
+}
+
+// appendAuditRecord write the audit record at the end of the storage file
+// with a single write.
+func (t *File) appendAuditRecord(entry *model.AuditEntry) error {
+	record, err := json.Marshal(dumpRecord{Type: dumpRecordAudit, Audit: entry})
+	if err != nil {
+		return internal.Errorf(internal.InternalError, "failed to encode the audit entry %q: %s", entry.ID, err)
+	}
+
+	file, err := os.OpenFile(t.path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return internal.Errorf(internal.InternalError, "failed to open the storage file: %s", err)
+	}
+
+	_, err = file.Write(append(record, '\n'))
+	if err != nil {
+		file.Close()
+		return internal.Errorf(internal.InternalError, "failed to append to the storage file: %s", err)
+	}
+
+	err = file.Close()
+	if err != nil {
+		return internal.Errorf(internal.InternalError, "failed to append to the storage file: %s", err)
+	}
+
+	return nil
+}
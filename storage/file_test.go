@@ -0,0 +1,220 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Peltoche/avro-gateway/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tempStoragePath(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "avro-gateway")
+	require.NoError(t, err)
+
+	return filepath.Join(dir, "storage.jsonl"), func() { os.RemoveAll(dir) }
+}
+
+func Test_File_persist_and_reload(t *testing.T) {
+	path, cleanup := tempStoragePath(t)
+	defer cleanup()
+
+	storage, err := NewFile(path)
+	require.NoError(t, err)
+
+	require.NoError(t, storage.RegisterNewClient(context.Background(), &dumpClient))
+	require.NoError(t, storage.RegisterNewClient(context.Background(), &dumpClient2))
+	require.NoError(t, storage.DeleteClient(context.Background(), dumpClient2.ID))
+
+	reloaded, err := NewFile(path)
+	require.NoError(t, err)
+
+	res, err := reloaded.GetAllClients(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []model.Client{dumpClient}, res)
+
+	client, err := reloaded.GetClientByID(context.Background(), dumpClient.ID)
+	require.NoError(t, err)
+	assert.Equal(t, &dumpClient, client)
+
+	clients, err := reloaded.GetAllClientsOnTopic(context.Background(), dumpClient.Topic)
+	require.NoError(t, err)
+	assert.Equal(t, []model.Client{dumpClient}, clients)
+}
+
+func Test_File_with_a_missing_file(t *testing.T) {
+	path, cleanup := tempStoragePath(t)
+	defer cleanup()
+
+	storage, err := NewFile(path)
+	require.NoError(t, err)
+
+	res, err := storage.GetAllClients(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, res)
+}
+
+func Test_File_with_a_corrupted_file(t *testing.T) {
+	path, cleanup := tempStoragePath(t)
+	defer cleanup()
+
+	require.NoError(t, ioutil.WriteFile(path, []byte("foobar"), 0600))
+
+	storage, err := NewFile(path)
+
+	assert.Nil(t, storage)
+	assert.Contains(t, err.Error(), "failed to load the storage file")
+}
+
+func Test_File_RegisterNewClient_twice(t *testing.T) {
+	path, cleanup := tempStoragePath(t)
+	defer cleanup()
+
+	storage, err := NewFile(path)
+	require.NoError(t, err)
+
+	require.NoError(t, storage.RegisterNewClient(context.Background(), &dumpClient))
+
+	err = storage.RegisterNewClient(context.Background(), &dumpClient)
+	assert.EqualError(t, err, `internal error: storage conflict: try to register client "some-id" twice`)
+}
+
+func Test_File_AppendAuditEntry_append_to_the_file(t *testing.T) {
+	path, cleanup := tempStoragePath(t)
+	defer cleanup()
+
+	storage, err := NewFile(path)
+	require.NoError(t, err)
+
+	entry := model.AuditEntry{ID: "some-audit-id", Topic: "some-topic", Decision: model.AuditAccepted}
+	entry2 := model.AuditEntry{ID: "some-other-audit-id", Topic: "some-topic", Decision: model.AuditRefused}
+
+	// The first entry create the file.
+	require.NoError(t, storage.AppendAuditEntry(context.Background(), &entry))
+	written, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+
+	require.NoError(t, storage.AppendAuditEntry(context.Background(), &entry2))
+	appended, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+
+	// The file is not rewritten.
+	assert.True(t, bytes.HasPrefix(appended, written))
+	assert.Equal(t, 1, storage.appended)
+
+	reloaded, err := NewFile(path)
+	require.NoError(t, err)
+
+	res, err := reloaded.FindAuditEntries(context.Background(), &model.AuditFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []model.AuditEntry{entry, entry2}, res)
+}
+
+func Test_File_compact_the_file_when_loaded(t *testing.T) {
+	path, cleanup := tempStoragePath(t)
+	defer cleanup()
+
+	storage, err := NewFile(path)
+	require.NoError(t, err)
+
+	require.NoError(t, storage.AppendAuditEntry(context.Background(), &model.AuditEntry{ID: "some-audit-id"}))
+	require.NoError(t, storage.AppendAuditEntry(context.Background(), &model.AuditEntry{ID: "some-other-audit-id"}))
+	require.Equal(t, 1, storage.appended)
+	appended, err := os.Stat(path)
+	require.NoError(t, err)
+
+	reloaded, err := NewFile(path)
+	require.NoError(t, err)
+
+	// The file is replaced by the dump of the loaded content, the appended
+	// entries being counted from zero again.
+	compacted, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.False(t, os.SameFile(appended, compacted))
+	assert.Equal(t, 0, reloaded.appended)
+
+	res, err := reloaded.FindAuditEntries(context.Background(), &model.AuditFilter{})
+	require.NoError(t, err)
+	assert.Len(t, res, 2)
+}
+
+func Test_File_AppendAuditEntry_compact_the_file(t *testing.T) {
+	path, cleanup := tempStoragePath(t)
+	defer cleanup()
+
+	storage, err := NewFile(path)
+	require.NoError(t, err)
+
+	require.NoError(t, storage.RegisterNewClient(context.Background(), &dumpClient))
+
+	storage.appended = MaxAuditEntries
+	require.NoError(t, storage.AppendAuditEntry(context.Background(), &model.AuditEntry{ID: "some-audit-id"}))
+
+	// The file is rewritten.
+	assert.Equal(t, 0, storage.appended)
+
+	reloaded, err := NewFile(path)
+	require.NoError(t, err)
+
+	res, err := reloaded.FindAuditEntries(context.Background(), &model.AuditFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []model.AuditEntry{{ID: "some-audit-id"}}, res)
+}
+
+func Test_File_Close_refuse_the_writes(t *testing.T) {
+	path, cleanup := tempStoragePath(t)
+	defer cleanup()
+
+	storage, err := NewFile(path)
+	require.NoError(t, err)
+
+	require.NoError(t, storage.Close())
+
+	err = storage.RegisterNewClient(context.Background(), &dumpClient)
+	assert.EqualError(t, err, "internal error: the storage is closed")
+
+	// The memory is rolled back.
+	client, err := storage.GetClientByID(context.Background(), dumpClient.ID)
+	require.NoError(t, err)
+	assert.Nil(t, client)
+}
+
+func Test_File_Import_replace_is_atomic(t *testing.T) {
+	path, cleanup := tempStoragePath(t)
+	defer cleanup()
+
+	storage, err := NewFile(path)
+	require.NoError(t, err)
+	require.NoError(t, storage.RegisterNewClient(context.Background(), &dumpClient))
+
+	src := NewInMemory()
+	require.NoError(t, src.RegisterNewClient(context.Background(), &dumpClient2))
+
+	buf := new(bytes.Buffer)
+	_, err = Export(context.Background(), src, buf)
+	require.NoError(t, err)
+
+	// The write fail once the storage is closed.
+	require.NoError(t, storage.Close())
+
+	report, err := Import(context.Background(), storage, buf, ImportOptions{Mode: ReplaceMode})
+	assert.Nil(t, report)
+	assert.EqualError(t, err, "internal error: failed to restore the dump: the storage is closed")
+
+	// Nothing has been replaced.
+	res, err := storage.GetAllClients(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []model.Client{dumpClient}, res)
+
+	reloaded, err := NewFile(path)
+	require.NoError(t, err)
+
+	res, err = reloaded.GetAllClients(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []model.Client{dumpClient}, res)
+}
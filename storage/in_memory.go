@@ -2,37 +2,52 @@ package storage
 
 import (
 	"context"
+	"sort"
 	"sync"
 
 	"github.com/Peltoche/avro-gateway/internal"
 	"github.com/Peltoche/avro-gateway/model"
+	"github.com/Peltoche/avro-gateway/tracing"
 )
 
+// MaxAuditEntries is the number of audit entries kept by a storage, the oldest
+// ones being dropped.
+const MaxAuditEntries = 10000
+
 // InMemory storage without any persistence.
 //
 // It's mainly used for tests. It's not safe to use in production as it doesn't
 // have any persistence!
 type InMemory struct {
-	clients map[string]model.Client
-	mutex   *sync.RWMutex
+	clients      map[string]model.Client
+	auditEntries []model.AuditEntry
+	aclRules     map[string]model.ACLRule
+	mutex        *sync.RWMutex
 }
 
 // NewInMemory instantiate a new InMemory.
 func NewInMemory() *InMemory {
 	return &InMemory{
-		clients: map[string]model.Client{},
-		mutex:   new(sync.RWMutex),
+		clients:      map[string]model.Client{},
+		auditEntries: []model.AuditEntry{},
+		aclRules:     map[string]model.ACLRule{},
+		mutex:        new(sync.RWMutex),
 	}
 }
 
 // RegisterNewClient register a new Client into the list of clients.
 func (t *InMemory) RegisterNewClient(ctx context.Context, client *model.Client) error {
+	_, span := tracing.Start(ctx, "storage.InMemory.RegisterNewClient")
+	defer span.End()
+
 	t.mutex.Lock()
 	defer t.mutex.Unlock()
 
 	_, taken := t.clients[client.ID]
 	if taken {
-		return internal.Errorf(internal.InternalError, "storage conflict: try to register client %q twice", client.ID)
+		err := internal.Errorf(internal.InternalError, "storage conflict: try to register client %q twice", client.ID)
+		span.SetError(err)
+		return err
 	}
 
 	t.clients[client.ID] = *client
@@ -42,6 +57,9 @@ func (t *InMemory) RegisterNewClient(ctx context.Context, client *model.Client)
 
 // GetClientByID retrieve the client matching the id.
 func (t *InMemory) GetClientByID(ctx context.Context, clientID string) (*model.Client, error) {
+	_, span := tracing.Start(ctx, "storage.InMemory.GetClientByID")
+	defer span.End()
+
 	t.mutex.RLock()
 	defer t.mutex.RUnlock()
 
@@ -55,6 +73,9 @@ func (t *InMemory) GetClientByID(ctx context.Context, clientID string) (*model.C
 
 // GetAllClientsOnTopic return all the client connected to a given topic.
 func (t *InMemory) GetAllClientsOnTopic(ctx context.Context, topicName string) ([]model.Client, error) {
+	_, span := tracing.Start(ctx, "storage.InMemory.GetAllClientsOnTopic")
+	defer span.End()
+
 	t.mutex.RLock()
 	defer t.mutex.RUnlock()
 
@@ -67,3 +88,199 @@ func (t *InMemory) GetAllClientsOnTopic(ctx context.Context, topicName string) (
 
 	return res, nil
 }
+
+// GetAllClients return all the registered clients sorted by ID.
+func (t *InMemory) GetAllClients(ctx context.Context) ([]model.Client, error) {
+	_, span := tracing.Start(ctx, "storage.InMemory.GetAllClients")
+	defer span.End()
+
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	res := make([]model.Client, 0, len(t.clients))
+	for _, client := range t.clients {
+		res = append(res, client)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].ID < res[j].ID
+	})
+
+	return res, nil
+}
+
+// DeleteClient remove the client matching the id. It's a no-op if the client
+// doesn't exist.
+func (t *InMemory) DeleteClient(ctx context.Context, clientID string) error {
+	_, span := tracing.Start(ctx, "storage.InMemory.DeleteClient")
+	defer span.End()
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	delete(t.clients, clientID)
+
+	return nil
+}
+
+// AppendAuditEntry add an entry at the end of the audit log. The oldest entry
+// is dropped once MaxAuditEntries is reached.
+func (t *InMemory) AppendAuditEntry(ctx context.Context, entry *model.AuditEntry) error {
+	_, span := tracing.Start(ctx, "storage.InMemory.AppendAuditEntry")
+	defer span.End()
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.auditEntries = append(t.auditEntries, *entry)
+	if len(t.auditEntries) > MaxAuditEntries {
+		t.auditEntries = t.auditEntries[len(t.auditEntries)-MaxAuditEntries:]
+	}
+
+	return nil
+}
+
+// FindAuditEntries return the audit entries matching the filter, oldest
+// first.
+func (t *InMemory) FindAuditEntries(ctx context.Context, filter *model.AuditFilter) ([]model.AuditEntry, error) {
+	_, span := tracing.Start(ctx, "storage.InMemory.FindAuditEntries")
+	defer span.End()
+
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	res := []model.AuditEntry{}
+	for i := range t.auditEntries {
+		if filter.Match(&t.auditEntries[i]) {
+			res = append(res, t.auditEntries[i])
+		}
+	}
+
+	if filter.Limit > 0 && len(res) > filter.Limit {
+		res = res[len(res)-filter.Limit:]
+	}
+
+	return res, nil
+}
+
+// RegisterNewACLRule save a new ACL rule.
+func (t *InMemory) RegisterNewACLRule(ctx context.Context, rule *model.ACLRule) error {
+	_, span := tracing.Start(ctx, "storage.InMemory.RegisterNewACLRule")
+	defer span.End()
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	_, taken := t.aclRules[rule.ID]
+	if taken {
+		err := internal.Errorf(internal.InternalError, "storage conflict: try to register acl rule %q twice", rule.ID)
+		span.SetError(err)
+		return err
+	}
+
+	t.aclRules[rule.ID] = *rule
+
+	return nil
+}
+
+// GetACLRuleByID retrieve the ACL rule matching the id.
+func (t *InMemory) GetACLRuleByID(ctx context.Context, ruleID string) (*model.ACLRule, error) {
+	_, span := tracing.Start(ctx, "storage.InMemory.GetACLRuleByID")
+	defer span.End()
+
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	rule, present := t.aclRules[ruleID]
+	if !present {
+		return nil, nil
+	}
+
+	return &rule, nil
+}
+
+// GetAllACLRules return all the ACL rules sorted by ID.
+func (t *InMemory) GetAllACLRules(ctx context.Context) ([]model.ACLRule, error) {
+	_, span := tracing.Start(ctx, "storage.InMemory.GetAllACLRules")
+	defer span.End()
+
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	res := make([]model.ACLRule, 0, len(t.aclRules))
+	for _, rule := range t.aclRules {
+		res = append(res, rule)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].ID < res[j].ID
+	})
+
+	return res, nil
+}
+
+// DeleteACLRule remove the ACL rule matching the id. It's a no-op if the rule
+// doesn't exist.
+func (t *InMemory) DeleteACLRule(ctx context.Context, ruleID string) error {
+	_, span := tracing.Start(ctx, "storage.InMemory.DeleteACLRule")
+	defer span.End()
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	delete(t.aclRules, ruleID)
+
+	return nil
+}
+
+// Restore replace all the storage content with the dump.
+func (t *InMemory) Restore(ctx context.Context, dump *Dump) error {
+	_, span := tracing.Start(ctx, "storage.InMemory.Restore")
+	defer span.End()
+
+	clients := make(map[string]model.Client, len(dump.Clients))
+	for _, client := range dump.Clients {
+		clients[client.ID] = client
+	}
+
+	aclRules := make(map[string]model.ACLRule, len(dump.ACLRules))
+	for _, rule := range dump.ACLRules {
+		aclRules[rule.ID] = rule
+	}
+
+	// Like the appends, only the last entries are kept.
+	dumpEntries := dump.AuditEntries
+	if len(dumpEntries) > MaxAuditEntries {
+		dumpEntries = dumpEntries[len(dumpEntries)-MaxAuditEntries:]
+	}
+
+	auditEntries := make([]model.AuditEntry, len(dumpEntries))
+	copy(auditEntries, dumpEntries)
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.clients = clients
+	t.auditEntries = auditEntries
+	t.aclRules = aclRules
+
+	return nil
+}
+
+// auditLog return the audit entries. The appends never modify the returned
+// entries, so it can be given to setAuditLog to rollback a failed write in the
+// File storage.
+func (t *InMemory) auditLog() []model.AuditEntry {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	return t.auditEntries
+}
+
+// setAuditLog replace the audit entries by the ones returned by auditLog.
+func (t *InMemory) setAuditLog(entries []model.AuditEntry) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.auditEntries = entries
+}
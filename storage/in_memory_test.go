@@ -2,7 +2,9 @@ package storage
 
 import (
 	"context"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/Peltoche/avro-gateway/model"
 	"github.com/stretchr/testify/assert"
@@ -124,3 +126,75 @@ func Test_InMemory_GetAllClientOnTopic_with_no_client_found(t *testing.T) {
 	require.NoError(t, err)
 	assert.Empty(t, res)
 }
+
+func Test_InMemory_AppendAuditEntry_FindAuditEntries_success(t *testing.T) {
+	date := time.Date(2019, time.February, 3, 10, 0, 0, 0, time.UTC)
+
+	entries := []model.AuditEntry{
+		{ID: "1", Time: date, Topic: "some-topic", Decision: model.AuditAccepted},
+		{ID: "2", Time: date.Add(time.Hour), Topic: "some-other-topic", Decision: model.AuditAccepted},
+		{ID: "3", Time: date.Add(2 * time.Hour), Topic: "some-topic", Decision: model.AuditRefused},
+		{ID: "4", Time: date.Add(3 * time.Hour), Topic: "some-topic", Decision: model.AuditAccepted},
+	}
+
+	storage := NewInMemory()
+	for i := range entries {
+		require.NoError(t, storage.AppendAuditEntry(context.Background(), &entries[i]))
+	}
+
+	res, err := storage.FindAuditEntries(context.Background(), &model.AuditFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, entries, res)
+
+	res, err = storage.FindAuditEntries(context.Background(), &model.AuditFilter{Topic: "some-topic"})
+	require.NoError(t, err)
+	assert.Equal(t, []model.AuditEntry{entries[0], entries[2], entries[3]}, res)
+
+	res, err = storage.FindAuditEntries(context.Background(), &model.AuditFilter{From: date.Add(time.Hour), To: date.Add(3 * time.Hour)})
+	require.NoError(t, err)
+	assert.Equal(t, []model.AuditEntry{entries[1], entries[2]}, res)
+
+	res, err = storage.FindAuditEntries(context.Background(), &model.AuditFilter{Topic: "some-topic", Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []model.AuditEntry{entries[2], entries[3]}, res)
+}
+
+func Test_InMemory_AppendAuditEntry_drop_the_oldest_entries(t *testing.T) {
+	storage := NewInMemory()
+	for i := 0; i <= MaxAuditEntries; i++ {
+		entry := model.AuditEntry{ID: strconv.Itoa(i), Topic: "some-topic", Decision: model.AuditAccepted}
+		require.NoError(t, storage.AppendAuditEntry(context.Background(), &entry))
+	}
+
+	res, err := storage.FindAuditEntries(context.Background(), &model.AuditFilter{})
+	require.NoError(t, err)
+	require.Len(t, res, MaxAuditEntries)
+	assert.Equal(t, "1", res[0].ID)
+	assert.Equal(t, strconv.Itoa(MaxAuditEntries), res[MaxAuditEntries-1].ID)
+}
+
+func Test_InMemory_ACLRules_success(t *testing.T) {
+	rule := model.ACLRule{ID: "some-rule-id", Effect: model.ACLAllow, Application: "some-app", Topic: "orders.*"}
+
+	storage := NewInMemory()
+
+	err := storage.RegisterNewACLRule(context.Background(), &rule)
+	require.NoError(t, err)
+
+	err = storage.RegisterNewACLRule(context.Background(), &rule)
+	assert.EqualError(t, err, `internal error: storage conflict: try to register acl rule "some-rule-id" twice`)
+
+	res, err := storage.GetACLRuleByID(context.Background(), "some-rule-id")
+	require.NoError(t, err)
+	assert.Equal(t, &rule, res)
+
+	rules, err := storage.GetAllACLRules(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []model.ACLRule{rule}, rules)
+
+	require.NoError(t, storage.DeleteACLRule(context.Background(), "some-rule-id"))
+
+	res, err = storage.GetACLRuleByID(context.Background(), "some-rule-id")
+	require.NoError(t, err)
+	assert.Nil(t, res)
+}
@@ -27,3 +27,46 @@ func (t *Mock) GetAllClientsOnTopic(ctx context.Context, topicName string) ([]mo
 
 	return args.Get(0).([]model.Client), args.Error(1)
 }
+
+// GetAllClients method mock.
+func (t *Mock) GetAllClients(ctx context.Context) ([]model.Client, error) {
+	args := t.Called()
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]model.Client), args.Error(1)
+}
+
+// RegisterNewACLRule method mock.
+func (t *Mock) RegisterNewACLRule(ctx context.Context, rule *model.ACLRule) error {
+	return t.Called(rule).Error(0)
+}
+
+// GetACLRuleByID method mock.
+func (t *Mock) GetACLRuleByID(ctx context.Context, ruleID string) (*model.ACLRule, error) {
+	args := t.Called(ruleID)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.ACLRule), args.Error(1)
+}
+
+// GetAllACLRules method mock.
+func (t *Mock) GetAllACLRules(ctx context.Context) ([]model.ACLRule, error) {
+	args := t.Called()
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]model.ACLRule), args.Error(1)
+}
+
+// DeleteACLRule method mock.
+func (t *Mock) DeleteACLRule(ctx context.Context, ruleID string) error {
+	return t.Called(ruleID).Error(0)
+}
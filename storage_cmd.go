@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Peltoche/avro-gateway/storage"
+)
+
+const storageUsage = `usage: avro-gateway storage <command> [flags]
+
+commands:
+  export    write all the storage content into a dump
+  import    restore a dump into the storage
+`
+
+// runStorageCommand run the "storage" sub-commands and return the exit code.
+func runStorageCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, storageUsage)
+		return 2
+	}
+
+	var err error
+	switch args[0] {
+	case "export":
+		err = runStorageExport(args[1:])
+	case "import":
+		err = runStorageImport(args[1:])
+	default:
+		fmt.Fprint(os.Stderr, storageUsage)
+		return 2
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	return 0
+}
+
+func runStorageExport(args []string) error {
+	flags := flag.NewFlagSet("storage export", flag.ExitOnError)
+	storageFile := flags.String("storage-file", "", "storage file to export")
+	output := flags.String("o", "-", "dump destination, stdout if \"-\"")
+	_ = flags.Parse(args)
+
+	if *storageFile == "" {
+		return fmt.Errorf("missing flag -storage-file")
+	}
+
+	src, err := storage.NewFile(*storageFile)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "-" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		w = file
+	}
+
+	count, err := storage.Export(context.Background(), src, w)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "%d records exported\n", count)
+
+	return nil
+}
+
+func runStorageImport(args []string) error {
+	flags := flag.NewFlagSet("storage import", flag.ExitOnError)
+	storageFile := flags.String("storage-file", "", "storage file to import into")
+	input := flags.String("i", "-", "dump source, stdin if \"-\"")
+	mode := flags.String("mode", string(storage.MergeMode), "import mode: \"merge\" or \"replace\"")
+	dryRun := flags.Bool("dry-run", false, "validate the dump and report the changes without applying them")
+	_ = flags.Parse(args)
+
+	if *storageFile == "" {
+		return fmt.Errorf("missing flag -storage-file")
+	}
+
+	dst, err := storage.NewFile(*storageFile)
+	if err != nil {
+		return err
+	}
+
+	var r io.Reader = os.Stdin
+	if *input != "-" {
+		file, err := os.Open(*input)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		r = file
+	}
+
+	report, err := storage.Import(context.Background(), dst, r, storage.ImportOptions{
+		Mode:   storage.ImportMode(*mode),
+		DryRun: *dryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	return json.NewEncoder(os.Stdout).Encode(report)
+}
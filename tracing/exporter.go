@@ -0,0 +1,264 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Peltoche/avro-gateway/logging"
+)
+
+// InMemoryExporter keep the spans in memory, mostly for the tests.
+type InMemoryExporter struct {
+	mutex *sync.Mutex
+	spans []SpanData
+}
+
+// NewInMemoryExporter instantiate a new InMemoryExporter.
+func NewInMemoryExporter() *InMemoryExporter {
+	return &InMemoryExporter{
+		mutex: new(sync.Mutex),
+		spans: []SpanData{},
+	}
+}
+
+// ExportSpan save the span.
+func (t *InMemoryExporter) ExportSpan(span *SpanData) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.spans = append(t.spans, *span)
+}
+
+// Spans return the exported spans in the end order.
+func (t *InMemoryExporter) Spans() []SpanData {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	res := make([]SpanData, len(t.spans))
+	copy(res, t.spans)
+
+	return res
+}
+
+// OTLPExporter send the spans in batches to an OpenTelemetry collector with
+// the OTLP/HTTP JSON protocol.
+type OTLPExporter struct {
+	endpoint    string
+	serviceName string
+	client      *http.Client
+	batchSize   int
+
+	mutex   *sync.Mutex
+	pending []SpanData
+	flush   chan struct{}
+}
+
+// NewOTLPExporter instantiate a new OTLPExporter sending the spans to
+// "<endpoint>/v1/traces".
+func NewOTLPExporter(endpoint string, serviceName string) *OTLPExporter {
+	return &OTLPExporter{
+		endpoint:    endpoint,
+		serviceName: serviceName,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		batchSize:   512,
+		mutex:       new(sync.Mutex),
+		pending:     []SpanData{},
+		flush:       make(chan struct{}, 1),
+	}
+}
+
+// ExportSpan add the span to the next batch. The span is dropped if the
+// collector is too slow and the pending list is full.
+func (t *OTLPExporter) ExportSpan(span *SpanData) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if len(t.pending) >= 4*t.batchSize {
+		return
+	}
+
+	t.pending = append(t.pending, *span)
+	if len(t.pending) >= t.batchSize {
+		select {
+		case t.flush <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Run send the pending spans at each interval, or as soon as a batch is full,
+// until the context is canceled. The last spans are sent before returning.
+func (t *OTLPExporter) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			t.logError(ctx, t.Flush(context.Background()))
+			return
+		case <-ticker.C:
+		case <-t.flush:
+		}
+
+		t.logError(ctx, t.Flush(ctx))
+	}
+}
+
+// Flush send all the pending spans.
+func (t *OTLPExporter) Flush(ctx context.Context) error {
+	for {
+		t.mutex.Lock()
+		size := len(t.pending)
+		if size > t.batchSize {
+			size = t.batchSize
+		}
+		batch := t.pending[:size]
+		t.pending = t.pending[size:]
+		t.mutex.Unlock()
+
+		if len(batch) == 0 {
+			return nil
+		}
+
+		err := t.send(ctx, batch)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (t *OTLPExporter) send(ctx context.Context, spans []SpanData) error {
+	body, err := json.Marshal(t.encode(spans))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", t.endpoint+"/v1/traces", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := t.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	// Drain the body in order to reuse the connection.
+	_, _ = io.Copy(ioutil.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response status: %s", res.Status)
+	}
+
+	return nil
+}
+
+func (t *OTLPExporter) logError(ctx context.Context, err error) {
+	if err != nil {
+		logging.Error(ctx, "failed to export the spans", logging.Fields{"error": err})
+	}
+}
+
+type otlpKeyValue struct {
+	Key   string `json:"key"`
+	Value struct {
+		StringValue string `json:"stringValue"`
+	} `json:"value"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              SpanKind       `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpScopeSpans struct {
+	Scope struct {
+		Name string `json:"name"`
+	} `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpResourceSpans struct {
+	Resource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	} `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+func (t *OTLPExporter) encode(spans []SpanData) *otlpRequest {
+	scopeSpans := otlpScopeSpans{Spans: []otlpSpan{}}
+	scopeSpans.Scope.Name = t.serviceName
+
+	for _, span := range spans {
+		encoded := otlpSpan{
+			TraceID:           span.Context.TraceID.String(),
+			SpanID:            span.Context.SpanID.String(),
+			Name:              span.Name,
+			Kind:              span.Kind,
+			StartTimeUnixNano: strconv.FormatInt(span.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.End.UnixNano(), 10),
+			Attributes:        encodeAttributes(span.Attributes),
+			// Unset.
+			Status: otlpStatus{Code: 0},
+		}
+
+		if span.ParentSpanID != (SpanID{}) {
+			encoded.ParentSpanID = span.ParentSpanID.String()
+		}
+
+		if span.Error != "" {
+			encoded.Status = otlpStatus{Code: 2, Message: span.Error}
+		}
+
+		scopeSpans.Spans = append(scopeSpans.Spans, encoded)
+	}
+
+	resourceSpans := otlpResourceSpans{ScopeSpans: []otlpScopeSpans{scopeSpans}}
+	resourceSpans.Resource.Attributes = encodeAttributes(map[string]string{"service.name": t.serviceName})
+
+	return &otlpRequest{ResourceSpans: []otlpResourceSpans{resourceSpans}}
+}
+
+func encodeAttributes(attributes map[string]string) []otlpKeyValue {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	res := make([]otlpKeyValue, len(keys))
+	for i, key := range keys {
+		res[i].Key = key
+		res[i].Value.StringValue = attributes[key]
+	}
+
+	return res
+}
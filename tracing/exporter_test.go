@@ -0,0 +1,136 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_OTLPExporter_Flush_success(t *testing.T) {
+	var body []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/v1/traces", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		var err error
+		body, err = ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	exporter := NewOTLPExporter(ts.URL, "avro-gateway")
+
+	ctx := WithTracer(context.Background(), newTestTracer(exporter))
+	ctx, parent := StartWithKind(ctx, "some-parent", SpanKindServer)
+	_, child := Start(ctx, "some-child")
+	child.SetAttribute("foo", "bar")
+	child.SetError(errors.New("some-error"))
+	child.End()
+	parent.End()
+
+	err := exporter.Flush(context.Background())
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"resourceSpans": [{
+			"resource": {"attributes": [{"key": "service.name", "value": {"stringValue": "avro-gateway"}}]},
+			"scopeSpans": [{
+				"scope": {"name": "avro-gateway"},
+				"spans": [
+					{
+						"traceId": "01010101010101010101010101010101",
+						"spanId": "0303030303030303",
+						"parentSpanId": "0202020202020202",
+						"name": "some-child",
+						"kind": 1,
+						"startTimeUnixNano": "1546300800020000000",
+						"endTimeUnixNano": "1546300800030000000",
+						"attributes": [{"key": "foo", "value": {"stringValue": "bar"}}],
+						"status": {"code": 2, "message": "some-error"}
+					},
+					{
+						"traceId": "01010101010101010101010101010101",
+						"spanId": "0202020202020202",
+						"name": "some-parent",
+						"kind": 2,
+						"startTimeUnixNano": "1546300800010000000",
+						"endTimeUnixNano": "1546300800040000000",
+						"status": {"code": 0}
+					}
+				]
+			}]
+		}]
+	}`, string(body))
+
+	// Nothing left to send.
+	body = nil
+	err = exporter.Flush(context.Background())
+	require.NoError(t, err)
+	assert.Nil(t, body)
+}
+
+func Test_OTLPExporter_Flush_with_an_error_status(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	exporter := NewOTLPExporter(ts.URL, "avro-gateway")
+	exporter.ExportSpan(&SpanData{Name: "some-span"})
+
+	err := exporter.Flush(context.Background())
+	assert.EqualError(t, err, "unexpected response status: 503 Service Unavailable")
+}
+
+func Test_OTLPExporter_ExportSpan_drop_when_full(t *testing.T) {
+	exporter := NewOTLPExporter("http://example.com", "avro-gateway")
+	exporter.batchSize = 2
+
+	for i := 0; i < 10; i++ {
+		exporter.ExportSpan(&SpanData{Name: "some-span"})
+	}
+
+	assert.Len(t, exporter.pending, 8)
+}
+
+func Test_OTLPExporter_Run_send_full_batches(t *testing.T) {
+	received := make(chan struct{}, 10)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- struct{}{}
+	}))
+	defer ts.Close()
+
+	exporter := NewOTLPExporter(ts.URL, "avro-gateway")
+	exporter.batchSize = 2
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		exporter.Run(ctx, time.Hour)
+		close(done)
+	}()
+
+	exporter.ExportSpan(&SpanData{Name: "some-span"})
+	exporter.ExportSpan(&SpanData{Name: "some-span"})
+
+	select {
+	case <-received:
+	case <-time.After(time.Second):
+		t.Fatal("the full batch has not been sent")
+	}
+
+	// The remaining spans are sent on stop.
+	exporter.ExportSpan(&SpanData{Name: "some-span"})
+	cancel()
+	<-done
+
+	assert.Len(t, received, 1)
+}
@@ -0,0 +1,48 @@
+package tracing
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Peltoche/avro-gateway/internal/recorder"
+	"github.com/Peltoche/avro-gateway/logging"
+	"github.com/gorilla/mux"
+)
+
+// Middleware put the tracer into the request context and create a server span
+// for each request, continuing the trace of the caller if any.
+func Middleware(tracer *Tracer) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := WithTracer(r.Context(), tracer)
+			if remote := Extract(r.Header); remote.IsValid() {
+				ctx = WithRemoteSpanContext(ctx, remote)
+			}
+
+			route := r.URL.Path
+			if current := mux.CurrentRoute(r); current != nil {
+				if template, err := current.GetPathTemplate(); err == nil {
+					route = template
+				}
+			}
+
+			ctx, span := StartWithKind(ctx, r.Method+" "+route, SpanKindServer)
+			defer span.End()
+
+			// Correlate the logs with the trace.
+			ctx = logging.WithFields(ctx, logging.Fields{"trace_id": span.Context().TraceID.String()})
+
+			span.SetAttribute("http.method", r.Method)
+			span.SetAttribute("http.route", route)
+
+			statusRecorder := recorder.NewStatusRecorder(w)
+			next.ServeHTTP(statusRecorder, r.WithContext(ctx))
+
+			span.SetAttribute("http.status_code", strconv.Itoa(statusRecorder.Status))
+			if statusRecorder.Status >= 500 {
+				span.SetError(errors.New(http.StatusText(statusRecorder.Status)))
+			}
+		})
+	}
+}
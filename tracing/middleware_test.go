@@ -0,0 +1,61 @@
+package tracing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Middleware_success(t *testing.T) {
+	exporter := NewInMemoryExporter()
+
+	router := mux.NewRouter()
+	router.Use(Middleware(newTestTracer(exporter)))
+	router.HandleFunc("/acls/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_, span := Start(r.Context(), "some-child")
+		span.End()
+
+		w.WriteHeader(http.StatusInternalServerError)
+	}).Methods("DELETE")
+
+	r := httptest.NewRequest("DELETE", "http://example.com/acls/some-id", nil)
+	r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), r)
+
+	spans := exporter.Spans()
+	require.Len(t, spans, 2)
+
+	assert.Equal(t, "some-child", spans[0].Name)
+	assert.Equal(t, spans[1].Context.SpanID, spans[0].ParentSpanID)
+
+	assert.Equal(t, "DELETE /acls/{id}", spans[1].Name)
+	assert.Equal(t, SpanKindServer, spans[1].Kind)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[1].Context.TraceID.String())
+	assert.Equal(t, "00f067aa0ba902b7", spans[1].ParentSpanID.String())
+	assert.Equal(t, map[string]string{
+		"http.method":      "DELETE",
+		"http.route":       "/acls/{id}",
+		"http.status_code": "500",
+	}, spans[1].Attributes)
+	assert.Equal(t, "Internal Server Error", spans[1].Error)
+}
+
+func Test_Middleware_without_remote_parent(t *testing.T) {
+	exporter := NewInMemoryExporter()
+
+	router := mux.NewRouter()
+	router.Use(Middleware(newTestTracer(exporter)))
+	router.HandleFunc("/acls", func(w http.ResponseWriter, r *http.Request) {}).Methods("GET")
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "http://example.com/acls", nil))
+
+	spans := exporter.Spans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, SpanID{}, spans[0].ParentSpanID)
+	assert.Equal(t, "200", spans[0].Attributes["http.status_code"])
+	assert.Empty(t, spans[0].Error)
+}
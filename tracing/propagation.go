@@ -0,0 +1,68 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// TraceParentHeader is the W3C trace-context header.
+const TraceParentHeader = "traceparent"
+
+// Inject the current span context of ctx into the headers of an outgoing
+// request.
+func Inject(ctx context.Context, header http.Header) {
+	spanContext := SpanFromContext(ctx).Context()
+	if !spanContext.IsValid() {
+		return
+	}
+
+	flags := "00"
+	if spanContext.Sampled {
+		flags = "01"
+	}
+
+	header.Set(TraceParentHeader, "00-"+spanContext.TraceID.String()+"-"+spanContext.SpanID.String()+"-"+flags)
+}
+
+// Extract the span context from the headers of an incoming request. The
+// returned span context is invalid if the header is missing or malformed.
+func Extract(header http.Header) SpanContext {
+	parts := strings.Split(strings.TrimSpace(header.Get(TraceParentHeader)), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return SpanContext{}
+	}
+
+	// The version 00 has exactly 4 parts, the future versions can add some.
+	if parts[0] == "00" && len(parts) != 4 {
+		return SpanContext{}
+	}
+
+	var spanContext SpanContext
+	if !decodeHex(parts[1], spanContext.TraceID[:]) || !decodeHex(parts[2], spanContext.SpanID[:]) {
+		return SpanContext{}
+	}
+
+	var flags [1]byte
+	if !decodeHex(parts[3], flags[:]) {
+		return SpanContext{}
+	}
+	spanContext.Sampled = flags[0]&0x01 == 0x01
+
+	if !spanContext.IsValid() {
+		return SpanContext{}
+	}
+
+	return spanContext
+}
+
+func decodeHex(value string, dst []byte) bool {
+	if len(value) != 2*len(dst) || strings.ToLower(value) != value {
+		return false
+	}
+
+	_, err := hex.Decode(dst, []byte(value))
+
+	return err == nil
+}
@@ -0,0 +1,72 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Inject_success(t *testing.T) {
+	ctx := WithTracer(context.Background(), newTestTracer(NewInMemoryExporter()))
+	ctx, _ = Start(ctx, "some-span")
+
+	header := http.Header{}
+	Inject(ctx, header)
+
+	assert.Equal(t, "00-01010101010101010101010101010101-0202020202020202-01", header.Get("traceparent"))
+}
+
+func Test_Inject_without_span(t *testing.T) {
+	header := http.Header{}
+	Inject(context.Background(), header)
+
+	assert.Empty(t, header.Get("traceparent"))
+}
+
+func Test_Extract_success(t *testing.T) {
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	spanContext := Extract(header)
+
+	assert.True(t, spanContext.IsValid())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spanContext.TraceID.String())
+	assert.Equal(t, "00f067aa0ba902b7", spanContext.SpanID.String())
+	assert.True(t, spanContext.Sampled)
+}
+
+func Test_Extract_with_future_version(t *testing.T) {
+	header := http.Header{}
+	header.Set("traceparent", "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-some-extra-field")
+
+	spanContext := Extract(header)
+
+	assert.True(t, spanContext.IsValid())
+	assert.False(t, spanContext.Sampled)
+}
+
+func Test_Extract_with_invalid_header(t *testing.T) {
+	tests := map[string]string{
+		"missing":             "",
+		"invalid version":     "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"too many parts":      "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-foo",
+		"too few parts":       "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"short trace id":      "00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01",
+		"upper case trace id": "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"invalid span id":     "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902zz-01",
+		"zero trace id":       "00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"zero span id":        "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"invalid flags":       "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-1",
+	}
+
+	for name, value := range tests {
+		t.Run(name, func(t *testing.T) {
+			header := http.Header{}
+			header.Set("traceparent", value)
+
+			assert.Equal(t, SpanContext{}, Extract(header))
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package tracing
+
+import (
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// TraceID identify a trace.
+type TraceID [16]byte
+
+// String return the hex encoded id.
+func (t TraceID) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+// SpanID identify a span inside a trace.
+type SpanID [8]byte
+
+// String return the hex encoded id.
+func (t SpanID) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+// SpanContext is the part of a span propagated to its children, possibly
+// across process boundaries.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+// IsValid return true if the trace and span ids are set.
+func (t SpanContext) IsValid() bool {
+	return t.TraceID != TraceID{} && t.SpanID != SpanID{}
+}
+
+// SpanKind is the role of a span in the trace.
+type SpanKind int
+
+// The values are the OTLP ones.
+const (
+	SpanKindInternal SpanKind = 1
+	SpanKindServer   SpanKind = 2
+	SpanKindClient   SpanKind = 3
+)
+
+// SpanData is the immutable content of an ended span, given to the exporters.
+type SpanData struct {
+	Name         string
+	Kind         SpanKind
+	Context      SpanContext
+	ParentSpanID SpanID
+	Start        time.Time
+	End          time.Time
+	Attributes   map[string]string
+	// Error is the error message if the operation failed.
+	Error string
+}
+
+// Span is a timed operation. All the methods are safe to call on a nil Span,
+// which is returned when the tracing is disabled.
+type Span struct {
+	tracer *Tracer
+	mutex  *sync.Mutex
+	data   SpanData
+	ended  bool
+}
+
+// Context return the span context, the zero value for a nil span.
+func (t *Span) Context() SpanContext {
+	if t == nil {
+		return SpanContext{}
+	}
+
+	return t.data.Context
+}
+
+// SetAttribute add a key/value to the span.
+func (t *Span) SetAttribute(key string, value string) {
+	if t == nil {
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.data.Attributes[key] = value
+}
+
+// SetError mark the span as failed. It's a no-op if err is nil.
+func (t *Span) SetError(err error) {
+	if t == nil || err == nil {
+		return
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.data.Error = err.Error()
+}
+
+// End the span and send it to the exporter. Only the first call has an
+// effect.
+func (t *Span) End() {
+	if t == nil {
+		return
+	}
+
+	t.mutex.Lock()
+	if t.ended {
+		t.mutex.Unlock()
+		return
+	}
+	t.ended = true
+	t.data.End = t.tracer.now()
+
+	data := t.data
+	data.Attributes = make(map[string]string, len(t.data.Attributes))
+	for key, value := range t.data.Attributes {
+		data.Attributes[key] = value
+	}
+	t.mutex.Unlock()
+
+	if data.Context.Sampled {
+		t.tracer.exporter.ExportSpan(&data)
+	}
+}
@@ -0,0 +1,105 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"sync"
+	"time"
+)
+
+// Exporter send the ended spans to a tracing backend.
+type Exporter interface {
+	ExportSpan(span *SpanData)
+}
+
+// Tracer create the spans and give them to its exporter once ended.
+//
+// The tracer is carried by the request context: the code creating the spans
+// only needs the context, and Start return a nil Span if there is no tracer.
+type Tracer struct {
+	exporter Exporter
+	// Set the time and id generation functions as attributes in order to be
+	// able to mock them.
+	now        func() time.Time
+	generateID func(b []byte)
+}
+
+// NewTracer instantiate a new Tracer.
+func NewTracer(exporter Exporter) *Tracer {
+	return &Tracer{
+		exporter: exporter,
+		now:      time.Now,
+		generateID: func(b []byte) {
+			_, _ = rand.Read(b)
+		},
+	}
+}
+
+type tracerKey struct{}
+type spanKey struct{}
+type remoteKey struct{}
+
+// WithTracer return a copy of the context containing the tracer.
+func WithTracer(ctx context.Context, tracer *Tracer) context.Context {
+	return context.WithValue(ctx, tracerKey{}, tracer)
+}
+
+// WithRemoteSpanContext return a copy of the context containing the span
+// context received from an other process, used as parent by the next span.
+func WithRemoteSpanContext(ctx context.Context, spanContext SpanContext) context.Context {
+	return context.WithValue(ctx, remoteKey{}, spanContext)
+}
+
+// SpanFromContext return the current span or nil.
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+
+	return span
+}
+
+// Start an internal span as child of the current span of the context.
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	return StartWithKind(ctx, name, SpanKindInternal)
+}
+
+// StartWithKind start a span of the given kind as child of the current span
+// of the context.
+func StartWithKind(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	tracer, _ := ctx.Value(tracerKey{}).(*Tracer)
+	if tracer == nil {
+		return ctx, nil
+	}
+
+	return tracer.start(ctx, name, kind)
+}
+
+func (t *Tracer) start(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	parent := SpanFromContext(ctx).Context()
+	if !parent.IsValid() {
+		parent, _ = ctx.Value(remoteKey{}).(SpanContext)
+	}
+
+	spanContext := SpanContext{Sampled: true}
+	if parent.IsValid() {
+		spanContext.TraceID = parent.TraceID
+		spanContext.Sampled = parent.Sampled
+	} else {
+		t.generateID(spanContext.TraceID[:])
+	}
+	t.generateID(spanContext.SpanID[:])
+
+	span := &Span{
+		tracer: t,
+		mutex:  new(sync.Mutex),
+		data: SpanData{
+			Name:         name,
+			Kind:         kind,
+			Context:      spanContext,
+			ParentSpanID: parent.SpanID,
+			Start:        t.now(),
+			Attributes:   map[string]string{},
+		},
+	}
+
+	return context.WithValue(ctx, spanKey{}, span), span
+}
@@ -0,0 +1,111 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTracer(exporter Exporter) *Tracer {
+	tracer := NewTracer(exporter)
+
+	now := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	tracer.now = func() time.Time {
+		now = now.Add(10 * time.Millisecond)
+		return now
+	}
+
+	var counter byte
+	tracer.generateID = func(b []byte) {
+		counter++
+		for i := range b {
+			b[i] = counter
+		}
+	}
+
+	return tracer
+}
+
+func Test_Start_without_tracer(t *testing.T) {
+	ctx, span := Start(context.Background(), "some-span")
+
+	assert.Nil(t, span)
+	assert.Equal(t, context.Background(), ctx)
+
+	// The nil span must be usable.
+	span.SetAttribute("foo", "bar")
+	span.SetError(errors.New("some-error"))
+	span.End()
+	assert.False(t, span.Context().IsValid())
+}
+
+func Test_Start_with_parent_span(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	ctx := WithTracer(context.Background(), newTestTracer(exporter))
+
+	ctx, parent := Start(ctx, "parent")
+	_, child := StartWithKind(ctx, "child", SpanKindClient)
+	child.SetAttribute("foo", "bar")
+	child.SetError(errors.New("some-error"))
+	child.End()
+	parent.End()
+
+	spans := exporter.Spans()
+	require.Len(t, spans, 2)
+
+	assert.Equal(t, "child", spans[0].Name)
+	assert.Equal(t, SpanKindClient, spans[0].Kind)
+	assert.Equal(t, parent.Context().TraceID, spans[0].Context.TraceID)
+	assert.Equal(t, parent.Context().SpanID, spans[0].ParentSpanID)
+	assert.Equal(t, map[string]string{"foo": "bar"}, spans[0].Attributes)
+	assert.Equal(t, "some-error", spans[0].Error)
+	assert.Equal(t, 10*time.Millisecond, spans[0].End.Sub(spans[0].Start))
+
+	assert.Equal(t, "parent", spans[1].Name)
+	assert.Equal(t, SpanKindInternal, spans[1].Kind)
+	assert.Equal(t, SpanID{}, spans[1].ParentSpanID)
+	assert.Empty(t, spans[1].Error)
+}
+
+func Test_Start_with_remote_parent(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	ctx := WithTracer(context.Background(), newTestTracer(exporter))
+
+	remote := SpanContext{TraceID: TraceID{0xaa}, SpanID: SpanID{0xbb}, Sampled: true}
+	ctx = WithRemoteSpanContext(ctx, remote)
+
+	_, span := Start(ctx, "some-span")
+	span.End()
+
+	spans := exporter.Spans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, remote.TraceID, spans[0].Context.TraceID)
+	assert.Equal(t, remote.SpanID, spans[0].ParentSpanID)
+	assert.NotEqual(t, remote.SpanID, spans[0].Context.SpanID)
+}
+
+func Test_Start_with_not_sampled_remote_parent(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	ctx := WithTracer(context.Background(), newTestTracer(exporter))
+	ctx = WithRemoteSpanContext(ctx, SpanContext{TraceID: TraceID{0xaa}, SpanID: SpanID{0xbb}, Sampled: false})
+
+	_, span := Start(ctx, "some-span")
+	span.End()
+
+	assert.Empty(t, exporter.Spans())
+}
+
+func Test_Span_End_twice(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	ctx := WithTracer(context.Background(), newTestTracer(exporter))
+
+	_, span := Start(ctx, "some-span")
+	span.End()
+	span.End()
+
+	assert.Len(t, exporter.Spans(), 1)
+}
@@ -0,0 +1,244 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/Peltoche/avro-gateway/logging"
+	"github.com/Peltoche/avro-gateway/model"
+)
+
+// Watcher poll the Schema Registry in order to detect the changes made on the
+// subjects used by the registered clients.
+type Watcher struct {
+	registry  Registry
+	storage   Storage
+	publisher Publisher
+	interval  time.Duration
+
+	// knownVersions contains the active versions seen during the last poll
+	// for each subject.
+	knownVersions map[string]map[int]bool
+	// pinnedStatus contains the last deletion event reported for each client.
+	pinnedStatus map[string]model.EventType
+}
+
+// Registry is used to list the subjects versions.
+type Registry interface {
+	ListVersions(ctx context.Context, subject string, includeDeleted bool) ([]int, error)
+}
+
+// Storage is used to retrieve the registered clients.
+type Storage interface {
+	GetAllClients(ctx context.Context) ([]model.Client, error)
+}
+
+// Publisher receive the detected events.
+type Publisher interface {
+	Publish(ctx context.Context, evt model.Event)
+}
+
+// NewWatcher instantiate a new Watcher.
+func NewWatcher(registry Registry, storage Storage, publisher Publisher, interval time.Duration) *Watcher {
+	return &Watcher{
+		registry:      registry,
+		storage:       storage,
+		publisher:     publisher,
+		interval:      interval,
+		knownVersions: map[string]map[int]bool{},
+		pinnedStatus:  map[string]model.EventType{},
+	}
+}
+
+// Run poll the Schema Registry at each interval until the context is canceled.
+func (t *Watcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for {
+		err := t.Poll(ctx)
+		if err != nil {
+			logging.Error(ctx, "failed to poll the registry", logging.Fields{"error": err})
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll fetch the versions of all the subjects in use and publish the events
+// corresponding to the changes since the previous poll.
+//
+// The first poll of a subject only record its versions: the versions
+// registered before the watcher started are not reported as new.
+//
+// Poll is not safe for concurrent use.
+func (t *Watcher) Poll(ctx context.Context) error {
+	clients, err := t.storage.GetAllClients(ctx)
+	if err != nil {
+		return internal.Wrap(err, "failed to retrieve the clients")
+	}
+
+	clientsBySubject := map[string][]model.Client{}
+	clientIDs := map[string]bool{}
+	for _, client := range clients {
+		clientsBySubject[client.Subject] = append(clientsBySubject[client.Subject], client)
+		clientIDs[client.ID] = true
+	}
+
+	subjects := make([]string, 0, len(clientsBySubject))
+	for subject := range clientsBySubject {
+		subjects = append(subjects, subject)
+	}
+	sort.Strings(subjects)
+
+	var firstErr error
+	for _, subject := range subjects {
+		err = t.pollSubject(ctx, subject, clientsBySubject[subject])
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	// Forget the subjects and the clients not present anymore.
+	for subject := range t.knownVersions {
+		if _, used := clientsBySubject[subject]; !used {
+			delete(t.knownVersions, subject)
+		}
+	}
+	for clientID := range t.pinnedStatus {
+		if !clientIDs[clientID] {
+			delete(t.pinnedStatus, clientID)
+		}
+	}
+
+	return firstErr
+}
+
+func (t *Watcher) pollSubject(ctx context.Context, subject string, clients []model.Client) error {
+	activeVersions, err := t.listVersions(ctx, subject, false)
+	if err != nil {
+		return internal.Wrapf(err, "failed to list the versions of the subject %q", subject)
+	}
+
+	allVersions, err := t.listVersions(ctx, subject, true)
+	if err != nil {
+		return internal.Wrapf(err, "failed to list the deleted versions of the subject %q", subject)
+	}
+
+	knownVersions, known := t.knownVersions[subject]
+	if known {
+		topics := topicsOf(clients)
+
+		for _, version := range sortedVersions(activeVersions) {
+			if knownVersions[version] {
+				continue
+			}
+
+			for _, topic := range topics {
+				t.publisher.Publish(ctx, model.Event{
+					Type:    model.EventVersionCreated,
+					Topic:   topic,
+					Subject: subject,
+					Version: strconv.Itoa(version),
+					Message: fmt.Sprintf("version %d of the subject %q has been registered", version, subject),
+				})
+			}
+		}
+	}
+	t.knownVersions[subject] = activeVersions
+
+	for _, client := range clients {
+		version, parseErr := strconv.Atoi(client.Version)
+		if parseErr != nil {
+			// The "latest" version can't be deleted.
+			continue
+		}
+
+		var status model.EventType
+		var description string
+		switch {
+		case activeVersions[version]:
+		case allVersions[version]:
+			status = model.EventVersionSoftDeleted
+			description = "soft-deleted"
+		default:
+			status = model.EventVersionHardDeleted
+			description = "permanently deleted"
+		}
+
+		if status == t.pinnedStatus[client.ID] {
+			continue
+		}
+
+		t.pinnedStatus[client.ID] = status
+		if status == "" {
+			continue
+		}
+
+		t.publisher.Publish(ctx, model.Event{
+			Type:        status,
+			Topic:       client.Topic,
+			Application: client.Application,
+			Action:      client.Action,
+			Subject:     client.Subject,
+			Version:     client.Version,
+			Message: fmt.Sprintf("version %s of the subject %q used by the application %q has been %s",
+				client.Version, client.Subject, client.Application, description),
+		})
+	}
+
+	return nil
+}
+
+// listVersions return the versions of the subject as a set. A subject not
+// found has no version.
+func (t *Watcher) listVersions(ctx context.Context, subject string, includeDeleted bool) (map[int]bool, error) {
+	versions, err := t.registry.ListVersions(ctx, subject, includeDeleted)
+	if internal.IsKind(internal.NotFound, err) {
+		return map[int]bool{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[int]bool, len(versions))
+	for _, version := range versions {
+		res[version] = true
+	}
+
+	return res, nil
+}
+
+func topicsOf(clients []model.Client) []string {
+	seen := map[string]bool{}
+	topics := []string{}
+	for _, client := range clients {
+		if !seen[client.Topic] {
+			seen[client.Topic] = true
+			topics = append(topics, client.Topic)
+		}
+	}
+
+	sort.Strings(topics)
+
+	return topics
+}
+
+func sortedVersions(versions map[int]bool) []int {
+	res := make([]int, 0, len(versions))
+	for version := range versions {
+		res = append(res, version)
+	}
+
+	sort.Ints(res)
+
+	return res
+}
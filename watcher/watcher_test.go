@@ -0,0 +1,160 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Peltoche/avro-gateway/event"
+	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/Peltoche/avro-gateway/model"
+	"github.com/Peltoche/avro-gateway/registry"
+	"github.com/Peltoche/avro-gateway/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+var watchedClient = model.Client{
+	ID:          "some-id",
+	Topic:       "some-topic",
+	Application: "some-app",
+	Action:      "read",
+	Subject:     "my-avro-subject",
+	Version:     "2",
+}
+
+func Test_Watcher_Poll_with_a_new_version(t *testing.T) {
+	registryMock := new(registry.Mock)
+	storageMock := new(storage.Mock)
+	publisherMock := new(event.Mock)
+
+	watcher := NewWatcher(registryMock, storageMock, publisherMock, 0)
+
+	storageMock.On("GetAllClients").Return([]model.Client{watchedClient}, nil).Twice()
+
+	// First poll: only record the state.
+	registryMock.On("ListVersions", "my-avro-subject", false).Return([]int{1, 2}, nil).Once()
+	registryMock.On("ListVersions", "my-avro-subject", true).Return([]int{1, 2}, nil).Once()
+
+	err := watcher.Poll(context.Background())
+	assert.NoError(t, err)
+
+	// Second poll: the version 3 is new.
+	registryMock.On("ListVersions", "my-avro-subject", false).Return([]int{1, 2, 3}, nil).Once()
+	registryMock.On("ListVersions", "my-avro-subject", true).Return([]int{1, 2, 3}, nil).Once()
+	publisherMock.On("Publish", model.Event{
+		Type:    model.EventVersionCreated,
+		Topic:   "some-topic",
+		Subject: "my-avro-subject",
+		Version: "3",
+		Message: `version 3 of the subject "my-avro-subject" has been registered`,
+	}).Once()
+
+	err = watcher.Poll(context.Background())
+	assert.NoError(t, err)
+
+	registryMock.AssertExpectations(t)
+	storageMock.AssertExpectations(t)
+	publisherMock.AssertExpectations(t)
+}
+
+func Test_Watcher_Poll_with_a_soft_deleted_then_hard_deleted_version(t *testing.T) {
+	registryMock := new(registry.Mock)
+	storageMock := new(storage.Mock)
+	publisherMock := new(event.Mock)
+
+	watcher := NewWatcher(registryMock, storageMock, publisherMock, 0)
+
+	storageMock.On("GetAllClients").Return([]model.Client{watchedClient}, nil).Times(3)
+
+	// The version 2 is soft-deleted.
+	registryMock.On("ListVersions", "my-avro-subject", false).Return([]int{1}, nil).Twice()
+	registryMock.On("ListVersions", "my-avro-subject", true).Return([]int{1, 2}, nil).Twice()
+	publisherMock.On("Publish", model.Event{
+		Type:        model.EventVersionSoftDeleted,
+		Topic:       "some-topic",
+		Application: "some-app",
+		Action:      "read",
+		Subject:     "my-avro-subject",
+		Version:     "2",
+		Message:     `version 2 of the subject "my-avro-subject" used by the application "some-app" has been soft-deleted`,
+	}).Once()
+
+	// Poll twice, the event must be sent only once.
+	assert.NoError(t, watcher.Poll(context.Background()))
+	assert.NoError(t, watcher.Poll(context.Background()))
+
+	// The whole subject is hard-deleted.
+	registryMock.On("ListVersions", "my-avro-subject", false).Return(nil, internal.NewError(internal.NotFound, "not found")).Once()
+	registryMock.On("ListVersions", "my-avro-subject", true).Return(nil, internal.NewError(internal.NotFound, "not found")).Once()
+	publisherMock.On("Publish", model.Event{
+		Type:        model.EventVersionHardDeleted,
+		Topic:       "some-topic",
+		Application: "some-app",
+		Action:      "read",
+		Subject:     "my-avro-subject",
+		Version:     "2",
+		Message:     `version 2 of the subject "my-avro-subject" used by the application "some-app" has been permanently deleted`,
+	}).Once()
+
+	assert.NoError(t, watcher.Poll(context.Background()))
+
+	registryMock.AssertExpectations(t)
+	storageMock.AssertExpectations(t)
+	publisherMock.AssertExpectations(t)
+}
+
+func Test_Watcher_Poll_with_a_latest_version(t *testing.T) {
+	registryMock := new(registry.Mock)
+	storageMock := new(storage.Mock)
+	publisherMock := new(event.Mock)
+
+	watcher := NewWatcher(registryMock, storageMock, publisherMock, 0)
+
+	client := watchedClient
+	client.Version = "latest"
+
+	storageMock.On("GetAllClients").Return([]model.Client{client}, nil).Once()
+	registryMock.On("ListVersions", "my-avro-subject", false).Return([]int{}, nil).Once()
+	registryMock.On("ListVersions", "my-avro-subject", true).Return([]int{}, nil).Once()
+
+	assert.NoError(t, watcher.Poll(context.Background()))
+
+	registryMock.AssertExpectations(t)
+	storageMock.AssertExpectations(t)
+	publisherMock.AssertExpectations(t)
+}
+
+func Test_Watcher_Poll_with_a_storage_error(t *testing.T) {
+	registryMock := new(registry.Mock)
+	storageMock := new(storage.Mock)
+	publisherMock := new(event.Mock)
+
+	watcher := NewWatcher(registryMock, storageMock, publisherMock, 0)
+
+	storageMock.On("GetAllClients").Return(nil, errors.New("some-error")).Once()
+
+	err := watcher.Poll(context.Background())
+	assert.EqualError(t, err, "internal error: failed to retrieve the clients: some-error")
+
+	registryMock.AssertExpectations(t)
+	storageMock.AssertExpectations(t)
+	publisherMock.AssertExpectations(t)
+}
+
+func Test_Watcher_Poll_with_a_registry_error(t *testing.T) {
+	registryMock := new(registry.Mock)
+	storageMock := new(storage.Mock)
+	publisherMock := new(event.Mock)
+
+	watcher := NewWatcher(registryMock, storageMock, publisherMock, 0)
+
+	storageMock.On("GetAllClients").Return([]model.Client{watchedClient}, nil).Once()
+	registryMock.On("ListVersions", "my-avro-subject", false).Return(nil, internal.NewError(internal.RemoteError, "some-error")).Once()
+
+	err := watcher.Poll(context.Background())
+	assert.EqualError(t, err, `remote error: failed to list the versions of the subject "my-avro-subject": some-error`)
+
+	registryMock.AssertExpectations(t)
+	storageMock.AssertExpectations(t)
+	publisherMock.AssertExpectations(t)
+}
@@ -0,0 +1,118 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/url"
+	"os"
+
+	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/Peltoche/avro-gateway/model"
+)
+
+// Hook is an url notified for each event matching its filters.
+type Hook struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+	// Secret used to sign the payloads.
+	Secret string `json:"secret"`
+	// Topics notified by the hook. The hook is global if empty.
+	Topics []string `json:"topics"`
+	// Events types notified by the hook. All the events are notified if empty.
+	Events []model.EventType `json:"events"`
+	// Namespaces notified by the hook, "" being the default namespace. All
+	// the namespaces are notified if empty.
+	Namespaces []string `json:"namespaces"`
+}
+
+// LoadConfig read the hooks list from a JSON file.
+func LoadConfig(path string) ([]Hook, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, internal.Errorf(internal.InternalError, "failed to open the webhooks config: %s", err)
+	}
+	defer file.Close()
+
+	hooks := []Hook{}
+	err = json.NewDecoder(file).Decode(&hooks)
+	if err != nil {
+		return nil, internal.Errorf(internal.ValidationError, "failed to decode the webhooks config: %s", err)
+	}
+
+	err = validateHooks(hooks)
+	if err != nil {
+		return nil, err
+	}
+
+	return hooks, nil
+}
+
+func validateHooks(hooks []Hook) error {
+	ids := map[string]bool{}
+	for i, hook := range hooks {
+		if hook.ID == "" {
+			return internal.Errorf(internal.ValidationError, `hook %d: missing field "id"`, i)
+		}
+		if ids[hook.ID] {
+			return internal.Errorf(internal.ValidationError, "hook %q: duplicated id", hook.ID)
+		}
+		ids[hook.ID] = true
+
+		hookURL, err := url.Parse(hook.URL)
+		if err != nil || (hookURL.Scheme != "http" && hookURL.Scheme != "https") {
+			return internal.Errorf(internal.ValidationError, `hook %q: invalid input for field "url"`, hook.ID)
+		}
+
+		if hook.Secret == "" {
+			return internal.Errorf(internal.ValidationError, `hook %q: missing field "secret"`, hook.ID)
+		}
+	}
+
+	return nil
+}
+
+// matches return true if the hook must be notified about the event.
+func (t *Hook) matches(evt *model.Event) bool {
+	return t.matchesNamespace(evt.Namespace) && t.matchesTopic(evt.Topic) && t.matchesType(evt.Type)
+}
+
+func (t *Hook) matchesNamespace(namespace string) bool {
+	if len(t.Namespaces) == 0 {
+		return true
+	}
+
+	for _, hookNamespace := range t.Namespaces {
+		if hookNamespace == namespace {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t *Hook) matchesTopic(topic string) bool {
+	if len(t.Topics) == 0 {
+		return true
+	}
+
+	for _, hookTopic := range t.Topics {
+		if hookTopic == topic {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t *Hook) matchesType(eventType model.EventType) bool {
+	if len(t.Events) == 0 {
+		return true
+	}
+
+	for _, hookEvent := range t.Events {
+		if hookEvent == eventType {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,106 @@
+package webhook
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/Peltoche/avro-gateway/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTempConfig(t *testing.T, content string) string {
+	file, err := ioutil.TempFile("", "webhooks")
+	require.NoError(t, err)
+	defer file.Close()
+
+	_, err = file.WriteString(content)
+	require.NoError(t, err)
+
+	return file.Name()
+}
+
+func Test_LoadConfig_success(t *testing.T) {
+	path := writeTempConfig(t, `[
+		{"id": "some-hook", "url": "http://example.com/hook", "secret": "some-secret", "topics": ["some-topic"], "events": ["client_registered"]},
+		{"id": "global-hook", "url": "https://example.com/global", "secret": "some-secret"}
+	]`)
+	defer os.Remove(path)
+
+	hooks, err := LoadConfig(path)
+
+	require.NoError(t, err)
+	assert.Equal(t, []Hook{
+		{ID: "some-hook", URL: "http://example.com/hook", Secret: "some-secret", Topics: []string{"some-topic"}, Events: []model.EventType{model.EventClientRegistered}},
+		{ID: "global-hook", URL: "https://example.com/global", Secret: "some-secret"},
+	}, hooks)
+}
+
+func Test_LoadConfig_with_a_missing_file(t *testing.T) {
+	hooks, err := LoadConfig("/some/unknown/path")
+
+	assert.Nil(t, hooks)
+	assert.EqualError(t, err, "internal error: failed to open the webhooks config: open /some/unknown/path: no such file or directory")
+}
+
+func Test_LoadConfig_with_invalid_hooks(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Config string
+		Error  string
+	}{
+		{
+			Name:   "invalid json",
+			Config: `foobar`,
+			Error:  "validation error: failed to decode the webhooks config: invalid character 'o' in literal false (expecting 'a')",
+		},
+		{
+			Name:   "missing id",
+			Config: `[{"url": "http://example.com", "secret": "some-secret"}]`,
+			Error:  `validation error: hook 0: missing field "id"`,
+		},
+		{
+			Name:   "duplicated id",
+			Config: `[{"id": "a", "url": "http://example.com", "secret": "s"}, {"id": "a", "url": "http://example.com", "secret": "s"}]`,
+			Error:  `validation error: hook "a": duplicated id`,
+		},
+		{
+			Name:   "invalid url",
+			Config: `[{"id": "a", "url": "ftp://example.com", "secret": "s"}]`,
+			Error:  `validation error: hook "a": invalid input for field "url"`,
+		},
+		{
+			Name:   "missing secret",
+			Config: `[{"id": "a", "url": "http://example.com"}]`,
+			Error:  `validation error: hook "a": missing field "secret"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			path := writeTempConfig(t, test.Config)
+			defer os.Remove(path)
+
+			hooks, err := LoadConfig(path)
+
+			assert.Nil(t, hooks)
+			assert.EqualError(t, err, test.Error)
+		})
+	}
+}
+
+func Test_Hook_matches(t *testing.T) {
+	hook := Hook{Topics: []string{"some-topic"}, Events: []model.EventType{model.EventClientRegistered}}
+
+	assert.True(t, hook.matches(&model.Event{Topic: "some-topic", Type: model.EventClientRegistered}))
+	assert.False(t, hook.matches(&model.Event{Topic: "some-other-topic", Type: model.EventClientRegistered}))
+	assert.False(t, hook.matches(&model.Event{Topic: "some-topic", Type: model.EventClientUpgraded}))
+
+	global := Hook{}
+	assert.True(t, global.matches(&model.Event{Topic: "some-other-topic", Type: model.EventClientUpgraded}))
+
+	defaultNamespace := Hook{Namespaces: []string{""}}
+	assert.True(t, defaultNamespace.matches(&model.Event{Topic: "some-topic", Type: model.EventClientRegistered}))
+	assert.False(t, defaultNamespace.matches(&model.Event{Namespace: "staging", Topic: "some-topic", Type: model.EventClientRegistered}))
+}
@@ -0,0 +1,261 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Peltoche/avro-gateway/logging"
+	"github.com/Peltoche/avro-gateway/model"
+	uuid "github.com/satori/go.uuid"
+)
+
+const (
+	// SignatureHeader contains the payload signature generated by Sign.
+	SignatureHeader = "X-Avro-Gateway-Signature"
+	// EventHeader contains the type of the event sent.
+	EventHeader = "X-Avro-Gateway-Event"
+	// DeliveryHeader contains the unique delivery id, the same for all the
+	// attempts.
+	DeliveryHeader = "X-Avro-Gateway-Delivery"
+
+	defaultMaxAttempts    = 5
+	defaultInitialBackoff = time.Second
+	defaultQueueSize      = 100
+	defaultLogSize        = 1000
+)
+
+// Delivery is a single attempt to notify a hook.
+type Delivery struct {
+	ID         string          `json:"id"`
+	HookID     string          `json:"hook_id"`
+	EventID    uint64          `json:"event_id"`
+	EventType  model.EventType `json:"event_type"`
+	Attempt    int             `json:"attempt"`
+	Time       time.Time       `json:"time"`
+	StatusCode int             `json:"status_code,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	Success    bool            `json:"success"`
+}
+
+// Dispatcher send the events to the matching hooks.
+//
+// Each hook has its own queue so a slow or failing receiver doesn't delay the
+// others. A failed delivery is retried with an exponential backoff.
+type Dispatcher struct {
+	hooks          []Hook
+	client         *http.Client
+	maxAttempts    int
+	initialBackoff time.Duration
+	queueSize      int
+	logSize        int
+
+	logMutex   *sync.Mutex
+	deliveries []Delivery
+
+	// Set the time and uuid generation functions as attributes in order to be
+	// able to mock them.
+	now          func() time.Time
+	generateUUID func() string
+}
+
+// NewDispatcher instantiate a new Dispatcher.
+func NewDispatcher(hooks []Hook) (*Dispatcher, error) {
+	err := validateHooks(hooks)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Dispatcher{
+		hooks:          hooks,
+		client:         &http.Client{Timeout: 10 * time.Second},
+		maxAttempts:    defaultMaxAttempts,
+		initialBackoff: defaultInitialBackoff,
+		queueSize:      defaultQueueSize,
+		logSize:        defaultLogSize,
+		logMutex:       new(sync.Mutex),
+		deliveries:     []Delivery{},
+		now:            time.Now,
+		generateUUID: func() string {
+			return uuid.NewV4().String()
+		},
+	}, nil
+}
+
+// Run dispatch the events received on the channel until the channel is closed
+// or the context is canceled.
+//
+// Once the channel is closed, Run wait for the already queued events to be
+// delivered before returning.
+func (t *Dispatcher) Run(ctx context.Context, events <-chan model.Event) {
+	wg := new(sync.WaitGroup)
+	queues := make([]chan model.Event, len(t.hooks))
+
+	for i := range t.hooks {
+		queues[i] = make(chan model.Event, t.queueSize)
+
+		wg.Add(1)
+		go func(hook *Hook, queue <-chan model.Event) {
+			defer wg.Done()
+
+			for evt := range queue {
+				t.deliver(ctx, hook, &evt)
+			}
+		}(&t.hooks[i], queues[i])
+	}
+
+	defer func() {
+		for _, queue := range queues {
+			close(queue)
+		}
+
+		wg.Wait()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case evt, open := <-events:
+			if !open {
+				return
+			}
+
+			for i := range t.hooks {
+				if !t.hooks[i].matches(&evt) {
+					continue
+				}
+
+				select {
+				case queues[i] <- evt:
+				default:
+					logging.Warn(ctx, "webhook queue full, event dropped", logging.Fields{"webhook": t.hooks[i].ID, "event": evt.ID})
+				}
+			}
+		}
+	}
+}
+
+// Deliveries return the last delivery attempts for the given hook, oldest
+// first. All the hooks are returned if hookID is empty.
+func (t *Dispatcher) Deliveries(hookID string) []Delivery {
+	t.logMutex.Lock()
+	defer t.logMutex.Unlock()
+
+	res := []Delivery{}
+	for _, delivery := range t.deliveries {
+		if hookID == "" || delivery.HookID == hookID {
+			res = append(res, delivery)
+		}
+	}
+
+	return res
+}
+
+// deliver send the event to the hook, retrying until it succeed, the maximum
+// number of attempts is reached or the context is canceled.
+func (t *Dispatcher) deliver(ctx context.Context, hook *Hook, evt *model.Event) {
+	body, err := json.Marshal(evt)
+	if err != nil {
+		logging.Error(ctx, "failed to encode the webhook event", logging.Fields{"webhook": hook.ID, "event": evt.ID, "error": err})
+		return
+	}
+
+	deliveryID := t.generateUUID()
+	backoff := t.initialBackoff
+
+	for attempt := 1; attempt <= t.maxAttempts; attempt++ {
+		delivery := Delivery{
+			ID:        deliveryID,
+			HookID:    hook.ID,
+			EventID:   evt.ID,
+			EventType: evt.Type,
+			Attempt:   attempt,
+			Time:      t.now().UTC(),
+		}
+
+		delivery.StatusCode, err = t.send(ctx, hook, deliveryID, evt.Type, body)
+		if err != nil {
+			delivery.Error = err.Error()
+		} else {
+			delivery.Success = true
+		}
+
+		t.record(delivery)
+
+		if delivery.Success || attempt == t.maxAttempts {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+	}
+}
+
+func (t *Dispatcher) send(ctx context.Context, hook *Hook, deliveryID string, eventType model.EventType, body []byte) (int, error) {
+	timestamp := t.now().Unix()
+
+	req, err := http.NewRequest("POST", hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(eventType))
+	req.Header.Set(DeliveryHeader, deliveryID)
+	req.Header.Set(SignatureHeader, Sign(hook.Secret, timestamp, body))
+
+	res, err := t.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	// Drain the body in order to reuse the connection.
+	_, _ = io.Copy(ioutil.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return res.StatusCode, fmt.Errorf("unexpected response status: %s", res.Status)
+	}
+
+	return res.StatusCode, nil
+}
+
+func (t *Dispatcher) record(delivery Delivery) {
+	t.logMutex.Lock()
+	defer t.logMutex.Unlock()
+
+	t.deliveries = append(t.deliveries, delivery)
+	if len(t.deliveries) > t.logSize {
+		t.deliveries = t.deliveries[len(t.deliveries)-t.logSize:]
+	}
+}
+
+// Sign generate the signature header value for a payload.
+//
+// The format is "t=<unix timestamp>,v1=<hex HMAC-SHA256>" where the HMAC is
+// computed with the hook secret over "<unix timestamp>.<payload>". Including
+// the timestamp allows the receivers to reject the replayed payloads.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	_, _ = mac.Write([]byte("."))
+	_, _ = mac.Write(body)
+
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, hex.EncodeToString(mac.Sum(nil)))
+}
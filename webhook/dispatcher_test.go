@@ -0,0 +1,185 @@
+package webhook
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Peltoche/avro-gateway/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var someDate = time.Date(2019, time.February, 3, 10, 0, 0, 0, time.UTC)
+
+var someEvent = model.Event{
+	ID:          42,
+	Type:        model.EventClientRegistered,
+	Time:        someDate,
+	Topic:       "some-topic",
+	Application: "some-app",
+}
+
+type receivedRequest struct {
+	Header http.Header
+	Body   string
+}
+
+// newReceiver start a local webhook receiver answering with the given status
+// codes, one per request.
+func newReceiver(t *testing.T, statusCodes ...int) (*httptest.Server, func() []receivedRequest) {
+	mutex := new(sync.Mutex)
+	received := []receivedRequest{}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		status := statusCodes[len(received)]
+		received = append(received, receivedRequest{Header: r.Header, Body: string(body)})
+		w.WriteHeader(status)
+	}))
+
+	return ts, func() []receivedRequest {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		return received
+	}
+}
+
+func newTestDispatcher(t *testing.T, hooks []Hook) *Dispatcher {
+	dispatcher, err := NewDispatcher(hooks)
+	require.NoError(t, err)
+
+	dispatcher.initialBackoff = time.Millisecond
+	dispatcher.now = func() time.Time { return someDate }
+	dispatcher.generateUUID = func() string { return "some-delivery-id" }
+
+	return dispatcher
+}
+
+func runDispatcher(dispatcher *Dispatcher, events ...model.Event) {
+	eventChan := make(chan model.Event, len(events))
+	for _, evt := range events {
+		eventChan <- evt
+	}
+	close(eventChan)
+
+	dispatcher.Run(context.Background(), eventChan)
+}
+
+func Test_Dispatcher_deliver_success(t *testing.T) {
+	ts, received := newReceiver(t, http.StatusOK)
+	defer ts.Close()
+
+	dispatcher := newTestDispatcher(t, []Hook{{ID: "some-hook", URL: ts.URL, Secret: "some-secret"}})
+
+	runDispatcher(dispatcher, someEvent)
+
+	requests := received()
+	require.Len(t, requests, 1)
+	assert.JSONEq(t, `{
+		"id": 42,
+		"type": "client_registered",
+		"time": "2019-02-03T10:00:00Z",
+		"topic": "some-topic",
+		"application": "some-app"
+	}`, requests[0].Body)
+	assert.Equal(t, "client_registered", requests[0].Header.Get(EventHeader))
+	assert.Equal(t, "some-delivery-id", requests[0].Header.Get(DeliveryHeader))
+	assert.Equal(t, Sign("some-secret", someDate.Unix(), []byte(requests[0].Body)), requests[0].Header.Get(SignatureHeader))
+
+	assert.Equal(t, []Delivery{
+		{ID: "some-delivery-id", HookID: "some-hook", EventID: 42, EventType: model.EventClientRegistered, Attempt: 1, Time: someDate, StatusCode: 200, Success: true},
+	}, dispatcher.Deliveries(""))
+}
+
+func Test_Dispatcher_deliver_with_retries(t *testing.T) {
+	ts, received := newReceiver(t, http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusNoContent)
+	defer ts.Close()
+
+	dispatcher := newTestDispatcher(t, []Hook{{ID: "some-hook", URL: ts.URL, Secret: "some-secret"}})
+
+	runDispatcher(dispatcher, someEvent)
+
+	assert.Len(t, received(), 3)
+
+	deliveries := dispatcher.Deliveries("some-hook")
+	require.Len(t, deliveries, 3)
+	assert.Equal(t, "unexpected response status: 500 Internal Server Error", deliveries[0].Error)
+	assert.False(t, deliveries[0].Success)
+	assert.Equal(t, 2, deliveries[1].Attempt)
+	assert.Equal(t, 503, deliveries[1].StatusCode)
+	assert.Equal(t, 3, deliveries[2].Attempt)
+	assert.True(t, deliveries[2].Success)
+}
+
+func Test_Dispatcher_deliver_give_up_after_max_attempts(t *testing.T) {
+	ts, received := newReceiver(t, 500, 500, 500)
+	defer ts.Close()
+
+	dispatcher := newTestDispatcher(t, []Hook{{ID: "some-hook", URL: ts.URL, Secret: "some-secret"}})
+	dispatcher.maxAttempts = 3
+
+	runDispatcher(dispatcher, someEvent)
+
+	assert.Len(t, received(), 3)
+	assert.Len(t, dispatcher.Deliveries("some-hook"), 3)
+}
+
+func Test_Dispatcher_with_filters(t *testing.T) {
+	ts, received := newReceiver(t, http.StatusOK)
+	defer ts.Close()
+
+	dispatcher := newTestDispatcher(t, []Hook{
+		{ID: "topic-hook", URL: ts.URL, Secret: "some-secret", Topics: []string{"some-other-topic"}},
+		{ID: "event-hook", URL: ts.URL, Secret: "some-secret", Events: []model.EventType{model.EventClientRegistered}},
+	})
+
+	runDispatcher(dispatcher, someEvent)
+
+	assert.Len(t, received(), 1)
+	assert.Empty(t, dispatcher.Deliveries("topic-hook"))
+	assert.Len(t, dispatcher.Deliveries("event-hook"), 1)
+}
+
+func Test_Dispatcher_delivery_log_is_bounded(t *testing.T) {
+	ts, _ := newReceiver(t, 200, 200, 200)
+	defer ts.Close()
+
+	dispatcher := newTestDispatcher(t, []Hook{{ID: "some-hook", URL: ts.URL, Secret: "some-secret"}})
+	dispatcher.logSize = 2
+
+	evt2 := someEvent
+	evt2.ID = 43
+	evt3 := someEvent
+	evt3.ID = 44
+
+	runDispatcher(dispatcher, someEvent, evt2, evt3)
+
+	deliveries := dispatcher.Deliveries("")
+	require.Len(t, deliveries, 2)
+	assert.Equal(t, uint64(43), deliveries[0].EventID)
+	assert.Equal(t, uint64(44), deliveries[1].EventID)
+}
+
+func Test_NewDispatcher_with_an_invalid_hook(t *testing.T) {
+	dispatcher, err := NewDispatcher([]Hook{{ID: "some-hook"}})
+
+	assert.Nil(t, dispatcher)
+	assert.EqualError(t, err, `validation error: hook "some-hook": invalid input for field "url"`)
+}
+
+func Test_Sign(t *testing.T) {
+	assert.Equal(t,
+		"t=1549188000,v1=0de853dcad04eb7c2060caf71dd0bb3cf3f26fa477aedfd39226f797797b7ef5",
+		Sign("some-secret", 1549188000, []byte(`{"foo":"bar"}`)))
+}
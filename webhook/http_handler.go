@@ -0,0 +1,54 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Peltoche/avro-gateway/auth"
+	"github.com/Peltoche/avro-gateway/internal"
+	"github.com/Peltoche/avro-gateway/logging"
+	"github.com/gorilla/mux"
+)
+
+// HTTPHandler exposing the webhooks delivery log to the admins.
+type HTTPHandler struct {
+	dispatcher dispatcher
+	// admins is the list of applications allowed to read the deliveries. No
+	// one can read them if empty.
+	admins []string
+}
+
+type dispatcher interface {
+	Deliveries(hookID string) []Delivery
+}
+
+// NewHTTPHandler instantiate a new HTTPHandler.
+func NewHTTPHandler(dispatcher dispatcher, admins []string) *HTTPHandler {
+	return &HTTPHandler{
+		dispatcher: dispatcher,
+		admins:     admins,
+	}
+}
+
+// RegisterRoutes into the givem mux.Router.
+func (t *HTTPHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/webhooks/deliveries", t.GetDeliveries).Methods("GET")
+}
+
+// GetDeliveries /webhooks/deliveries?hook={hookID}
+func (t *HTTPHandler) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+	err := auth.CheckAdmin(r.Context(), t.admins, "webhook deliveries API")
+	if err != nil {
+		internal.WriteErrorIntoResponse(w, err)
+		return
+	}
+
+	deliveries := t.dispatcher.Deliveries(r.URL.Query().Get("hook"))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	err = json.NewEncoder(w).Encode(deliveries)
+	if err != nil {
+		logging.Warn(r.Context(), "failed to write the response", logging.Fields{"error": err})
+	}
+}
@@ -0,0 +1,81 @@
+package webhook
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Peltoche/avro-gateway/auth"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_HTTPHandler_GetDeliveries_success(t *testing.T) {
+	dispatcher := newTestDispatcher(t, []Hook{{ID: "some-hook", URL: "http://example.com", Secret: "some-secret"}})
+	dispatcher.record(Delivery{ID: "some-delivery-id", HookID: "some-hook", EventID: 42, EventType: "client_registered", Attempt: 1, Time: someDate, StatusCode: 200, Success: true})
+	dispatcher.record(Delivery{ID: "some-other-id", HookID: "some-other-hook", EventID: 43, EventType: "client_registered", Attempt: 1, Time: someDate, StatusCode: 200, Success: true})
+
+	handler := NewHTTPHandler(dispatcher, []string{"admin-app"})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com/webhooks/deliveries?hook=some-hook", nil)
+	r = r.WithContext(auth.WithPrincipal(context.Background(), &auth.Principal{Application: "admin-app", Method: auth.APIKeyMethod}))
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	res := w.Result()
+	body, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.JSONEq(t, `[{
+		"id": "some-delivery-id",
+		"hook_id": "some-hook",
+		"event_id": 42,
+		"event_type": "client_registered",
+		"attempt": 1,
+		"time": "2019-02-03T10:00:00Z",
+		"status_code": 200,
+		"success": true
+	}]`, string(body))
+}
+
+func Test_HTTPHandler_GetDeliveries_with_a_non_admin_caller(t *testing.T) {
+	dispatcher := newTestDispatcher(t, []Hook{{ID: "some-hook", URL: "http://example.com", Secret: "some-secret"}})
+
+	handler := NewHTTPHandler(dispatcher, []string{"admin-app"})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com/webhooks/deliveries", nil)
+	r = r.WithContext(auth.WithPrincipal(context.Background(), &auth.Principal{Application: "some-app", Method: auth.APIKeyMethod}))
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	res := w.Result()
+
+	assert.Equal(t, http.StatusForbidden, res.StatusCode)
+}
+
+func Test_HTTPHandler_GetDeliveries_with_an_anonymous_caller(t *testing.T) {
+	dispatcher := newTestDispatcher(t, []Hook{{ID: "some-hook", URL: "http://example.com", Secret: "some-secret"}})
+
+	handler := NewHTTPHandler(dispatcher, []string{"admin-app"})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com/webhooks/deliveries", nil)
+
+	router := mux.NewRouter()
+	handler.RegisterRoutes(router)
+	router.ServeHTTP(w, r)
+
+	res := w.Result()
+
+	assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+}